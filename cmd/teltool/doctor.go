@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+// defaultTimeout bounds each step of a probe
+const defaultTimeout = 5 * time.Second
+
+// certExpiryWarning is how long before its expiry a certificate is reported
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// rootCAs verifies server certificates, the system pool if nil
+var rootCAs *x509.CertPool
+
+// status is the outcome of a single check
+type status string
+
+const (
+	statusOK   status = "ok"
+	statusWarn status = "warn"
+	statusFail status = "FAIL"
+)
+
+// check is the outcome of one step of a probe
+type check struct {
+	status  status
+	message string
+}
+
+// target is the endpoint of the exporter of a signal
+type target struct {
+	signal   string
+	endpoint string
+	headers  map[string]string
+	grpc     bool
+	insecure bool
+
+	// prometheus endpoints are scraped from the application, not pushed to
+	prometheus bool
+}
+
+// runDoctor probes the endpoints of the exporters of all enabled signals
+func runDoctor(file string, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	timeout := flags.Duration("timeout", defaultTimeout, "timeout of each probe step")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg, used, err := loadConfig(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+	if used != "" {
+		fmt.Fprintf(stdout, "configuration: %s\n", used)
+	}
+	if !cfg.IsEnabled() {
+		fmt.Fprintln(stdout, "telemetry is disabled, nothing is exported")
+		return exitOK
+	}
+
+	failed := false
+	for _, signal := range signalExporters(cfg) {
+		fmt.Fprintf(stdout, "\n%s: %s\n", signal.name, describe(signal.exporter))
+		if signal.exporter == nil {
+			printCheck(stdout, check{statusFail, "no exporter configured"})
+			failed = true
+			continue
+		}
+
+		t, ok := newTarget(signal.name, signal.exporter)
+		if !ok {
+			printCheck(stdout, check{statusOK, "no endpoint to probe"})
+			continue
+		}
+		for _, c := range probe(context.Background(), t, *timeout) {
+			printCheck(stdout, c)
+			failed = failed || c.status == statusFail
+		}
+	}
+
+	if failed {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// signalExporter is the exporter of an enabled signal
+type signalExporter struct {
+	name     string
+	exporter *config.ExporterConfig
+}
+
+// signalExporters returns the exporters of the enabled signals
+func signalExporters(cfg *config.Config) []signalExporter {
+	var signals []signalExporter
+	if cfg.Tracing != nil && cfg.Tracing.Enabled {
+		signals = append(signals, signalExporter{"traces", cfg.Tracing.Exporter})
+	}
+	if cfg.Metrics != nil && cfg.Metrics.Enabled {
+		signals = append(signals, signalExporter{"metrics", cfg.Metrics.Exporter})
+	}
+	if cfg.Logging != nil && cfg.Logging.Enabled {
+		signals = append(signals, signalExporter{"logs", cfg.Logging.Exporter})
+	}
+	return signals
+}
+
+// describe names the module and class of an exporter
+func describe(exporter *config.ExporterConfig) string {
+	if exporter == nil {
+		return "none"
+	}
+	if exporter.Class == "" {
+		return exporter.Module
+	}
+	return fmt.Sprintf("%s (%s)", exporter.Module, exporter.Class)
+}
+
+// printCheck prints a check indented below its signal
+func printCheck(w io.Writer, c check) {
+	fmt.Fprintf(w, "  [%-4s] %s\n", c.status, c.message)
+}
+
+// newTarget derives the endpoint to probe from the exporter config and the
+// standard OTEL_EXPORTER_OTLP_* variables. Exporters without an endpoint,
+// e.g. console, yield false.
+func newTarget(signal string, exporter *config.ExporterConfig) (target, bool) {
+	module := strings.ToLower(exporter.Module)
+	t := target{
+		signal:     signal,
+		headers:    map[string]string{},
+		grpc:       strings.Contains(module, "grpc"),
+		prometheus: strings.Contains(module, "prometheus"),
+	}
+
+	for _, key := range []string{"endpoint", "url", "collector_endpoint"} {
+		if value, ok := exporter.Config[key].(string); ok && value != "" {
+			t.endpoint = value
+			break
+		}
+	}
+	if strings.Contains(module, "otlp") {
+		if value := os.Getenv("OTEL_EXPORTER_OTLP_" + strings.ToUpper(signal) + "_ENDPOINT"); value != "" {
+			t.endpoint = value
+		} else if value := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); value != "" && t.endpoint == "" {
+			t.endpoint = appendSignalPath(value, signal, t.grpc)
+		}
+		for key, value := range parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")) {
+			t.headers[key] = value
+		}
+	}
+	if t.prometheus && t.endpoint == "" {
+		port := 9464
+		if value, ok := exporter.Config["port"]; ok {
+			if p, err := strconv.Atoi(fmt.Sprint(value)); err == nil {
+				port = p
+			}
+		}
+		path := "/metrics"
+		if value, ok := exporter.Config["endpoint_path"].(string); ok && value != "" {
+			path = value
+		}
+		t.endpoint = fmt.Sprintf("http://localhost:%d%s", port, path)
+	}
+	if t.endpoint == "" {
+		return t, false
+	}
+
+	if headers, ok := exporter.Config["headers"].(map[string]interface{}); ok {
+		for key, value := range headers {
+			t.headers[key] = fmt.Sprint(value)
+		}
+	}
+	if secret, ok := exporter.Secret("token"); ok {
+		if token, err := secret.Value(); err == nil && token != "" {
+			t.headers["Authorization"] = "Bearer " + token
+		}
+	}
+	if insecure, ok := exporter.Config["insecure"].(bool); ok {
+		t.insecure = insecure
+	}
+	return t, true
+}
+
+// appendSignalPath appends /v1/<signal> to a base OTLP/HTTP endpoint, as
+// the exporters do for OTEL_EXPORTER_OTLP_ENDPOINT
+func appendSignalPath(endpoint, signal string, grpc bool) string {
+	if grpc {
+		return endpoint
+	}
+	return strings.TrimRight(endpoint, "/") + "/v1/" + signal
+}
+
+// parseHeaders parses key=value pairs separated by commas
+func parseHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = unescaped
+		}
+		headers[strings.TrimSpace(key)] = value
+	}
+	return headers
+}
+
+// probe checks name resolution, the connection, the TLS certificate and,
+// for HTTP endpoints, the response including authentication. It stops at
+// the first failure, as later steps can't succeed.
+func probe(ctx context.Context, t target, timeout time.Duration) []check {
+	host, port, scheme, err := splitEndpoint(t)
+	if err != nil {
+		return []check{{statusFail, err.Error()}}
+	}
+	var checks []check
+	fail := func(format string, args ...interface{}) []check {
+		return append(checks, check{statusFail, fmt.Sprintf(format, args...)})
+	}
+
+	// Name resolution
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	addrs, err := net.DefaultResolver.LookupHost(lookupCtx, host)
+	cancel()
+	if err != nil {
+		return fail("cannot resolve %s: %v", host, err)
+	}
+	checks = append(checks, check{statusOK, fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))})
+
+	// Connection
+	address := net.JoinHostPort(host, port)
+	start := time.Now()
+	conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		if t.prometheus {
+			return fail("cannot connect to %s, is the application running? %v", address, err)
+		}
+		return fail("cannot connect to %s: %v", address, err)
+	}
+	checks = append(checks, check{statusOK, fmt.Sprintf("connected to %s in %v", address, time.Since(start).Round(time.Millisecond))})
+
+	// TLS
+	useTLS := scheme == "https" || (t.grpc && scheme != "http" && !t.insecure)
+	if useTLS {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, RootCAs: rootCAs, MinVersion: tls.VersionTLS12})
+		_ = tlsConn.SetDeadline(time.Now().Add(timeout))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return fail("TLS handshake failed: %s", describeTLSError(err))
+		}
+		checks = append(checks, certificateCheck(tlsConn.ConnectionState(), time.Now()))
+		tlsConn.Close()
+	} else {
+		conn.Close()
+		if !t.prometheus && !isLoopback(host) {
+			checks = append(checks, check{statusWarn, "the connection is not encrypted"})
+		}
+	}
+
+	if t.grpc {
+		return append(checks, check{statusOK, "gRPC endpoint is reachable; authentication is checked on the first export"})
+	}
+	return append(checks, httpCheck(ctx, t, timeout))
+}
+
+// splitEndpoint returns the host, port and scheme of the target. gRPC
+// endpoints may be given as host:port.
+func splitEndpoint(t target) (host, port, scheme string, err error) {
+	endpoint := t.endpoint
+	if !strings.Contains(endpoint, "://") {
+		if !t.grpc {
+			return "", "", "", fmt.Errorf("endpoint %q has no scheme, expected http:// or https://", endpoint)
+		}
+		endpoint = "grpc://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid endpoint %q: %v", t.endpoint, err)
+	}
+	if u.Hostname() == "" {
+		return "", "", "", fmt.Errorf("endpoint %q has no host", t.endpoint)
+	}
+
+	port = u.Port()
+	if port == "" {
+		switch {
+		case u.Scheme == "https":
+			port = "443"
+		case u.Scheme == "http":
+			port = "80"
+		case t.grpc:
+			port = "4317"
+		}
+	}
+	return u.Hostname(), port, u.Scheme, nil
+}
+
+// describeTLSError explains common certificate errors
+func describeTLSError(err error) string {
+	var unknown x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &unknown):
+		return "the certificate is signed by an unknown authority, install the CA certificate"
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("the certificate is not valid for %s: %v", hostname.Host, err)
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return fmt.Sprintf("the certificate has expired: %v", err)
+	default:
+		return err.Error()
+	}
+}
+
+// certificateCheck reports the server certificate and warns before it expires
+func certificateCheck(state tls.ConnectionState, now time.Time) check {
+	if len(state.PeerCertificates) == 0 {
+		return check{statusWarn, "no server certificate"}
+	}
+	cert := state.PeerCertificates[0]
+	message := fmt.Sprintf("TLS certificate for %s valid until %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+	if cert.Subject.CommonName == "" && len(cert.DNSNames) > 0 {
+		message = fmt.Sprintf("TLS certificate for %s valid until %s", cert.DNSNames[0], cert.NotAfter.Format(time.DateOnly))
+	}
+	if cert.NotAfter.Sub(now) < certExpiryWarning {
+		return check{statusWarn, message + ", expires soon"}
+	}
+	return check{statusOK, message}
+}
+
+// httpCheck sends an empty export request with the configured headers.
+// Collectors reject the empty payload, but they authenticate first, so 401
+// and 403 reveal missing or wrong credentials and 404 a wrong path.
+func httpCheck(ctx context.Context, t target, timeout time.Duration) check {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := http.MethodPost
+	if t.prometheus {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.endpoint, nil)
+	if err != nil {
+		return check{statusFail, fmt.Sprintf("invalid endpoint: %v", err)}
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	}
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}},
+		// Redirects are not followed by exporters
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return check{statusFail, fmt.Sprintf("request failed: %v", err)}
+	}
+	resp.Body.Close()
+
+	sent := "without headers"
+	if len(t.headers) > 0 {
+		names := make([]string, 0, len(t.headers))
+		for key := range t.headers {
+			names = append(names, http.CanonicalHeaderKey(key))
+		}
+		sort.Strings(names)
+		sent = "with headers " + strings.Join(names, ", ")
+	}
+
+	code := resp.StatusCode
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return check{statusFail, fmt.Sprintf("%s %s: authentication failed, check the credentials", req.Method, resp.Status) + " (sent " + sent + ")"}
+	case code == http.StatusNotFound:
+		return check{statusFail, fmt.Sprintf("%s %s: check the path of the endpoint", req.Method, resp.Status)}
+	case code >= 300 && code < 400:
+		return check{statusFail, fmt.Sprintf("%s %s: redirected to %s, configure the final URL", req.Method, resp.Status, resp.Header.Get("Location"))}
+	case code >= 500:
+		return check{statusWarn, fmt.Sprintf("%s %s: the endpoint has a problem", req.Method, resp.Status)}
+	default:
+		return check{statusOK, fmt.Sprintf("%s %s: endpoint accepts requests %s", req.Method, resp.Status, sent)}
+	}
+}
+
+// isLoopback returns whether host is localhost or a loopback address
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
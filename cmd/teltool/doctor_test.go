@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+// newCollector starts a TLS server requiring the given API token
+func newCollector(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/v1/traces":
+			http.NotFound(w, r)
+		case r.Header.Get("Authorization") != "Api-Token "+token:
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			// An empty payload is not a valid export request
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	rootCAs = pool
+	t.Cleanup(func() { rootCAs = nil })
+	return server
+}
+
+func TestProbe(t *testing.T) {
+	server := newCollector(t, "s3cr3t")
+
+	checks := probe(context.Background(), target{
+		signal:   "traces",
+		endpoint: server.URL + "/v1/traces",
+		headers:  map[string]string{"Authorization": "Api-Token s3cr3t"},
+	}, time.Second)
+	if len(checks) != 4 {
+		t.Fatalf("Expected resolution, connection, TLS and HTTP checks, got %+v", checks)
+	}
+	for _, c := range checks {
+		if c.status != statusOK {
+			t.Errorf("Expected all checks to pass, got %+v", c)
+		}
+	}
+	if !strings.Contains(checks[2].message, "TLS certificate") {
+		t.Errorf("Expected the certificate to be reported, got %s", checks[2].message)
+	}
+
+	checks = probe(context.Background(), target{signal: "traces", endpoint: server.URL + "/v1/traces"}, time.Second)
+	last := checks[len(checks)-1]
+	if last.status != statusFail || !strings.Contains(last.message, "authentication failed") {
+		t.Errorf("Expected authentication to fail without token, got %+v", last)
+	}
+
+	checks = probe(context.Background(), target{signal: "traces", endpoint: server.URL + "/traces"}, time.Second)
+	if last := checks[len(checks)-1]; last.status != statusFail || !strings.Contains(last.message, "path") {
+		t.Errorf("Expected a wrong path to be reported, got %+v", last)
+	}
+}
+
+func TestProbeFailures(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	checks := probe(context.Background(), target{signal: "traces", endpoint: server.URL}, time.Second)
+	if last := checks[len(checks)-1]; last.status != statusFail || !strings.Contains(last.message, "unknown authority") {
+		t.Errorf("Expected an untrusted certificate to fail, got %+v", checks)
+	}
+
+	checks = probe(context.Background(), target{signal: "traces", endpoint: "collector:4318"}, time.Second)
+	if len(checks) != 1 || !strings.Contains(checks[0].message, "no scheme") {
+		t.Errorf("Expected an endpoint without scheme to be rejected, got %+v", checks)
+	}
+
+	checks = probe(context.Background(), target{signal: "traces", endpoint: "http://127.0.0.1:1/v1/traces"}, time.Second)
+	if last := checks[len(checks)-1]; last.status != statusFail || !strings.Contains(last.message, "cannot connect") {
+		t.Errorf("Expected a closed port to fail, got %+v", checks)
+	}
+}
+
+func TestNewTarget(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector.example.com/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-tenant=acme")
+
+	target, ok := newTarget("metrics", &config.ExporterConfig{
+		Module: "otlp",
+		Config: map[string]interface{}{"headers": map[string]interface{}{"x-team": "checkout"}},
+	})
+	if !ok || target.endpoint != "https://collector.example.com/v1/metrics" {
+		t.Errorf("Expected the signal path appended to the OTLP endpoint, got %q", target.endpoint)
+	}
+	if target.headers["x-tenant"] != "acme" || target.headers["x-team"] != "checkout" {
+		t.Errorf("Expected headers from the environment and the config, got %v", target.headers)
+	}
+
+	if _, ok := newTarget("traces", &config.ExporterConfig{Module: "console"}); ok {
+		t.Error("Expected no target for the console exporter")
+	}
+
+	target, ok = newTarget("metrics", &config.ExporterConfig{Module: "prometheus", Config: map[string]interface{}{"port": 9090}})
+	if !ok || target.endpoint != "http://localhost:9090/metrics" {
+		t.Errorf("Expected the local scrape endpoint, got %q", target.endpoint)
+	}
+}
+
+func TestCertificateCheck(t *testing.T) {
+	now := time.Now()
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{{NotAfter: now.Add(48 * time.Hour), DNSNames: []string{"collector"}}}}
+	if c := certificateCheck(state, now); c.status != statusWarn || !strings.Contains(c.message, "expires soon") {
+		t.Errorf("Expected a warning before expiry, got %+v", c)
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	server := newCollector(t, "s3cr3t")
+	file := writeConfig(t, `
+tracing:
+  exporter:
+    module: otlp
+    config:
+      endpoint: `+server.URL+`/v1/traces
+      headers:
+        authorization: Api-Token s3cr3t
+metrics:
+  exporter:
+    module: console
+`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", file, "doctor", "-timeout", "2s"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected doctor to pass, got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "traces: otlp") || !strings.Contains(out, "no endpoint to probe") {
+		t.Errorf("Expected a report per signal, got %s", out)
+	}
+}
@@ -0,0 +1,210 @@
+// Command teltool checks the telemetry configuration of an application and
+// the reachability of its exporter endpoints.
+//
+//	teltool [-config telemetry.yaml] validate
+//	teltool [-config telemetry.yaml] print-config [-format json|yaml] [-env]
+//	teltool [-config telemetry.yaml] doctor [-timeout 5s]
+//	teltool version
+//
+// Without -config, the configuration file is searched for like the
+// telemetry package does, and TELEMETRY_* environment variables apply.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/iklimetscisco/cap-go-telemetry/internal/version"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `Usage: teltool [-config file] <command> [flags]
+
+Commands:
+  validate      check the configuration file and load the configuration
+  print-config  print the effective configuration with secrets masked
+  doctor        probe the configured exporter endpoints
+  version       print the version
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("teltool", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	configFile := flags.String("config", "", "configuration file, searched for if unset")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "validate":
+		return runValidate(*configFile, args, stdout, stderr)
+	case "print-config":
+		return runPrintConfig(*configFile, args, stdout, stderr)
+	case "doctor":
+		return runDoctor(*configFile, args, stdout, stderr)
+	case "version":
+		fmt.Fprintln(stdout, version.Get())
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", command)
+		flags.Usage()
+		return exitUsage
+	}
+}
+
+// loadConfig loads the configuration from file, or searches for the
+// configuration file if it is empty. It returns the file used, if any.
+func loadConfig(file string) (*config.Config, string, error) {
+	loader := config.NewLoader()
+	var cfg *config.Config
+	var err error
+	if file != "" {
+		cfg, err = loader.LoadFromFile(file)
+	} else {
+		cfg, err = loader.Load()
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, loader.GetConfigFile(), nil
+}
+
+// runValidate checks the configuration file against the schema, then loads
+// the configuration to catch invalid combinations and environment variables
+func runValidate(file string, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if file != "" {
+		if err := config.Validate(file); err != nil {
+			printErrors(stderr, err)
+			return exitFailed
+		}
+	}
+	_, used, err := loadConfig(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+	if file == "" && used != "" {
+		if err := config.Validate(used); err != nil {
+			printErrors(stderr, err)
+			return exitFailed
+		}
+	}
+
+	if used == "" {
+		fmt.Fprintln(stdout, "no configuration file found, defaults and environment variables are valid")
+	} else {
+		fmt.Fprintf(stdout, "%s is valid\n", used)
+	}
+	return exitOK
+}
+
+// printErrors prints joined errors one per line
+func printErrors(w io.Writer, err error) {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		for _, e := range joined.Unwrap() {
+			fmt.Fprintln(w, e)
+		}
+		return
+	}
+	fmt.Fprintln(w, err)
+}
+
+// runPrintConfig prints the effective configuration with secrets masked,
+// and optionally the environment variables overriding single settings
+func runPrintConfig(file string, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("print-config", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "yaml", "output format, yaml or json")
+	env := flags.Bool("env", false, "also list the environment variables of all settings and which are set")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != "yaml" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q, expected yaml or json\n", *format)
+		return exitUsage
+	}
+
+	cfg, used, err := loadConfig(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+	masked, err := cfg.Masked()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(masked)
+	} else {
+		if used != "" {
+			fmt.Fprintf(stdout, "# loaded from %s\n", used)
+		}
+		encoder := yaml.NewEncoder(stdout)
+		encoder.SetIndent(2)
+		err = encoder.Encode(masked)
+		if err == nil {
+			err = encoder.Close()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to print config: %v\n", err)
+		return exitFailed
+	}
+
+	if *env {
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "# environment variables")
+		names := config.EnvVars()
+		instrumentations := make([]string, 0, len(cfg.Instrumentations))
+		for name := range cfg.Instrumentations {
+			instrumentations = append(instrumentations, config.InstrumentationEnv(name))
+		}
+		sort.Strings(instrumentations)
+		for _, name := range append(names, instrumentations...) {
+			if _, ok := os.LookupEnv(name); ok {
+				fmt.Fprintf(stdout, "# %s (set)\n", name)
+			} else {
+				fmt.Fprintf(stdout, "# %s\n", name)
+			}
+		}
+	}
+	return exitOK
+}
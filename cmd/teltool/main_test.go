@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a configuration file into a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "telemetry.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected usage exit code without command, got %d", code)
+	}
+	if code := run([]string{"frobnicate"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected usage exit code for unknown command, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "print-config") {
+		t.Errorf("Expected usage listing the commands, got %s", stderr.String())
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	valid := writeConfig(t, "service_name: bookshop\ntracing:\n  processor: simple\n")
+	if code := run([]string{"-config", valid, "validate"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected valid config, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "is valid") {
+		t.Errorf("Expected confirmation, got %s", stdout.String())
+	}
+
+	stdout.Reset()
+	invalid := writeConfig(t, "tracing:\n  procesor: simple\n  sampler:\n    ratio: high\n")
+	if code := run([]string{"-config", invalid, "validate"}, &stdout, &stderr); code != exitFailed {
+		t.Errorf("Expected invalid config to fail, got %d", code)
+	}
+	for _, expected := range []string{"did you mean processor", "tracing.sampler.ratio"} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("Expected %q in %s", expected, stderr.String())
+		}
+	}
+}
+
+func TestRunPrintConfig(t *testing.T) {
+	file := writeConfig(t, `
+service_name: bookshop
+tracing:
+  exporter:
+    module: console
+    config:
+      token: s3cr3t
+instrumentations:
+  http_client:
+    enabled: true
+`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", file, "print-config"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("print-config failed with %d: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "service_name: bookshop") || !strings.Contains(out, "# loaded from "+file) {
+		t.Errorf("Expected the effective config as YAML, got %s", out)
+	}
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("Expected the token to be masked, got %s", out)
+	}
+
+	stdout.Reset()
+	t.Setenv("TELEMETRY_TRACING_PROCESSOR", "simple")
+	if code := run([]string{"-config", file, "print-config", "-format", "json", "-env"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("print-config failed with %d: %s", code, stderr.String())
+	}
+	out = stdout.String()
+	if !strings.Contains(out, `"processor": "simple"`) || !strings.Contains(out, "# TELEMETRY_TRACING_PROCESSOR (set)") {
+		t.Errorf("Expected JSON and the environment variables, got %s", out)
+	}
+	if !strings.Contains(out, "# TELEMETRY_INSTRUMENTATION_HTTP_CLIENT_ENABLED\n") {
+		t.Errorf("Expected the kill switch of the configured instrumentation, got %s", out)
+	}
+
+	if code := run([]string{"print-config", "-format", "xml"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected unknown format to be rejected, got %d", code)
+	}
+}
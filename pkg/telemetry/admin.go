@@ -0,0 +1,190 @@
+package telemetry
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/audit"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+// AdminPath is the path AdminHandler is mounted on
+const AdminPath = "/__telemetry/"
+
+// maxAdminBody limits the size of admin request bodies
+const maxAdminBody = 1 << 10
+
+// logLevelSetting is the document of the loglevel endpoint
+type logLevelSetting struct {
+	Level string `json:"level"`
+}
+
+// samplingSetting is the document of the sampling endpoint
+type samplingSetting struct {
+	Ratio *float64 `json:"ratio"`
+}
+
+// AdminHandler lets operators change the log level and sampling ratio at
+// runtime, as SetLogLevel and SetSamplingRatio do:
+//
+//	PUT /__telemetry/loglevel  {"level": "debug"}
+//	PUT /__telemetry/sampling  {"ratio": 0.5}
+//
+// GET returns the current setting. Requests must send the token as
+// "Authorization: Bearer <token>"; an empty token disables the handler.
+// Changes are recorded as configuration change audit events and are
+// overridden by the next poll of a remote configuration. Mount it on
+// AdminPath:
+//
+//	mux.Handle(telemetry.AdminPath, tel.AdminHandler(os.Getenv("TELEMETRY_ADMIN_TOKEN")))
+func (t *Telemetry) AdminHandler(token string) http.Handler {
+	if token == "" {
+		return http.NotFoundHandler()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(AdminPath+"loglevel", t.serveLogLevel)
+	mux.HandleFunc(AdminPath+"sampling", t.serveSampling)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validAdminToken(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="telemetry"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// validAdminToken reports whether r carries token as bearer token
+func validAdminToken(r *http.Request, token string) bool {
+	scheme, credentials, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) == 1
+}
+
+// serveLogLevel serves and changes the minimum exported log severity
+func (t *Telemetry) serveLogLevel(w http.ResponseWriter, r *http.Request) {
+	if t.logLevel == nil {
+		http.Error(w, "logging is not enabled", http.StatusConflict)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		t.settingsMu.Lock()
+		setting := logLevelSetting{Level: t.config.Logging.Level}
+		t.settingsMu.Unlock()
+		writeAdminSetting(w, setting)
+	case http.MethodPut:
+		var setting logLevelSetting
+		if !decodeAdminSetting(w, r, &setting) {
+			return
+		}
+		t.settingsMu.Lock()
+		previous := t.config.Logging.Level
+		err := t.setLogLevel(setting.Level)
+		t.settingsMu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		t.auditAdminChange(r, "logging.level", previous, setting.Level)
+		t.logger.Printf("log level set to %s by the admin endpoint", setting.Level)
+		writeAdminSetting(w, setting)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// serveSampling serves and changes the sampling ratio of root spans
+func (t *Telemetry) serveSampling(w http.ResponseWriter, r *http.Request) {
+	if t.sampler == nil {
+		http.Error(w, "tracing is not enabled", http.StatusConflict)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		t.settingsMu.Lock()
+		current := t.samplerSetting()
+		t.settingsMu.Unlock()
+		writeAdminSetting(w, current)
+	case http.MethodPut:
+		var setting samplingSetting
+		if !decodeAdminSetting(w, r, &setting) {
+			return
+		}
+		if setting.Ratio == nil {
+			http.Error(w, "ratio is required", http.StatusBadRequest)
+			return
+		}
+		t.settingsMu.Lock()
+		previous := ""
+		if sc := t.config.Tracing.Sampler; sc != nil {
+			previous = fmt.Sprintf("%s %s %v", sc.Kind, sc.Root, sc.Ratio)
+		}
+		err := t.setSamplingRatio(*setting.Ratio)
+		current := t.samplerSetting()
+		t.settingsMu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ratio := strconv.FormatFloat(*setting.Ratio, 'g', -1, 64)
+		t.auditAdminChange(r, "tracing.sampler", previous, ratio)
+		t.logger.Printf("sampling ratio set to %s by the admin endpoint", ratio)
+		writeAdminSetting(w, current)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// samplerSetting returns a copy of the sampler configuration, so it can be
+// encoded after settingsMu is released; settingsMu must be held
+func (t *Telemetry) samplerSetting() *config.SamplerConfig {
+	if t.config.Tracing.Sampler == nil {
+		return nil
+	}
+	copied := *t.config.Tracing.Sampler
+	return &copied
+}
+
+// auditAdminChange records a change made through the admin endpoint
+func (t *Telemetry) auditAdminChange(r *http.Request, name, previous, value string) {
+	t.AuditLogger().ConfigurationChange(r.Context(), audit.ConfigurationChange{
+		User:    "telemetry-admin",
+		Object:  audit.Object{Type: "telemetry", ID: t.config.ServiceName},
+		Changes: []audit.Change{{Name: name, OldValue: previous, NewValue: value}},
+	})
+}
+
+// decodeAdminSetting decodes the JSON body of r into v, responding with
+// bad request if it is invalid
+func decodeAdminSetting(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf("invalid setting: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeAdminSetting responds with v as JSON
+func writeAdminSetting(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
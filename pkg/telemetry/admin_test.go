@@ -0,0 +1,153 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestAdminHandler(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.Level = "info"
+	tel, err := New(WithConfig(cfg), WithSpanExporter(keptSpans{tracetest.NewInMemoryExporter()}),
+		WithLogExporter(discardLogs{}), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+	handler := tel.AdminHandler("s3cret")
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPut, AdminPath+"loglevel", "", `{"level":"debug"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, AdminPath+"loglevel", "wrong", `{"level":"debug"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodPut, AdminPath+"loglevel", "s3cret", `{"level":"debug"}`)
+	if rec.Code != http.StatusOK || cfg.Logging.Level != "debug" {
+		t.Errorf("Expected the log level to be changed, got %d %s", rec.Code, rec.Body.String())
+	}
+	var level logLevelSetting
+	if err := json.Unmarshal(do(http.MethodGet, AdminPath+"loglevel", "s3cret", "").Body.Bytes(), &level); err != nil || level.Level != "debug" {
+		t.Errorf("Expected the current level debug, got %+v %v", level, err)
+	}
+	if rec := do(http.MethodPut, AdminPath+"loglevel", "s3cret", `{"level":"verbose"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown level, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodPut, AdminPath+"sampling", "s3cret", `{"ratio":0.25}`)
+	if rec.Code != http.StatusOK || cfg.Tracing.Sampler.Ratio != 0.25 {
+		t.Errorf("Expected the sampling ratio to be changed, got %d %s", rec.Code, rec.Body.String())
+	}
+	for _, body := range []string{`{"ratio":2}`, `{}`, `{"rate":0.5}`, `not json`} {
+		if rec := do(http.MethodPut, AdminPath+"sampling", "s3cret", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+
+	if rec := do(http.MethodDelete, AdminPath+"sampling", "s3cret", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for DELETE, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, AdminPath+"unknown", "s3cret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown setting, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	tel.AdminHandler("").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, AdminPath+"loglevel", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected the handler to be disabled without token, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandlerConcurrent(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	tel, err := New(WithConfig(cfg), WithSpanExporter(keptSpans{tracetest.NewInMemoryExporter()}),
+		WithLogExporter(discardLogs{}), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+	handler := tel.AdminHandler("s3cret")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, req := range []struct{ method, path, body string }{
+			{http.MethodPut, "sampling", `{"ratio":0.5}`},
+			{http.MethodGet, "sampling", ""},
+			{http.MethodPut, "loglevel", `{"level":"debug"}`},
+			{http.MethodGet, "loglevel", ""},
+		} {
+			wg.Add(1)
+			go func(method, path, body string) {
+				defer wg.Done()
+				r := httptest.NewRequest(method, AdminPath+path, strings.NewReader(body))
+				r.Header.Set("Authorization", "Bearer s3cret")
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+				if rec.Code != http.StatusOK {
+					t.Errorf("Expected 200 for %s %s, got %d", method, path, rec.Code)
+				}
+			}(req.method, req.path, req.body)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tel.EffectiveConfig(); err != nil {
+				t.Errorf("failed to get the effective config: %v", err)
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tel.Config() == nil {
+				t.Error("Expected a copy of the config")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAdminHandlerDisabledSignals(t *testing.T) {
+	tel := &Telemetry{config: config.NewDefaultConfig(), logger: log.New(io.Discard, "", 0)}
+	for _, path := range []string{"loglevel", "sampling"} {
+		req := httptest.NewRequest(http.MethodGet, AdminPath+path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		tel.AdminHandler("s3cret").ServeHTTP(rec, req)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("Expected 409 for %s of a disabled signal, got %d", path, rec.Code)
+		}
+	}
+}
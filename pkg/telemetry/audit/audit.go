@@ -0,0 +1,162 @@
+// Package audit emits audit log events, such as access to personal data or
+// configuration changes, as required in SAP BTP compliance scenarios.
+//
+// Audit events are log records marked with log.type=audit. They are
+// emitted through a dedicated logger provider so that they bypass the level
+// filter and rate limiter of the application log pipeline and can be sent
+// to their own exporter.
+package audit
+
+import (
+	"context"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/noop"
+)
+
+// ScopeName is the instrumentation scope of audit records
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/audit"
+
+// Attribute keys of audit records
+const (
+	LogTypeKey         = "log.type"
+	LogTypeAudit       = "audit"
+	EventKey           = "audit.event"
+	UserKey            = "audit.user"
+	ObjectTypeKey      = "audit.object.type"
+	ObjectIDKey        = "audit.object.id"
+	DataSubjectTypeKey = "audit.data_subject.type"
+	DataSubjectIDKey   = "audit.data_subject.id"
+	AttributesKey      = "audit.attributes"
+	ChannelKey         = "audit.channel"
+	SuccessKey         = "audit.success"
+)
+
+// Event names recorded as audit.event
+const (
+	EventDataAccess          = "data_access"
+	EventConfigurationChange = "configuration_change"
+)
+
+// Object identifies an audited object or data subject, e.g. {"Customer", "4711"}
+type Object struct {
+	Type string
+	ID   string
+}
+
+// DataAccess records read access to personal data
+type DataAccess struct {
+	// User is the ID of the user accessing the data
+	User string
+	// Object is the accessed object
+	Object Object
+	// DataSubject is the person the data belongs to
+	DataSubject Object
+	// Attributes lists the accessed fields
+	Attributes []string
+	// Channel describes how the data was accessed, e.g. "odata" or "export"
+	Channel string
+}
+
+// Change is a changed configuration attribute
+type Change struct {
+	Name     string
+	OldValue string
+	NewValue string
+}
+
+// ConfigurationChange records a change of security-relevant configuration
+type ConfigurationChange struct {
+	// User is the ID of the user changing the configuration
+	User string
+	// Object is the changed configuration object
+	Object Object
+	// Changes lists the changed attributes
+	Changes []Change
+	// Failed marks attempted changes that were rejected
+	Failed bool
+}
+
+// Logger emits audit events
+type Logger struct {
+	logger log.Logger
+}
+
+// NewLogger creates an audit logger emitting through lp
+func NewLogger(lp log.LoggerProvider) *Logger {
+	return &Logger{logger: lp.Logger(ScopeName)}
+}
+
+// NewNoopLogger creates an audit logger that drops all events
+func NewNoopLogger() *Logger {
+	return NewLogger(noop.NewLoggerProvider())
+}
+
+// DataAccess emits a data access event
+func (l *Logger) DataAccess(ctx context.Context, e DataAccess) {
+	attrs := []log.KeyValue{
+		log.String(ObjectTypeKey, e.Object.Type),
+		log.String(ObjectIDKey, e.Object.ID),
+	}
+	if e.DataSubject.Type != "" || e.DataSubject.ID != "" {
+		attrs = append(attrs,
+			log.String(DataSubjectTypeKey, e.DataSubject.Type),
+			log.String(DataSubjectIDKey, e.DataSubject.ID),
+		)
+	}
+	if len(e.Attributes) > 0 {
+		values := make([]log.Value, len(e.Attributes))
+		for i, a := range e.Attributes {
+			values[i] = log.StringValue(a)
+		}
+		attrs = append(attrs, log.Slice(AttributesKey, values...))
+	}
+	if e.Channel != "" {
+		attrs = append(attrs, log.String(ChannelKey, e.Channel))
+	}
+	l.Emit(ctx, EventDataAccess, e.User, log.SeverityInfo, "data access", attrs...)
+}
+
+// ConfigurationChange emits a configuration change event
+func (l *Logger) ConfigurationChange(ctx context.Context, e ConfigurationChange) {
+	changes := make([]log.Value, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = log.MapValue(
+			log.String("name", c.Name),
+			log.String("old", c.OldValue),
+			log.String("new", c.NewValue),
+		)
+	}
+	attrs := []log.KeyValue{
+		log.String(ObjectTypeKey, e.Object.Type),
+		log.String(ObjectIDKey, e.Object.ID),
+		log.Slice(AttributesKey, changes...),
+		log.Bool(SuccessKey, !e.Failed),
+	}
+
+	severity := log.SeverityInfo
+	if e.Failed {
+		severity = log.SeverityWarn
+	}
+	l.Emit(ctx, EventConfigurationChange, e.User, severity, "configuration change", attrs...)
+}
+
+// Emit emits a custom audit event, recording the tenant of ctx as well
+func (l *Logger) Emit(ctx context.Context, event, user string, severity log.Severity, body string, attrs ...log.KeyValue) {
+	var r log.Record
+	r.SetSeverity(severity)
+	r.SetBody(log.StringValue(body))
+	r.AddAttributes(
+		log.String(LogTypeKey, LogTypeAudit),
+		log.String(EventKey, event),
+	)
+	if user != "" {
+		r.AddAttributes(log.String(UserKey, user))
+	}
+	if id := tenant.FromContext(ctx); id != "" {
+		r.AddAttributes(log.String(tenant.Key, id))
+	}
+	r.AddAttributes(attrs...)
+	l.logger.Emit(ctx, r)
+}
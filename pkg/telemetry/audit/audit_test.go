@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// recorder is a log processor keeping the emitted records
+type recorder struct {
+	records []sdklog.Record
+}
+
+func (r *recorder) OnEmit(_ context.Context, record *sdklog.Record) error {
+	r.records = append(r.records, record.Clone())
+	return nil
+}
+func (r *recorder) Shutdown(context.Context) error   { return nil }
+func (r *recorder) ForceFlush(context.Context) error { return nil }
+
+// attributes collects the record attributes by key
+func attributes(r sdklog.Record) map[string]log.Value {
+	attrs := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestDataAccess(t *testing.T) {
+	rec := &recorder{}
+	logger := NewLogger(sdklog.NewLoggerProvider(sdklog.WithProcessor(rec)))
+
+	ctx := tenant.ContextWithID(context.Background(), "t1")
+	logger.DataAccess(ctx, DataAccess{
+		User:        "alice",
+		Object:      Object{Type: "Customer", ID: "4711"},
+		DataSubject: Object{Type: "Person", ID: "p-1"},
+		Attributes:  []string{"email", "iban"},
+		Channel:     "odata",
+	})
+
+	if len(rec.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(rec.records))
+	}
+	attrs := attributes(rec.records[0])
+	if attrs[LogTypeKey].AsString() != LogTypeAudit {
+		t.Error("Expected log.type=audit")
+	}
+	if attrs[EventKey].AsString() != EventDataAccess {
+		t.Errorf("Expected data_access event, got %s", attrs[EventKey].AsString())
+	}
+	if attrs[UserKey].AsString() != "alice" || attrs[ObjectIDKey].AsString() != "4711" {
+		t.Error("Expected user and object attributes")
+	}
+	if len(attrs[AttributesKey].AsSlice()) != 2 {
+		t.Error("Expected accessed attributes")
+	}
+	if attrs[tenant.Key].AsString() != "t1" {
+		t.Error("Expected tenant attribute")
+	}
+}
+
+func TestConfigurationChange(t *testing.T) {
+	rec := &recorder{}
+	logger := NewLogger(sdklog.NewLoggerProvider(sdklog.WithProcessor(rec)))
+
+	logger.ConfigurationChange(context.Background(), ConfigurationChange{
+		User:    "admin",
+		Object:  Object{Type: "Role", ID: "Viewer"},
+		Changes: []Change{{Name: "scopes", OldValue: "read", NewValue: "read,write"}},
+		Failed:  true,
+	})
+
+	r := rec.records[0]
+	if r.Severity() != log.SeverityWarn {
+		t.Errorf("Expected failed change to be a warning, got %v", r.Severity())
+	}
+	attrs := attributes(r)
+	if attrs[SuccessKey].AsBool() {
+		t.Error("Expected audit.success=false")
+	}
+	if changes := attrs[AttributesKey].AsSlice(); len(changes) != 1 || len(changes[0].AsMap()) != 3 {
+		t.Errorf("Expected one change with name, old and new value, got %v", changes)
+	}
+}
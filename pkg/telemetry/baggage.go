@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Limits of the W3C baggage specification enforced by SetBaggage
+const (
+	MaxBaggageMembers     = 180
+	MaxBaggageMemberBytes = 4096
+	MaxBaggageBytes       = 8192
+)
+
+// ErrBaggageTooLarge is returned by SetBaggage when a member or the baggage
+// would exceed the W3C limits
+var ErrBaggageTooLarge = errors.New("baggage too large")
+
+// BaggageOption configures SetBaggage
+type BaggageOption func(*baggageConfig)
+
+// baggageConfig holds the settings of SetBaggage
+type baggageConfig struct {
+	spanAttribute bool
+}
+
+// WithSpanAttribute also sets the member as an attribute of the span of the
+// context. Spans started later get it through tracing.baggage_attributes.
+func WithSpanAttribute() BaggageOption {
+	return func(c *baggageConfig) {
+		c.spanAttribute = true
+	}
+}
+
+// SetBaggage returns a context whose baggage has key set to value. The key
+// must be a W3C token; the value is percent-encoded when propagated. An
+// error leaves ctx unchanged.
+func SetBaggage(ctx context.Context, key, value string, opts ...BaggageOption) (context.Context, error) {
+	cfg := &baggageConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	member, err := baggage.NewMember(key, url.PathEscape(value))
+	if err != nil {
+		return ctx, fmt.Errorf("failed to create baggage member %q: %w", key, err)
+	}
+	if n := len(member.String()); n > MaxBaggageMemberBytes {
+		return ctx, fmt.Errorf("%w: member %q has %d bytes", ErrBaggageTooLarge, key, n)
+	}
+
+	b, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to set baggage member %q: %w", key, err)
+	}
+	if n := b.Len(); n > MaxBaggageMembers {
+		return ctx, fmt.Errorf("%w: %d members", ErrBaggageTooLarge, n)
+	}
+	if n := len(b.String()); n > MaxBaggageBytes {
+		return ctx, fmt.Errorf("%w: %d bytes", ErrBaggageTooLarge, n)
+	}
+
+	if cfg.spanAttribute {
+		oteltrace.SpanFromContext(ctx).SetAttributes(attribute.String(key, value))
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// GetBaggage returns the value of the baggage member key, empty if it is
+// not set
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// DeleteBaggage returns a context whose baggage does not have key
+func DeleteBaggage(ctx context.Context, key string) context.Context {
+	return baggage.ContextWithBaggage(ctx, baggage.FromContext(ctx).DeleteMember(key))
+}
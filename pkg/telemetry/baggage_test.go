@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/processors"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetBaggage(t *testing.T) {
+	ctx, err := SetBaggage(context.Background(), "feature", "new checkout; beta")
+	if err != nil {
+		t.Fatalf("failed to set baggage: %v", err)
+	}
+	if got := GetBaggage(ctx, "feature"); got != "new checkout; beta" {
+		t.Errorf("Expected the raw value, got %q", got)
+	}
+	if got := GetBaggage(ctx, "missing"); got != "" {
+		t.Errorf("Expected no value, got %q", got)
+	}
+	if got := GetBaggage(DeleteBaggage(ctx, "feature"), "feature"); got != "" {
+		t.Errorf("Expected the member to be deleted, got %q", got)
+	}
+
+	if _, err := SetBaggage(ctx, "bad key", "v"); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+	if _, err := SetBaggage(ctx, "big", strings.Repeat("x", MaxBaggageMemberBytes)); !errors.Is(err, ErrBaggageTooLarge) {
+		t.Errorf("Expected ErrBaggageTooLarge for a large member, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if ctx, err = SetBaggage(ctx, fmt.Sprintf("k%d", i), strings.Repeat("x", 3000)); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, ErrBaggageTooLarge) {
+		t.Errorf("Expected ErrBaggageTooLarge for large baggage, got %v", err)
+	}
+	if GetBaggage(ctx, "k2") != "" || GetBaggage(ctx, "k1") == "" {
+		t.Error("Expected the context to be unchanged on error")
+	}
+}
+
+func TestSetBaggage_SpanAttribute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processors.NewBaggageAttributes("user.plan")),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	ctx, err := SetBaggage(ctx, "user.plan", "gold", WithSpanAttribute())
+	if err != nil {
+		t.Fatalf("failed to set baggage: %v", err)
+	}
+	_, child := tp.Tracer("test").Start(ctx, "query")
+	child.End()
+	parent.End()
+
+	for _, span := range recorder.Ended() {
+		attrs := attribute.NewSet(span.Attributes()...)
+		if v, _ := attrs.Value("user.plan"); v.AsString() != "gold" {
+			t.Errorf("Expected user.plan on span %s, got %q", span.Name(), v.AsString())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/scheduled"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// spanBatcherOptions translates tracing.batcher into batch span processor options
+func spanBatcherOptions(cfg *config.BatcherConfig) []trace.BatchSpanProcessorOption {
+	if cfg == nil {
+		return nil
+	}
+
+	var opts []trace.BatchSpanProcessorOption
+	if cfg.MaxQueueSize > 0 {
+		opts = append(opts, trace.WithMaxQueueSize(cfg.MaxQueueSize))
+	}
+	if cfg.MaxExportBatchSize > 0 {
+		opts = append(opts, trace.WithMaxExportBatchSize(cfg.MaxExportBatchSize))
+	}
+	if d := cfg.GetScheduleDelay(); d > 0 {
+		opts = append(opts, trace.WithBatchTimeout(d))
+	}
+	if d := cfg.GetExportTimeout(); d > 0 {
+		opts = append(opts, trace.WithExportTimeout(d))
+	}
+	return opts
+}
+
+// logBatcherOptions translates logging.batcher into batch log processor options
+func logBatcherOptions(cfg *config.BatcherConfig) []sdklog.BatchProcessorOption {
+	if cfg == nil {
+		return nil
+	}
+
+	var opts []sdklog.BatchProcessorOption
+	if cfg.MaxQueueSize > 0 {
+		opts = append(opts, sdklog.WithMaxQueueSize(cfg.MaxQueueSize))
+	}
+	if cfg.MaxExportBatchSize > 0 {
+		opts = append(opts, sdklog.WithExportMaxBatchSize(cfg.MaxExportBatchSize))
+	}
+	if d := cfg.GetScheduleDelay(); d > 0 {
+		opts = append(opts, sdklog.WithExportInterval(d))
+	}
+	if d := cfg.GetExportTimeout(); d > 0 {
+		opts = append(opts, sdklog.WithExportTimeout(d))
+	}
+	return opts
+}
+
+// metricReaderOptions translates metrics.config into periodic reader options
+func metricReaderOptions(cfg *config.MetricsExportConfig) []metric.PeriodicReaderOption {
+	opts := []metric.PeriodicReaderOption{metric.WithInterval(cfg.GetExportInterval())}
+	if d := cfg.GetExportTimeout(); d > 0 {
+		opts = append(opts, metric.WithTimeout(d))
+	}
+	return opts
+}
+
+// metricReader creates the reader exporting the metrics and those of the
+// producers, a scheduled reader when metrics.config asks for jitter or
+// alignment and the SDK's periodic reader otherwise
+func metricReader(exporter metric.Exporter, cfg *config.MetricsExportConfig, producers ...metric.Producer) metric.Reader {
+	if cfg.GetExportJitter() <= 0 && !cfg.AlignExports {
+		opts := metricReaderOptions(cfg)
+		for _, producer := range producers {
+			opts = append(opts, metric.WithProducer(producer))
+		}
+		return metric.NewPeriodicReader(exporter, opts...)
+	}
+
+	opts := []scheduled.Option{scheduled.WithJitter(cfg.GetExportJitter())}
+	for _, producer := range producers {
+		opts = append(opts, scheduled.WithProducer(producer))
+	}
+	if cfg.AlignExports {
+		opts = append(opts, scheduled.WithAlignment())
+	}
+	if d := cfg.GetExportTimeout(); d > 0 {
+		opts = append(opts, scheduled.WithTimeout(d))
+	}
+	return scheduled.NewReader(exporter, cfg.GetExportInterval(), opts...)
+}
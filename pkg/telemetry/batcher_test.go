@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/console"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/scheduled"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestBatcherOptions(t *testing.T) {
+	if spanBatcherOptions(nil) != nil || logBatcherOptions(nil) != nil {
+		t.Error("Expected no options without batcher config")
+	}
+
+	cfg := &config.BatcherConfig{MaxQueueSize: 100, ExportTimeoutMillis: 5000}
+	if n := len(spanBatcherOptions(cfg)); n != 2 {
+		t.Errorf("Expected 2 span batcher options, got %d", n)
+	}
+	if n := len(logBatcherOptions(cfg)); n != 2 {
+		t.Errorf("Expected 2 log batcher options, got %d", n)
+	}
+}
+
+func TestMetricReaderOptions(t *testing.T) {
+	cfg := &config.MetricsExportConfig{ExportIntervalMillis: 10000}
+	if n := len(metricReaderOptions(cfg)); n != 1 {
+		t.Errorf("Expected only the interval without timeout, got %d options", n)
+	}
+
+	cfg.ExportTimeoutMillis = 5000
+	if n := len(metricReaderOptions(cfg)); n != 2 {
+		t.Errorf("Expected interval and timeout, got %d options", n)
+	}
+}
+
+func TestMetricReader(t *testing.T) {
+	exporter := console.NewMetricExporter(console.WithMetricWriter(io.Discard))
+
+	cfg := &config.MetricsExportConfig{ExportIntervalMillis: 10000}
+	reader := metricReader(exporter, cfg)
+	if _, ok := reader.(*metric.PeriodicReader); !ok {
+		t.Errorf("Expected the periodic reader by default, got %T", reader)
+	}
+
+	cfg.ExportJitterMillis = 1000
+	reader = metricReader(exporter, cfg)
+	if _, ok := reader.(*scheduled.Reader); !ok {
+		t.Errorf("Expected the scheduled reader with jitter, got %T", reader)
+	}
+	_ = reader.Shutdown(context.Background())
+}
+
+// staticProducer produces a single gauge, standing in for a bridge
+type staticProducer struct{}
+
+func (staticProducer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	return []metricdata.ScopeMetrics{{Metrics: []metricdata.Metrics{{
+		Name: "bridged.gauge",
+		Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{{Value: 7}}},
+	}}}}, nil
+}
+
+func TestMetricReader_Producers(t *testing.T) {
+	for _, jitter := range []int{0, 1000} {
+		var buf bytes.Buffer
+		exporter := console.NewMetricExporter(console.WithMetricWriter(&buf))
+		cfg := &config.MetricsExportConfig{ExportIntervalMillis: 10000, ExportJitterMillis: jitter}
+		reader := metricReader(exporter, cfg, staticProducer{})
+		metric.NewMeterProvider(metric.WithReader(reader))
+		if err := reader.Shutdown(context.Background()); err != nil {
+			t.Errorf("failed to shut down reader: %v", err)
+		}
+		if !strings.Contains(buf.String(), "bridged.gauge") {
+			t.Errorf("Expected the produced metric to be exported with jitter %d, got:\n%s", jitter, buf.String())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package clock abstracts the time source of timestamps the library
+// generates itself, e.g. the observed time of bridged log records or the
+// time console formatters print for records without one, so tests can
+// inject a fixed time.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// Func adapts a function to a Clock
+type Func func() time.Time
+
+// Now calls f
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// System is the wall clock of the operating system
+var System Clock = systemClock{}
+
+// systemClock reads time.Now
+type systemClock struct{}
+
+// Now returns time.Now
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// OrSystem returns c, or System if c is nil
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+// Fixed is a clock standing still unless advanced, for tests
+type Fixed struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixed creates a clock standing at t
+func NewFixed(t time.Time) *Fixed {
+	return &Fixed{now: t}
+}
+
+// Now returns the time the clock stands at
+func (f *Fixed) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d
+func (f *Fixed) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set moves the clock to t
+func (f *Fixed) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+}
+
+// Monotonic is a high-resolution clock counting from the wall time it was
+// created at with the monotonic clock of the process, like process.hrtime
+// in Node.js. Its timestamps keep their order and distances when the wall
+// clock is adjusted, e.g. by NTP.
+type Monotonic struct {
+	anchor time.Time
+}
+
+// NewMonotonic creates a monotonic clock anchored at the current time
+func NewMonotonic() *Monotonic {
+	return &Monotonic{anchor: time.Now()}
+}
+
+// Now returns the anchor plus the monotonic time elapsed since
+func (m *Monotonic) Now() time.Time {
+	return m.Time(time.Now())
+}
+
+// Time converts a timestamp taken with time.Now in this process to the
+// monotonic clock. Timestamps without a monotonic reading, e.g. parsed or
+// received ones, keep their instant.
+func (m *Monotonic) Time(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	// Sub uses the monotonic readings if both times have one and the wall
+	// times otherwise, which leaves t as it is
+	return m.anchor.Add(t.Sub(m.anchor)).Round(0)
+}
@@ -0,0 +1,50 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixed(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFixed(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("Expected %v, got %v", start, c.Now())
+	}
+	c.Advance(time.Second)
+	if got := c.Now().Sub(start); got != time.Second {
+		t.Errorf("Expected the clock to advance by 1s, got %v", got)
+	}
+}
+
+func TestOrSystem(t *testing.T) {
+	if OrSystem(nil) != System {
+		t.Error("Expected the system clock for nil")
+	}
+	fixed := NewFixed(time.Time{})
+	if OrSystem(fixed) != Clock(fixed) {
+		t.Error("Expected the given clock")
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	c := NewMonotonic()
+	before := time.Now()
+	first := c.Now()
+	second := c.Now()
+	if second.Before(first) {
+		t.Errorf("Expected %v not to be before %v", second, first)
+	}
+	if d := first.Sub(before); d < -time.Second || d > time.Second {
+		t.Errorf("Expected the clock to start at the wall time, off by %v", d)
+	}
+
+	// Timestamps without a monotonic reading keep their instant
+	parsed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := c.Time(parsed); !got.Equal(parsed) {
+		t.Errorf("Expected %v, got %v", parsed, got)
+	}
+	if got := c.Time(time.Time{}); !got.IsZero() {
+		t.Errorf("Expected the zero time to stay zero, got %v", got)
+	}
+}
@@ -0,0 +1,448 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Builder assembles a configuration in code, starting from the defaults and
+// the predefined kind:
+//
+//	cfg, err := config.NewBuilder().
+//		ServiceName("bookshop").
+//		Tracing(config.WithSamplingRatio(0.1)).
+//		Metrics(config.WithExportInterval(30 * time.Second)).
+//		Build()
+type Builder struct {
+	kind  string
+	steps []func(*Config) error
+}
+
+// NewBuilder creates a builder starting from NewDefaultConfig
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// TracingOption configures the tracing section of a Builder
+type TracingOption func(*TracingConfig) error
+
+// MetricsOption configures the metrics section of a Builder
+type MetricsOption func(*MetricsConfig) error
+
+// LoggingOption configures the logging section of a Builder
+type LoggingOption func(*LoggingConfig) error
+
+// step records a change applied by Build
+func (b *Builder) step(fn func(*Config) error) *Builder {
+	b.steps = append(b.steps, fn)
+	return b
+}
+
+// ServiceName sets the service name
+func (b *Builder) ServiceName(name string) *Builder {
+	return b.step(func(c *Config) error {
+		if name == "" {
+			return errors.New("service name must not be empty")
+		}
+		c.ServiceName = name
+		return nil
+	})
+}
+
+// Kind sets the predefined kind, e.g. telemetry-to-console. Its settings
+// are applied before all other options, wherever Kind is called.
+func (b *Builder) Kind(kind string) *Builder {
+	b.kind = kind
+	return b
+}
+
+// Disabled turns all telemetry off
+func (b *Builder) Disabled(disabled bool) *Builder {
+	return b.step(func(c *Config) error {
+		c.Disabled = disabled
+		return nil
+	})
+}
+
+// FailOpen sets whether New continues with the console exporter when an
+// exporter can't be created
+func (b *Builder) FailOpen(enabled bool) *Builder {
+	return b.step(func(c *Config) error {
+		c.FailOpen = &enabled
+		return nil
+	})
+}
+
+// ResourceAttributes adds static resource attributes
+func (b *Builder) ResourceAttributes(attrs map[string]string) *Builder {
+	return b.step(func(c *Config) error {
+		if c.Resource == nil {
+			c.Resource = &ResourceConfig{}
+		}
+		if c.Resource.Attributes == nil {
+			c.Resource.Attributes = make(map[string]string, len(attrs))
+		}
+		for k, v := range attrs {
+			c.Resource.Attributes[k] = v
+		}
+		return nil
+	})
+}
+
+// Tracing enables tracing and applies opts
+func (b *Builder) Tracing(opts ...TracingOption) *Builder {
+	return b.step(func(c *Config) error {
+		if c.Tracing == nil {
+			c.Tracing = NewDefaultTracingConfig()
+		}
+		c.Tracing.Enabled = true
+		var errs []error
+		for _, opt := range opts {
+			if err := opt(c.Tracing); err != nil {
+				errs = append(errs, fmt.Errorf("invalid tracing option: %w", err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// Metrics enables metrics and applies opts
+func (b *Builder) Metrics(opts ...MetricsOption) *Builder {
+	return b.step(func(c *Config) error {
+		if c.Metrics == nil {
+			c.Metrics = NewDefaultMetricsConfig()
+		}
+		c.Metrics.Enabled = true
+		var errs []error
+		for _, opt := range opts {
+			if err := opt(c.Metrics); err != nil {
+				errs = append(errs, fmt.Errorf("invalid metrics option: %w", err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// Logging enables logging and applies opts
+func (b *Builder) Logging(opts ...LoggingOption) *Builder {
+	return b.step(func(c *Config) error {
+		if c.Logging == nil {
+			c.Logging = NewDefaultLoggingConfig()
+		}
+		c.Logging.Enabled = true
+		var errs []error
+		for _, opt := range opts {
+			if err := opt(c.Logging); err != nil {
+				errs = append(errs, fmt.Errorf("invalid logging option: %w", err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// DisableTracing turns tracing off
+func (b *Builder) DisableTracing() *Builder {
+	return b.step(func(c *Config) error {
+		if c.Tracing != nil {
+			c.Tracing.Enabled = false
+		}
+		return nil
+	})
+}
+
+// DisableMetrics turns metrics off
+func (b *Builder) DisableMetrics() *Builder {
+	return b.step(func(c *Config) error {
+		if c.Metrics != nil {
+			c.Metrics.Enabled = false
+		}
+		return nil
+	})
+}
+
+// DisableLogging turns logging off
+func (b *Builder) DisableLogging() *Builder {
+	return b.step(func(c *Config) error {
+		if c.Logging != nil {
+			c.Logging.Enabled = false
+		}
+		return nil
+	})
+}
+
+// Build applies the defaults, the predefined kind and then the options in
+// the order they were given, and validates the result like the Loader
+// does. All invalid options are reported together.
+func (b *Builder) Build() (*Config, error) {
+	config := NewDefaultConfig()
+	if b.kind != "" {
+		config.Kind = b.kind
+	}
+	if config.Kind != "" {
+		if err := mergePredefinedKind(config, nil, true); err != nil {
+			return nil, fmt.Errorf("failed to apply predefined kind %s: %w", config.Kind, err)
+		}
+	}
+
+	var errs []error
+	for _, step := range b.steps {
+		if err := step(config); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// The loader's checks don't depend on its sources
+	if err := (&Loader{}).validateConfig(config); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return config, nil
+}
+
+// WithSampler sets the sampler kind and, for parent based samplers, the root sampler
+func WithSampler(kind, root string) TracingOption {
+	return func(c *TracingConfig) error {
+		if kind == "" {
+			return errors.New("sampler kind must not be empty")
+		}
+		if c.Sampler == nil {
+			c.Sampler = &SamplerConfig{}
+		}
+		c.Sampler.Kind = kind
+		c.Sampler.Root = root
+		return nil
+	}
+}
+
+// WithSamplingRatio samples the given fraction of root spans, keeping the
+// decision of the parent for child spans. Use WithSampler with
+// AlwaysOffSampler to sample nothing.
+func WithSamplingRatio(ratio float64) TracingOption {
+	return func(c *TracingConfig) error {
+		if ratio <= 0 || ratio > 1 {
+			return fmt.Errorf("sampling ratio %v must be greater than 0 and at most 1", ratio)
+		}
+		if c.Sampler == nil {
+			c.Sampler = &SamplerConfig{}
+		}
+		c.Sampler.Kind = "ParentBasedSampler"
+		c.Sampler.Root = "TraceIdRatioBasedSampler"
+		c.Sampler.Ratio = ratio
+		return nil
+	}
+}
+
+// WithIgnoredPaths drops server spans of the given request paths
+func WithIgnoredPaths(paths ...string) TracingOption {
+	return func(c *TracingConfig) error {
+		if c.Sampler == nil {
+			c.Sampler = &SamplerConfig{}
+		}
+		c.Sampler.IgnoreIncomingPaths = append([]string{}, paths...)
+		return nil
+	}
+}
+
+// WithTracingExporter sets the span exporter module and its settings
+func WithTracingExporter(module string, settings map[string]interface{}) TracingOption {
+	return func(c *TracingConfig) error {
+		exporter, err := newExporterConfig(module, settings)
+		c.Exporter = exporter
+		return err
+	}
+}
+
+// WithSpanProcessor sets the span processor, batch or simple
+func WithSpanProcessor(processor string) TracingOption {
+	return func(c *TracingConfig) error {
+		switch processor {
+		case "batch", "simple":
+			c.Processor = processor
+			return nil
+		default:
+			return fmt.Errorf("unknown span processor %q", processor)
+		}
+	}
+}
+
+// WithHRTime takes span and log times from a monotonic clock anchored at
+// startup, unaffected by adjustments of the wall clock
+func WithHRTime(enabled bool) TracingOption {
+	return func(c *TracingConfig) error {
+		c.HRTime = enabled
+		return nil
+	}
+}
+
+// WithTraceURL sets the template of links to traces in the tracing backend,
+// e.g. "http://localhost:16686/trace/{trace_id}" for Jaeger
+func WithTraceURL(template string) TracingOption {
+	return func(c *TracingConfig) error {
+		if !strings.Contains(template, TraceIDPlaceholder) {
+			return fmt.Errorf("trace URL %q must contain %s", template, TraceIDPlaceholder)
+		}
+		c.TraceURL = template
+		return nil
+	}
+}
+
+// WithPropagators sets the context propagation formats
+func WithPropagators(propagators ...string) TracingOption {
+	return func(c *TracingConfig) error {
+		c.Propagators = append([]string{}, propagators...)
+		return nil
+	}
+}
+
+// WithMetricsExporter sets the metric exporter module and its settings
+func WithMetricsExporter(module string, settings map[string]interface{}) MetricsOption {
+	return func(c *MetricsConfig) error {
+		exporter, err := newExporterConfig(module, settings)
+		c.Exporter = exporter
+		return err
+	}
+}
+
+// WithExportInterval sets the interval between two metric exports
+func WithExportInterval(d time.Duration) MetricsOption {
+	return func(c *MetricsConfig) error {
+		if d < time.Millisecond {
+			return fmt.Errorf("export interval %v must be at least 1ms", d)
+		}
+		if c.Config == nil {
+			c.Config = &MetricsExportConfig{}
+		}
+		c.Config.ExportIntervalMillis = int(d / time.Millisecond)
+		return nil
+	}
+}
+
+// WithExportTimeout bounds each metric export, including the last one on shutdown
+func WithExportTimeout(d time.Duration) MetricsOption {
+	return func(c *MetricsConfig) error {
+		if d < time.Millisecond {
+			return fmt.Errorf("export timeout %v must be at least 1ms", d)
+		}
+		if c.Config == nil {
+			c.Config = &MetricsExportConfig{}
+		}
+		c.Config.ExportTimeoutMillis = int(d / time.Millisecond)
+		return nil
+	}
+}
+
+// WithExportJitter delays each metric export by a random duration below d
+func WithExportJitter(d time.Duration) MetricsOption {
+	return func(c *MetricsConfig) error {
+		if d < 0 {
+			return fmt.Errorf("export jitter %v must not be negative", d)
+		}
+		if c.Config == nil {
+			c.Config = &MetricsExportConfig{}
+		}
+		c.Config.ExportJitterMillis = int(d / time.Millisecond)
+		return nil
+	}
+}
+
+// WithExportAlignment aligns metric exports to multiples of the interval on the wall clock
+func WithExportAlignment(enabled bool) MetricsOption {
+	return func(c *MetricsConfig) error {
+		if c.Config == nil {
+			c.Config = &MetricsExportConfig{}
+		}
+		c.Config.AlignExports = enabled
+		return nil
+	}
+}
+
+// WithTemporality sets the metric temporality: cumulative, delta or lowmemory
+func WithTemporality(temporality string) MetricsOption {
+	return func(c *MetricsConfig) error {
+		switch temporality {
+		case "cumulative", "delta", "lowmemory":
+		default:
+			return fmt.Errorf("unknown temporality %q", temporality)
+		}
+		if c.Config == nil {
+			c.Config = &MetricsExportConfig{}
+		}
+		c.Config.Temporality = temporality
+		return nil
+	}
+}
+
+// WithHostMetrics enables or disables the host metrics
+func WithHostMetrics(enabled bool) MetricsOption {
+	return func(c *MetricsConfig) error {
+		c.HostMetrics = enabled
+		return nil
+	}
+}
+
+// WithRuntimeMetrics enables or disables the Go runtime metrics
+func WithRuntimeMetrics(enabled bool) MetricsOption {
+	return func(c *MetricsConfig) error {
+		c.RuntimeMetrics = enabled
+		return nil
+	}
+}
+
+// WithGoroutineDiagnostics enables or disables the goroutine diagnostics,
+// counting goroutines by the given runtime/pprof label keys
+func WithGoroutineDiagnostics(enabled bool, labels ...string) MetricsOption {
+	return func(c *MetricsConfig) error {
+		if c.GoroutineDiagnostics == nil {
+			c.GoroutineDiagnostics = &GoroutineDiagnosticsConfig{}
+		}
+		c.GoroutineDiagnostics.Enabled = enabled
+		c.GoroutineDiagnostics.Labels = labels
+		return nil
+	}
+}
+
+// WithLoggingExporter sets the log exporter module and its settings
+func WithLoggingExporter(module string, settings map[string]interface{}) LoggingOption {
+	return func(c *LoggingConfig) error {
+		exporter, err := newExporterConfig(module, settings)
+		c.Exporter = exporter
+		return err
+	}
+}
+
+// WithStartupReport enables or disables the startup report log record
+func WithStartupReport(enabled bool) LoggingOption {
+	return func(c *LoggingConfig) error {
+		c.StartupReport = enabled
+		return nil
+	}
+}
+
+// WithLogLevel sets the minimum exported severity
+func WithLogLevel(level string) LoggingOption {
+	return func(c *LoggingConfig) error {
+		switch level {
+		case "trace", "debug", "info", "warn", "warning", "error", "fatal":
+			c.Level = level
+			return nil
+		default:
+			return fmt.Errorf("unknown log level %q", level)
+		}
+	}
+}
+
+// newExporterConfig creates the config of an exporter module
+func newExporterConfig(module string, settings map[string]interface{}) (*ExporterConfig, error) {
+	if module == "" {
+		return nil, errors.New("exporter module must not be empty")
+	}
+	exporter := &ExporterConfig{Module: module, Config: make(map[string]interface{}, len(settings))}
+	for k, v := range settings {
+		exporter.Config[k] = v
+	}
+	return exporter, nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	config, err := NewBuilder().
+		ServiceName("bookshop").
+		ResourceAttributes(map[string]string{"deployment.environment": "dev"}).
+		Tracing(
+			WithSamplingRatio(0.25),
+			WithIgnoredPaths("/health"),
+			WithSpanProcessor("batch"),
+		).
+		Metrics(
+			WithExportInterval(30*time.Second),
+			WithExportTimeout(5*time.Second),
+			WithExportJitter(10*time.Second),
+			WithExportAlignment(true),
+			WithTemporality("delta"),
+			WithHostMetrics(false),
+			WithGoroutineDiagnostics(true, "handler"),
+		).
+		Logging(WithLogLevel("warn"), WithLoggingExporter("console", map[string]interface{}{"utc": true})).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if config.ServiceName != "bookshop" {
+		t.Errorf("Expected service name bookshop, got %q", config.ServiceName)
+	}
+	if config.Resource.Attributes["deployment.environment"] != "dev" {
+		t.Error("Expected resource attribute to be set")
+	}
+	if s := config.Tracing.Sampler; s.Root != "TraceIdRatioBasedSampler" || s.Ratio != 0.25 || len(s.IgnoreIncomingPaths) != 1 {
+		t.Errorf("Unexpected sampler %+v", s)
+	}
+	if config.Tracing.Processor != "batch" {
+		t.Errorf("Expected explicit processor to be kept, got %q", config.Tracing.Processor)
+	}
+	if c := config.Metrics.Config; c.ExportIntervalMillis != 30000 || c.ExportTimeoutMillis != 5000 || c.Temporality != "delta" {
+		t.Errorf("Unexpected metrics export config %+v", config.Metrics.Config)
+	}
+	if c := config.Metrics.Config; c.GetExportJitter() != 10*time.Second || !c.AlignExports {
+		t.Errorf("Expected jittered and aligned exports, got %+v", c)
+	}
+	if config.Metrics.HostMetrics {
+		t.Error("Expected host metrics to be disabled")
+	}
+	if gd := config.Metrics.GoroutineDiagnostics; gd == nil || !gd.Enabled || len(gd.Labels) != 1 {
+		t.Errorf("Expected goroutine diagnostics by handler, got %+v", gd)
+	}
+	if !config.Logging.Enabled || config.Logging.Level != "warn" || config.Logging.Exporter.Config["utc"] != true {
+		t.Errorf("Unexpected logging config %+v", config.Logging)
+	}
+}
+
+func TestBuilder_Validation(t *testing.T) {
+	_, err := NewBuilder().
+		ServiceName("").
+		Tracing(WithSamplingRatio(2), WithSpanProcessor("eager"), WithTraceURL("http://jaeger/trace")).
+		Logging(WithLogLevel("loud")).
+		Build()
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, expected := range []string{"service name", "sampling ratio", "span processor", "trace URL", "log level"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error about %s, got: %v", expected, err)
+		}
+	}
+
+	if _, err := NewBuilder().Kind("telemetry-to-nowhere").Build(); err == nil {
+		t.Error("Expected error for unknown kind")
+	}
+}
+
+func TestBuilder_Disable(t *testing.T) {
+	config, err := NewBuilder().DisableTracing().DisableMetrics().Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if config.IsTracingEnabled() || config.IsMetricsEnabled() {
+		t.Error("Expected tracing and metrics to be disabled")
+	}
+}
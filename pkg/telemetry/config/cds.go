@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// CDSConfigEnv holds a JSON cds configuration overriding the project files,
+// as understood by CAP Node.js
+const CDSConfigEnv = "CDS_CONFIG"
+
+// loadCDSConfig reads the CAP Node.js-style telemetry configuration
+// (cds.requires.telemetry) from package.json and .cdsrc.json in dir and
+// from CDS_CONFIG, later sources overriding earlier ones. It returns nil
+// when none of them configures telemetry.
+func loadCDSConfig(dir string) (map[string]interface{}, error) {
+	var merged map[string]interface{}
+
+	sources := []struct {
+		file string
+		path []string
+	}{
+		{"package.json", []string{"cds", "requires", "telemetry"}},
+		{".cdsrc.json", []string{"requires", "telemetry"}},
+	}
+	for _, src := range sources {
+		data, err := os.ReadFile(filepath.Join(dir, src.file))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", src.file, err)
+		}
+		telemetry, err := cdsTelemetry(data, src.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", src.file, err)
+		}
+		merged = mergeCDS(merged, telemetry)
+	}
+
+	if env := os.Getenv(CDSConfigEnv); env != "" {
+		telemetry, err := cdsTelemetry([]byte(env), []string{"requires", "telemetry"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", CDSConfigEnv, err)
+		}
+		merged = mergeCDS(merged, telemetry)
+	}
+
+	return merged, nil
+}
+
+// cdsTelemetry extracts and normalizes the telemetry section at path
+func cdsTelemetry(data []byte, path []string) (map[string]interface{}, error) {
+	var node interface{}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	for _, key := range path {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		node = m[key]
+	}
+
+	switch v := node.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		// "telemetry": false disables it, true keeps the defaults
+		return map[string]interface{}{"disabled": !v}, nil
+	case string:
+		// "telemetry": "<kind>" is a shorthand for the kind
+		return map[string]interface{}{"kind": v}, nil
+	case map[string]interface{}:
+		return normalizeCDSKeys(v), nil
+	default:
+		return nil, fmt.Errorf("unexpected telemetry configuration of type %T", node)
+	}
+}
+
+// normalizeCDSKeys converts the camelCase keys used by CAP Node.js (e.g.
+// ignoreIncomingPaths, exportIntervalMillis) into the snake_case keys of
+// Config. Exporter configs and attributes are passed through unchanged.
+func normalizeCDSKeys(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		switch key {
+		case "exporter":
+			if exp, ok := value.(map[string]interface{}); ok {
+				value = normalizeExporter(exp)
+			}
+		case "attributes":
+		default:
+			if nested, ok := value.(map[string]interface{}); ok {
+				value = normalizeCDSKeys(nested)
+			}
+		}
+		out[snakeCase(key)] = value
+	}
+	return out
+}
+
+// normalizeExporter keeps the exporter's own config untouched
+func normalizeExporter(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		out[snakeCase(key)] = value
+	}
+	return out
+}
+
+// snakeCase converts a camelCase key; snake_case keys are returned as is
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mergeCDS deep-merges src into dst, src taking precedence
+func mergeCDS(dst, src map[string]interface{}) map[string]interface{} {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				dst[key] = mergeCDS(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+	return dst
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCDSConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+		"name": "bookshop",
+		"cds": {"requires": {"telemetry": {
+			"kind": "telemetry-to-console",
+			"tracing": {"sampler": {"kind": "ParentBasedSampler", "root": "AlwaysOnSampler", "ignoreIncomingPaths": ["/health"]}},
+			"metrics": {"config": {"exportIntervalMillis": 30000}, "_db_pool": false}
+		}}}
+	}`), 0644)
+	os.WriteFile(filepath.Join(dir, ".cdsrc.json"), []byte(`{"requires": {"telemetry": {"metrics": {"config": {"exportIntervalMillis": 10000}}}}}`), 0644)
+	t.Setenv(CDSConfigEnv, `{"requires": {"telemetry": {"tracing": {"hrtime": true}}}}`)
+
+	loader := NewLoader()
+	loader.cdsDir = dir
+	config, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if got := config.Tracing.Sampler.IgnoreIncomingPaths; len(got) != 1 || got[0] != "/health" {
+		t.Errorf("Expected ignoreIncomingPaths to be mapped, got %v", got)
+	}
+	if config.Metrics.Config.ExportIntervalMillis != 10000 {
+		t.Errorf("Expected .cdsrc.json to override package.json, got %d", config.Metrics.Config.ExportIntervalMillis)
+	}
+	if config.Metrics.DBPool {
+		t.Error("Expected _db_pool to be mapped")
+	}
+	if !config.Tracing.HRTime {
+		t.Error("Expected CDS_CONFIG to be applied")
+	}
+}
+
+func TestCDSTelemetryShorthand(t *testing.T) {
+	m, err := cdsTelemetry([]byte(`{"requires": {"telemetry": "telemetry-to-jaeger"}}`), []string{"requires", "telemetry"})
+	if err != nil || m["kind"] != "telemetry-to-jaeger" {
+		t.Errorf("Expected kind shorthand, got %v (%v)", m, err)
+	}
+
+	m, err = cdsTelemetry([]byte(`{"requires": {"telemetry": false}}`), []string{"requires", "telemetry"})
+	if err != nil || m["disabled"] != true {
+		t.Errorf("Expected telemetry: false to disable, got %v (%v)", m, err)
+	}
+}
@@ -11,15 +11,61 @@ type Config struct {
 	ServiceName string `mapstructure:"service_name" yaml:"service_name" json:"service_name"`
 	Kind        string `mapstructure:"kind" yaml:"kind" json:"kind"`
 
+	// FailOpen lets New continue when an exporter can't be created, with
+	// the console exporter for the affected signal. Unset, it is on for
+	// all kinds but telemetry-to-console, see IsFailOpen.
+	FailOpen *bool `mapstructure:"fail_open" yaml:"fail_open" json:"fail_open,omitempty"`
+
 	// Telemetry signals
 	Tracing *TracingConfig `mapstructure:"tracing" yaml:"tracing" json:"tracing"`
 	Metrics *MetricsConfig `mapstructure:"metrics" yaml:"metrics" json:"metrics"`
 	Logging *LoggingConfig `mapstructure:"logging" yaml:"logging" json:"logging"`
 
+	// Exporter configures the exporters of all signals at once, with
+	// per-signal overrides; it is resolved into the signals' exporters on Load
+	Exporter *SharedExporterConfig `mapstructure:"exporter" yaml:"exporter" json:"exporter,omitempty"`
+
+	// Resource configures the attributes describing the service
+	Resource *ResourceConfig `mapstructure:"resource" yaml:"resource" json:"resource"`
+
+	// CorrelationID propagates X-Correlation-ID / x-vcap-request-id and
+	// stamps it onto spans and log records as correlation_id
+	CorrelationID bool `mapstructure:"correlation_id" yaml:"correlation_id" json:"correlation_id"`
+
+	// Tenant stamps sap.tenant_id onto spans, span metrics and log records
+	Tenant *TenantConfig `mapstructure:"tenant" yaml:"tenant" json:"tenant"`
+
+	// Redaction of sensitive attribute values before export
+	Redaction *RedactionConfig `mapstructure:"redaction" yaml:"redaction" json:"redaction"`
+
+	// Remote overrides sampling, log levels and enabled signals at runtime
+	Remote *RemoteConfig `mapstructure:"remote" yaml:"remote" json:"remote"`
+
+	// Profiling captures pprof profiles continuously
+	Profiling *ProfilingConfig `mapstructure:"profiling" yaml:"profiling" json:"profiling"`
+
+	// Dashboard records recent traces, logs and metric values for the
+	// development UI served by DashboardHandler
+	Dashboard *DashboardConfig `mapstructure:"dashboard" yaml:"dashboard" json:"dashboard"`
+
+	// FlightRecorder keeps the most recent spans and logs in memory, to
+	// dump them when an incident occurs
+	FlightRecorder *FlightRecorderConfig `mapstructure:"flight_recorder" yaml:"flight_recorder" json:"flight_recorder"`
+
+	// Heartbeat runs probes periodically as synthetic transactions and
+	// records their availability
+	Heartbeat *HeartbeatConfig `mapstructure:"heartbeat" yaml:"heartbeat" json:"heartbeat"`
+
 	// Instrumentations
 	Instrumentations map[string]*InstrumentationConfig `mapstructure:"instrumentations" yaml:"instrumentations" json:"instrumentations"`
 }
 
+// Placeholders of the tracing.trace_url template
+const (
+	TraceIDPlaceholder     = "{trace_id}"
+	ServiceNamePlaceholder = "{service_name}"
+)
+
 // TracingConfig configures distributed tracing
 type TracingConfig struct {
 	Enabled    bool            `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
@@ -28,6 +74,43 @@ type TracingConfig struct {
 	HRTime     bool            `mapstructure:"hrtime" yaml:"hrtime" json:"hrtime"`
 	TxEnabled  bool            `mapstructure:"_tx" yaml:"_tx" json:"_tx"`
 	HanaPrompt bool            `mapstructure:"_hana_prom" yaml:"_hana_prom" json:"_hana_prom"`
+
+	// SpanMetrics derives request/error/duration metrics from ended spans
+	SpanMetrics bool `mapstructure:"span_metrics" yaml:"span_metrics" json:"span_metrics"`
+
+	// BaggageAttributes lists baggage keys copied onto every started span
+	BaggageAttributes []string `mapstructure:"baggage_attributes" yaml:"baggage_attributes" json:"baggage_attributes"`
+
+	// Propagators lists the context propagation formats (tracecontext, baggage,
+	// b3, b3multi, jaeger, xray, ottrace, correlation, none); OTEL_PROPAGATORS
+	// takes precedence
+	Propagators []string `mapstructure:"propagators" yaml:"propagators" json:"propagators"`
+
+	// AttributeFilter removes span attributes before export
+	AttributeFilter *AttributeFilterConfig `mapstructure:"attribute_filter" yaml:"attribute_filter" json:"attribute_filter"`
+
+	// MaxAttributeLength truncates longer string attribute values of spans
+	// and span events before export; 0 disables it
+	MaxAttributeLength int `mapstructure:"max_attribute_length" yaml:"max_attribute_length" json:"max_attribute_length"`
+
+	// DropSpans drops ended spans matching any of the rules, e.g. health
+	// checks created by third-party libraries
+	DropSpans []*DropSpanConfig `mapstructure:"drop_spans" yaml:"drop_spans" json:"drop_spans"`
+
+	// Processor is batch (default) or simple; simple exports each span
+	// synchronously when it ends, for local development
+	Processor string `mapstructure:"processor" yaml:"processor" json:"processor"`
+
+	// Batcher tunes the batch span processor
+	Batcher *BatcherConfig `mapstructure:"batcher" yaml:"batcher" json:"batcher"`
+
+	// TraceURL is the template of links to traces in the tracing backend,
+	// with the placeholders {trace_id} and {service_name}
+	TraceURL string `mapstructure:"trace_url" yaml:"trace_url" json:"trace_url"`
+
+	// StartupSpan records the setup of the telemetry as a telemetry.startup
+	// span with the startup report
+	StartupSpan bool `mapstructure:"startup_span" yaml:"startup_span" json:"startup_span"`
 }
 
 // MetricsConfig configures metrics collection
@@ -39,12 +122,307 @@ type MetricsConfig struct {
 	Queue          bool                 `mapstructure:"_queue" yaml:"_queue" json:"_queue"`
 	HostMetrics    bool                 `mapstructure:"host_metrics" yaml:"host_metrics" json:"host_metrics"`
 	RuntimeMetrics bool                 `mapstructure:"runtime_metrics" yaml:"runtime_metrics" json:"runtime_metrics"`
+
+	// GoroutineDiagnostics reports goroutine count changes, blocked
+	// goroutines and scheduler latency to catch leaks
+	GoroutineDiagnostics *GoroutineDiagnosticsConfig `mapstructure:"goroutine_diagnostics" yaml:"goroutine_diagnostics" json:"goroutine_diagnostics"`
+
+	// Views customize instruments: rename, filter attributes, set buckets or drop them
+	Views []*MetricViewConfig `mapstructure:"views" yaml:"views" json:"views"`
+
+	// CardinalityLimit caps the distinct attribute sets per instrument;
+	// further ones are collapsed into otel.metric.overflow=true. 0 disables it.
+	CardinalityLimit int `mapstructure:"cardinality_limit" yaml:"cardinality_limit" json:"cardinality_limit"`
+}
+
+// GoroutineDiagnosticsConfig configures the goroutine diagnostics metrics
+type GoroutineDiagnosticsConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// BlockedThresholdMillis is how long a goroutine waits before it counts
+	// as blocked, in whole minutes; 0 keeps 5 minutes
+	BlockedThresholdMillis int `mapstructure:"blocked_threshold_millis" yaml:"blocked_threshold_millis" json:"blocked_threshold_millis"`
+
+	// Labels lists the runtime/pprof label keys goroutines are counted by
+	Labels []string `mapstructure:"labels" yaml:"labels" json:"labels"`
+}
+
+// GetBlockedThreshold returns the blocked threshold, 0 for the default
+func (g *GoroutineDiagnosticsConfig) GetBlockedThreshold() time.Duration {
+	return time.Duration(g.BlockedThresholdMillis) * time.Millisecond
+}
+
+// MetricViewConfig configures a view applied to the matching instruments
+type MetricViewConfig struct {
+	// Instrument is the instrument name to match; "*" and "?" wildcards are supported
+	Instrument string `mapstructure:"instrument" yaml:"instrument" json:"instrument"`
+	// Meter optionally restricts the view to a meter (instrumentation scope) name
+	Meter string `mapstructure:"meter" yaml:"meter" json:"meter"`
+
+	// Name renames the instrument; not allowed with wildcards
+	Name        string `mapstructure:"name" yaml:"name" json:"name"`
+	Description string `mapstructure:"description" yaml:"description" json:"description"`
+	// AttributeKeys keeps only these attributes, DropAttributes removes these
+	AttributeKeys  []string `mapstructure:"attribute_keys" yaml:"attribute_keys" json:"attribute_keys"`
+	DropAttributes []string `mapstructure:"drop_attributes" yaml:"drop_attributes" json:"drop_attributes"`
+	// Buckets sets explicit histogram bucket boundaries
+	Buckets []float64 `mapstructure:"buckets" yaml:"buckets" json:"buckets"`
+	// Drop disables the instrument
+	Drop bool `mapstructure:"drop" yaml:"drop" json:"drop"`
 }
 
 // LoggingConfig configures logging export
 type LoggingConfig struct {
 	Enabled  bool            `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
 	Exporter *ExporterConfig `mapstructure:"exporter" yaml:"exporter" json:"exporter"`
+
+	// Level is the minimum severity exported (trace, debug, info, warn, error, fatal)
+	Level string `mapstructure:"level" yaml:"level" json:"level"`
+
+	// SpanEvents records error logs as events on the active span,
+	// SpanErrorStatus additionally marks that span as failed
+	SpanEvents      bool `mapstructure:"span_events" yaml:"span_events" json:"span_events"`
+	SpanErrorStatus bool `mapstructure:"span_error_status" yaml:"span_error_status" json:"span_error_status"`
+
+	// MaxAttributeLength truncates longer string attribute values of log
+	// records before export; 0 disables it
+	MaxAttributeLength int `mapstructure:"max_attribute_length" yaml:"max_attribute_length" json:"max_attribute_length"`
+
+	// RateLimit suppresses floods of identical log messages
+	RateLimit *LogRateLimitConfig `mapstructure:"rate_limit" yaml:"rate_limit" json:"rate_limit"`
+
+	// Deduplication collapses identical consecutive log records
+	Deduplication *LogDeduplicationConfig `mapstructure:"deduplication" yaml:"deduplication" json:"deduplication"`
+
+	// Batcher tunes the batch log processor
+	Batcher *BatcherConfig `mapstructure:"batcher" yaml:"batcher" json:"batcher"`
+
+	// Audit configures the separate audit log pipeline
+	Audit *AuditLogConfig `mapstructure:"audit" yaml:"audit" json:"audit"`
+
+	// StartupReport emits the startup report as a telemetry.startup record
+	StartupReport bool `mapstructure:"startup_report" yaml:"startup_report" json:"startup_report"`
+
+	// RuntimeEvents logs long GC pauses and heap growth beyond a watermark
+	RuntimeEvents *RuntimeEventsConfig `mapstructure:"runtime_events" yaml:"runtime_events" json:"runtime_events"`
+}
+
+// RuntimeEventsConfig configures the GC pause and memory pressure events
+type RuntimeEventsConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// GCPauseThresholdMillis is the GC pause above which an event is
+	// logged; 0 keeps 10 milliseconds
+	GCPauseThresholdMillis int `mapstructure:"gc_pause_threshold_millis" yaml:"gc_pause_threshold_millis" json:"gc_pause_threshold_millis"`
+
+	// HeapWatermarkBytes is the live heap above which an event is logged;
+	// 0 keeps 90% of GOMEMLIMIT, no heap events without limit
+	HeapWatermarkBytes int64 `mapstructure:"heap_watermark_bytes" yaml:"heap_watermark_bytes" json:"heap_watermark_bytes"`
+
+	// SpanEvents adds the events to the active local root spans as well
+	SpanEvents bool `mapstructure:"span_events" yaml:"span_events" json:"span_events"`
+}
+
+// GetGCPauseThreshold returns the GC pause threshold, 0 for the default
+func (r *RuntimeEventsConfig) GetGCPauseThreshold() time.Duration {
+	return time.Duration(r.GCPauseThresholdMillis) * time.Millisecond
+}
+
+// AuditLogConfig configures the audit logger. Audit events bypass the
+// level filter and rate limiter and are exported synchronously.
+type AuditLogConfig struct {
+	// Enabled turns on audit logging, independently of logging.enabled
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Exporter defaults to the console
+	Exporter *ExporterConfig `mapstructure:"exporter" yaml:"exporter" json:"exporter"`
+}
+
+// AttributeFilterConfig lists attribute keys or globs (e.g. "internal.*");
+// Allow keeps only the matching attributes, Deny then removes matches
+type AttributeFilterConfig struct {
+	Allow []string `mapstructure:"allow" yaml:"allow" json:"allow"`
+	Deny  []string `mapstructure:"deny" yaml:"deny" json:"deny"`
+}
+
+// BatcherConfig tunes a batch processor; zero values keep the SDK defaults
+type BatcherConfig struct {
+	MaxQueueSize        int `mapstructure:"max_queue_size" yaml:"max_queue_size" json:"max_queue_size"`
+	MaxExportBatchSize  int `mapstructure:"max_export_batch_size" yaml:"max_export_batch_size" json:"max_export_batch_size"`
+	ScheduleDelayMillis int `mapstructure:"schedule_delay_millis" yaml:"schedule_delay_millis" json:"schedule_delay_millis"`
+	ExportTimeoutMillis int `mapstructure:"export_timeout_millis" yaml:"export_timeout_millis" json:"export_timeout_millis"`
+}
+
+// LogRateLimitConfig configures the per-message token bucket of the log pipeline
+type LogRateLimitConfig struct {
+	Enabled   bool    `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	PerSecond float64 `mapstructure:"per_second" yaml:"per_second" json:"per_second"`
+	Burst     int     `mapstructure:"burst" yaml:"burst" json:"burst"`
+}
+
+// LogDeduplicationConfig configures the collapsing of identical consecutive
+// log records into one with an occurrences attribute
+type LogDeduplicationConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// WindowMillis is how long identical records are collapsed, which
+	// delays every record by up to the window; 0 keeps one second
+	WindowMillis int `mapstructure:"window_millis" yaml:"window_millis" json:"window_millis"`
+}
+
+// GetWindow returns the deduplication window, 0 if unset
+func (d *LogDeduplicationConfig) GetWindow() time.Duration {
+	return time.Duration(d.WindowMillis) * time.Millisecond
+}
+
+// DefaultMaxTenants is the number of distinct tenants recorded in metrics
+// unless tenant.max_tenants is set
+const DefaultMaxTenants = 100
+
+// TenantConfig configures the tenant attribute of multitenant applications
+type TenantConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// MaxTenants caps the distinct tenants recorded in metrics, further
+	// ones are recorded as "_other". The tenant comes from inbound baggage,
+	// so it is capped by default; 0 keeps DefaultMaxTenants and a negative
+	// value means unlimited.
+	MaxTenants int `mapstructure:"max_tenants" yaml:"max_tenants" json:"max_tenants"`
+}
+
+// GetMaxTenants returns the tenant limit for metrics, 0 if unlimited
+func (c *TenantConfig) GetMaxTenants() int {
+	switch {
+	case c.MaxTenants < 0:
+		return 0
+	case c.MaxTenants == 0:
+		return DefaultMaxTenants
+	default:
+		return c.MaxTenants
+	}
+}
+
+// ProfilingConfig configures continuous profiling
+type ProfilingConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// Types lists the profile types captured (cpu, heap, allocs, goroutine,
+	// mutex, block); defaults to cpu and heap
+	Types []string `mapstructure:"types" yaml:"types" json:"types"`
+
+	// IntervalMillis is how often profiles are captured, CPUDurationMillis
+	// how long each CPU profile runs; 0 keeps 60 and 10 seconds
+	IntervalMillis    int `mapstructure:"interval_millis" yaml:"interval_millis" json:"interval_millis"`
+	CPUDurationMillis int `mapstructure:"cpu_duration_millis" yaml:"cpu_duration_millis" json:"cpu_duration_millis"`
+
+	// TraceLabels labels the samples of sampled local root spans with
+	// their trace and span IDs
+	TraceLabels bool `mapstructure:"trace_labels" yaml:"trace_labels" json:"trace_labels"`
+
+	// Exporter is pyroscope (url, tags, basic_auth_user,
+	// basic_auth_password) or file (dir)
+	Exporter *ExporterConfig `mapstructure:"exporter" yaml:"exporter" json:"exporter"`
+}
+
+// GetInterval returns the profiling interval, 0 for the default
+func (c *ProfilingConfig) GetInterval() time.Duration {
+	return time.Duration(c.IntervalMillis) * time.Millisecond
+}
+
+// GetCPUDuration returns the CPU profile duration, 0 for the default
+func (c *ProfilingConfig) GetCPUDuration() time.Duration {
+	return time.Duration(c.CPUDurationMillis) * time.Millisecond
+}
+
+// DashboardConfig configures the development dashboard. Spans and logs
+// are recorded before redaction, do not enable it in production.
+type DashboardConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// MaxTraces and MaxLogs size the ring buffers; 0 keeps 100 traces and
+	// 500 log records
+	MaxTraces int `mapstructure:"max_traces" yaml:"max_traces" json:"max_traces"`
+	MaxLogs   int `mapstructure:"max_logs" yaml:"max_logs" json:"max_logs"`
+}
+
+// FlightRecorderConfig configures the flight recorder. Spans dropped by
+// the sampler are recorded as well while it is enabled, which costs the
+// overhead of recording every span.
+type FlightRecorderConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// MaxSpans and MaxLogs size the ring buffers; 0 keeps 10000 each
+	MaxSpans int `mapstructure:"max_spans" yaml:"max_spans" json:"max_spans"`
+	MaxLogs  int `mapstructure:"max_logs" yaml:"max_logs" json:"max_logs"`
+
+	// Dir is the directory dumps are written to; defaults to the
+	// temporary directory
+	Dir string `mapstructure:"dir" yaml:"dir" json:"dir"`
+}
+
+// HeartbeatConfig configures the synthetic transaction monitor
+type HeartbeatConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+
+	// IntervalMillis is the time between two runs of the probes; 0 keeps
+	// one minute
+	IntervalMillis int `mapstructure:"interval_millis" yaml:"interval_millis" json:"interval_millis"`
+	// TimeoutMillis bounds a probe run; 0 keeps 10 seconds
+	TimeoutMillis int `mapstructure:"timeout_millis" yaml:"timeout_millis" json:"timeout_millis"`
+
+	// URLs are probed with GET requests, keyed by probe name; further
+	// probes are registered in code
+	URLs map[string]string `mapstructure:"urls" yaml:"urls" json:"urls"`
+}
+
+// GetInterval returns the interval of the probe runs, 0 if unset
+func (h *HeartbeatConfig) GetInterval() time.Duration {
+	return time.Duration(h.IntervalMillis) * time.Millisecond
+}
+
+// GetTimeout returns the timeout of a probe run, 0 if unset
+func (h *HeartbeatConfig) GetTimeout() time.Duration {
+	return time.Duration(h.TimeoutMillis) * time.Millisecond
+}
+
+// ResourceConfig configures the resource attributes and their detection
+type ResourceConfig struct {
+	// Attributes are static attributes added to the resource, e.g. deployment.environment
+	Attributes map[string]string `mapstructure:"attributes" yaml:"attributes" json:"attributes"`
+
+	// Detectors lists the opt-in cloud detectors to run (aws_ec2, gcp, azure)
+	Detectors []string `mapstructure:"detectors" yaml:"detectors" json:"detectors"`
+	// DetectorTimeoutMillis bounds the time spent on cloud detection at startup
+	DetectorTimeoutMillis int `mapstructure:"detector_timeout_millis" yaml:"detector_timeout_millis" json:"detector_timeout_millis"`
+}
+
+// GetDetectorTimeout returns the cloud detection timeout as a duration
+func (r *ResourceConfig) GetDetectorTimeout() time.Duration {
+	if r.DetectorTimeoutMillis <= 0 {
+		return time.Second // Default to 1 second
+	}
+	return time.Duration(r.DetectorTimeoutMillis) * time.Millisecond
+}
+
+// RedactionConfig configures masking of sensitive values in spans, logs and metrics
+type RedactionConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Keys are attribute keys masked entirely, in addition to the built-in ones
+	Keys []string `mapstructure:"keys" yaml:"keys" json:"keys"`
+	// Patterns are regular expressions masked inside string values
+	Patterns []string `mapstructure:"patterns" yaml:"patterns" json:"patterns"`
+	Mask     string   `mapstructure:"mask" yaml:"mask" json:"mask"`
+}
+
+// RemoteConfig configures the remote configuration endpoint
+type RemoteConfig struct {
+	// URL serves the settings document; remote configuration is off if empty
+	URL                string `mapstructure:"url" yaml:"url" json:"url"`
+	PollIntervalMillis int    `mapstructure:"poll_interval_millis" yaml:"poll_interval_millis" json:"poll_interval_millis"`
+	// PublicKey is a base64 encoded ed25519 key; if set, documents must be signed
+	PublicKey string `mapstructure:"public_key" yaml:"public_key" json:"public_key"`
+}
+
+// GetPollInterval returns the interval between two polls, 0 if unset
+func (r *RemoteConfig) GetPollInterval() time.Duration {
+	return time.Duration(r.PollIntervalMillis) * time.Millisecond
 }
 
 // SamplerConfig configures trace sampling
@@ -53,6 +431,38 @@ type SamplerConfig struct {
 	Root                string   `mapstructure:"root" yaml:"root" json:"root"`
 	Ratio               float64  `mapstructure:"ratio" yaml:"ratio" json:"ratio"`
 	IgnoreIncomingPaths []string `mapstructure:"ignore_incoming_paths" yaml:"ignore_incoming_paths" json:"ignore_incoming_paths"`
+
+	// Rules are evaluated in order before the sampler above; the first
+	// matching rule decides
+	Rules []*SamplingRuleConfig `mapstructure:"rules" yaml:"rules" json:"rules"`
+
+	// DebugHeader forces sampling of requests carrying it (e.g. X-Debug-Trace: 1)
+	// through Telemetry.DebugHeaderMiddleware or router.WithDebugHeader,
+	// DebugBaggageKey of requests carrying that baggage entry
+	DebugHeader     string `mapstructure:"debug_header" yaml:"debug_header" json:"debug_header"`
+	DebugBaggageKey string `mapstructure:"debug_baggage_key" yaml:"debug_baggage_key" json:"debug_baggage_key"`
+}
+
+// SamplingRuleConfig samples matching root spans at a fixed ratio
+type SamplingRuleConfig struct {
+	Name string `mapstructure:"name" yaml:"name" json:"name"`
+	// Route is a glob matched against http.route or url.path; "/**" matches any suffix
+	Route string `mapstructure:"route" yaml:"route" json:"route"`
+	// SpanKind is one of server, client, producer, consumer, internal
+	SpanKind   string            `mapstructure:"span_kind" yaml:"span_kind" json:"span_kind"`
+	Attributes map[string]string `mapstructure:"attributes" yaml:"attributes" json:"attributes"`
+	// Ratio is the fraction of matching traces sampled; 0 drops them
+	Ratio float64 `mapstructure:"ratio" yaml:"ratio" json:"ratio"`
+}
+
+// DropSpanConfig matches spans to drop; all non-empty criteria must match
+type DropSpanConfig struct {
+	// Name is a glob matched against the span name
+	Name string `mapstructure:"name" yaml:"name" json:"name"`
+	// SpanKind is one of server, client, producer, consumer, internal
+	SpanKind string `mapstructure:"span_kind" yaml:"span_kind" json:"span_kind"`
+	// Attributes maps attribute keys to globs matched against their values
+	Attributes map[string]string `mapstructure:"attributes" yaml:"attributes" json:"attributes"`
 }
 
 // ExporterConfig configures telemetry exporters
@@ -65,6 +475,21 @@ type ExporterConfig struct {
 // MetricsExportConfig configures metrics export behavior
 type MetricsExportConfig struct {
 	ExportIntervalMillis int `mapstructure:"export_interval_millis" yaml:"export_interval_millis" json:"export_interval_millis"`
+	// ExportTimeoutMillis bounds each export, including the last one on
+	// shutdown; 0 keeps the SDK default of 30 seconds
+	ExportTimeoutMillis int `mapstructure:"export_timeout_millis" yaml:"export_timeout_millis" json:"export_timeout_millis"`
+	// ExportJitterMillis delays each export by a random duration below it,
+	// spreading the exports of instances started together
+	ExportJitterMillis int `mapstructure:"export_jitter_millis" yaml:"export_jitter_millis" json:"export_jitter_millis"`
+	// AlignExports aligns exports to multiples of the interval on the wall
+	// clock, e.g. full minutes
+	AlignExports bool `mapstructure:"align_exports" yaml:"align_exports" json:"align_exports"`
+
+	// Temporality is cumulative (default), delta or lowmemory
+	Temporality string `mapstructure:"temporality" yaml:"temporality" json:"temporality"`
+	// HistogramAggregation is explicit_bucket_histogram (default) or
+	// base2_exponential_bucket_histogram
+	HistogramAggregation string `mapstructure:"histogram_aggregation" yaml:"histogram_aggregation" json:"histogram_aggregation"`
 }
 
 // InstrumentationConfig configures individual instrumentations
@@ -83,6 +508,14 @@ type PredefinedKind struct {
 	Logging   *LoggingConfig `yaml:"logging" json:"logging"`
 	VCAP      *VCAPConfig    `yaml:"vcap" json:"vcap"`
 	TokenName string         `yaml:"token_name" json:"token_name"`
+
+	// Extends names the kind whose settings this kind starts from
+	Extends string `yaml:"extends" json:"extends,omitempty"`
+
+	// settings holds the tracing, metrics and logging sections as written
+	// in the kinds: section, including false and 0; nil for kinds defined
+	// in code, whose zero values are left out
+	settings map[string]interface{}
 }
 
 // VCAPConfig for cloud foundry service binding
@@ -98,11 +531,43 @@ func (m *MetricsExportConfig) GetExportInterval() time.Duration {
 	return time.Duration(m.ExportIntervalMillis) * time.Millisecond
 }
 
+// GetExportTimeout returns the timeout of a single metric export, 0 if unset
+func (m *MetricsExportConfig) GetExportTimeout() time.Duration {
+	return time.Duration(m.ExportTimeoutMillis) * time.Millisecond
+}
+
+// GetExportJitter returns the maximum random delay of a metric export, 0 if unset
+func (m *MetricsExportConfig) GetExportJitter() time.Duration {
+	return time.Duration(m.ExportJitterMillis) * time.Millisecond
+}
+
+// GetScheduleDelay returns the delay between two exports, 0 if unset
+func (b *BatcherConfig) GetScheduleDelay() time.Duration {
+	return time.Duration(b.ScheduleDelayMillis) * time.Millisecond
+}
+
+// GetExportTimeout returns the timeout of a single export, 0 if unset
+func (b *BatcherConfig) GetExportTimeout() time.Duration {
+	return time.Duration(b.ExportTimeoutMillis) * time.Millisecond
+}
+
 // IsEnabled returns whether the given configuration is enabled
 func (c *Config) IsEnabled() bool {
 	return !c.Disabled
 }
 
+// DevelopmentKind is the predefined kind for local development, which
+// fails on exporter errors unless fail_open is set
+const DevelopmentKind = "telemetry-to-console"
+
+// IsFailOpen returns whether exporter errors are tolerated on startup
+func (c *Config) IsFailOpen() bool {
+	if c.FailOpen != nil {
+		return *c.FailOpen
+	}
+	return c.Kind != DevelopmentKind
+}
+
 // IsTracingEnabled returns whether tracing is enabled
 func (c *Config) IsTracingEnabled() bool {
 	return c.IsEnabled() && c.Tracing != nil && c.Tracing.Enabled
@@ -113,6 +578,42 @@ func (c *Config) IsMetricsEnabled() bool {
 	return c.IsEnabled() && c.Metrics != nil && c.Metrics.Enabled
 }
 
+// IsTenantEnabled returns whether the tenant attribute is enabled
+func (c *Config) IsTenantEnabled() bool {
+	return c.IsEnabled() && c.Tenant != nil && c.Tenant.Enabled
+}
+
+// IsProfilingEnabled returns whether continuous profiling is enabled
+func (c *Config) IsProfilingEnabled() bool {
+	return c.IsEnabled() && c.Profiling != nil && c.Profiling.Enabled
+}
+
+// IsDashboardEnabled returns whether the development dashboard is enabled
+func (c *Config) IsDashboardEnabled() bool {
+	return c.IsEnabled() && c.Dashboard != nil && c.Dashboard.Enabled
+}
+
+// IsFlightRecorderEnabled returns whether the flight recorder is enabled
+func (c *Config) IsFlightRecorderEnabled() bool {
+	return c.IsEnabled() && c.FlightRecorder != nil && c.FlightRecorder.Enabled
+}
+
+// IsHeartbeatEnabled returns whether the heartbeat probes run
+func (c *Config) IsHeartbeatEnabled() bool {
+	return c.IsEnabled() && c.Heartbeat != nil && c.Heartbeat.Enabled
+}
+
+// IsRuntimeEventsEnabled returns whether GC pause and memory pressure
+// events are logged
+func (c *Config) IsRuntimeEventsEnabled() bool {
+	return c.IsLoggingEnabled() && c.Logging.RuntimeEvents != nil && c.Logging.RuntimeEvents.Enabled
+}
+
+// IsAuditEnabled returns whether audit logging is enabled
+func (c *Config) IsAuditEnabled() bool {
+	return c.IsEnabled() && c.Logging != nil && c.Logging.Audit != nil && c.Logging.Audit.Enabled
+}
+
 // IsLoggingEnabled returns whether logging is enabled
 func (c *Config) IsLoggingEnabled() bool {
 	return c.IsEnabled() && c.Logging != nil && c.Logging.Enabled
@@ -2,7 +2,11 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewDefaultConfig(t *testing.T) {
@@ -47,6 +51,25 @@ func TestConfigIsEnabled(t *testing.T) {
 	}
 }
 
+func TestConfigIsFailOpen(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Kind = DevelopmentKind
+	if config.IsFailOpen() {
+		t.Error("Expected telemetry-to-console to fail on exporter errors")
+	}
+
+	config.Kind = "telemetry-to-dynatrace"
+	if !config.IsFailOpen() {
+		t.Error("Expected production kinds to fail open by default")
+	}
+
+	failOpen := false
+	config.FailOpen = &failOpen
+	if config.IsFailOpen() {
+		t.Error("Expected fail_open: false to take precedence over the kind")
+	}
+}
+
 func TestEnvVarParsing(t *testing.T) {
 	// Test NO_TELEMETRY environment variable
 	os.Setenv("NO_TELEMETRY", "true")
@@ -76,6 +99,34 @@ func TestPredefinedKinds(t *testing.T) {
 	}
 }
 
+func TestPredefinedKindProcessor(t *testing.T) {
+	loader := NewLoader()
+
+	dev := &Config{Kind: "telemetry-to-console", Tracing: &TracingConfig{}}
+	if err := loader.applyPredefinedKind(dev); err != nil {
+		t.Fatalf("Failed to apply kind: %v", err)
+	}
+	if dev.Tracing.Processor != "simple" {
+		t.Errorf("Expected simple processor for console kind, got %q", dev.Tracing.Processor)
+	}
+
+	prod := &Config{Kind: "telemetry-to-dynatrace", Tracing: &TracingConfig{}}
+	if err := loader.applyPredefinedKind(prod); err != nil {
+		t.Fatalf("Failed to apply kind: %v", err)
+	}
+	if prod.Tracing.Processor != "" {
+		t.Errorf("Expected batch processor for dynatrace kind, got %q", prod.Tracing.Processor)
+	}
+
+	explicit := &Config{Kind: "telemetry-to-console", Tracing: &TracingConfig{Processor: "batch"}}
+	if err := loader.applyPredefinedKind(explicit); err != nil {
+		t.Fatalf("Failed to apply kind: %v", err)
+	}
+	if explicit.Tracing.Processor != "batch" {
+		t.Error("Expected explicit processor to be kept")
+	}
+}
+
 func TestConfigLoader(t *testing.T) {
 	loader := NewLoader()
 
@@ -104,4 +155,250 @@ func TestMetricsExportInterval(t *testing.T) {
 	if interval.Nanoseconds() != int64(expected) {
 		t.Errorf("Expected interval %d, got %d", expected, interval.Nanoseconds())
 	}
+	if config.GetExportTimeout() != 0 {
+		t.Errorf("Expected no export timeout by default, got %v", config.GetExportTimeout())
+	}
+	config.ExportTimeoutMillis = 5000
+	if config.GetExportTimeout() != 5*time.Second {
+		t.Errorf("Expected export timeout 5s, got %v", config.GetExportTimeout())
+	}
+}
+
+func TestBatcherConfig(t *testing.T) {
+	b := &BatcherConfig{ScheduleDelayMillis: 500, ExportTimeoutMillis: 10000}
+
+	if b.GetScheduleDelay() != 500*time.Millisecond {
+		t.Errorf("Expected schedule delay 500ms, got %v", b.GetScheduleDelay())
+	}
+	if b.GetExportTimeout() != 10*time.Second {
+		t.Errorf("Expected export timeout 10s, got %v", b.GetExportTimeout())
+	}
+}
+
+func TestConfigFileFormats(t *testing.T) {
+	files := map[string]string{
+		"telemetry.json": `{"service_name": "json-service", "tracing": {"hrtime": true}}`,
+		"telemetry.toml": "service_name = \"toml-service\"\n\n[tracing]\nhrtime = true\n",
+		"telemetry.yml":  "service_name: yml-service\ntracing:\n  hrtime: true\n",
+	}
+
+	for name, content := range files {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		loader := NewLoader()
+		loader.configPaths = []string{dir}
+		config, err := loader.Load()
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", name, err)
+		}
+		expected := strings.TrimPrefix(name, "telemetry.") + "-service"
+		if config.ServiceName != expected {
+			t.Errorf("Expected service name %q from %s, got %q", expected, name, config.ServiceName)
+		}
+		if !config.Tracing.HRTime {
+			t.Errorf("Expected nested setting from %s to be applied", name)
+		}
+		if loader.GetConfigFile() != filepath.Join(dir, name) {
+			t.Errorf("Expected %s to be used, got %q", name, loader.GetConfigFile())
+		}
+	}
+}
+
+func TestLoadFromFile_TOML(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "custom.toml")
+	os.WriteFile(file, []byte("service_name = \"custom\"\n"), 0644)
+
+	config, err := NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.ServiceName != "custom" {
+		t.Errorf("Expected service name custom, got %q", config.ServiceName)
+	}
+
+	if _, err := NewLoader().LoadFromFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for missing config file")
+	}
+}
+
+func TestLoadFromYAML(t *testing.T) {
+	config, err := NewLoader().LoadFromYAML(`
+service_name: yaml-service
+tracing:
+  enabled: true
+  sampler:
+    kind: AlwaysOnSampler
+  exporter:
+    module: console
+    config:
+      slow_threshold_ms: 250
+`)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.ServiceName != "yaml-service" {
+		t.Errorf("Expected service name yaml-service, got %q", config.ServiceName)
+	}
+	if got := config.Tracing.Exporter.Config["slow_threshold_ms"]; got != 250 {
+		t.Errorf("Expected exporter config to be kept, got %v", got)
+	}
+
+	if _, err := NewLoader().LoadFromYAML("tracing: [unclosed"); err == nil {
+		t.Error("Expected error for invalid YAML")
+	}
+	if _, err := NewLoader().LoadFromYAML("tracing:\n  trace_url: http://localhost:16686/search"); err == nil {
+		t.Error("Expected error for a trace URL without {trace_id}")
+	}
+}
+
+func TestPredefinedKindPartialOverride(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte(`
+kind: telemetry-to-console
+tracing:
+  hrtime: true
+  exporter:
+    config:
+      slow_threshold_ms: 250
+`), 0644)
+
+	config, err := NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	// A single setting under tracing keeps the kind's other settings
+	if config.Tracing.Processor != "simple" {
+		t.Errorf("Expected kind processor to survive a partial override, got %q", config.Tracing.Processor)
+	}
+	if config.Tracing.Exporter.Module != "console" || config.Tracing.Exporter.Config["slow_threshold_ms"] != 250 {
+		t.Errorf("Expected exporter module from the kind and config from the file, got %+v", config.Tracing.Exporter)
+	}
+	if config.Tracing.Sampler == nil || config.Tracing.Sampler.Kind != "ParentBasedSampler" {
+		t.Errorf("Expected default sampler to be kept, got %+v", config.Tracing.Sampler)
+	}
+	if !config.Tracing.HRTime {
+		t.Error("Expected explicit hrtime to be applied")
+	}
+
+	// Environment variables win over the file and the kind
+	t.Setenv("TELEMETRY_TRACING_PROCESSOR", "batch")
+	config, err = NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Tracing.Processor != "batch" {
+		t.Errorf("Expected environment to override the kind, got %q", config.Tracing.Processor)
+	}
+}
+
+func TestPredefinedKindFileOverride(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte(`
+kind: telemetry-to-dynatrace
+tracing:
+  processor: simple
+  exporter:
+    config:
+      token_name: custom
+`), 0644)
+
+	config, err := NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Tracing.Exporter.Module != "otlp" {
+		t.Errorf("Expected otlp exporter from the kind, got %q", config.Tracing.Exporter.Module)
+	}
+	if config.Tracing.Processor != "simple" {
+		t.Errorf("Expected file to override the kind, got %q", config.Tracing.Processor)
+	}
+}
+
+func TestPredefinedKindTemporality(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte("kind: telemetry-to-dynatrace\n"), 0644)
+
+	config, err := NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := config.Metrics.Config.Temporality; got != "delta" {
+		t.Errorf("Expected delta temporality for dynatrace kind, got %q", got)
+	}
+	if config.Metrics.Config.ExportIntervalMillis != 60000 {
+		t.Errorf("Expected the default export interval to be kept, got %d", config.Metrics.Config.ExportIntervalMillis)
+	}
+
+	built, err := NewBuilder().Kind("telemetry-to-dynatrace").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if got := built.Metrics.Config.Temporality; got != "delta" {
+		t.Errorf("Expected delta temporality for dynatrace kind from the builder, got %q", got)
+	}
+
+	// The file overrides the kind
+	os.WriteFile(file, []byte(`
+kind: telemetry-to-dynatrace
+metrics:
+  config:
+    temporality: lowmemory
+`), 0644)
+	config, err = NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := config.Metrics.Config.Temporality; got != "lowmemory" {
+		t.Errorf("Expected file to override the kind temporality, got %q", got)
+	}
+
+	// So does the environment
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", "cumulative")
+	os.WriteFile(file, []byte("kind: telemetry-to-dynatrace\n"), 0644)
+	config, err = NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := config.Metrics.Config.Temporality; got != "cumulative" {
+		t.Errorf("Expected environment to override the kind temporality, got %q", got)
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	dst := &TracingConfig{
+		Processor: "batch",
+		Exporter:  &ExporterConfig{Config: map[string]interface{}{"a": 1, "nested": map[string]interface{}{"x": 1}}},
+	}
+	src := &TracingConfig{
+		Enabled:   true,
+		Processor: "simple",
+		Exporter:  &ExporterConfig{Module: "otlp", Config: map[string]interface{}{"a": 2, "b": 2, "nested": map[string]interface{}{"y": 2}}},
+	}
+	mergeValues(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem(), false)
+
+	if dst.Processor != "batch" || !dst.Enabled || dst.Exporter.Module != "otlp" {
+		t.Errorf("Expected empty fields to be filled and explicit ones kept, got %+v", dst)
+	}
+	cfg := dst.Exporter.Config
+	if cfg["a"] != 1 || cfg["b"] != 2 {
+		t.Errorf("Expected exporter config keys to be merged, got %v", cfg)
+	}
+	if nested := cfg["nested"].(map[string]interface{}); nested["x"] != 1 || nested["y"] != 2 {
+		t.Errorf("Expected nested maps to be merged, got %v", nested)
+	}
+}
+
+func TestTenantMaxTenants(t *testing.T) {
+	for _, tt := range []struct{ configured, expected int }{
+		{0, DefaultMaxTenants},
+		{5, 5},
+		{-1, 0},
+	} {
+		if got := (&TenantConfig{MaxTenants: tt.configured}).GetMaxTenants(); got != tt.expected {
+			t.Errorf("Expected %d tenants for max_tenants %d, got %d", tt.expected, tt.configured, got)
+		}
+	}
 }
@@ -2,7 +2,6 @@ package config
 
 import (
 	"os"
-	"strings"
 )
 
 // NewDefaultConfig creates a new configuration with default values
@@ -57,8 +56,12 @@ func NewDefaultMetricsConfig() *MetricsConfig {
 		Queue:          true,
 		HostMetrics:    getEnvBool("HOST_METRICS_ENABLED", true),
 		RuntimeMetrics: true,
+		// Same default as the OpenTelemetry specification
+		CardinalityLimit: 2000,
 		Config: &MetricsExportConfig{
 			ExportIntervalMillis: 60000, // 60 seconds
+			Temporality:          getEnvString("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", "cumulative"),
+			HistogramAggregation: getEnvString("OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION", "explicit_bucket_histogram"),
 		},
 		Exporter: &ExporterConfig{
 			Module: "console",
@@ -71,7 +74,9 @@ func NewDefaultMetricsConfig() *MetricsConfig {
 // NewDefaultLoggingConfig creates default logging configuration
 func NewDefaultLoggingConfig() *LoggingConfig {
 	return &LoggingConfig{
-		Enabled: false, // Disabled by default, opt-in
+		Enabled:       false, // Disabled by default, opt-in
+		Level:         getEnvString("TELEMETRY_LOG_LEVEL", "info"),
+		StartupReport: true,
 		Exporter: &ExporterConfig{
 			Module: "console",
 			Class:  "ConsoleLogExporter",
@@ -87,6 +92,8 @@ func GetPredefinedKinds() map[string]*PredefinedKind {
 			Name: "telemetry-to-console",
 			Tracing: &TracingConfig{
 				Enabled: true,
+				// Show spans as soon as they end during development
+				Processor: "simple",
 				Exporter: &ExporterConfig{
 					Module: "console",
 					Class:  "ConsoleSpanExporter",
@@ -115,6 +122,10 @@ func GetPredefinedKinds() map[string]*PredefinedKind {
 			},
 			Metrics: &MetricsConfig{
 				Enabled: true,
+				// Dynatrace only accepts delta temporality
+				Config: &MetricsExportConfig{
+					Temporality: getEnvString("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", "delta"),
+				},
 				Exporter: &ExporterConfig{
 					Module: "otlp",
 					Class:  "OTLPMetricExporter",
@@ -182,12 +193,9 @@ func getEnvString(key, defaultValue string) string {
 
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
-		// Consider "false", "0", "" as false, everything else as true
-		switch strings.ToLower(value) {
-		case "false", "0", "":
-			return false
-		default:
-			return true
+		// Invalid values keep the default and are reported by Loader.Load
+		if v, err := parseBool(value); err == nil {
+			return v
 		}
 	}
 	return defaultValue
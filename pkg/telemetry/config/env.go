@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix prefixes the environment variables of nested settings, e.g.
+// TELEMETRY_TRACING_SAMPLER_RATIO for tracing.sampler.ratio
+const EnvPrefix = "TELEMETRY_"
+
+// InstrumentationEnvPrefix and InstrumentationEnvSuffix enclose the name
+// of an instrumentation in the variable turning it on or off, e.g.
+// TELEMETRY_INSTRUMENTATION_HTTP_CLIENT_ENABLED=false
+const (
+	InstrumentationEnvPrefix = "TELEMETRY_INSTRUMENTATION_"
+	InstrumentationEnvSuffix = "_ENABLED"
+)
+
+// defaultBoolEnv are the boolean variables read by NewDefaultConfig
+var defaultBoolEnv = []string{"NO_TELEMETRY", "TELEMETRY_HRTIME", "HOST_METRICS_ENABLED"}
+
+// envBinding maps an environment variable to a setting
+type envBinding struct {
+	env string
+	key string
+	typ reflect.Type
+}
+
+// EnvVars returns the environment variables of all settings, in the order
+// of the Config fields
+func EnvVars() []string {
+	bindings := envBindings()
+	names := make([]string, len(bindings))
+	for i, b := range bindings {
+		names[i] = b.env
+	}
+	return names
+}
+
+// envBindings derives the bindings of all scalar and list settings of Config
+func envBindings() []envBinding {
+	var bindings []envBinding
+	collectEnvBindings(reflect.TypeOf(Config{}), nil, &bindings)
+	return bindings
+}
+
+// collectEnvBindings walks the fields of a struct type, nested structs
+// included. Maps and lists of structs can't be set from the environment.
+func collectEnvBindings(t reflect.Type, path []string, bindings *[]envBinding) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), name)
+
+		typ := field.Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		switch typ.Kind() {
+		case reflect.Struct:
+			collectEnvBindings(typ, fieldPath, bindings)
+			continue
+		case reflect.Bool, reflect.Int, reflect.Float64, reflect.String:
+		case reflect.Slice:
+			if kind := typ.Elem().Kind(); kind != reflect.String && kind != reflect.Float64 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		*bindings = append(*bindings, envBinding{
+			env: EnvPrefix + strings.ToUpper(strings.Join(fieldPath, "_")),
+			key: strings.Join(fieldPath, "::"),
+			typ: typ,
+		})
+	}
+}
+
+// applyEnv sets the settings whose environment variables are set, on top
+// of all other sources. Values that don't parse as the type of their
+// setting are reported together instead of being coerced.
+func (l *Loader) applyEnv() error {
+	var errs []error
+	for _, name := range defaultBoolEnv {
+		if value := os.Getenv(name); value != "" {
+			if _, err := parseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+
+	errs = append(errs, l.applyInstrumentationEnv()...)
+
+	for _, b := range envBindings() {
+		raw := os.Getenv(b.env)
+		if raw == "" {
+			continue
+		}
+		value, err := parseEnvValue(raw, b.typ)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b.env, err))
+			continue
+		}
+		l.v.Set(b.key, value)
+	}
+	return errors.Join(errs...)
+}
+
+// InstrumentationEnv returns the variable turning the instrumentation of
+// the instrumentations map entry name on or off
+func InstrumentationEnv(name string) string {
+	return InstrumentationEnvPrefix + envName(name) + InstrumentationEnvSuffix
+}
+
+// envName converts a config key to its part of a variable name
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// applyInstrumentationEnv sets instrumentations.<name>.enabled from the
+// kill switch variables. Variables are matched against the configured
+// entries; others add an entry named after the lowercased variable part.
+func (l *Loader) applyInstrumentationEnv() []error {
+	configured := make(map[string]string)
+	for name := range l.v.GetStringMap("instrumentations") {
+		configured[envName(name)] = name
+	}
+
+	var errs []error
+	for _, entry := range os.Environ() {
+		env, raw, _ := strings.Cut(entry, "=")
+		part, ok := strings.CutPrefix(env, InstrumentationEnvPrefix)
+		if !ok {
+			continue
+		}
+		part, ok = strings.CutSuffix(part, InstrumentationEnvSuffix)
+		if !ok || part == "" || raw == "" {
+			continue
+		}
+		enabled, err := parseBool(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			continue
+		}
+		name, ok := configured[part]
+		if !ok {
+			name = strings.ToLower(part)
+		}
+		l.v.Set("instrumentations::"+name+"::enabled", enabled)
+	}
+	return errs
+}
+
+// parseEnvValue parses an environment variable as a value of type t; lists
+// are comma-separated
+func parseEnvValue(raw string, t reflect.Type) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		return parseBool(raw)
+	case reflect.Int:
+		v, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", raw)
+		}
+		return v, nil
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", raw)
+		}
+		return v, nil
+	case reflect.Slice:
+		items := splitList(raw)
+		if t.Elem().Kind() == reflect.String {
+			return items, nil
+		}
+		numbers := make([]float64, len(items))
+		for i, item := range items {
+			v, err := strconv.ParseFloat(item, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q in list", item)
+			}
+			numbers[i] = v
+		}
+		return numbers, nil
+	default:
+		return raw, nil
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseBool parses true, false, 1, 0, yes, no, on and off, case-insensitive
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "true", "1", "yes", "on":
+		return true, nil
+	case "false", "0", "no", "off":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q, expected true or false", raw)
+	}
+}
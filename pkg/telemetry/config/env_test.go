@@ -0,0 +1,121 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvVars(t *testing.T) {
+	names := strings.Join(EnvVars(), " ")
+	for _, name := range []string{
+		"TELEMETRY_SERVICE_NAME",
+		"TELEMETRY_TRACING_SAMPLER_RATIO",
+		"TELEMETRY_TRACING_PROPAGATORS",
+		"TELEMETRY_METRICS_CONFIG_EXPORT_INTERVAL_MILLIS",
+		"TELEMETRY_LOGGING_RATE_LIMIT_PER_SECOND",
+	} {
+		if !strings.Contains(names, name) {
+			t.Errorf("Expected binding %s", name)
+		}
+	}
+	// Maps and lists of structs can't be set from the environment
+	if strings.Contains(names, "TELEMETRY_RESOURCE_ATTRIBUTES") || strings.Contains(names, "TELEMETRY_TRACING_DROP_SPANS") {
+		t.Errorf("Expected no bindings of maps or lists of structs, got %s", names)
+	}
+}
+
+func TestLoadNestedEnv(t *testing.T) {
+	t.Setenv("TELEMETRY_TRACING_SAMPLER_ROOT", "TraceIdRatioBasedSampler")
+	t.Setenv("TELEMETRY_TRACING_SAMPLER_RATIO", "0.25")
+	t.Setenv("TELEMETRY_TRACING_PROPAGATORS", "tracecontext, b3")
+	t.Setenv("TELEMETRY_METRICS_CONFIG_EXPORT_INTERVAL_MILLIS", "15000")
+	t.Setenv("TELEMETRY_LOGGING_ENABLED", "yes")
+
+	config, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", "tracing:\n  sampler:\n    kind: ParentBasedSampler\n"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Tracing.Sampler.Ratio != 0.25 || config.Tracing.Sampler.Root != "TraceIdRatioBasedSampler" {
+		t.Errorf("Expected sampler from the environment, got %+v", config.Tracing.Sampler)
+	}
+	if config.Tracing.Sampler.Kind != "ParentBasedSampler" || len(config.Tracing.Sampler.IgnoreIncomingPaths) == 0 {
+		t.Errorf("Expected other sampler settings to be kept, got %+v", config.Tracing.Sampler)
+	}
+	if len(config.Tracing.Propagators) != 2 || config.Tracing.Propagators[1] != "b3" {
+		t.Errorf("Expected comma-separated propagators, got %v", config.Tracing.Propagators)
+	}
+	if config.Metrics.Config.ExportIntervalMillis != 15000 || !config.Logging.Enabled {
+		t.Errorf("Expected export interval and logging from the environment, got %d, %v",
+			config.Metrics.Config.ExportIntervalMillis, config.Logging.Enabled)
+	}
+}
+
+func TestLoadInvalidEnv(t *testing.T) {
+	t.Setenv("TELEMETRY_TRACING_SAMPLER_RATIO", "high")
+	t.Setenv("TELEMETRY_TRACING_ENABLED", "sure")
+	t.Setenv("HOST_METRICS_ENABLED", "maybe")
+
+	_, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", ""))
+	if err == nil {
+		t.Fatal("Expected invalid environment variables to be reported")
+	}
+	for _, expected := range []string{
+		`TELEMETRY_TRACING_SAMPLER_RATIO: invalid number "high"`,
+		`TELEMETRY_TRACING_ENABLED: invalid boolean "sure"`,
+		`HOST_METRICS_ENABLED: invalid boolean "maybe"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %v", expected, err)
+		}
+	}
+
+	// Invalid values keep the default
+	if NewDefaultConfig().Metrics.HostMetrics != true {
+		t.Error("Expected the default for an invalid boolean")
+	}
+	t.Setenv("HOST_METRICS_ENABLED", "off")
+	if NewDefaultConfig().Metrics.HostMetrics {
+		t.Error("Expected off to disable host metrics")
+	}
+}
+
+func TestLoadInstrumentationEnv(t *testing.T) {
+	t.Setenv("TELEMETRY_INSTRUMENTATION_HTTP_CLIENT_ENABLED", "false")
+	t.Setenv("TELEMETRY_INSTRUMENTATION_OUTBOX_ENABLED", "on")
+
+	config, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", `
+instrumentations:
+  http-client:
+    enabled: true
+    config:
+      timing_spans: true
+  db:
+    enabled: true
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	client := config.Instrumentations["http-client"]
+	if client == nil || client.Enabled || client.Config["timing_spans"] != true {
+		t.Errorf("Expected http-client to be disabled with its settings kept, got %+v", client)
+	}
+	if db := config.Instrumentations["db"]; db == nil || !db.Enabled {
+		t.Errorf("Expected db to stay enabled, got %+v", db)
+	}
+	if outbox := config.Instrumentations["outbox"]; outbox == nil || !outbox.Enabled {
+		t.Errorf("Expected outbox to be added and enabled, got %+v", outbox)
+	}
+
+	if got := InstrumentationEnv("http-client"); got != "TELEMETRY_INSTRUMENTATION_HTTP_CLIENT_ENABLED" {
+		t.Errorf("Unexpected variable %s", got)
+	}
+}
+
+func TestLoadInvalidInstrumentationEnv(t *testing.T) {
+	t.Setenv("TELEMETRY_INSTRUMENTATION_DB_ENABLED", "disable")
+
+	_, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", ""))
+	if err == nil || !strings.Contains(err.Error(), "TELEMETRY_INSTRUMENTATION_DB_ENABLED") {
+		t.Errorf("Expected the invalid variable to be reported, got %v", err)
+	}
+}
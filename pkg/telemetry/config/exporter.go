@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+)
+
+// SharedExporterConfig configures the exporters of all signals in one
+// block. Traces, Metrics and Logs override the shared settings per signal,
+// e.g. the endpoint, and settings under tracing.exporter, metrics.exporter
+// and logging.exporter still take precedence over both.
+type SharedExporterConfig struct {
+	Module string                 `mapstructure:"module" yaml:"module" json:"module"`
+	Class  string                 `mapstructure:"class" yaml:"class" json:"class"`
+	Config map[string]interface{} `mapstructure:"config" yaml:"config" json:"config"`
+
+	Traces  *ExporterConfig `mapstructure:"traces" yaml:"traces" json:"traces"`
+	Metrics *ExporterConfig `mapstructure:"metrics" yaml:"metrics" json:"metrics"`
+	Logs    *ExporterConfig `mapstructure:"logs" yaml:"logs" json:"logs"`
+}
+
+// sharedExporterSignals maps the overrides of the shared exporter to the
+// sections of their signals
+var sharedExporterSignals = []struct {
+	override string
+	section  string
+}{
+	{"traces", "tracing"},
+	{"metrics", "metrics"},
+	{"logs", "logging"},
+}
+
+// applySharedExporter resolves the top-level exporter block into the
+// exporters of the signals. For each signal, the shared settings are
+// deep-merged with its override and the signal's own exporter settings, so
+// headers of all three are combined. Predefined kinds only fill in what
+// none of them sets.
+func (l *Loader) applySharedExporter() {
+	if !l.v.IsSet("exporter") {
+		return
+	}
+
+	shared := l.settingsUnder("exporter")
+	for _, signal := range sharedExporterSignals {
+		exporter := map[string]interface{}{}
+		for key, value := range shared {
+			switch key {
+			case "traces", "metrics", "logs":
+			default:
+				exporter[key] = copySettings(value)
+			}
+		}
+		if override, ok := shared[signal.override].(map[string]interface{}); ok {
+			exporter = mergeCDS(exporter, override)
+		}
+		exporter = mergeCDS(exporter, l.settingsUnder(signal.section+"::exporter"))
+		l.v.Set(signal.section+"::exporter", exporter)
+	}
+}
+
+// settingsUnder returns the settings below key as a nested map. The leaf
+// keys are read one by one, as settings of the same section may come from
+// different sources, e.g. the file and environment variables.
+func (l *Loader) settingsUnder(key string) map[string]interface{} {
+	settings := map[string]interface{}{}
+	prefix := key + "::"
+	for _, leaf := range l.v.AllKeys() {
+		if !strings.HasPrefix(leaf, prefix) {
+			continue
+		}
+		path := strings.Split(strings.TrimPrefix(leaf, prefix), "::")
+		section := settings
+		for _, name := range path[:len(path)-1] {
+			next, ok := section[name].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				section[name] = next
+			}
+			section = next
+		}
+		section[path[len(path)-1]] = l.v.Get(leaf)
+	}
+	return settings
+}
+
+// copySettings deep-copies nested maps, so merging into the copy leaves the
+// shared settings of the other signals untouched
+func copySettings(value interface{}) interface{} {
+	settings, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	copied := make(map[string]interface{}, len(settings))
+	for key, v := range settings {
+		copied[key] = copySettings(v)
+	}
+	return copied
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadSharedExporter(t *testing.T) {
+	t.Setenv("TELEMETRY_EXPORTER_LOGS_MODULE", "console")
+
+	config, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", `
+kind: telemetry-to-dynatrace
+exporter:
+  config:
+    endpoint: https://collector.example.com
+    headers:
+      x-tenant: acme
+  metrics:
+    config:
+      endpoint: https://metrics.example.com
+      headers:
+        x-metrics: "1"
+tracing:
+  exporter:
+    config:
+      compression: gzip
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tracing := config.Tracing.Exporter
+	if tracing.Module != "otlp" || tracing.Class != "OTLPTraceExporter" {
+		t.Errorf("Expected the kind's trace exporter, got %s %s", tracing.Module, tracing.Class)
+	}
+	if tracing.Config["endpoint"] != "https://collector.example.com" || tracing.Config["compression"] != "gzip" {
+		t.Errorf("Expected shared endpoint and own compression, got %v", tracing.Config)
+	}
+
+	metrics := config.Metrics.Exporter.Config
+	if metrics["endpoint"] != "https://metrics.example.com" {
+		t.Errorf("Expected metrics endpoint override, got %v", metrics["endpoint"])
+	}
+	headers, _ := metrics["headers"].(map[string]interface{})
+	if headers["x-tenant"] != "acme" || headers["x-metrics"] != "1" {
+		t.Errorf("Expected shared and override headers to be combined, got %v", headers)
+	}
+
+	logging := config.Logging.Exporter
+	if logging.Module != "console" || logging.Config["endpoint"] != "https://collector.example.com" {
+		t.Errorf("Expected logs module from the environment and the shared endpoint, got %+v", logging)
+	}
+}
+
+func TestLoadSignalExporterWins(t *testing.T) {
+	config, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", `
+exporter:
+  module: "otlp"
+  config:
+    endpoint: https://collector.example.com
+tracing:
+  exporter:
+    module: console
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if tracing := config.Tracing.Exporter; tracing.Module != "console" || tracing.Config["endpoint"] != "https://collector.example.com" {
+		t.Errorf("Expected the signal's module over the shared one, got %+v", tracing)
+	}
+	if module := config.Metrics.Exporter.Module; module != "otlp" {
+		t.Errorf("Expected the shared module for metrics, got %s", module)
+	}
+}
+
+func TestLoadWithoutSharedExporter(t *testing.T) {
+	config, err := NewLoader().LoadFromFile(writeConfigFile(t, "telemetry.yaml", "tracing:\n  exporter:\n    module: console\n"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Exporter != nil {
+		t.Errorf("Expected no shared exporter, got %+v", config.Exporter)
+	}
+	if config.Metrics.Exporter.Module != "console" {
+		t.Errorf("Expected the default metrics exporter, got %s", config.Metrics.Exporter.Module)
+	}
+}
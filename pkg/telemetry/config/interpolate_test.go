@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("OTLP_ENDPOINT", "collector:4317")
+	t.Setenv("EMPTY_VALUE", "")
+
+	tests := map[string]string{
+		"endpoint: ${OTLP_ENDPOINT}":             "endpoint: collector:4317",
+		"endpoint: ${OTLP_ENDPOINT:-localhost}":  "endpoint: collector:4317",
+		"endpoint: ${UNSET_ENDPOINT:-localhost}": "endpoint: localhost",
+		"endpoint: ${EMPTY_VALUE:-localhost}":    "endpoint: localhost",
+		"endpoint: ${UNSET_ENDPOINT}":            "endpoint: ",
+		"price: $$${OTLP_ENDPOINT}":              "price: $${OTLP_ENDPOINT}",
+		"literal: $${OTLP_ENDPOINT}":             "literal: ${OTLP_ENDPOINT}",
+		"plain: $HOME":                           "plain: $HOME",
+	}
+	for in, expected := range tests {
+		got, err := interpolate(in)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", in, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, in, got)
+		}
+	}
+
+	for _, invalid := range []string{"${UNTERMINATED", "${1ABC}", "${}"} {
+		if _, err := interpolate(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadFromFile_Interpolation(t *testing.T) {
+	t.Setenv("APP_NAME", "bookshop")
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte("service_name: ${APP_NAME}\nkind: ${TELEMETRY_KIND_OVERRIDE:-telemetry-to-console}\n"), 0644)
+
+	config, err := NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.ServiceName != "bookshop" {
+		t.Errorf("Expected service name from the environment, got %q", config.ServiceName)
+	}
+	if config.Kind != "telemetry-to-console" {
+		t.Errorf("Expected default kind, got %q", config.Kind)
+	}
+}
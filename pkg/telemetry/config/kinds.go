@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	kindsMu         sync.RWMutex
+	registeredKinds = make(map[string]*PredefinedKind)
+)
+
+// RegisterKind makes a kind available to all loaders and builders under
+// kind.Name, e.g. an organization-standard kind shipped by a platform team.
+// Registering a name again replaces the kind; built-in kinds can be
+// replaced as well. Zero values such as false are left out, as they can't be
+// told apart from unset ones; define a kind in the kinds: section to turn a
+// setting off.
+func RegisterKind(kind *PredefinedKind) error {
+	if kind == nil || kind.Name == "" {
+		return errors.New("kind name must not be empty")
+	}
+	clone, err := cloneKind(kind)
+	if err != nil {
+		return err
+	}
+
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+	registeredKinds[kind.Name] = clone
+	return nil
+}
+
+// findKind returns the kind called name with the settings of the kinds it
+// extends merged in. Kinds defined in the configuration file (local) take
+// precedence over registered and built-in kinds.
+func findKind(name string, local map[string]*PredefinedKind) (*PredefinedKind, error) {
+	return resolveKind(name, local, make(map[string]bool))
+}
+
+// resolveKind follows the Extends chain of a kind, rejecting cycles
+func resolveKind(name string, local map[string]*PredefinedKind, seen map[string]bool) (*PredefinedKind, error) {
+	if seen[name] {
+		return nil, fmt.Errorf("kind %s extends itself", name)
+	}
+	seen[name] = true
+
+	kind, err := lookupKind(name, local)
+	if err != nil {
+		return nil, err
+	}
+	if kind.Extends == "" {
+		return kind, nil
+	}
+
+	base, err := resolveKind(kind.Extends, local, seen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve base of kind %s: %w", name, err)
+	}
+	// The kind's own settings win over those of its base, also where they
+	// turn something off
+	settings, err := kindSettings(base)
+	if err != nil {
+		return nil, err
+	}
+	own, err := kindSettings(kind)
+	if err != nil {
+		return nil, err
+	}
+	mergeSettings(settings, own)
+
+	mergeValues(reflect.ValueOf(base).Elem(), reflect.ValueOf(kind).Elem(), true)
+	base.Name, base.Extends = kind.Name, ""
+	if err := base.applySettings(settings); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+// lookupKind returns a copy of the kind called name, so callers may modify it
+func lookupKind(name string, local map[string]*PredefinedKind) (*PredefinedKind, error) {
+	if kind, ok := local[strings.ToLower(name)]; ok {
+		return cloneKind(kind)
+	}
+
+	kindsMu.RLock()
+	kind, ok := registeredKinds[name]
+	kindsMu.RUnlock()
+	if ok {
+		return cloneKind(kind)
+	}
+
+	if kind, ok := GetPredefinedKinds()[name]; ok {
+		return kind, nil
+	}
+	return nil, fmt.Errorf("unknown predefined kind: %s", name)
+}
+
+// cloneKind deep-copies a kind
+func cloneKind(kind *PredefinedKind) (*PredefinedKind, error) {
+	data, err := json.Marshal(kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode kind %s: %w", kind.Name, err)
+	}
+	var clone PredefinedKind
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to decode kind %s: %w", kind.Name, err)
+	}
+	if kind.settings != nil {
+		clone.settings = copySettings(kind.settings).(map[string]interface{})
+	}
+	return &clone, nil
+}
+
+// parseKinds reads the kinds: section of the configuration file, a map of
+// kind names to kind definitions
+func parseKinds(section interface{}) (map[string]*PredefinedKind, error) {
+	if section == nil {
+		return nil, nil
+	}
+	definitions, ok := section.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("kinds must be a map of kind names, got %T", section)
+	}
+
+	kinds := make(map[string]*PredefinedKind, len(definitions))
+	for name, definition := range definitions {
+		data, err := json.Marshal(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kind %s: %w", name, err)
+		}
+		var kind PredefinedKind
+		if err := json.Unmarshal(data, &kind); err != nil {
+			return nil, fmt.Errorf("invalid kind %s: %w", name, err)
+		}
+		kind.Name = name
+
+		// Keep the sections as written, so false and 0 override the base kind
+		settings := make(map[string]interface{})
+		if fields, ok := definition.(map[string]interface{}); ok {
+			for _, section := range kindSections {
+				if value, ok := fields[section].(map[string]interface{}); ok {
+					settings[section] = copySettings(value)
+				}
+			}
+		}
+		kind.settings = settings
+		kinds[strings.ToLower(name)] = &kind
+	}
+	return kinds, nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func registerTestKind(t *testing.T, kind *PredefinedKind) {
+	t.Helper()
+	if err := RegisterKind(kind); err != nil {
+		t.Fatalf("RegisterKind failed: %v", err)
+	}
+	t.Cleanup(func() {
+		kindsMu.Lock()
+		delete(registeredKinds, kind.Name)
+		kindsMu.Unlock()
+	})
+}
+
+func TestRegisterKind(t *testing.T) {
+	registerTestKind(t, &PredefinedKind{
+		Name:    "acme-standard",
+		Extends: "telemetry-to-otlp",
+		Tracing: &TracingConfig{Processor: "batch", Exporter: &ExporterConfig{Config: map[string]interface{}{"compression": "gzip"}}},
+	})
+
+	kind, err := findKind("acme-standard", nil)
+	if err != nil {
+		t.Fatalf("findKind failed: %v", err)
+	}
+	if kind.Tracing.Exporter.Module != "otlp-env" || kind.Tracing.Exporter.Config["compression"] != "gzip" {
+		t.Errorf("Expected exporter of the base kind with the own config, got %+v", kind.Tracing.Exporter)
+	}
+	if kind.Tracing.Processor != "batch" || !kind.Metrics.Enabled {
+		t.Errorf("Expected own processor and base metrics, got %+v", kind)
+	}
+
+	// The registered kind is used by the builder as well
+	config, err := NewBuilder().Kind("acme-standard").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if config.Tracing.Exporter.Module != "otlp-env" {
+		t.Errorf("Expected registered kind to be applied, got %q", config.Tracing.Exporter.Module)
+	}
+
+	if err := RegisterKind(&PredefinedKind{}); err == nil {
+		t.Error("Expected error for a kind without name")
+	}
+}
+
+func TestKindsFromConfigFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte(`
+kind: team-kind
+kinds:
+  org-kind:
+    extends: telemetry-to-console
+    tracing:
+      hrtime: true
+  team-kind:
+    extends: org-kind
+    tracing:
+      processor: batch
+`), 0644)
+
+	config, err := NewLoader().LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !config.Tracing.HRTime || config.Tracing.Processor != "batch" {
+		t.Errorf("Expected settings of the whole kind chain, got hrtime=%v processor=%q", config.Tracing.HRTime, config.Tracing.Processor)
+	}
+	if config.Tracing.Exporter.Module != "console" {
+		t.Errorf("Expected exporter of the built-in base kind, got %q", config.Tracing.Exporter.Module)
+	}
+}
+
+func TestKindCycles(t *testing.T) {
+	local := map[string]*PredefinedKind{
+		"a": {Name: "a", Extends: "b"},
+		"b": {Name: "b", Extends: "a"},
+		"c": {Name: "c", Extends: "missing"},
+	}
+	if _, err := findKind("a", local); err == nil {
+		t.Error("Expected error for cyclic kinds")
+	}
+	if _, err := findKind("c", local); err == nil {
+		t.Error("Expected error for unknown base kind")
+	}
+}
+
+func TestKindTurnsSettingsOff(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte(`
+kind: quiet
+kinds:
+  quiet:
+    extends: telemetry-to-dynatrace
+    metrics:
+      enabled: false
+      cardinality_limit: 0
+    tracing:
+      exporter:
+        config:
+          compression: gzip
+`), 0644)
+
+	loader := NewLoader()
+	config, err := loader.LoadFromFile(file)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Metrics.Enabled {
+		t.Error("Expected the kind to turn metrics off")
+	}
+	if config.Metrics.CardinalityLimit != 0 {
+		t.Errorf("Expected the kind to turn the cardinality limit off, got %d", config.Metrics.CardinalityLimit)
+	}
+	if config.Tracing.Exporter.Module != "otlp" || config.Tracing.Exporter.Config["compression"] != "gzip" {
+		t.Errorf("Expected exporter of the base kind with the own config, got %+v", config.Tracing.Exporter)
+	}
+
+	// The builder applies the zero values of the kind as well
+	kind, err := findKind("quiet", loader.kinds)
+	if err != nil {
+		t.Fatalf("findKind failed: %v", err)
+	}
+	registerTestKind(t, kind)
+	config, err = NewBuilder().Kind("quiet").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if config.Metrics.Enabled || config.Metrics.CardinalityLimit != 0 {
+		t.Errorf("Expected the builder to apply the zero values of the kind, got enabled=%v limit=%d", config.Metrics.Enabled, config.Metrics.CardinalityLimit)
+	}
+	if config.Metrics.Config.Temporality != "delta" {
+		t.Errorf("Expected settings of the base kind, got temporality %q", config.Metrics.Config.Temporality)
+	}
+}
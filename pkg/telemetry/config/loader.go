@@ -5,64 +5,131 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
+// ConfigFileName is the name of the configuration file without extension
+const ConfigFileName = "telemetry"
+
+// ProfileEnv selects the profile of the configuration file, e.g. dev
+const ProfileEnv = "TELEMETRY_PROFILE"
+
+// ConfigFileExtensions are the supported configuration file formats, in the
+// order they are searched for in each directory
+var ConfigFileExtensions = []string{"yaml", "yml", "json", "toml"}
+
 // Loader handles configuration loading from multiple sources
 type Loader struct {
 	v *viper.Viper
+
+	// cdsDir is searched for package.json and .cdsrc.json
+	cdsDir string
+
+	// configPaths are searched for the configuration file, unless
+	// configFile is set
+	configPaths []string
+	configFile  string
+
+	// kinds are defined in the kinds: section of the configuration file
+	kinds map[string]*PredefinedKind
 }
 
 // NewLoader creates a new configuration loader
 func NewLoader() *Loader {
-	v := viper.New()
-
-	// Set default configuration file names and paths
-	v.SetConfigName("telemetry")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(".")
-	v.AddConfigPath("./config")
-	v.AddConfigPath("$HOME/.cap-go-telemetry")
-	v.AddConfigPath("/etc/cap-go-telemetry")
+	// Attribute keys such as deployment.environment contain dots, so nested
+	// config keys must use a different delimiter
+	v := viper.NewWithOptions(viper.KeyDelimiter("::"))
 
 	// Enable environment variable support
 	v.SetEnvPrefix("TELEMETRY")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.SetEnvKeyReplacer(strings.NewReplacer("::", "_", ".", "_", "-", "_"))
 	v.AutomaticEnv()
 
-	return &Loader{v: v}
+	return &Loader{
+		v:      v,
+		cdsDir: ".",
+		configPaths: []string{
+			".",
+			"./config",
+			"$HOME/.cap-go-telemetry",
+			"/etc/cap-go-telemetry",
+		},
+	}
 }
 
 // Load loads configuration from multiple sources in order of precedence:
 // 1. Environment variables
-// 2. Configuration file
-// 3. Defaults
+// 2. Profile of the configuration file selected by TELEMETRY_PROFILE
+// 3. Configuration file
+// 4. cds.requires.telemetry (CDS_CONFIG, .cdsrc.json, package.json)
+// 5. Defaults
 func (l *Loader) Load() (*Config, error) {
 	// Start with defaults
 	config := NewDefaultConfig()
 
-	// Try to read config file (optional)
-	if err := l.v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+	// Share the configuration of CAP Node.js projects (optional)
+	cds, err := loadCDSConfig(l.cdsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cds configuration: %w", err)
+	}
+	if cds != nil {
+		if err := l.v.MergeConfigMap(cds); err != nil {
+			return nil, fmt.Errorf("failed to merge cds configuration: %w", err)
 		}
-		// Config file not found is OK, we'll use defaults and env vars
 	}
 
-	// Unmarshal into our config struct
-	if err := l.v.Unmarshal(config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	// Try to read config file (optional), on top of the cds configuration.
+	// The format follows the file extension.
+	file := l.configFile
+	if file == "" {
+		file = l.findConfigFile()
+	}
+	if file != "" {
+		if err := l.mergeConfigFile(file); err != nil {
+			return nil, err
+		}
+	}
+	// Config file not found is OK, we'll use defaults and env vars
+
+	if err := l.applyProfile(); err != nil {
+		return nil, err
+	}
+
+	// Environment variables of nested settings, parsed by type
+	if err := l.applyEnv(); err != nil {
+		return nil, fmt.Errorf("invalid environment variables: %w", err)
+	}
+
+	// The top-level exporter block fills in the exporters of all signals
+	l.applySharedExporter()
+
+	kinds, err := parseKinds(l.v.Get("kinds"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kinds: %w", err)
 	}
+	l.kinds = kinds
 
-	// Apply predefined kind if specified
-	if config.Kind != "" {
-		if err := l.applyPredefinedKind(config); err != nil {
-			return nil, fmt.Errorf("failed to apply predefined kind %s: %w", config.Kind, err)
+	// The predefined kind ranks between the defaults and the configuration
+	// file, so single settings can be overridden without losing the rest
+	kind := config.Kind
+	if l.v.IsSet("kind") {
+		kind = l.v.GetString("kind")
+	}
+	if kind != "" {
+		if err := l.applyKindDefaults(kind); err != nil {
+			return nil, fmt.Errorf("failed to apply predefined kind %s: %w", kind, err)
 		}
 	}
 
+	// Unmarshal into our config struct
+	if err := l.v.Unmarshal(config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
 	// Validate configuration
 	if err := l.validateConfig(config); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -71,9 +138,61 @@ func (l *Loader) Load() (*Config, error) {
 	return config, nil
 }
 
-// LoadFromFile loads configuration from a specific file
+// findConfigFile returns the first telemetry.yaml, .yml, .json or .toml
+// file in the config paths, or "" if there is none
+func (l *Loader) findConfigFile() string {
+	for _, dir := range l.configPaths {
+		dir = os.ExpandEnv(dir)
+		for _, ext := range ConfigFileExtensions {
+			file := filepath.Join(dir, ConfigFileName+"."+ext)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// mergeConfigFile reads a config file, replacing ${VAR} references with
+// environment variables, and merges it into the loaded configuration
+func (l *Loader) mergeConfigFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	content, err := interpolate(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to interpolate config file %s: %w", file, err)
+	}
+
+	l.v.SetConfigFile(file)
+	if err := l.v.MergeConfig(strings.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	return nil
+}
+
+// applyProfile deep-merges the profiles.<name> section selected by
+// TELEMETRY_PROFILE over the configuration read so far
+func (l *Loader) applyProfile() error {
+	profile := strings.ToLower(strings.TrimSpace(os.Getenv(ProfileEnv)))
+	if profile == "" || !l.v.IsSet("profiles") {
+		return nil
+	}
+
+	section, ok := l.v.Get("profiles::" + profile).(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unknown profile %q selected by %s", profile, ProfileEnv)
+	}
+	if err := l.v.MergeConfigMap(section); err != nil {
+		return fmt.Errorf("failed to merge profile %s: %w", profile, err)
+	}
+	return nil
+}
+
+// LoadFromFile loads configuration from a specific YAML, JSON or TOML file
 func (l *Loader) LoadFromFile(filename string) (*Config, error) {
-	l.v.SetConfigFile(filename)
+	l.configFile = filename
 	return l.Load()
 }
 
@@ -81,6 +200,10 @@ func (l *Loader) LoadFromFile(filename string) (*Config, error) {
 func (l *Loader) LoadFromJSON(jsonStr string) (*Config, error) {
 	config := NewDefaultConfig()
 
+	jsonStr, err := interpolate(jsonStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate JSON config: %w", err)
+	}
 	if err := json.Unmarshal([]byte(jsonStr), config); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON config: %w", err)
 	}
@@ -92,42 +215,83 @@ func (l *Loader) LoadFromJSON(jsonStr string) (*Config, error) {
 	return config, nil
 }
 
-// applyPredefinedKind applies a predefined configuration kind
-func (l *Loader) applyPredefinedKind(config *Config) error {
-	kinds := GetPredefinedKinds()
-	predefined, exists := kinds[config.Kind]
-	if !exists {
-		return fmt.Errorf("unknown predefined kind: %s", config.Kind)
+// LoadFromYAML loads configuration from YAML string
+func (l *Loader) LoadFromYAML(yamlStr string) (*Config, error) {
+	config := NewDefaultConfig()
+
+	yamlStr, err := interpolate(yamlStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate YAML config: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(yamlStr), config); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML config: %w", err)
 	}
 
-	// Merge predefined configuration, but don't override explicit settings
-	if config.Tracing == nil && predefined.Tracing != nil {
-		config.Tracing = predefined.Tracing
-	} else if config.Tracing != nil && predefined.Tracing != nil {
-		// Merge tracing config
-		if config.Tracing.Exporter == nil {
-			config.Tracing.Exporter = predefined.Tracing.Exporter
-		}
+	if err := l.validateConfig(config); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	if config.Metrics == nil && predefined.Metrics != nil {
-		config.Metrics = predefined.Metrics
-	} else if config.Metrics != nil && predefined.Metrics != nil {
-		// Merge metrics config
-		if config.Metrics.Exporter == nil {
-			config.Metrics.Exporter = predefined.Metrics.Exporter
-		}
+	return config, nil
+}
+
+// applyKindDefaults registers the settings of a predefined kind as
+// defaults, which the configuration file and environment variables override
+func (l *Loader) applyKindDefaults(kind string) error {
+	predefined, err := findKind(kind, l.kinds)
+	if err != nil {
+		return err
+	}
+	settings, err := kindSettings(predefined)
+	if err != nil {
+		return err
 	}
+	for section, value := range settings {
+		l.v.SetDefault(section, value)
+	}
+	return nil
+}
 
-	if config.Logging == nil && predefined.Logging != nil {
-		config.Logging = predefined.Logging
-	} else if config.Logging != nil && predefined.Logging != nil {
-		// Merge logging config
-		if config.Logging.Exporter == nil {
-			config.Logging.Exporter = predefined.Logging.Exporter
-		}
+// applyPredefinedKind fills the settings of config left empty with those of
+// its predefined kind, recursively; explicit settings are kept
+func (l *Loader) applyPredefinedKind(config *Config) error {
+	return mergePredefinedKind(config, l.kinds, false)
+}
+
+// mergePredefinedKind merges the settings of the predefined kind of config
+// into it, recursively. With overwrite set, the kind wins where it sets a value.
+func mergePredefinedKind(config *Config, local map[string]*PredefinedKind, overwrite bool) error {
+	predefined, err := findKind(config.Kind, local)
+	if err != nil {
+		return err
+	}
+	if !overwrite {
+		kind := &Config{Tracing: predefined.Tracing, Metrics: predefined.Metrics, Logging: predefined.Logging}
+		mergeValues(reflect.ValueOf(config).Elem(), reflect.ValueOf(kind).Elem(), false)
+		return nil
 	}
 
+	// Overwrite with the settings of the kind, including explicit zero values
+	settings, err := kindSettings(predefined)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return fmt.Errorf("failed to decode config: %w", err)
+	}
+	mergeSettings(merged, settings)
+	if data, err = json.Marshal(merged); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	var result Config
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("invalid settings of kind %s: %w", config.Kind, err)
+	}
+	*config = result
 	return nil
 }
 
@@ -146,6 +310,9 @@ func (l *Loader) validateConfig(config *Config) error {
 			return fmt.Errorf("tracing exporter configuration is required when tracing is enabled")
 		}
 	}
+	if config.Tracing != nil && config.Tracing.TraceURL != "" && !strings.Contains(config.Tracing.TraceURL, TraceIDPlaceholder) {
+		return fmt.Errorf("tracing trace_url must contain %s", TraceIDPlaceholder)
+	}
 
 	// Validate metrics configuration
 	if config.Metrics != nil && config.Metrics.Enabled {
@@ -166,6 +333,18 @@ func (l *Loader) validateConfig(config *Config) error {
 		}
 	}
 
+	// Validate profiling configuration
+	if config.Profiling != nil && config.Profiling.Enabled && config.Profiling.Exporter == nil {
+		return fmt.Errorf("profiling exporter configuration is required when profiling is enabled")
+	}
+
+	// Secret files must be readable at startup
+	for _, exporter := range config.exporters() {
+		if err := exporter.validateSecretFiles(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
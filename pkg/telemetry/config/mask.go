@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaskedValue replaces secrets in masked configurations
+const MaskedValue = "[REDACTED]"
+
+// sensitiveKeyParts mark exporter and instrumentation settings holding secrets
+var sensitiveKeyParts = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"credential",
+	"cookie",
+	"api_key",
+	"apikey",
+	"api-key",
+	"private_key",
+}
+
+// Clone returns a deep copy of the configuration. Numbers in exporter and
+// instrumentation settings are decoded as float64, as for JSON files.
+func (c *Config) Clone() (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var clone Config
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return &clone, nil
+}
+
+// Masked returns a deep copy of the configuration with secrets replaced by
+// MaskedValue, safe to log or serve for debugging. Exporter and
+// instrumentation settings are masked by key, e.g. token or headers with an
+// Authorization entry, and passwords in URLs are masked as well. Paths of
+// secret files are kept.
+func (c *Config) Masked() (*Config, error) {
+	masked, err := c.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, exporter := range masked.exporters() {
+		if exporter != nil {
+			maskSettings(exporter.Config)
+		}
+	}
+	for _, instrumentation := range masked.Instrumentations {
+		if instrumentation != nil {
+			maskSettings(instrumentation.Config)
+		}
+	}
+	if masked.Remote != nil {
+		masked.Remote.URL = maskURL(masked.Remote.URL)
+	}
+	if masked.Heartbeat != nil {
+		for name, u := range masked.Heartbeat.URLs {
+			masked.Heartbeat.URLs[name] = maskURL(u)
+		}
+	}
+	return masked, nil
+}
+
+// exporters returns the exporter configs of all signals and the shared
+// exporter block, nil if unset
+func (c *Config) exporters() []*ExporterConfig {
+	var exporters []*ExporterConfig
+	if c.Tracing != nil {
+		exporters = append(exporters, c.Tracing.Exporter)
+	}
+	if c.Metrics != nil {
+		exporters = append(exporters, c.Metrics.Exporter)
+	}
+	if c.Logging != nil {
+		exporters = append(exporters, c.Logging.Exporter)
+		if c.Logging.Audit != nil {
+			exporters = append(exporters, c.Logging.Audit.Exporter)
+		}
+	}
+	if c.Profiling != nil {
+		exporters = append(exporters, c.Profiling.Exporter)
+	}
+	if shared := c.Exporter; shared != nil {
+		exporters = append(exporters,
+			&ExporterConfig{Module: shared.Module, Class: shared.Class, Config: shared.Config},
+			shared.Traces, shared.Metrics, shared.Logs,
+		)
+	}
+	return exporters
+}
+
+// maskSettings masks the sensitive entries of settings in place, recursively
+func maskSettings(settings map[string]interface{}) {
+	for key, value := range settings {
+		if isSensitiveKey(key) {
+			settings[key] = MaskedValue
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			maskSettings(v)
+		case string:
+			settings[key] = maskURL(v)
+		}
+	}
+}
+
+// isSensitiveKey reports whether a setting holds a secret; *_file settings
+// hold the path of the secret
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, SecretFileSuffix) {
+		return false
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskURL masks the password of URLs with credentials as xxxxx, other
+// values are returned unchanged
+func maskURL(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
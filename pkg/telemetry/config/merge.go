@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// mergeValues recursively copies the non-zero values of src into dst.
+// Structs, pointers to structs and maps are merged field by field and key
+// by key; other values, including slices, are replaced as a whole. With
+// overwrite unset, only zero values of dst are filled.
+func mergeValues(dst, src reflect.Value, overwrite bool) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(src)
+			return
+		}
+		mergeValues(dst.Elem(), src.Elem(), overwrite)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				mergeValues(dst.Field(i), src.Field(i), overwrite)
+			}
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			key, value := iter.Key(), iter.Value()
+			existing := dst.MapIndex(key)
+			switch {
+			case !existing.IsValid():
+				dst.SetMapIndex(key, value)
+			case existing.Kind() == reflect.Ptr && !existing.IsNil():
+				mergeValues(existing, value, overwrite)
+			default:
+				// Nested settings such as exporter configs are maps as well
+				dstMap, dstOK := existing.Interface().(map[string]interface{})
+				srcMap, srcOK := value.Interface().(map[string]interface{})
+				if dstOK && srcOK {
+					mergeValues(reflect.ValueOf(dstMap), reflect.ValueOf(srcMap), overwrite)
+				} else if overwrite {
+					dst.SetMapIndex(key, value)
+				}
+			}
+		}
+	default:
+		if src.IsZero() {
+			return
+		}
+		if overwrite || dst.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// mergeSettings copies the settings of src into dst, recursively. Unlike
+// mergeValues, the values of src win even if they are false or 0, as they
+// were set explicitly.
+func mergeSettings(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]interface{})
+		dstMap, dstOK := dst[key].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeSettings(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// kindSections are the settings a kind provides
+var kindSections = []string{"tracing", "metrics", "logging"}
+
+// kindSettings converts a predefined kind into nested settings keyed like
+// the configuration file. Kinds from the configuration file keep the
+// settings as written; for kinds defined in code zero values are left out,
+// so they don't hide the defaults.
+func kindSettings(kind *PredefinedKind) (map[string]interface{}, error) {
+	if kind.settings != nil {
+		return copySettings(kind.settings).(map[string]interface{}), nil
+	}
+
+	data, err := json.Marshal(kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode predefined kind: %w", err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode predefined kind: %w", err)
+	}
+
+	// Only the telemetry sections are configuration keys
+	result := make(map[string]interface{})
+	for _, section := range kindSections {
+		if pruned, ok := pruneZero(settings[section]).(map[string]interface{}); ok {
+			result[section] = pruned
+		}
+	}
+	return result, nil
+}
+
+// pruneZero removes false, 0, "", null and empty maps and slices
+func pruneZero(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		pruned := make(map[string]interface{})
+		for k, item := range value {
+			if item = pruneZero(item); item != nil {
+				pruned[k] = item
+			}
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		return pruned
+	case []interface{}:
+		if len(value) == 0 {
+			return nil
+		}
+		return value
+	case bool:
+		if !value {
+			return nil
+		}
+	case float64:
+		if value == 0 {
+			return nil
+		}
+	case string:
+		if value == "" {
+			return nil
+		}
+	}
+	return v
+}
+
+// applySettings sets the sections of kind from settings, so that its
+// fields match the settings including explicit zero values
+func (kind *PredefinedKind) applySettings(settings map[string]interface{}) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode kind %s: %w", kind.Name, err)
+	}
+	var sections Config
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("invalid settings of kind %s: %w", kind.Name, err)
+	}
+	kind.Tracing, kind.Metrics, kind.Logging = sections.Tracing, sections.Metrics, sections.Logging
+	kind.settings = settings
+	return nil
+}
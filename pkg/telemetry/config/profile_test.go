@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const profileConfig = `
+service_name: bookshop
+tracing:
+  hrtime: false
+  sampler:
+    kind: ParentBasedSampler
+    root: AlwaysOnSampler
+    ignore_incoming_paths: ["/health"]
+metrics:
+  config:
+    export_interval_millis: 60000
+profiles:
+  dev:
+    tracing:
+      hrtime: true
+  prod:
+    metrics:
+      config:
+        export_interval_millis: 10000
+`
+
+func loadProfile(t *testing.T, profile string) (*Config, error) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "telemetry.yaml")
+	os.WriteFile(file, []byte(profileConfig), 0644)
+	t.Setenv(ProfileEnv, profile)
+	return NewLoader().LoadFromFile(file)
+}
+
+func TestProfiles(t *testing.T) {
+	config, err := loadProfile(t, "dev")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !config.Tracing.HRTime {
+		t.Error("Expected dev profile to enable hrtime")
+	}
+	// Deep merge keeps the base settings next to the overridden ones
+	if config.Tracing.Sampler.Root != "AlwaysOnSampler" || len(config.Tracing.Sampler.IgnoreIncomingPaths) != 1 {
+		t.Errorf("Expected base sampler to be kept, got %+v", config.Tracing.Sampler)
+	}
+
+	config, err = loadProfile(t, "PROD")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Metrics.Config.ExportIntervalMillis != 10000 {
+		t.Errorf("Expected prod export interval, got %d", config.Metrics.Config.ExportIntervalMillis)
+	}
+	if config.Tracing.HRTime {
+		t.Error("Expected dev settings not to leak into prod")
+	}
+
+	config, err = loadProfile(t, "")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Metrics.Config.ExportIntervalMillis != 60000 || config.Tracing.HRTime {
+		t.Error("Expected base configuration without a profile")
+	}
+
+	if _, err := loadProfile(t, "qa"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
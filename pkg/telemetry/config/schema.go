@@ -0,0 +1,335 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaURI is the JSON Schema dialect of Schema
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema describing the configuration
+type jsonSchema struct {
+	Schema     string                 `json:"$schema,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Properties map[string]*jsonSchema `json:"properties,omitempty"`
+	Items      *jsonSchema            `json:"items,omitempty"`
+
+	// AdditionalProperties is false for structs, the schema of the values
+	// for maps and nil if anything is allowed
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+}
+
+// Schema returns a JSON Schema of the configuration file, including the
+// kinds: and profiles: sections, for editors and CI checks
+func Schema() ([]byte, error) {
+	data, err := json.MarshalIndent(configSchema(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return data, nil
+}
+
+// configSchema builds the schema of Config with the file-only sections
+func configSchema() *jsonSchema {
+	config := schemaOf(reflect.TypeOf(Config{}))
+
+	root := &jsonSchema{
+		Schema:               SchemaURI,
+		Title:                "cap-go-telemetry configuration",
+		Type:                 "object",
+		Properties:           make(map[string]*jsonSchema, len(config.Properties)+2),
+		AdditionalProperties: false,
+	}
+	for name, property := range config.Properties {
+		root.Properties[name] = property
+	}
+	root.Properties["kinds"] = &jsonSchema{
+		Type:                 "object",
+		AdditionalProperties: schemaOf(reflect.TypeOf(PredefinedKind{})),
+	}
+	// Profiles can't nest, so they share the schema without profiles:
+	root.Properties["profiles"] = &jsonSchema{
+		Type:                 "object",
+		AdditionalProperties: config,
+	}
+	return root
+}
+
+// schemaOf derives the schema of a type from its json tags
+func schemaOf(t reflect.Type) *jsonSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		s := &jsonSchema{Type: "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			s.AdditionalProperties = schemaOf(t.Elem())
+		}
+		return s
+	case reflect.Struct:
+		s := &jsonSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*jsonSchema, t.NumField()),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			s.Properties[name] = schemaOf(field.Type)
+		}
+		return s
+	default:
+		// interface{} accepts any value
+		return &jsonSchema{}
+	}
+}
+
+// ValidationError locates a setting of a configuration file that doesn't
+// match the schema. Line and Column are 0 for TOML files.
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+// Error returns the error as file:line:column: path: message
+func (e *ValidationError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", location, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, e.Path, e.Message)
+}
+
+// Validate checks a YAML, JSON or TOML configuration file against Schema
+// after replacing ${VAR} references, e.g. in CI before deploying. All
+// mismatches are returned together as *ValidationError, joined.
+func Validate(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	content, err := interpolate(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to interpolate config file %s: %w", path, err)
+	}
+
+	root, err := parseConfigNode(path, content)
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		return nil
+	}
+
+	v := &validator{file: path}
+	v.validate(root, configSchema(), "")
+	return errors.Join(v.errs...)
+}
+
+// parseConfigNode parses a configuration file into a YAML node, which keeps
+// the positions of YAML and JSON files. It returns nil for empty files.
+func parseConfigNode(path, content string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		// TOML files have no positions, settings are located by path only
+		v := viper.NewWithOptions(viper.KeyDelimiter("::"))
+		v.SetConfigType("toml")
+		if err := v.ReadConfig(strings.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if err := doc.Encode(v.AllSettings()); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		return &doc, nil
+	}
+
+	// JSON is valid YAML
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+// validator collects the mismatches of a file with the schema
+type validator struct {
+	file string
+	errs []error
+}
+
+// fail records a mismatch at node
+func (v *validator) fail(node *yaml.Node, path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		File:    v.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate checks node and its children against s
+func (v *validator) validate(node *yaml.Node, s *jsonSchema, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" || s.Type == "" {
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			v.fail(node, path, "expected an object, got %s", describeNode(node))
+			return
+		}
+		v.validateObject(node, s, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.fail(node, path, "expected an array, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.fail(node, path, "expected a boolean, got %s", describeNode(node))
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.fail(node, path, "expected an integer, got %s", describeNode(node))
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			v.fail(node, path, "expected a number, got %s", describeNode(node))
+		}
+	case "string":
+		// Scalars such as versions are read as strings
+		if node.Kind != yaml.ScalarNode {
+			v.fail(node, path, "expected a string, got %s", describeNode(node))
+		}
+	}
+}
+
+// validateObject checks the keys and values of a mapping node
+func (v *validator) validateObject(node *yaml.Node, s *jsonSchema, path string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			// YAML merge keys are resolved by the parser
+			continue
+		}
+		keyPath := key.Value
+		if path != "" {
+			keyPath = path + "." + key.Value
+		}
+
+		// Keys are case-insensitive, as for the loader
+		if property, ok := s.Properties[strings.ToLower(key.Value)]; ok {
+			v.validate(value, property, keyPath)
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case *jsonSchema:
+			v.validate(value, additional, keyPath)
+		case bool:
+			v.fail(key, keyPath, "unknown setting%s", suggestKey(key.Value, s.Properties))
+		}
+	}
+}
+
+// describeNode names the type of a node for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "an array"
+	}
+	switch node.Tag {
+	case "!!bool":
+		return "boolean " + node.Value
+	case "!!int", "!!float":
+		return "number " + node.Value
+	default:
+		return fmt.Sprintf("string %q", node.Value)
+	}
+}
+
+// suggestKey returns a hint naming the closest known key, if any
+func suggestKey(key string, properties map[string]*jsonSchema) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", best)
+}
+
+// editDistance returns the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
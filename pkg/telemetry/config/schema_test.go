@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if schema["$schema"] != SchemaURI {
+		t.Errorf("Expected $schema %s, got %v", SchemaURI, schema["$schema"])
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	for _, key := range []string{"service_name", "tracing", "metrics", "logging", "kinds", "profiles"} {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("Expected property %s", key)
+		}
+	}
+
+	tracing := properties["tracing"].(map[string]interface{})
+	if tracing["additionalProperties"] != false {
+		t.Errorf("Expected unknown tracing settings to be rejected, got %v", tracing["additionalProperties"])
+	}
+	ratio := tracing["properties"].(map[string]interface{})["sampler"].(map[string]interface{})["properties"].(map[string]interface{})["ratio"]
+	if ratio.(map[string]interface{})["type"] != "number" {
+		t.Errorf("Expected sampler ratio to be a number, got %v", ratio)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	path := writeConfigFile(t, "telemetry.yaml", `service_name: bookshop
+kind: telemetry-to-console
+tracing:
+  enabled: true
+  sampler:
+    ratio: 0.5
+  exporter:
+    module: otlp
+    config:
+      anything: [1, 2]
+resource:
+  attributes:
+    deployment.environment: prod
+profiles:
+  dev:
+    metrics:
+      host_metrics: true
+kinds:
+  acme:
+    extends: telemetry-to-otlp
+`)
+	if err := Validate(path); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	path := writeConfigFile(t, "telemetry.yaml", `service_name: bookshop
+tracing:
+  enabled: yes please
+  procesor: simple
+  sampler:
+    ratio: high
+metrics:
+  config:
+    export_interval_millis: 1.5
+profiles:
+  dev:
+    logging:
+      levl: debug
+`)
+	err := Validate(path)
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+
+	expected := []string{
+		path + `:3:12: tracing.enabled: expected a boolean, got string "yes please"`,
+		path + ":4:3: tracing.procesor: unknown setting, did you mean processor?",
+		path + `:6:12: tracing.sampler.ratio: expected a number, got string "high"`,
+		path + ":9:29: metrics.config.export_interval_millis: expected an integer, got number 1.5",
+		path + ":13:7: profiles.dev.logging.levl: unknown setting, did you mean level?",
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), err)
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], line)
+		}
+	}
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Line != 3 || validationErr.Path != "tracing.enabled" {
+		t.Errorf("Expected the first error as *ValidationError, got %#v", validationErr)
+	}
+}
+
+func TestValidateInterpolates(t *testing.T) {
+	t.Setenv("TEST_SAMPLING_RATIO", "0.1")
+	path := writeConfigFile(t, "telemetry.yaml", "tracing:\n  sampler:\n    ratio: ${TEST_SAMPLING_RATIO}\n")
+	if err := Validate(path); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidateJSONAndTOML(t *testing.T) {
+	path := writeConfigFile(t, "telemetry.json", "{\n  \"tracing\": {\n    \"hrtime\": 1\n  }\n}\n")
+	err := Validate(path)
+	if err == nil || err.Error() != path+":3:15: tracing.hrtime: expected a boolean, got number 1" {
+		t.Errorf("Expected a located JSON error, got %v", err)
+	}
+
+	path = writeConfigFile(t, "telemetry.toml", "[tracing]\nprocessor = \"simple\"\nunknown = true\n")
+	err = Validate(path)
+	if err == nil || err.Error() != path+": tracing.unknown: unknown setting" {
+		t.Errorf("Expected a TOML error without position, got %v", err)
+	}
+}
+
+func TestValidateEmptyFile(t *testing.T) {
+	if err := Validate(writeConfigFile(t, "telemetry.yaml", "")); err != nil {
+		t.Errorf("Expected an empty file to be valid, got %v", err)
+	}
+	if err := Validate(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
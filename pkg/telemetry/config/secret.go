@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretFileSuffix marks config keys and environment variables holding the
+// path of a file with the actual value, e.g. token_file or
+// TELEMETRY_EXPORTER_TOKEN_FILE for Kubernetes and Docker secrets
+const SecretFileSuffix = "_file"
+
+// Secret is a sensitive config value. Values read from a file are read
+// again whenever the file changes, so long-lived exporters pick up
+// rotated secrets.
+type Secret struct {
+	value string
+	path  string
+
+	mu      sync.Mutex
+	read    bool
+	modTime time.Time
+	size    int64
+}
+
+// NewSecret creates a secret with a fixed value
+func NewSecret(value string) *Secret {
+	return &Secret{value: value, read: true}
+}
+
+// NewFileSecret creates a secret read from the file at path
+func NewFileSecret(path string) *Secret {
+	return &Secret{path: path}
+}
+
+// File returns the path of the secret file, "" for fixed values
+func (s *Secret) File() string {
+	return s.path
+}
+
+// Value returns the secret, reading the file again if it was modified.
+// Trailing newlines of the file are removed.
+func (s *Secret) Value() (string, error) {
+	if s.path == "" {
+		return s.value, nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.read && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.value, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	s.value = strings.TrimRight(string(data), "\r\n")
+	s.read, s.modTime, s.size = true, info.ModTime(), info.Size()
+	return s.value, nil
+}
+
+// Secret returns the sensitive value key of the exporter config. It is read
+// from the file named by TELEMETRY_EXPORTER_<KEY>_FILE or the <key>_file
+// entry if set, otherwise the <key> entry is used as is.
+func (e *ExporterConfig) Secret(key string) (*Secret, bool) {
+	env := "TELEMETRY_EXPORTER_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key)) + "_FILE"
+	if path := os.Getenv(env); path != "" {
+		return NewFileSecret(path), true
+	}
+	if e == nil {
+		return nil, false
+	}
+	if path, ok := e.Config[key+SecretFileSuffix].(string); ok && path != "" {
+		return NewFileSecret(path), true
+	}
+	if value, ok := e.Config[key]; ok {
+		return NewSecret(fmt.Sprint(value)), true
+	}
+	return nil, false
+}
+
+// validateSecretFiles checks that the files of all *_file entries of the
+// exporter config can be read, so a missing secret fails at startup
+func (e *ExporterConfig) validateSecretFiles() error {
+	if e == nil {
+		return nil
+	}
+	for key, value := range e.Config {
+		path, ok := value.(string)
+		if !ok || !strings.HasSuffix(key, SecretFileSuffix) {
+			continue
+		}
+		if _, err := NewFileSecret(path).Value(); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
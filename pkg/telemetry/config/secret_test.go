@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSecret_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("first\n"), 0600)
+
+	secret := NewFileSecret(path)
+	if got, err := secret.Value(); err != nil || got != "first" {
+		t.Fatalf("Expected first, got %q (%v)", got, err)
+	}
+
+	os.WriteFile(path, []byte("second-token\n"), 0600)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if got, err := secret.Value(); err != nil || got != "second-token" {
+		t.Errorf("Expected rotated secret, got %q (%v)", got, err)
+	}
+
+	os.Remove(path)
+	if _, err := secret.Value(); err == nil {
+		t.Error("Expected error for removed secret file")
+	}
+}
+
+func TestExporterConfig_Secret(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config-token")
+	envFile := filepath.Join(dir, "env-token")
+	os.WriteFile(configFile, []byte("from-config-file"), 0600)
+	os.WriteFile(envFile, []byte("from-env-file"), 0600)
+
+	exporter := &ExporterConfig{Config: map[string]interface{}{
+		"token":      "inline",
+		"token_file": configFile,
+		"api_key":    "inline-key",
+	}}
+
+	secret, ok := exporter.Secret("token")
+	if got, _ := secret.Value(); !ok || got != "from-config-file" {
+		t.Errorf("Expected token_file to win over token, got %q", got)
+	}
+	secret, ok = exporter.Secret("api_key")
+	if got, _ := secret.Value(); !ok || got != "inline-key" {
+		t.Errorf("Expected inline api_key, got %q", got)
+	}
+	if _, ok := exporter.Secret("password"); ok {
+		t.Error("Expected no secret for missing key")
+	}
+
+	t.Setenv("TELEMETRY_EXPORTER_TOKEN_FILE", envFile)
+	secret, _ = exporter.Secret("token")
+	if got, _ := secret.Value(); got != "from-env-file" {
+		t.Errorf("Expected TELEMETRY_EXPORTER_TOKEN_FILE to win, got %q", got)
+	}
+}
+
+func TestLoadFromJSON_MissingSecretFile(t *testing.T) {
+	_, err := NewLoader().LoadFromJSON(`{"tracing": {"exporter": {"module": "otlp", "config": {"token_file": "/nonexistent/token"}}}}`)
+	if err == nil {
+		t.Error("Expected error for unreadable token_file")
+	}
+}
@@ -0,0 +1,189 @@
+package telemetry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/console"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/processors"
+)
+
+// consoleSpanOptions translates tracing.exporter.config into console span
+// exporter options
+func consoleSpanOptions(cfg map[string]interface{}) ([]console.SpanExporterOption, error) {
+	var opts []console.SpanExporterOption
+	if v, ok := cfg["slow_threshold_ms"]; ok {
+		ms, err := toFloat(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slow_threshold_ms: %w", err)
+		}
+		opts = append(opts, console.WithSlowThreshold(time.Duration(ms*float64(time.Millisecond))))
+	}
+	if v, ok := cfg["min_duration_ms"]; ok {
+		ms, err := toFloat(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_duration_ms: %w", err)
+		}
+		opts = append(opts, console.WithMinDuration(time.Duration(ms*float64(time.Millisecond))))
+	}
+	if v, ok := cfg["time_layout"]; ok {
+		opts = append(opts, console.WithTimeLayout(fmt.Sprint(v)))
+	}
+	if v, ok := cfg["utc"]; ok {
+		utc, err := toBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utc: %w", err)
+		}
+		opts = append(opts, console.WithUTC(utc))
+	}
+	if v, ok := cfg["important_attributes"]; ok {
+		patterns, err := toStrings(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid important_attributes: %w", err)
+		}
+		opts = append(opts, console.WithImportantAttributes(patterns...))
+	}
+	if v, ok := cfg["max_attribute_length"]; ok {
+		n, err := toFloat(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_attribute_length: %w", err)
+		}
+		opts = append(opts, console.WithMaxAttributeLength(int(n)))
+	}
+	return opts, nil
+}
+
+// consoleCorrelation reads whether tracing.exporter.config asks for the
+// correlated view, printing logs nested under their spans, and its window
+func consoleCorrelation(cfg map[string]interface{}) (bool, time.Duration, error) {
+	v, ok := cfg["correlated"]
+	if !ok {
+		return false, 0, nil
+	}
+	correlated, err := toBool(v)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid correlated: %w", err)
+	}
+	var window time.Duration
+	if v, ok := cfg["correlation_window_ms"]; ok {
+		ms, err := toFloat(v)
+		if err != nil {
+			return false, 0, fmt.Errorf("invalid correlation_window_ms: %w", err)
+		}
+		window = time.Duration(ms * float64(time.Millisecond))
+	}
+	return correlated, window, nil
+}
+
+// consoleMetricOptions translates metrics.exporter.config into console
+// metric exporter options
+func consoleMetricOptions(cfg map[string]interface{}) ([]console.MetricExporterOption, error) {
+	var opts []console.MetricExporterOption
+	if v, ok := cfg["rates"]; ok {
+		rates, err := toBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rates: %w", err)
+		}
+		opts = append(opts, console.WithMetricRates(rates))
+	}
+	return opts, nil
+}
+
+// consoleLogOptions translates logging.exporter.config into console log
+// exporter options
+func consoleLogOptions(cfg map[string]interface{}) ([]console.LogExporterOption, error) {
+	var opts []console.LogExporterOption
+	if v, ok := cfg["min_severity"]; ok {
+		level, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid min_severity: expected a string, got %T", v)
+		}
+		minimum, err := processors.ParseSeverity(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_severity: %w", err)
+		}
+		opts = append(opts, console.WithMinSeverity(minimum))
+	}
+	if v, ok := cfg["time_layout"]; ok {
+		opts = append(opts, console.WithLogTimeLayout(fmt.Sprint(v)))
+	}
+	if v, ok := cfg["utc"]; ok {
+		utc, err := toBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utc: %w", err)
+		}
+		opts = append(opts, console.WithLogUTC(utc))
+	}
+	if v, ok := cfg["attributes"]; ok {
+		keys, err := toStrings(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attributes: %w", err)
+		}
+		opts = append(opts, console.WithAttributeFilter(keys...))
+	}
+	if v, ok := cfg["max_attribute_length"]; ok {
+		n, err := toFloat(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_attribute_length: %w", err)
+		}
+		opts = append(opts, console.WithLogMaxAttributeLength(int(n)))
+	}
+	return opts, nil
+}
+
+// toFloat converts a number decoded from YAML, JSON or the environment
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", v)
+	}
+}
+
+// toBool converts a boolean decoded from YAML, JSON or the environment
+func toBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		return strconv.ParseBool(b)
+	default:
+		return false, fmt.Errorf("expected a boolean, got %T", v)
+	}
+}
+
+// toStrings converts a single string or a list decoded from YAML or JSON
+func toStrings(v interface{}) ([]string, error) {
+	switch s := v.(type) {
+	case string:
+		// Comma-separated, as set via the environment
+		result := strings.Split(s, ",")
+		for i := range result {
+			result[i] = strings.TrimSpace(result[i])
+		}
+		return result, nil
+	case []string:
+		return s, nil
+	case []interface{}:
+		result := make([]string, 0, len(s))
+		for _, item := range s {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %T", item)
+			}
+			result = append(result, str)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected a list of strings, got %T", v)
+	}
+}
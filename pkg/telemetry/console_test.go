@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConsoleSpanOptions(t *testing.T) {
+	opts, err := consoleSpanOptions(map[string]interface{}{"slow_threshold_ms": 250})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(opts) != 1 {
+		t.Errorf("Expected 1 option, got %d", len(opts))
+	}
+
+	if _, err := consoleSpanOptions(map[string]interface{}{"slow_threshold_ms": "soon"}); err == nil {
+		t.Error("Expected error for non-numeric slow_threshold_ms")
+	}
+
+	opts, err = consoleSpanOptions(nil)
+	if err != nil || len(opts) != 0 {
+		t.Errorf("Expected no options without config, got %d (%v)", len(opts), err)
+	}
+}
+
+func TestConsoleSpanOptions_ImportantAttributes(t *testing.T) {
+	opts, err := consoleSpanOptions(map[string]interface{}{
+		"important_attributes": []interface{}{"db.*", "http.route"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(opts) != 1 {
+		t.Errorf("Expected 1 option, got %d", len(opts))
+	}
+
+	if _, err := consoleSpanOptions(map[string]interface{}{"important_attributes": 42}); err == nil {
+		t.Error("Expected error for invalid important_attributes")
+	}
+
+	patterns, err := toStrings("db.*, http.route")
+	if err != nil || len(patterns) != 2 || patterns[1] != "http.route" {
+		t.Errorf("Expected comma-separated patterns to be split, got %v (%v)", patterns, err)
+	}
+}
+
+func TestConsoleLogOptions(t *testing.T) {
+	opts, err := consoleLogOptions(map[string]interface{}{
+		"min_severity": "warn",
+		"attributes":   []interface{}{"http.*"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(opts) != 2 {
+		t.Errorf("Expected 2 options, got %d", len(opts))
+	}
+
+	if _, err := consoleLogOptions(map[string]interface{}{"min_severity": "loud"}); err == nil {
+		t.Error("Expected error for unknown min_severity")
+	}
+}
+
+func TestConsoleOptions_Timestamps(t *testing.T) {
+	cfg := map[string]interface{}{"time_layout": "15:04:05", "utc": "true"}
+
+	spanOpts, err := consoleSpanOptions(cfg)
+	if err != nil || len(spanOpts) != 2 {
+		t.Errorf("Expected 2 span options, got %d (%v)", len(spanOpts), err)
+	}
+	logOpts, err := consoleLogOptions(cfg)
+	if err != nil || len(logOpts) != 2 {
+		t.Errorf("Expected 2 log options, got %d (%v)", len(logOpts), err)
+	}
+
+	if _, err := consoleLogOptions(map[string]interface{}{"utc": 3}); err == nil {
+		t.Error("Expected error for non-boolean utc")
+	}
+}
+
+func TestConsoleMetricOptions(t *testing.T) {
+	opts, err := consoleMetricOptions(map[string]interface{}{"rates": true})
+	if err != nil || len(opts) != 1 {
+		t.Errorf("Expected 1 metric option, got %d (%v)", len(opts), err)
+	}
+
+	if _, err := consoleMetricOptions(map[string]interface{}{"rates": "often"}); err == nil {
+		t.Error("Expected error for non-boolean rates")
+	}
+}
+
+func TestConsoleCorrelation(t *testing.T) {
+	correlated, window, err := consoleCorrelation(map[string]interface{}{"correlated": true, "correlation_window_ms": 500})
+	if err != nil || !correlated || window != 500*time.Millisecond {
+		t.Errorf("Expected correlated view with a 500ms window, got %v %v (%v)", correlated, window, err)
+	}
+
+	if correlated, _, _ := consoleCorrelation(map[string]interface{}{}); correlated {
+		t.Error("Expected correlated view to be off by default")
+	}
+	if _, _, err := consoleCorrelation(map[string]interface{}{"correlated": "maybe"}); err == nil {
+		t.Error("Expected error for non-boolean correlated")
+	}
+}
@@ -0,0 +1,72 @@
+// Package correlation propagates a request correlation ID, as known from
+// Cloud Foundry's x-vcap-request-id or the X-Correlation-ID header, and
+// stamps it onto spans and log records as correlation_id, like the CAP
+// Node.js telemetry plugin does.
+package correlation
+
+import (
+	"context"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/internal/baggageattr"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	// Key is the baggage member and attribute key holding the correlation ID
+	Key = "correlation_id"
+
+	// HeaderCorrelationID is the header read and written by the propagator
+	HeaderCorrelationID = "X-Correlation-ID"
+	// HeaderVCAPRequestID is the header set by the Cloud Foundry router
+	HeaderVCAPRequestID = "X-Vcap-Request-Id"
+)
+
+// FromContext returns the correlation ID carried in the baggage of ctx
+func FromContext(ctx context.Context) string {
+	return baggageattr.FromContext(ctx, Key)
+}
+
+// ContextWithID stores the correlation ID in the baggage of ctx
+func ContextWithID(ctx context.Context, id string) context.Context {
+	return baggageattr.ContextWithValue(ctx, Key, id)
+}
+
+// Propagator extracts the correlation ID from X-Correlation-ID, falling
+// back to x-vcap-request-id, and injects it as X-Correlation-ID. Place it
+// after the baggage propagator, which replaces the baggage on extraction.
+type Propagator struct{}
+
+var _ propagation.TextMapPropagator = Propagator{}
+
+// Inject sets the X-Correlation-ID header from the baggage of ctx
+func (Propagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	if id := FromContext(ctx); id != "" {
+		carrier.Set(HeaderCorrelationID, id)
+	}
+}
+
+// Extract stores the incoming correlation ID in the baggage
+func (Propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	id := carrier.Get(HeaderCorrelationID)
+	if id == "" {
+		id = carrier.Get(HeaderVCAPRequestID)
+	}
+	if id == "" {
+		return ctx
+	}
+	return ContextWithID(ctx, id)
+}
+
+// Fields returns the headers used by the propagator
+func (Propagator) Fields() []string {
+	return []string{HeaderCorrelationID, HeaderVCAPRequestID}
+}
+
+// Processor stamps the correlation ID of the current context onto spans
+// (on start) and log records (on emit). It is both a span and a log processor.
+type Processor = baggageattr.Processor
+
+// NewProcessor creates a correlation ID stamping processor
+func NewProcessor() Processor {
+	return baggageattr.NewProcessor(Key)
+}
@@ -0,0 +1,77 @@
+package correlation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPropagator(t *testing.T) {
+	p := propagation.NewCompositeTextMapPropagator(propagation.Baggage{}, Propagator{})
+
+	in := http.Header{}
+	in.Set(HeaderVCAPRequestID, "vcap-1")
+	in.Set("Baggage", "tenant=t1")
+	ctx := p.Extract(context.Background(), propagation.HeaderCarrier(in))
+	if got := FromContext(ctx); got != "vcap-1" {
+		t.Errorf("Expected correlation ID from x-vcap-request-id, got %q", got)
+	}
+
+	in.Set(HeaderCorrelationID, "corr-1")
+	ctx = p.Extract(context.Background(), propagation.HeaderCarrier(in))
+	if got := FromContext(ctx); got != "corr-1" {
+		t.Errorf("Expected X-Correlation-ID to take precedence, got %q", got)
+	}
+
+	out := http.Header{}
+	p.Inject(ctx, propagation.HeaderCarrier(out))
+	if got := out.Get(HeaderCorrelationID); got != "corr-1" {
+		t.Errorf("Expected injected X-Correlation-ID, got %q", got)
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	ctx := ContextWithID(context.Background(), "corr-1")
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewProcessor()), sdktrace.WithSyncer(exporter))
+	_, span := tp.Tracer("test").Start(ctx, "op")
+	span.End()
+
+	found := false
+	for _, kv := range exporter.GetSpans()[0].Attributes {
+		if kv.Key == Key && kv.Value.AsString() == "corr-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected correlation_id span attribute")
+	}
+
+	var got string
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(NewProcessor()), sdklog.WithProcessor(inspect(func(r *sdklog.Record) {
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == Key {
+				got = kv.Value.AsString()
+			}
+			return true
+		})
+	})))
+	lp.Logger("test").Emit(ctx, log.Record{})
+	if got != "corr-1" {
+		t.Errorf("Expected correlation_id log attribute, got %q", got)
+	}
+}
+
+// inspect is a log processor calling fn for every record
+type inspect func(*sdklog.Record)
+
+func (f inspect) OnEmit(_ context.Context, r *sdklog.Record) error { f(r); return nil }
+func (f inspect) Shutdown(context.Context) error                   { return nil }
+func (f inspect) ForceFlush(context.Context) error                 { return nil }
@@ -0,0 +1,32 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/dashboard"
+)
+
+// DashboardPath is the path DashboardHandler is mounted on
+const DashboardPath = dashboard.Path
+
+// initDashboard creates the recorder, added to the providers as they are
+// initialized
+func (t *Telemetry) initDashboard() {
+	cfg := t.config.Dashboard
+	t.dashboard = dashboard.New(
+		dashboard.WithMaxTraces(cfg.MaxTraces),
+		dashboard.WithMaxLogs(cfg.MaxLogs),
+	)
+}
+
+// DashboardHandler serves the development dashboard with the recent
+// traces, logs and metric values, or not found if dashboard.enabled is not
+// set. Mount it on DashboardPath:
+//
+//	mux.Handle(telemetry.DashboardPath, tel.DashboardHandler())
+func (t *Telemetry) DashboardHandler() http.Handler {
+	if t.dashboard == nil {
+		return http.NotFoundHandler()
+	}
+	return t.dashboard.Handler()
+}
@@ -0,0 +1,401 @@
+// Package dashboard provides an embedded development UI showing the recent
+// traces, logs and current metric values of the process.
+//
+// A Recorder keeps the last traces and log records in in-memory ring
+// buffers and reads the metric values on demand. Its Handler serves a
+// single page under Path rendering each trace as a waterfall, a local
+// Jaeger-lite for development. Spans and logs are recorded before
+// redaction, so the dashboard must not be enabled in production.
+package dashboard
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Path is the path the dashboard is served under
+const Path = "/__telemetry/"
+
+// Defaults of the ring buffers
+const (
+	DefaultMaxTraces = 100
+	DefaultMaxLogs   = 500
+)
+
+// MaxSpansPerTrace caps the spans kept of a single trace, further spans
+// are counted as dropped
+const MaxSpansPerTrace = 1000
+
+// Trace is a recorded trace
+type Trace struct {
+	TraceID string `json:"trace_id"`
+	// Root is the name of the root span, or of the first ended span while
+	// the root span is running
+	Root       string    `json:"root"`
+	Start      time.Time `json:"start"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      bool      `json:"error"`
+	SpanCount  int       `json:"span_count"`
+	Dropped    int       `json:"dropped,omitempty"`
+	Spans      []Span    `json:"spans,omitempty"`
+
+	end time.Time
+}
+
+// Span is a recorded span
+type Span struct {
+	SpanID        string            `json:"span_id"`
+	ParentSpanID  string            `json:"parent_span_id,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	Scope         string            `json:"scope,omitempty"`
+	Start         time.Time         `json:"start"`
+	DurationMs    float64           `json:"duration_ms"`
+	Error         bool              `json:"error"`
+	StatusMessage string            `json:"status_message,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Events        []Event           `json:"events,omitempty"`
+}
+
+// Event is a recorded span event
+type Event struct {
+	Name       string            `json:"name"`
+	Time       time.Time         `json:"time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Log is a recorded log record
+type Log struct {
+	Time       time.Time         `json:"time"`
+	Severity   string            `json:"severity"`
+	Body       string            `json:"body"`
+	Scope      string            `json:"scope,omitempty"`
+	TraceID    string            `json:"trace_id,omitempty"`
+	SpanID     string            `json:"span_id,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Metric is the current state of an instrument
+type Metric struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Unit        string  `json:"unit,omitempty"`
+	Scope       string  `json:"scope"`
+	Type        string  `json:"type"`
+	Points      []Point `json:"points"`
+}
+
+// Point is a data point of a metric; histograms have a count and sum
+// instead of a value
+type Point struct {
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Value      float64           `json:"value"`
+	Count      uint64            `json:"count,omitempty"`
+	Sum        float64           `json:"sum,omitempty"`
+}
+
+// Recorder is a span and log processor recording into ring buffers, and
+// provides a metric reader for the current metric values
+type Recorder struct {
+	maxTraces int
+	maxLogs   int
+	reader    *metric.ManualReader
+
+	mu     sync.Mutex
+	traces map[trace.TraceID]*Trace
+	order  []trace.TraceID // oldest first
+	logs   []Log           // ring of maxLogs records
+	next   int             // position of the next log record
+}
+
+// Compile-time checks that the recorder can be added to the providers
+var (
+	_ sdktrace.SpanProcessor = (*Recorder)(nil)
+	_ sdklog.Processor       = (*Recorder)(nil)
+)
+
+// Option configures the recorder
+type Option func(*Recorder)
+
+// WithMaxTraces sets the number of traces kept, DefaultMaxTraces if unset
+func WithMaxTraces(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.maxTraces = n
+		}
+	}
+}
+
+// WithMaxLogs sets the number of log records kept, DefaultMaxLogs if unset
+func WithMaxLogs(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.maxLogs = n
+		}
+	}
+}
+
+// New creates a recorder
+func New(opts ...Option) *Recorder {
+	r := &Recorder{
+		maxTraces: DefaultMaxTraces,
+		maxLogs:   DefaultMaxLogs,
+		reader:    metric.NewManualReader(),
+		traces:    make(map[trace.TraceID]*Trace),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.logs = make([]Log, 0, r.maxLogs)
+	return r
+}
+
+// Reader returns the metric reader to register with the meter provider
+func (r *Recorder) Reader() metric.Reader {
+	return r.reader
+}
+
+// OnStart does nothing, spans are recorded when they end
+func (r *Recorder) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+// OnEnd records the span in its trace, evicting the oldest trace if the
+// buffer is full. Spans recorded but not sampled are left out.
+func (r *Recorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	sc := s.SpanContext()
+	if !sc.IsSampled() {
+		return
+	}
+	span := Span{
+		SpanID:        sc.SpanID().String(),
+		Name:          s.Name(),
+		Kind:          s.SpanKind().String(),
+		Scope:         s.InstrumentationScope().Name,
+		Start:         s.StartTime(),
+		DurationMs:    milliseconds(s.EndTime().Sub(s.StartTime())),
+		Error:         s.Status().Code == codes.Error,
+		StatusMessage: s.Status().Description,
+		Attributes:    attributes(s.Attributes()),
+	}
+	if parent := s.Parent(); parent.IsValid() && !parent.IsRemote() {
+		span.ParentSpanID = parent.SpanID().String()
+	}
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, Event{Name: e.Name, Time: e.Time, Attributes: attributes(e.Attributes)})
+	}
+	root := span.ParentSpanID == ""
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.traces[sc.TraceID()]
+	if !ok {
+		if len(r.order) >= r.maxTraces {
+			delete(r.traces, r.order[0])
+			r.order = r.order[1:]
+		}
+		t = &Trace{TraceID: sc.TraceID().String(), Root: span.Name, Start: span.Start}
+		r.traces[sc.TraceID()] = t
+		r.order = append(r.order, sc.TraceID())
+	}
+
+	t.SpanCount++
+	if len(t.Spans) >= MaxSpansPerTrace {
+		t.Dropped++
+	} else {
+		t.Spans = append(t.Spans, span)
+	}
+	if root {
+		t.Root = span.Name
+	}
+	if span.Start.Before(t.Start) {
+		t.Start = span.Start
+	}
+	if end := s.EndTime(); end.After(t.end) {
+		t.end = end
+	}
+	t.DurationMs = milliseconds(t.end.Sub(t.Start))
+	t.Error = t.Error || span.Error
+}
+
+// OnEmit records the log record, overwriting the oldest if the buffer is
+// full
+func (r *Recorder) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	l := Log{
+		Time:     record.Timestamp(),
+		Severity: record.SeverityText(),
+		Body:     record.Body().String(),
+		Scope:    record.InstrumentationScope().Name,
+	}
+	if l.Time.IsZero() {
+		l.Time = record.ObservedTimestamp()
+	}
+	if l.Severity == "" && record.Severity() != log.SeverityUndefined {
+		l.Severity = record.Severity().String()
+	}
+	if tid := record.TraceID(); tid.IsValid() {
+		l.TraceID = tid.String()
+	}
+	if sid := record.SpanID(); sid.IsValid() {
+		l.SpanID = sid.String()
+	}
+	if record.AttributesLen() > 0 {
+		l.Attributes = make(map[string]string, record.AttributesLen())
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			l.Attributes[kv.Key] = kv.Value.String()
+			return true
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.logs) < r.maxLogs {
+		r.logs = append(r.logs, l)
+	} else {
+		r.logs[r.next] = l
+	}
+	r.next = (r.next + 1) % r.maxLogs
+	return nil
+}
+
+// Shutdown does nothing, the recorded data stays available
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (r *Recorder) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Traces returns the recorded traces without their spans, newest first
+func (r *Recorder) Traces() []Trace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	traces := make([]Trace, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		t := *r.traces[r.order[i]]
+		t.Spans = nil
+		traces = append(traces, t)
+	}
+	return traces
+}
+
+// Trace returns the recorded trace with the hex trace ID, its spans
+// ordered by start time
+func (r *Recorder) Trace(traceID string) (Trace, bool) {
+	id, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return Trace{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.traces[id]
+	if !ok {
+		return Trace{}, false
+	}
+	result := *t
+	result.Spans = append([]Span(nil), t.Spans...)
+	sort.SliceStable(result.Spans, func(i, j int) bool {
+		return result.Spans[i].Start.Before(result.Spans[j].Start)
+	})
+	return result, true
+}
+
+// Logs returns the recorded log records, newest first
+func (r *Recorder) Logs() []Log {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	logs := make([]Log, 0, len(r.logs))
+	for i := 1; i <= len(r.logs); i++ {
+		logs = append(logs, r.logs[(r.next-i+len(r.logs))%len(r.logs)])
+	}
+	return logs
+}
+
+// Metrics collects the current metric values
+func (r *Recorder) Metrics(ctx context.Context) ([]Metric, error) {
+	var rm metricdata.ResourceMetrics
+	if err := r.reader.Collect(ctx, &rm); err != nil {
+		return nil, err
+	}
+	metrics := []Metric{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			mt := Metric{Name: m.Name, Description: m.Description, Unit: m.Unit, Scope: sm.Scope.Name}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				mt.Type = sumType(data.IsMonotonic)
+				for _, dp := range data.DataPoints {
+					mt.Points = append(mt.Points, Point{Attributes: attributes(dp.Attributes.ToSlice()), Value: float64(dp.Value)})
+				}
+			case metricdata.Sum[float64]:
+				mt.Type = sumType(data.IsMonotonic)
+				for _, dp := range data.DataPoints {
+					mt.Points = append(mt.Points, Point{Attributes: attributes(dp.Attributes.ToSlice()), Value: dp.Value})
+				}
+			case metricdata.Gauge[int64]:
+				mt.Type = "gauge"
+				for _, dp := range data.DataPoints {
+					mt.Points = append(mt.Points, Point{Attributes: attributes(dp.Attributes.ToSlice()), Value: float64(dp.Value)})
+				}
+			case metricdata.Gauge[float64]:
+				mt.Type = "gauge"
+				for _, dp := range data.DataPoints {
+					mt.Points = append(mt.Points, Point{Attributes: attributes(dp.Attributes.ToSlice()), Value: dp.Value})
+				}
+			case metricdata.Histogram[int64]:
+				mt.Type = "histogram"
+				for _, dp := range data.DataPoints {
+					mt.Points = append(mt.Points, Point{Attributes: attributes(dp.Attributes.ToSlice()), Count: dp.Count, Sum: float64(dp.Sum)})
+				}
+			case metricdata.Histogram[float64]:
+				mt.Type = "histogram"
+				for _, dp := range data.DataPoints {
+					mt.Points = append(mt.Points, Point{Attributes: attributes(dp.Attributes.ToSlice()), Count: dp.Count, Sum: dp.Sum})
+				}
+			default:
+				continue
+			}
+			metrics = append(metrics, mt)
+		}
+	}
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics, nil
+}
+
+// sumType names counters and up-down counters
+func sumType(monotonic bool) string {
+	if monotonic {
+		return "counter"
+	}
+	return "updowncounter"
+}
+
+// attributes renders attributes as strings
+func attributes(kvs []attribute.KeyValue) map[string]string {
+	if len(kvs) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
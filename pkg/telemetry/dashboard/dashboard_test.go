@@ -0,0 +1,240 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newProviders creates providers recording into r
+func newProviders(r *Recorder) (*sdktrace.TracerProvider, *sdklog.LoggerProvider, *sdkmetric.MeterProvider) {
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(r)),
+		sdklog.NewLoggerProvider(sdklog.WithProcessor(r)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(r.Reader()))
+}
+
+func TestRecorderTraces(t *testing.T) {
+	r := New()
+	tp, _, _ := newProviders(r)
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /books")
+	_, child := tracer.Start(ctx, "SELECT books")
+	child.SetAttributes(attribute.String("db.system", "postgresql"))
+	child.RecordError(errors.New("connection reset"))
+	child.SetStatus(codes.Error, "connection reset")
+	child.End()
+	root.End()
+
+	traces := r.Traces()
+	if len(traces) != 1 {
+		t.Fatalf("Expected 1 trace, got %d", len(traces))
+	}
+	summary := traces[0]
+	if summary.Root != "GET /books" {
+		t.Errorf("Expected root 'GET /books', got '%s'", summary.Root)
+	}
+	if summary.SpanCount != 2 || !summary.Error {
+		t.Errorf("Expected 2 spans with an error, got %+v", summary)
+	}
+	if summary.Spans != nil {
+		t.Error("Expected no spans in the trace list")
+	}
+
+	trace, ok := r.Trace(root.SpanContext().TraceID().String())
+	if !ok {
+		t.Fatal("Expected the trace to be found")
+	}
+	if len(trace.Spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(trace.Spans))
+	}
+	if trace.Spans[0].Name != "GET /books" || trace.Spans[0].ParentSpanID != "" {
+		t.Errorf("Expected the root span first, got %+v", trace.Spans[0])
+	}
+	db := trace.Spans[1]
+	if db.ParentSpanID != root.SpanContext().SpanID().String() {
+		t.Errorf("Expected the parent span ID of the root span, got '%s'", db.ParentSpanID)
+	}
+	if db.Attributes["db.system"] != "postgresql" {
+		t.Errorf("Expected db.system attribute, got %v", db.Attributes)
+	}
+	if len(db.Events) != 1 || db.Events[0].Name != "exception" {
+		t.Errorf("Expected an exception event, got %v", db.Events)
+	}
+	if !db.Error || db.StatusMessage != "connection reset" {
+		t.Errorf("Expected the error status, got %+v", db)
+	}
+
+	if _, ok := r.Trace("not-a-trace-id"); ok {
+		t.Error("Expected an invalid trace ID not to be found")
+	}
+}
+
+func TestRecorderEvictsOldestTrace(t *testing.T) {
+	r := New(WithMaxTraces(2))
+	tp, _, _ := newProviders(r)
+	tracer := tp.Tracer("test")
+
+	for i := 0; i < 3; i++ {
+		_, span := tracer.Start(context.Background(), fmt.Sprintf("span-%d", i))
+		span.End()
+	}
+
+	traces := r.Traces()
+	if len(traces) != 2 {
+		t.Fatalf("Expected 2 traces, got %d", len(traces))
+	}
+	if traces[0].Root != "span-2" || traces[1].Root != "span-1" {
+		t.Errorf("Expected the newest traces first, got %s and %s", traces[0].Root, traces[1].Root)
+	}
+}
+
+func TestRecorderLogs(t *testing.T) {
+	r := New(WithMaxLogs(2))
+	tp, lp, _ := newProviders(r)
+	logger := lp.Logger("test")
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	for i := 0; i < 3; i++ {
+		var record log.Record
+		record.SetBody(log.StringValue(fmt.Sprintf("message %d", i)))
+		record.SetSeverity(log.SeverityInfo)
+		record.AddAttributes(log.Int("attempt", i))
+		logger.Emit(ctx, record)
+	}
+	span.End()
+
+	logs := r.Logs()
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+	if logs[0].Body != "message 2" || logs[1].Body != "message 1" {
+		t.Errorf("Expected the newest logs first, got '%s' and '%s'", logs[0].Body, logs[1].Body)
+	}
+	if logs[0].Severity != "INFO" {
+		t.Errorf("Expected severity INFO, got '%s'", logs[0].Severity)
+	}
+	if logs[0].Attributes["attempt"] != "2" {
+		t.Errorf("Expected attempt attribute, got %v", logs[0].Attributes)
+	}
+	if logs[0].TraceID != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected the trace ID of the active span, got '%s'", logs[0].TraceID)
+	}
+	if logs[0].Time.IsZero() {
+		t.Error("Expected a timestamp")
+	}
+}
+
+func TestRecorderMetrics(t *testing.T) {
+	r := New()
+	_, _, mp := newProviders(r)
+	meter := mp.Meter("test")
+	ctx := context.Background()
+
+	counter, _ := meter.Int64Counter("orders", metric.WithUnit("{order}"))
+	counter.Add(ctx, 3, metric.WithAttributes(attribute.String("channel", "web")))
+	histogram, _ := meter.Float64Histogram("latency")
+	histogram.Record(ctx, 0.5)
+	histogram.Record(ctx, 1.5)
+
+	metrics, err := r.Metrics(ctx)
+	if err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("Expected 2 metrics, got %d", len(metrics))
+	}
+	latency, orders := metrics[0], metrics[1]
+	if latency.Type != "histogram" || latency.Points[0].Count != 2 || latency.Points[0].Sum != 2 {
+		t.Errorf("Expected histogram with count 2 and sum 2, got %+v", latency)
+	}
+	if orders.Type != "counter" || orders.Unit != "{order}" || orders.Points[0].Value != 3 {
+		t.Errorf("Expected counter with value 3, got %+v", orders)
+	}
+	if orders.Points[0].Attributes["channel"] != "web" {
+		t.Errorf("Expected channel attribute, got %v", orders.Points[0].Attributes)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := New()
+	tp, lp, _ := newProviders(r)
+	ctx, span := tp.Tracer("test").Start(context.Background(), "GET /books")
+	var record log.Record
+	record.SetBody(log.StringValue("listing books"))
+	lp.Logger("test").Emit(ctx, record)
+	span.End()
+	traceID := span.SpanContext().TraceID().String()
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, r.Handler())
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(path string, v interface{}) *http.Response {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("Failed to decode %s: %v", path, err)
+			}
+		}
+		return resp
+	}
+
+	resp := get(Path, nil)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Expected the HTML page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var traces []Trace
+	get(Path+"api/traces", &traces)
+	if len(traces) != 1 || traces[0].TraceID != traceID {
+		t.Errorf("Expected the recorded trace, got %+v", traces)
+	}
+
+	var trace Trace
+	get(Path+"api/traces/"+traceID, &trace)
+	if len(trace.Spans) != 1 {
+		t.Errorf("Expected 1 span, got %d", len(trace.Spans))
+	}
+	if resp := get(Path+"api/traces/00000000000000000000000000000001", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown trace, got %d", resp.StatusCode)
+	}
+
+	var logs []Log
+	get(Path+"api/logs?trace_id="+traceID, &logs)
+	if len(logs) != 1 || logs[0].Body != "listing books" {
+		t.Errorf("Expected the log of the trace, got %+v", logs)
+	}
+	get(Path+"api/logs?trace_id=00000000000000000000000000000001", &logs)
+	if len(logs) != 0 {
+		t.Errorf("Expected no logs of another trace, got %+v", logs)
+	}
+
+	var metrics []Metric
+	if resp := get(Path+"api/metrics", &metrics); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 for metrics, got %d", resp.StatusCode)
+	}
+
+	if resp := get(Path+"unknown", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown path, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+//go:embed index.html
+var index []byte
+
+// Handler serves the dashboard page and its JSON API under Path:
+//
+//	GET /__telemetry/                  the page
+//	GET /__telemetry/api/traces        recent traces, newest first
+//	GET /__telemetry/api/traces/{id}   a trace with its spans
+//	GET /__telemetry/api/logs          recent log records, newest first; ?trace_id= filters
+//	GET /__telemetry/api/metrics       current metric values
+func (r *Recorder) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+Path+"{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(index)
+	})
+	mux.HandleFunc("GET "+Path+"api/traces", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, r.Traces())
+	})
+	mux.HandleFunc("GET "+Path+"api/traces/{id}", func(w http.ResponseWriter, req *http.Request) {
+		t, ok := r.Trace(req.PathValue("id"))
+		if !ok {
+			http.Error(w, "trace not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, t)
+	})
+	mux.HandleFunc("GET "+Path+"api/logs", func(w http.ResponseWriter, req *http.Request) {
+		logs := r.Logs()
+		if traceID := strings.ToLower(req.URL.Query().Get("trace_id")); traceID != "" {
+			filtered := logs[:0]
+			for _, l := range logs {
+				if l.TraceID == traceID {
+					filtered = append(filtered, l)
+				}
+			}
+			logs = filtered
+		}
+		writeJSON(w, logs)
+	})
+	mux.HandleFunc("GET "+Path+"api/metrics", func(w http.ResponseWriter, req *http.Request) {
+		metrics, err := r.Metrics(req.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, metrics)
+	})
+	return mux
+}
+
+// writeJSON writes v as the JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/dashboard"
+	"go.opentelemetry.io/otel"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// discardLogs drops the exported log records
+type discardLogs struct{}
+
+func (discardLogs) Export(ctx context.Context, records []sdklog.Record) error { return nil }
+func (discardLogs) Shutdown(ctx context.Context) error                        { return nil }
+func (discardLogs) ForceFlush(ctx context.Context) error                      { return nil }
+
+func TestDashboardHandler(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.StartupReport = false
+	cfg.Logging.Level = "info"
+	cfg.Dashboard = &config.DashboardConfig{Enabled: true, MaxTraces: 10}
+	tel, err := New(WithConfig(cfg), WithSpanExporter(tracetest.NewInMemoryExporter()), WithLogExporter(discardLogs{}), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	ctx, span, end := Start(context.Background(), "GET /books")
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("cache miss"))
+	record.SetSeverity(otellog.SeverityDebug)
+	tel.LoggerProvider().Logger("test").Emit(ctx, record)
+	end(nil)
+
+	mux := http.NewServeMux()
+	mux.Handle(DashboardPath, tel.DashboardHandler())
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DashboardPath+"api/traces", nil))
+	var traces []dashboard.Trace
+	if err := json.Unmarshal(rec.Body.Bytes(), &traces); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, rec.Body.String())
+	}
+	if len(traces) != 1 || traces[0].TraceID != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected the recorded trace, got %+v", traces)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DashboardPath+"api/logs", nil))
+	var logs []dashboard.Log
+	if err := json.Unmarshal(rec.Body.Bytes(), &logs); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, rec.Body.String())
+	}
+	if len(logs) != 1 || logs[0].Body != "cache miss" {
+		t.Errorf("Expected the debug log below the level to be recorded, got %+v", logs)
+	}
+}
+
+func TestDashboardHandlerDisabled(t *testing.T) {
+	tel := &Telemetry{config: config.NewDefaultConfig(), logger: log.New(io.Discard, "", 0)}
+
+	rec := httptest.NewRecorder()
+	tel.DashboardHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DashboardPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 if the dashboard is disabled, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+)
+
+// DebugHeader returns the tracing.sampler.debug_header forcing sampling of
+// requests carrying it, "" if unset. Pass it to router.WithDebugHeader:
+//
+//	engine.Use(router.Gin(router.WithDebugHeader(tel.DebugHeader())))
+func (t *Telemetry) DebugHeader() string {
+	t.settingsMu.Lock()
+	defer t.settingsMu.Unlock()
+	if t.config.Tracing == nil || t.config.Tracing.Sampler == nil {
+		return ""
+	}
+	return t.config.Tracing.Sampler.DebugHeader
+}
+
+// DebugHeaderMiddleware marks requests carrying the configured debug header
+// for forced sampling, see sampling.DebugHeaderMiddleware. Without a debug
+// header it returns next. Install it in front of the tracing middleware.
+func (t *Telemetry) DebugHeaderMiddleware(next http.Handler) http.Handler {
+	header := t.DebugHeader()
+	if header == "" {
+		return next
+	}
+	return sampling.DebugHeaderMiddleware(header, next)
+}
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+)
+
+func TestDebugHeaderMiddleware(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tracing.Sampler.DebugHeader = "X-Debug-Trace"
+	tel := &Telemetry{config: cfg}
+	if got := tel.DebugHeader(); got != "X-Debug-Trace" {
+		t.Errorf("Expected the configured debug header, got %q", got)
+	}
+
+	forced := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forced = sampling.ForceSamplingFromContext(r.Context())
+	})
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Debug-Trace", "1")
+	tel.DebugHeaderMiddleware(next).ServeHTTP(httptest.NewRecorder(), req)
+	if !forced {
+		t.Error("Expected a request with the configured header to be forced")
+	}
+
+	cfg.Tracing.Sampler = nil
+	if got := tel.DebugHeader(); got != "" {
+		t.Errorf("Expected no debug header without a sampler config, got %q", got)
+	}
+	tel.DebugHeaderMiddleware(next).ServeHTTP(httptest.NewRecorder(), req)
+	if forced {
+		t.Error("Expected no forced sampling without a debug header")
+	}
+}
@@ -0,0 +1,70 @@
+package detectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// azureEndpoint is the Azure instance metadata service
+const azureEndpoint = "http://169.254.169.254"
+
+// Azure detects Azure virtual machines
+type Azure struct {
+	cfg   config
+	cache cache
+}
+
+var _ resource.Detector = (*Azure)(nil)
+
+// NewAzure creates an Azure VM detector
+func NewAzure(opts ...Option) *Azure {
+	return &Azure{cfg: newConfig(azureEndpoint, opts)}
+}
+
+// azureCompute is the subset of the compute metadata in use
+type azureCompute struct {
+	Location       string `json:"location"`
+	Zone           string `json:"zone"`
+	VMID           string `json:"vmId"`
+	VMSize         string `json:"vmSize"`
+	Name           string `json:"name"`
+	SubscriptionID string `json:"subscriptionId"`
+}
+
+// Detect returns the cloud and host attributes of the virtual machine
+func (d *Azure) Detect(ctx context.Context) (*resource.Resource, error) {
+	return d.cache.detect(ctx, d.cfg.timeout, d.detect), nil
+}
+
+func (d *Azure) detect(ctx context.Context) (*resource.Resource, error) {
+	body, err := d.cfg.get(ctx, http.MethodGet, "/metadata/instance/compute?api-version=2021-12-13&format=json", map[string]string{
+		"Metadata": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var compute azureCompute
+	if err := json.Unmarshal(body, &compute); err != nil {
+		return nil, err
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAzure,
+		semconv.CloudPlatformAzureVM,
+	}
+	attrs = appendNonEmpty(attrs,
+		semconv.CloudRegion(compute.Location),
+		semconv.CloudAvailabilityZone(compute.Zone),
+		semconv.CloudAccountID(compute.SubscriptionID),
+		semconv.HostID(compute.VMID),
+		semconv.HostType(compute.VMSize),
+		semconv.HostName(compute.Name),
+	)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
@@ -0,0 +1,185 @@
+// Package detectors provides opt-in resource detectors for cloud provider
+// metadata (AWS EC2, Google Compute Engine, Azure VMs) and Dynatrace
+// entity enrichment.
+//
+// Detectors query the instance metadata endpoint of their provider with a
+// short timeout. Hosts without such an endpoint yield an empty resource
+// rather than an error, and every detector caches its result, so detection
+// only delays the first startup of a process by at most one timeout.
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// DefaultTimeout bounds a single detection, including all metadata
+// requests, unless set explicitly or the context carries a deadline
+const DefaultTimeout = time.Second
+
+// config holds the settings shared by all detectors
+type config struct {
+	endpoint string
+	timeout  time.Duration
+	client   *http.Client
+}
+
+// Option configures a detector
+type Option func(*config)
+
+// WithEndpoint overrides the metadata endpoint, e.g. for tests
+func WithEndpoint(endpoint string) Option {
+	return func(c *config) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithTimeout sets the detection timeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to query the metadata endpoint
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// newConfig applies the options on top of the defaults
+func newConfig(endpoint string, opts []Option) config {
+	c := config{
+		endpoint: endpoint,
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// get performs a metadata request and returns the body of a 200 response
+func (c *config) get(ctx context.Context, method, path string, header map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata request %s returned %s", path, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// cache remembers the result of the first detection
+type cache struct {
+	once sync.Once
+	res  *resource.Resource
+}
+
+// detect runs fn once with the detection timeout applied. Failures mean the
+// provider is not present and yield an empty resource.
+func (c *cache) detect(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (*resource.Resource, error)) *resource.Resource {
+	c.once.Do(func() {
+		if _, ok := ctx.Deadline(); timeout <= 0 && !ok {
+			timeout = DefaultTimeout
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		res, err := fn(ctx)
+		if err != nil || res == nil {
+			res = resource.Empty()
+		}
+		c.res = res
+	})
+	return c.res
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]func() resource.Detector{
+		"aws_ec2":   func() resource.Detector { return NewEC2() },
+		"gcp":       func() resource.Detector { return NewGCE() },
+		"azure":     func() resource.Detector { return NewAzure() },
+		"dynatrace": func() resource.Detector { return NewDynatrace() },
+	}
+	// instances are shared so repeated detections are served from the cache
+	instances = make(map[string]resource.Detector)
+)
+
+// Names returns the sorted names accepted by Detect
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Detect runs the named detectors (aws_ec2, gcp, azure, dynatrace)
+// concurrently and merges their resources. Results are cached for the
+// lifetime of the process.
+func Detect(ctx context.Context, names ...string) (*resource.Resource, error) {
+	detectors := make([]resource.Detector, len(names))
+	registryMu.Lock()
+	for i, name := range names {
+		d, ok := instances[name]
+		if !ok {
+			newDetector, known := registry[name]
+			if !known {
+				registryMu.Unlock()
+				return nil, fmt.Errorf("unknown resource detector: %s", name)
+			}
+			d = newDetector()
+			instances[name] = d
+		}
+		detectors[i] = d
+	}
+	registryMu.Unlock()
+
+	results := make([]*resource.Resource, len(detectors))
+	var wg sync.WaitGroup
+	for i, d := range detectors {
+		wg.Add(1)
+		go func(i int, d resource.Detector) {
+			defer wg.Done()
+			results[i], _ = d.Detect(ctx)
+		}(i, d)
+	}
+	wg.Wait()
+
+	merged := resource.Empty()
+	for _, r := range results {
+		var err error
+		if merged, err = resource.Merge(merged, r); err != nil {
+			return nil, fmt.Errorf("failed to merge detected resources: %w", err)
+		}
+	}
+	return merged, nil
+}
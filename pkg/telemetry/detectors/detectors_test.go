@@ -0,0 +1,138 @@
+package detectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func attr(r *resource.Resource, key string) string {
+	v, _ := r.Set().Value(attribute.Key(key))
+	return v.Emit()
+}
+
+func TestEC2(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			w.Write([]byte("token"))
+		case r.URL.Path == "/latest/dynamic/instance-identity/document" && r.Header.Get("X-aws-ec2-metadata-token") == "token":
+			w.Write([]byte(`{"region":"eu-central-1","availabilityZone":"eu-central-1a","instanceId":"i-123","instanceType":"t3.micro","accountId":"42"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	d := NewEC2(WithEndpoint(srv.URL))
+	res, err := d.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if attr(res, "cloud.provider") != "aws" || attr(res, "cloud.region") != "eu-central-1" || attr(res, "host.id") != "i-123" {
+		t.Errorf("Unexpected resource %v", res)
+	}
+
+	d.Detect(context.Background())
+	if requests.Load() != 2 {
+		t.Errorf("Expected the second detection to be cached, got %d requests", requests.Load())
+	}
+}
+
+func TestGCE(t *testing.T) {
+	values := map[string]string{
+		"/computeMetadata/v1/instance/id":           "123",
+		"/computeMetadata/v1/project/project-id":    "my-project",
+		"/computeMetadata/v1/instance/zone":         "projects/1/zones/europe-west3-b",
+		"/computeMetadata/v1/instance/machine-type": "projects/1/machineTypes/e2-small",
+		"/computeMetadata/v1/instance/name":         "vm-1",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(values[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	res, _ := NewGCE(WithEndpoint(srv.URL)).Detect(context.Background())
+	if attr(res, "cloud.region") != "europe-west3" || attr(res, "cloud.availability_zone") != "europe-west3-b" {
+		t.Errorf("Unexpected region/zone in %v", res)
+	}
+	if attr(res, "host.type") != "e2-small" || attr(res, "cloud.account.id") != "my-project" {
+		t.Errorf("Unexpected host type/account in %v", res)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"location":"westeurope","vmId":"vm-id","vmSize":"Standard_B1s","name":"vm-1","subscriptionId":"sub"}`))
+	}))
+	defer srv.Close()
+
+	res, _ := NewAzure(WithEndpoint(srv.URL)).Detect(context.Background())
+	if attr(res, "cloud.provider") != "azure" || attr(res, "cloud.region") != "westeurope" || attr(res, "host.type") != "Standard_B1s" {
+		t.Errorf("Unexpected resource %v", res)
+	}
+}
+
+func TestDynatrace(t *testing.T) {
+	dir := t.TempDir()
+	actual := filepath.Join(dir, "oneagent.properties")
+	os.WriteFile(actual, []byte("dt.entity.process_group_instance=PROCESS_GROUP_INSTANCE-1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, filepath.Base(DynatraceMetadataFiles[0])), []byte(actual+"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "dt_host_metadata.properties"), []byte("# host\ndt.entity.host=HOST-1\nother=ignored\n"), 0644)
+
+	res, _ := NewDynatrace(WithEndpoint(dir)).Detect(context.Background())
+	if attr(res, "dt.entity.host") != "HOST-1" || attr(res, "dt.entity.process_group_instance") != "PROCESS_GROUP_INSTANCE-1" {
+		t.Errorf("Unexpected resource %v", res)
+	}
+	if res.Len() != 2 {
+		t.Errorf("Expected only dt.* attributes, got %v", res)
+	}
+
+	empty, _ := NewDynatrace(WithEndpoint(t.TempDir())).Detect(context.Background())
+	if empty.Len() != 0 {
+		t.Errorf("Expected empty resource without enrichment files, got %v", empty)
+	}
+}
+
+func TestDetector_NoEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	res, err := NewAzure(WithEndpoint(srv.URL), WithTimeout(20*time.Millisecond)).Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error without metadata endpoint, got %v", err)
+	}
+	if res.Len() != 0 {
+		t.Errorf("Expected empty resource, got %v", res)
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Error("Expected detection to respect the timeout")
+	}
+}
+
+func TestDetect_Unknown(t *testing.T) {
+	if _, err := Detect(context.Background(), "openstack"); err == nil {
+		t.Error("Expected error for unknown detector")
+	}
+}
@@ -0,0 +1,93 @@
+package detectors
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// DynatraceMetadataFiles are read by the Dynatrace detector. The first one
+// is provided by OneAgent and contains the path of the actual properties
+// file; the others are written by the Dynatrace Operator on Kubernetes and
+// by the host enrichment.
+var DynatraceMetadataFiles = []string{
+	"dt_metadata_e617c525669e072eebe3d0f08212e8f2.properties",
+	"/var/lib/dynatrace/enrichment/dt_metadata.properties",
+	"/var/lib/dynatrace/enrichment/dt_host_metadata.properties",
+}
+
+// Dynatrace reads the Dynatrace enrichment files, so telemetry exported via
+// OTLP is linked to the monitored entities (dt.entity.host,
+// dt.entity.process_group_instance, ...)
+type Dynatrace struct {
+	cfg   config
+	files []string
+	cache cache
+}
+
+var _ resource.Detector = (*Dynatrace)(nil)
+
+// NewDynatrace creates a Dynatrace metadata detector. WithEndpoint sets a
+// directory the default file names are looked up in instead of their
+// regular locations, e.g. for tests.
+func NewDynatrace(opts ...Option) *Dynatrace {
+	d := &Dynatrace{cfg: newConfig("", opts), files: DynatraceMetadataFiles}
+	if dir := d.cfg.endpoint; dir != "" {
+		d.files = make([]string, len(DynatraceMetadataFiles))
+		for i, f := range DynatraceMetadataFiles {
+			d.files[i] = filepath.Join(dir, filepath.Base(f))
+		}
+	}
+	return d
+}
+
+// Detect returns the dt.* attributes of all enrichment files found
+func (d *Dynatrace) Detect(ctx context.Context) (*resource.Resource, error) {
+	return d.cache.detect(ctx, d.cfg.timeout, d.detect), nil
+}
+
+func (d *Dynatrace) detect(ctx context.Context) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	for i, name := range d.files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		// The OneAgent file only points to the properties file
+		if i == 0 {
+			if data, err = os.ReadFile(strings.TrimSpace(string(data))); err != nil {
+				continue
+			}
+		}
+		attrs = append(attrs, parseProperties(data)...)
+	}
+	if len(attrs) == 0 {
+		return resource.Empty(), nil
+	}
+	return resource.NewSchemaless(attrs...), nil
+}
+
+// parseProperties reads the dt.* key=value lines of a properties file
+func parseProperties(data []byte) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !strings.HasPrefix(key, "dt.") {
+			continue
+		}
+		attrs = append(attrs, attribute.String(key, strings.TrimSpace(value)))
+	}
+	return attrs
+}
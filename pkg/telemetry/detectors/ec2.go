@@ -0,0 +1,87 @@
+package detectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// ec2Endpoint is the EC2 instance metadata service
+const ec2Endpoint = "http://169.254.169.254"
+
+// EC2 detects AWS EC2 instances through IMDSv2
+type EC2 struct {
+	cfg   config
+	cache cache
+}
+
+var _ resource.Detector = (*EC2)(nil)
+
+// NewEC2 creates an EC2 detector
+func NewEC2(opts ...Option) *EC2 {
+	return &EC2{cfg: newConfig(ec2Endpoint, opts)}
+}
+
+// ec2Identity is the subset of the instance identity document in use
+type ec2Identity struct {
+	AccountID        string `json:"accountId"`
+	AvailabilityZone string `json:"availabilityZone"`
+	Region           string `json:"region"`
+	InstanceID       string `json:"instanceId"`
+	InstanceType     string `json:"instanceType"`
+	ImageID          string `json:"imageId"`
+}
+
+// Detect returns the cloud and host attributes of the instance
+func (d *EC2) Detect(ctx context.Context) (*resource.Resource, error) {
+	return d.cache.detect(ctx, d.cfg.timeout, d.detect), nil
+}
+
+func (d *EC2) detect(ctx context.Context) (*resource.Resource, error) {
+	token, err := d.cfg.get(ctx, http.MethodPut, "/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := d.cfg.get(ctx, http.MethodGet, "/latest/dynamic/instance-identity/document", map[string]string{
+		"X-aws-ec2-metadata-token": string(token),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var doc ec2Identity
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSEC2,
+	}
+	attrs = appendNonEmpty(attrs,
+		semconv.CloudRegion(doc.Region),
+		semconv.CloudAvailabilityZone(doc.AvailabilityZone),
+		semconv.CloudAccountID(doc.AccountID),
+		semconv.HostID(doc.InstanceID),
+		semconv.HostType(doc.InstanceType),
+		semconv.HostImageID(doc.ImageID),
+	)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// appendNonEmpty appends the attributes with a non-empty string value
+func appendNonEmpty(attrs []attribute.KeyValue, kvs ...attribute.KeyValue) []attribute.KeyValue {
+	for _, kv := range kvs {
+		if kv.Value.AsString() != "" {
+			attrs = append(attrs, kv)
+		}
+	}
+	return attrs
+}
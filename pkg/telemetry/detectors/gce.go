@@ -0,0 +1,77 @@
+package detectors
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// gceEndpoint is the Compute Engine metadata server
+const gceEndpoint = "http://metadata.google.internal"
+
+// GCE detects Google Compute Engine instances
+type GCE struct {
+	cfg   config
+	cache cache
+}
+
+var _ resource.Detector = (*GCE)(nil)
+
+// NewGCE creates a Compute Engine detector
+func NewGCE(opts ...Option) *GCE {
+	return &GCE{cfg: newConfig(gceEndpoint, opts)}
+}
+
+// Detect returns the cloud and host attributes of the instance
+func (d *GCE) Detect(ctx context.Context) (*resource.Resource, error) {
+	return d.cache.detect(ctx, d.cfg.timeout, d.detect), nil
+}
+
+func (d *GCE) detect(ctx context.Context) (*resource.Resource, error) {
+	get := func(path string) (string, error) {
+		body, err := d.cfg.get(ctx, http.MethodGet, "/computeMetadata/v1/"+path, map[string]string{
+			"Metadata-Flavor": "Google",
+		})
+		return strings.TrimSpace(string(body)), err
+	}
+
+	// The instance ID decides whether we are on Compute Engine at all
+	id, err := get("instance/id")
+	if err != nil {
+		return nil, err
+	}
+	project, _ := get("project/project-id")
+	zone, _ := get("instance/zone")
+	machineType, _ := get("instance/machine-type")
+	name, _ := get("instance/name")
+
+	// zone and machine type are returned as full resource paths
+	zone = lastSegment(zone)
+	region := ""
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderGCP,
+		semconv.CloudPlatformGCPComputeEngine,
+	}
+	attrs = appendNonEmpty(attrs,
+		semconv.CloudAccountID(project),
+		semconv.CloudRegion(region),
+		semconv.CloudAvailabilityZone(zone),
+		semconv.HostID(id),
+		semconv.HostType(lastSegment(machineType)),
+		semconv.HostName(name),
+	)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// lastSegment returns the part after the last slash
+func lastSegment(s string) string {
+	return s[strings.LastIndex(s, "/")+1:]
+}
@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+// diagnostics is the document served by DiagnosticsHandler
+type diagnostics struct {
+	Config *config.Config `json:"config"`
+	Health Health         `json:"health"`
+}
+
+// EffectiveConfig returns the configuration in use, merged from the
+// defaults, the predefined kind, the configuration file, the profile and
+// environment variables and including runtime changes, with secrets masked
+func (t *Telemetry) EffectiveConfig() (*config.Config, error) {
+	t.settingsMu.Lock()
+	defer t.settingsMu.Unlock()
+	return t.config.Masked()
+}
+
+// DiagnosticsHandler serves the effective configuration and the health as
+// JSON, to debug which setting won. Mount it on an internal port or behind authentication.
+func (t *Telemetry) DiagnosticsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		cfg, err := t.EffectiveConfig()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data, err := json.MarshalIndent(diagnostics{Config: cfg, Health: t.Health()}, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(data)
+	})
+}
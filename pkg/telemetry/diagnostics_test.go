@@ -0,0 +1,45 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+func TestDiagnosticsHandler(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Tracing.Exporter.Config["api_key"] = "abc"
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	rec := httptest.NewRecorder()
+	tel.DiagnosticsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/telemetry", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected JSON response, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if strings.Contains(rec.Body.String(), "abc") {
+		t.Errorf("Expected secrets to be masked, got %s", rec.Body.String())
+	}
+
+	var body struct {
+		Config *config.Config `json:"config"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if body.Config.ServiceName != "bookshop" || body.Config.Tracing.Exporter.Config["api_key"] != config.MaskedValue {
+		t.Errorf("Expected effective config with masked api_key, got %+v", body.Config)
+	}
+
+	rec = httptest.NewRecorder()
+	tel.DiagnosticsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/telemetry", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
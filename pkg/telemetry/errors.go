@@ -0,0 +1,129 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Well-known error types set as error.type by RecordError
+const (
+	ErrorTypeTimeout  = "timeout"
+	ErrorTypeCanceled = "canceled"
+	ErrorTypeNotFound = "not_found"
+	ErrorTypePanic    = "panic"
+)
+
+// RecordErrorOption configures RecordError
+type RecordErrorOption func(*recordErrorConfig)
+
+// recordErrorConfig holds the settings of RecordError
+type recordErrorConfig struct {
+	counter metric.Int64Counter
+	attrs   []attribute.KeyValue
+}
+
+// WithErrorCounter increments counter once per recorded error, with the
+// error.type of the error and the attributes of WithErrorAttributes
+func WithErrorCounter(counter metric.Int64Counter) RecordErrorOption {
+	return func(c *recordErrorConfig) {
+		c.counter = counter
+	}
+}
+
+// WithErrorAttributes adds attrs to the exception events and the counter
+func WithErrorAttributes(attrs ...attribute.KeyValue) RecordErrorOption {
+	return func(c *recordErrorConfig) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+// RecordError records err on the span of ctx as exception events with a
+// stack trace and sets the error status. The errors of an errors.Join chain
+// are recorded as one event each. Nothing is recorded for a nil err.
+func RecordError(ctx context.Context, err error, opts ...RecordErrorOption) {
+	if err == nil {
+		return
+	}
+	cfg := &recordErrorConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	recordError(ctx, oteltrace.SpanFromContext(ctx), err, cfg)
+}
+
+// recordError records err on span, counting it with the configured counter
+func recordError(ctx context.Context, span oteltrace.Span, err error, cfg *recordErrorConfig) {
+	for _, e := range joinedErrors(err) {
+		errType := ErrorType(e)
+		attrs := append([]attribute.KeyValue{semconv.ErrorTypeKey.String(errType)}, cfg.attrs...)
+
+		if span.IsRecording() {
+			eventAttrs := attrs
+			var perr *PanicError
+			if errors.As(e, &perr) {
+				// The stack of the panic, RecordError would only see the
+				// recovering goroutine
+				eventAttrs = append(eventAttrs, semconv.ExceptionStacktrace(string(perr.Stack)))
+				span.RecordError(e, oteltrace.WithAttributes(eventAttrs...))
+			} else {
+				span.RecordError(e, oteltrace.WithAttributes(eventAttrs...), oteltrace.WithStackTrace(true))
+			}
+		}
+		if cfg.counter != nil {
+			cfg.counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+		}
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// joinedErrors flattens the errors of errors.Join chains, any other error
+// is returned as is
+func joinedErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		if e != nil {
+			errs = append(errs, joinedErrors(e)...)
+		}
+	}
+	if len(errs) == 0 {
+		return []error{err}
+	}
+	return errs
+}
+
+// ErrorType classifies err for the error.type attribute: context deadlines
+// and network timeouts are "timeout", canceled contexts "canceled",
+// sql.ErrNoRows "not_found" and recovered panics "panic". Any other error
+// is reported by its Go type.
+func ErrorType(err error) string {
+	var netErr net.Error
+	var perr *PanicError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &perr):
+		return ErrorTypePanic
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTypeTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTypeTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrorTypeNotFound
+	}
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{context.DeadlineExceeded, ErrorTypeTimeout},
+		{fmt.Errorf("failed to query: %w", context.DeadlineExceeded), ErrorTypeTimeout},
+		{context.Canceled, ErrorTypeCanceled},
+		{fmt.Errorf("failed to load book: %w", sql.ErrNoRows), ErrorTypeNotFound},
+		{&PanicError{Value: "boom"}, ErrorTypePanic},
+		{errors.New("boom"), "*errors.errorString"},
+	}
+	for _, tt := range tests {
+		if got := ErrorType(tt.err); got != tt.want {
+			t.Errorf("Expected %q for %v, got %q", tt.want, tt.err, got)
+		}
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := recordGlobalSpans(t)
+	reader := sdkmetric.NewManualReader()
+	counter, err := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test").Int64Counter("errors")
+	if err != nil {
+		t.Fatalf("failed to create counter: %v", err)
+	}
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "checkout")
+	RecordError(ctx, nil)
+	RecordError(ctx,
+		errors.Join(sql.ErrNoRows, errors.Join(context.DeadlineExceeded, nil)),
+		WithErrorCounter(counter), WithErrorAttributes(attribute.String("step", "payment")))
+	span.End()
+
+	ended := recorder.Ended()[0]
+	if ended.Status().Code != codes.Error {
+		t.Errorf("Expected the error status, got %v", ended.Status())
+	}
+	events := ended.Events()
+	if len(events) != 2 {
+		t.Fatalf("Expected an event per joined error, got %d", len(events))
+	}
+	for i, want := range []string{ErrorTypeNotFound, ErrorTypeTimeout} {
+		attrs := attribute.NewSet(events[i].Attributes...)
+		if v, _ := attrs.Value(semconv.ErrorTypeKey); v.AsString() != want {
+			t.Errorf("Expected error.type %q, got %q", want, v.AsString())
+		}
+		if v, _ := attrs.Value("step"); v.AsString() != "payment" {
+			t.Errorf("Expected the extra attribute, got %q", v.AsString())
+		}
+		if v, _ := attrs.Value(semconv.ExceptionStacktraceKey); v.AsString() == "" {
+			t.Error("Expected a stack trace")
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if len(sum.DataPoints) != 2 {
+		t.Fatalf("Expected a data point per error type, got %d", len(sum.DataPoints))
+	}
+	for _, dp := range sum.DataPoints {
+		if dp.Value != 1 {
+			t.Errorf("Expected 1 error of each type, got %d", dp.Value)
+		}
+	}
+}
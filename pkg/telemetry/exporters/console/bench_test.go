@@ -0,0 +1,98 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// benchmarkSpans records a trace with a root and n-1 children
+func benchmarkSpans(n int) []sdktrace.ReadOnlySpan {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("bench")
+
+	ctx, root := tracer.Start(context.Background(), "GET /odata/v4/Books")
+	for i := 1; i < n; i++ {
+		_, span := tracer.Start(ctx, fmt.Sprintf("SELECT Books %d", i))
+		span.SetAttributes(attribute.String("db.system", "hana"), attribute.Int("http.status_code", 200))
+		span.End()
+	}
+	root.End()
+	return recorder.Ended()
+}
+
+func BenchmarkSpanExporter(b *testing.B) {
+	spans := benchmarkSpans(512)
+	exporter := NewSpanExporter(WithWriter(io.Discard), WithColor(false))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLogExporter(b *testing.B) {
+	records := make([]sdklog.Record, 512)
+	for i := range records {
+		records[i] = createTestLogRecord(log.SeverityInfo, fmt.Sprintf("message %d", i))
+	}
+
+	for _, bm := range []struct {
+		name      string
+		formatter LogFormatter
+	}{
+		{"default", &defaultLogFormatter{}},
+		{"compact", &CompactLogFormatter{}},
+		{"ndjson", &JSONLogFormatter{NDJSON: true}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			exporter := NewLogExporter(WithLogWriter(io.Discard), WithLogFormatter(bm.formatter))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := exporter.Export(context.Background(), records); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSpanFormatter_10k(b *testing.B) {
+	// 100 traces of 100 spans each
+	var spans []sdktrace.ReadOnlySpan
+	for i := 0; i < 100; i++ {
+		spans = append(spans, benchmarkSpans(100)...)
+	}
+	formatter := &defaultSpanFormatter{}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := formatter.Format(io.Discard, spans); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSortSpansByStartTime_10k(b *testing.B) {
+	spans := benchmarkSpans(10000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sortSpansByStartTime(spans)
+	}
+}
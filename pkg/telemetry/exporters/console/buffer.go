@@ -0,0 +1,49 @@
+package console
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not pooled, so a
+// single huge export doesn't pin its memory
+const maxPooledBuffer = 1 << 20
+
+// bufferPool reuses the buffers exports are formatted into
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to the pool
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// bufferFor returns the buffer formatters write into. Buffers handed in by
+// the exporters are written to directly; for any other writer a pooled
+// buffer is used, which flush writes to w in one call.
+func bufferFor(w io.Writer) (*bytes.Buffer, func() error) {
+	if buf, ok := w.(*bytes.Buffer); ok {
+		return buf, func() error { return nil }
+	}
+
+	buf := getBuffer()
+	return buf, func() error {
+		defer putBuffer(buf)
+		if buf.Len() == 0 {
+			return nil
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+}
@@ -0,0 +1,68 @@
+package console
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// palette creates the colors used by the default formatters. The zero value
+// renders plain text.
+type palette struct {
+	enabled bool
+}
+
+// newPalette decides whether output to w is colored. An explicit setting
+// wins, otherwise FORCE_COLOR and NO_COLOR are honored before falling back
+// to terminal detection.
+func newPalette(explicit *bool, w io.Writer) palette {
+	if explicit != nil {
+		return palette{enabled: *explicit}
+	}
+	return palette{enabled: colorEnabled(w)}
+}
+
+// sprint returns a function coloring its arguments with attrs, or printing
+// them unchanged when colors are disabled
+func (p palette) sprint(attrs ...color.Attribute) func(a ...interface{}) string {
+	c := color.New(attrs...)
+	if p.enabled {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+	return c.SprintFunc()
+}
+
+// colorEnabled reports whether colors should be written to w, following
+// the NO_COLOR (https://no-color.org) and FORCE_COLOR conventions
+func colorEnabled(w io.Writer) bool {
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		switch strings.ToLower(v) {
+		case "0", "false":
+			return false
+		default:
+			return true
+		}
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	if f, ok := w.(*os.File); ok {
+		return isTerminal(f)
+	}
+	return false
+}
+
+// isTerminal reports whether f is a character device, i.e. an interactive
+// terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
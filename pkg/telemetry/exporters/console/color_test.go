@@ -0,0 +1,53 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("NO_COLOR", "")
+
+	if colorEnabled(&bytes.Buffer{}) {
+		t.Error("Expected colors to be disabled for non-terminal writers")
+	}
+
+	t.Setenv("FORCE_COLOR", "1")
+	if !colorEnabled(&bytes.Buffer{}) {
+		t.Error("Expected FORCE_COLOR to enable colors")
+	}
+}
+
+func TestColorEnabled_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if colorEnabled(os.Stdout) {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+}
+
+func TestLogExporter_WithLogColor(t *testing.T) {
+	record := createTestLogRecord(log.SeverityError, "Colored message")
+
+	plain := &bytes.Buffer{}
+	if err := NewLogExporter(WithLogWriter(plain), WithLogColor(false)).Export(context.Background(), []sdklog.Record{record}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Error("Expected no ANSI escapes with colors disabled")
+	}
+
+	colored := &bytes.Buffer{}
+	if err := NewLogExporter(WithLogWriter(colored), WithLogColor(true)).Export(context.Background(), []sdklog.Record{record}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(colored.String(), "\x1b[") {
+		t.Error("Expected ANSI escapes with colors enabled")
+	}
+}
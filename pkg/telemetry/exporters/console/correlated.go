@@ -0,0 +1,244 @@
+package console
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// DefaultCorrelationWindow is how long the correlator waits for further
+// spans and logs of a trace before printing it
+const DefaultCorrelationWindow = 2 * time.Second
+
+// DefaultMaxPendingTraces bounds the traces a correlator holds; the oldest
+// is printed early when another trace arrives
+const DefaultMaxPendingTraces = 1000
+
+// maxHoldWindows bounds how long a trace is held, in correlation windows,
+// when new spans or logs keep restarting its window
+const maxHoldWindows = 10
+
+// Correlator collects the spans and logs of each trace for a short window
+// and prints the logs nested under the span they were emitted in. Use its
+// SpanExporter and LogExporter in place of the separate console exporters.
+type Correlator struct {
+	window     time.Duration
+	maxHold    time.Duration
+	maxPending int
+	spans      *SpanExporter
+	formatter  *defaultSpanFormatter
+
+	mu      sync.Mutex
+	pending map[oteltrace.TraceID]*pendingTrace
+	order   *list.List // pending traces, oldest first
+}
+
+// pendingTrace holds the spans and logs of a trace until its window ends
+type pendingTrace struct {
+	id      oteltrace.TraceID
+	first   time.Time
+	spans   []trace.ReadOnlySpan
+	records []sdklog.Record
+	timer   *time.Timer
+	elem    *list.Element
+}
+
+// NewCorrelator creates a correlator printing traces window after their
+// last span or log arrived, DefaultCorrelationWindow if window <= 0. A
+// trace is held for at most ten windows, and at most DefaultMaxPendingTraces
+// traces are held. The span options configure the output as for
+// NewSpanExporter.
+func NewCorrelator(window time.Duration, opts ...SpanExporterOption) *Correlator {
+	if window <= 0 {
+		window = DefaultCorrelationWindow
+	}
+	spans := NewSpanExporter(opts...)
+	formatter, _ := spans.formatter.(*defaultSpanFormatter)
+	return &Correlator{
+		window:     window,
+		maxHold:    maxHoldWindows * window,
+		maxPending: DefaultMaxPendingTraces,
+		spans:      spans,
+		formatter:  formatter,
+		pending:    make(map[oteltrace.TraceID]*pendingTrace),
+		order:      list.New(),
+	}
+}
+
+// SpanExporter returns the exporter collecting spans
+func (c *Correlator) SpanExporter() trace.SpanExporter {
+	return &correlatedSpanExporter{correlator: c}
+}
+
+// LogExporter returns the exporter collecting log records. Records without
+// trace context are printed right away by a log exporter configured with
+// opts, whose severity and attribute filters apply to all records.
+func (c *Correlator) LogExporter(opts ...LogExporterOption) sdklog.Exporter {
+	return &correlatedLogExporter{correlator: c, logs: NewLogExporter(opts...)}
+}
+
+// add stores the spans and records of a trace and restarts its window,
+// unless the trace was held for maxHold already. If too many traces are
+// pending, the oldest is printed right away.
+func (c *Correlator) add(id oteltrace.TraceID, spans []trace.ReadOnlySpan, records []sdklog.Record) {
+	c.mu.Lock()
+	now := time.Now()
+	var evicted *pendingTrace
+	p, ok := c.pending[id]
+	if !ok {
+		if c.order.Len() >= c.maxPending {
+			evicted = c.removeLocked(c.order.Front().Value.(*pendingTrace))
+			evicted.timer.Stop()
+		}
+		p = &pendingTrace{id: id, first: now}
+		p.timer = time.AfterFunc(c.window, func() {
+			_ = c.flushTrace(p)
+		})
+		p.elem = c.order.PushBack(p)
+		c.pending[id] = p
+	} else if remaining := c.maxHold - now.Sub(p.first); remaining < c.window {
+		p.timer.Reset(max(remaining, 0))
+	} else {
+		p.timer.Reset(c.window)
+	}
+	p.spans = append(p.spans, spans...)
+	p.records = append(p.records, records...)
+	c.mu.Unlock()
+
+	if evicted != nil {
+		_ = c.print(evicted.spans, evicted.records)
+	}
+}
+
+// removeLocked stops tracking a pending trace and returns it
+func (c *Correlator) removeLocked(p *pendingTrace) *pendingTrace {
+	delete(c.pending, p.id)
+	c.order.Remove(p.elem)
+	return p
+}
+
+// flushTrace prints a trace if it is still pending
+func (c *Correlator) flushTrace(p *pendingTrace) error {
+	c.mu.Lock()
+	// The trace may have been printed already, and a new one with the same
+	// ID be pending since
+	ok := c.pending[p.id] == p
+	if ok {
+		c.removeLocked(p)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return c.print(p.spans, p.records)
+}
+
+// flush prints all pending traces
+func (c *Correlator) flush() error {
+	c.mu.Lock()
+	var spans []trace.ReadOnlySpan
+	var records []sdklog.Record
+	for _, p := range c.pending {
+		p.timer.Stop()
+		spans = append(spans, p.spans...)
+		records = append(records, p.records...)
+		c.removeLocked(p)
+	}
+	c.mu.Unlock()
+
+	if len(spans) == 0 && len(records) == 0 {
+		return nil
+	}
+	return c.print(spans, records)
+}
+
+// print writes spans with their logs in a single write
+func (c *Correlator) print(spans []trace.ReadOnlySpan, records []sdklog.Record) error {
+	if n := c.spans.normalizer; n != nil {
+		spans, records = n.normalize(spans, records)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := c.formatter.format(buf, spans, records); err != nil {
+		return fmt.Errorf("failed to format correlated spans: %w", err)
+	}
+	return c.spans.out.write(buf.Bytes())
+}
+
+// correlatedSpanExporter hands spans to the correlator
+type correlatedSpanExporter struct {
+	correlator *Correlator
+}
+
+// ExportSpans buffers spans by trace. Spans are exported directly by a
+// span exporter with a custom formatter, as logs can't be nested then.
+func (e *correlatedSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	c := e.correlator
+	if c.formatter == nil {
+		return c.spans.ExportSpans(ctx, spans)
+	}
+
+	groups := make(map[oteltrace.TraceID][]trace.ReadOnlySpan)
+	for _, span := range spans {
+		id := span.SpanContext().TraceID()
+		groups[id] = append(groups[id], span)
+	}
+	for id, traceSpans := range groups {
+		c.add(id, traceSpans, nil)
+	}
+	return nil
+}
+
+// Shutdown prints all pending traces
+func (e *correlatedSpanExporter) Shutdown(ctx context.Context) error {
+	return e.correlator.flush()
+}
+
+// correlatedLogExporter hands records with trace context to the correlator
+type correlatedLogExporter struct {
+	correlator *Correlator
+	logs       *LogExporter
+}
+
+// Export buffers records by trace and prints the others directly
+func (e *correlatedLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	c := e.correlator
+	if c.formatter == nil {
+		return e.logs.Export(ctx, records)
+	}
+
+	var direct []sdklog.Record
+	groups := make(map[oteltrace.TraceID][]sdklog.Record)
+	for _, record := range e.logs.filter(records) {
+		if !record.TraceID().IsValid() {
+			direct = append(direct, record)
+			continue
+		}
+		// Records must not be retained after Export returns
+		groups[record.TraceID()] = append(groups[record.TraceID()], record.Clone())
+	}
+	for id, traceRecords := range groups {
+		c.add(id, nil, traceRecords)
+	}
+	if len(direct) == 0 {
+		return nil
+	}
+	return e.logs.Export(ctx, direct)
+}
+
+// Shutdown prints all pending traces
+func (e *correlatedLogExporter) Shutdown(ctx context.Context) error {
+	return e.correlator.flush()
+}
+
+// ForceFlush prints all pending traces
+func (e *correlatedLogExporter) ForceFlush(ctx context.Context) error {
+	return e.correlator.flush()
+}
@@ -0,0 +1,158 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestCorrelator_NestsLogsUnderSpans(t *testing.T) {
+	var buf bytes.Buffer
+	correlator := NewCorrelator(time.Minute, WithWriter(&buf), WithColor(false))
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(correlator.SpanExporter()))
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(correlator.LogExporter(WithLogWriter(&buf)))))
+	logger := lp.Logger("test")
+
+	emit := func(ctx context.Context, body string) {
+		var rec log.Record
+		rec.SetTimestamp(time.Now())
+		rec.SetSeverity(log.SeverityInfo)
+		rec.SetBody(log.StringValue(body))
+		logger.Emit(ctx, rec)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "GET /orders")
+	childCtx, child := tp.Tracer("test").Start(ctx, "SELECT orders")
+	emit(childCtx, "loading orders")
+	child.End()
+	emit(ctx, "orders loaded")
+	parent.End()
+
+	if buf.Len() != 0 {
+		t.Fatalf("Expected output to wait for the correlation window, got:\n%s", buf.String())
+	}
+	if err := lp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush failed: %v", err)
+	}
+
+	output := buf.String()
+	order := []string{"GET /orders", "▸ INF orders loaded", "SELECT orders", "▸ INF loading orders"}
+	last := -1
+	for _, s := range order {
+		i := strings.Index(output, s)
+		if i <= last {
+			t.Fatalf("Expected %q after the previous lines, got:\n%s", s, output)
+		}
+		last = i
+	}
+}
+
+func TestCorrelator_UncorrelatedLogs(t *testing.T) {
+	var buf bytes.Buffer
+	correlator := NewCorrelator(time.Minute, WithWriter(&buf), WithColor(false))
+	exporter := correlator.LogExporter(WithLogWriter(&buf), WithLogFormatter(&CompactLogFormatter{}))
+
+	var rec sdklog.Record
+	rec.SetSeverity(log.SeverityWarn)
+	rec.SetBody(log.IntValue(42))
+	if err := exporter.Export(context.Background(), []sdklog.Record{rec}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "WRN 42") {
+		t.Errorf("Expected record without trace context to be printed directly, got %q", buf.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to read while timers print into it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// spanInTrace returns an ended span named name in the trace with the given
+// first ID byte
+func spanInTrace(traceByte byte, name string) sdktrace.ReadOnlySpan {
+	now := time.Now()
+	return tracetest.SpanStub{
+		Name: name,
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID:    oteltrace.TraceID{traceByte},
+			SpanID:     oteltrace.SpanID{traceByte},
+			TraceFlags: oteltrace.FlagsSampled,
+		}),
+		StartTime: now,
+		EndTime:   now,
+	}.Snapshot()
+}
+
+func TestCorrelator_BoundsPendingTraces(t *testing.T) {
+	var buf syncBuffer
+	correlator := NewCorrelator(time.Minute, WithWriter(&buf), WithColor(false))
+	correlator.maxPending = 2
+	exporter := correlator.SpanExporter()
+
+	for i, name := range []string{"GET /first", "GET /second", "GET /third"} {
+		if err := exporter.ExportSpans(context.Background(), []sdktrace.ReadOnlySpan{spanInTrace(byte(i+1), name)}); err != nil {
+			t.Fatalf("ExportSpans failed: %v", err)
+		}
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "GET /first") || strings.Contains(output, "GET /second") {
+		t.Errorf("Expected only the oldest trace to be printed early, got:\n%s", output)
+	}
+	correlator.mu.Lock()
+	pending := len(correlator.pending)
+	correlator.mu.Unlock()
+	if pending != 2 {
+		t.Errorf("Expected 2 pending traces, got %d", pending)
+	}
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "GET /third") {
+		t.Errorf("Expected pending traces to be printed on shutdown, got:\n%s", output)
+	}
+}
+
+func TestCorrelator_BoundsHoldTime(t *testing.T) {
+	var buf syncBuffer
+	correlator := NewCorrelator(50*time.Millisecond, WithWriter(&buf), WithColor(false))
+	correlator.maxHold = 100 * time.Millisecond
+	exporter := correlator.SpanExporter()
+
+	// Spans arriving faster than the window would hold the trace forever
+	deadline := time.Now().Add(2 * time.Second)
+	for i := 0; !strings.Contains(buf.String(), "GET /orders"); i++ {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the trace to be printed after the maximum hold time")
+		}
+		if err := exporter.ExportSpans(context.Background(), []sdktrace.ReadOnlySpan{spanInTrace(1, "GET /orders")}); err != nil {
+			t.Fatalf("ExportSpans failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
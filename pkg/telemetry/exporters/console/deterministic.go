@@ -0,0 +1,344 @@
+package console
+
+import (
+	"cmp"
+	"encoding/binary"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// DeterministicStart is the first timestamp of deterministic output unless
+// another start is given
+var DeterministicStart = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// deterministicStep separates consecutive timestamps in deterministic output
+const deterministicStep = time.Millisecond
+
+// normalizer rewrites telemetry so formatting it gives the same output on
+// every run. Timestamps keep their order but are replaced by a fixed clock
+// ticking once per distinct timestamp, trace and span IDs are numbered in
+// order of appearance and attributes and data points are sorted.
+type normalizer struct {
+	mu    sync.Mutex
+	start time.Time
+	next  time.Time
+	times map[int64]time.Time
+	trace map[oteltrace.TraceID]oteltrace.TraceID
+	spans map[oteltrace.SpanID]oteltrace.SpanID
+}
+
+// newNormalizer creates a normalizer whose clock starts at start,
+// DeterministicStart if start is zero
+func newNormalizer(start time.Time) *normalizer {
+	if start.IsZero() {
+		start = DeterministicStart
+	}
+	return &normalizer{
+		start: start.UTC(),
+		next:  start.UTC(),
+		times: make(map[int64]time.Time),
+		trace: make(map[oteltrace.TraceID]oteltrace.TraceID),
+		spans: make(map[oteltrace.SpanID]oteltrace.SpanID),
+	}
+}
+
+// tick assigns clock times to the timestamps not seen before, in order.
+// Timestamps are remembered across exports, so a span keeps its times
+// when exported with later spans of the same trace.
+func (n *normalizer) tick(timestamps []time.Time) {
+	slices.SortFunc(timestamps, func(a, b time.Time) int { return a.Compare(b) })
+	for _, ts := range timestamps {
+		if ts.IsZero() {
+			continue
+		}
+		if _, ok := n.times[ts.UnixNano()]; !ok {
+			n.times[ts.UnixNano()] = n.next
+			n.next = n.next.Add(deterministicStep)
+		}
+	}
+}
+
+// time returns the clock time of ts, zero timestamps stay zero
+func (n *normalizer) time(ts time.Time) time.Time {
+	if ts.IsZero() {
+		return ts
+	}
+	return n.times[ts.UnixNano()]
+}
+
+// traceID returns the sequential replacement of id
+func (n *normalizer) traceID(id oteltrace.TraceID) oteltrace.TraceID {
+	if !id.IsValid() {
+		return id
+	}
+	mapped, ok := n.trace[id]
+	if !ok {
+		number := uint32(len(n.trace) + 1)
+		for i := 0; i < len(mapped); i += 4 {
+			binary.BigEndian.PutUint32(mapped[i:], number)
+		}
+		n.trace[id] = mapped
+	}
+	return mapped
+}
+
+// spanID returns the sequential replacement of id
+func (n *normalizer) spanID(id oteltrace.SpanID) oteltrace.SpanID {
+	if !id.IsValid() {
+		return id
+	}
+	mapped, ok := n.spans[id]
+	if !ok {
+		number := uint32(len(n.spans) + 1)
+		for i := 0; i < len(mapped); i += 4 {
+			binary.BigEndian.PutUint32(mapped[i:], number)
+		}
+		n.spans[id] = mapped
+	}
+	return mapped
+}
+
+// spanContext replaces the IDs of sc
+func (n *normalizer) spanContext(sc oteltrace.SpanContext) oteltrace.SpanContext {
+	if !sc.IsValid() {
+		return sc
+	}
+	return sc.WithTraceID(n.traceID(sc.TraceID())).WithSpanID(n.spanID(sc.SpanID()))
+}
+
+// normalize rewrites spans and records together, so the logs of a span
+// keep their place relative to it
+func (n *normalizer) normalize(spans []trace.ReadOnlySpan, records []sdklog.Record) ([]trace.ReadOnlySpan, []sdklog.Record) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var timestamps []time.Time
+	for _, span := range spans {
+		timestamps = append(timestamps, span.StartTime(), span.EndTime())
+		for _, event := range span.Events() {
+			timestamps = append(timestamps, event.Time)
+		}
+	}
+	for _, record := range records {
+		timestamps = append(timestamps, record.Timestamp(), record.ObservedTimestamp())
+	}
+	n.tick(timestamps)
+
+	// IDs are numbered in order of start, independent of the export order
+	ordered := slices.Clone(spans)
+	slices.SortStableFunc(ordered, func(a, b trace.ReadOnlySpan) int {
+		return a.StartTime().Compare(b.StartTime())
+	})
+	for _, span := range ordered {
+		n.spanContext(span.SpanContext())
+	}
+
+	normalized := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		stub := tracetest.SpanStubFromReadOnlySpan(span)
+		stub.SpanContext = n.spanContext(stub.SpanContext)
+		stub.Parent = n.spanContext(stub.Parent)
+		stub.StartTime = n.time(stub.StartTime)
+		stub.EndTime = n.time(stub.EndTime)
+		stub.Attributes = sortedAttributes(stub.Attributes)
+		for j, event := range stub.Events {
+			event.Time = n.time(event.Time)
+			event.Attributes = sortedAttributes(event.Attributes)
+			stub.Events[j] = event
+		}
+		for j, link := range stub.Links {
+			link.SpanContext = n.spanContext(link.SpanContext)
+			link.Attributes = sortedAttributes(link.Attributes)
+			stub.Links[j] = link
+		}
+		normalized[i] = stub.Snapshot()
+	}
+
+	cloned := make([]sdklog.Record, len(records))
+	for i, record := range records {
+		record = record.Clone()
+		record.SetTimestamp(n.time(record.Timestamp()))
+		record.SetObservedTimestamp(n.time(record.ObservedTimestamp()))
+		record.SetTraceID(n.traceID(record.TraceID()))
+		record.SetSpanID(n.spanID(record.SpanID()))
+
+		attrs := make([]log.KeyValue, 0, record.AttributesLen())
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			attrs = append(attrs, kv)
+			return true
+		})
+		slices.SortStableFunc(attrs, func(a, b log.KeyValue) int { return cmp.Compare(a.Key, b.Key) })
+		record.SetAttributes(attrs...)
+		cloned[i] = record
+	}
+	return normalized, cloned
+}
+
+// sortedAttributes returns a copy of attrs sorted by key
+func sortedAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return attrs
+	}
+	sorted := slices.Clone(attrs)
+	slices.SortStableFunc(sorted, func(a, b attribute.KeyValue) int { return cmp.Compare(a.Key, b.Key) })
+	return sorted
+}
+
+// metrics returns a copy of rm with scopes, metrics and data points sorted
+// and the times of data points replaced. rm itself is reused by the reader
+// and left unchanged.
+func (n *normalizer) metrics(rm *metricdata.ResourceMetrics) *metricdata.ResourceMetrics {
+	if rm == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var timestamps []time.Time
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			timestamps = append(timestamps, dataPointTimes(m.Data)...)
+		}
+	}
+	n.tick(timestamps)
+
+	normalized := &metricdata.ResourceMetrics{Resource: rm.Resource}
+	for _, sm := range rm.ScopeMetrics {
+		scope := metricdata.ScopeMetrics{Scope: sm.Scope}
+		for _, m := range sm.Metrics {
+			m.Data = n.aggregation(m.Data)
+			scope.Metrics = append(scope.Metrics, m)
+		}
+		slices.SortStableFunc(scope.Metrics, func(a, b metricdata.Metrics) int { return cmp.Compare(a.Name, b.Name) })
+		normalized.ScopeMetrics = append(normalized.ScopeMetrics, scope)
+	}
+	slices.SortStableFunc(normalized.ScopeMetrics, func(a, b metricdata.ScopeMetrics) int {
+		return cmp.Or(cmp.Compare(a.Scope.Name, b.Scope.Name), cmp.Compare(a.Scope.Version, b.Scope.Version))
+	})
+	return normalized
+}
+
+// dataPointTimes returns the start and end times of the data points
+func dataPointTimes(data metricdata.Aggregation) []time.Time {
+	var timestamps []time.Time
+	add := func(start, ts time.Time) {
+		timestamps = append(timestamps, start, ts)
+	}
+	switch d := data.(type) {
+	case metricdata.Gauge[int64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.Gauge[float64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.Sum[int64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.Sum[float64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.Histogram[int64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.Histogram[float64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.ExponentialHistogram[int64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.ExponentialHistogram[float64]:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	case metricdata.Summary:
+		for _, dp := range d.DataPoints {
+			add(dp.StartTime, dp.Time)
+		}
+	}
+	return timestamps
+}
+
+// aggregation returns data with sorted, re-timed copies of its data points
+func (n *normalizer) aggregation(data metricdata.Aggregation) metricdata.Aggregation {
+	switch d := data.(type) {
+	case metricdata.Gauge[int64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, dataPointRef[int64])
+		return d
+	case metricdata.Gauge[float64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, dataPointRef[float64])
+		return d
+	case metricdata.Sum[int64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, dataPointRef[int64])
+		return d
+	case metricdata.Sum[float64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, dataPointRef[float64])
+		return d
+	case metricdata.Histogram[int64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, histogramPointRef[int64])
+		return d
+	case metricdata.Histogram[float64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, histogramPointRef[float64])
+		return d
+	case metricdata.ExponentialHistogram[int64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, exponentialPointRef[int64])
+		return d
+	case metricdata.ExponentialHistogram[float64]:
+		d.DataPoints = normalizePoints(n, d.DataPoints, exponentialPointRef[float64])
+		return d
+	case metricdata.Summary:
+		d.DataPoints = normalizePoints(n, d.DataPoints, func(dp *metricdata.SummaryDataPoint) (*attribute.Set, *time.Time, *time.Time) {
+			return &dp.Attributes, &dp.StartTime, &dp.Time
+		})
+		return d
+	default:
+		return data
+	}
+}
+
+// pointRef returns the attributes and times of a data point for rewriting
+type pointRef[P any] func(dp *P) (attrs *attribute.Set, start, ts *time.Time)
+
+func dataPointRef[N int64 | float64](dp *metricdata.DataPoint[N]) (*attribute.Set, *time.Time, *time.Time) {
+	return &dp.Attributes, &dp.StartTime, &dp.Time
+}
+
+func histogramPointRef[N int64 | float64](dp *metricdata.HistogramDataPoint[N]) (*attribute.Set, *time.Time, *time.Time) {
+	return &dp.Attributes, &dp.StartTime, &dp.Time
+}
+
+func exponentialPointRef[N int64 | float64](dp *metricdata.ExponentialHistogramDataPoint[N]) (*attribute.Set, *time.Time, *time.Time) {
+	return &dp.Attributes, &dp.StartTime, &dp.Time
+}
+
+// normalizePoints copies points sorted by their attributes with the times
+// replaced by clock times
+func normalizePoints[P any](n *normalizer, points []P, ref pointRef[P]) []P {
+	points = slices.Clone(points)
+	for i := range points {
+		_, start, ts := ref(&points[i])
+		*start = n.time(*start)
+		*ts = n.time(*ts)
+	}
+	slices.SortStableFunc(points, func(a, b P) int {
+		attrsA, _, _ := ref(&a)
+		attrsB, _, _ := ref(&b)
+		return cmp.Compare(attrsA.Encoded(attribute.DefaultEncoder()), attrsB.Encoded(attribute.DefaultEncoder()))
+	})
+	return points
+}
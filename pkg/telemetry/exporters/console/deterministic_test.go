@@ -0,0 +1,106 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// traceOnce exports a small trace with real, varying timings and IDs
+func traceOnce(t *testing.T, opts ...SpanExporterOption) string {
+	buf := &bytes.Buffer{}
+	exporter := NewSpanExporter(append([]SpanExporterOption{WithWriter(buf)}, opts...)...)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /books")
+	root.SetAttributes(attribute.String("http.route", "/books"), attribute.String("http.method", "GET"))
+	_, child := tracer.Start(ctx, "db.query")
+	time.Sleep(time.Millisecond)
+	child.End()
+	root.End()
+
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+	return buf.String()
+}
+
+func TestWithDeterministic(t *testing.T) {
+	first := traceOnce(t, WithDeterministic(time.Time{}), WithColor(true), WithImportantAttributes("*"))
+	second := traceOnce(t, WithDeterministic(time.Time{}), WithImportantAttributes("*"))
+	if first != second {
+		t.Fatalf("Expected identical output, got:\n%s\nand:\n%s", first, second)
+	}
+
+	expected := `[telemetry] - elapsed times (trace: 00000001):
+    0.00 →     3.00 =     3.00 ms  GET /books
+                                   │    http.method: GET
+                                   │    http.route: /books
+    1.00 →     2.00 =     1.00 ms  └─ db.query
+
+`
+	if first != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, first)
+	}
+}
+
+func TestWithLogDeterministic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := NewLogExporter(WithLogWriter(buf), WithLogColor(true),
+		WithLogDeterministic(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(log.SeverityInfo)
+	record.SetBody(log.StringValue("order placed"))
+	record.AddAttributes(log.String("order.id", "42"), log.String("customer", "acme"))
+	lp.Logger("test").Emit(context.Background(), record)
+
+	output := buf.String()
+	if !strings.Contains(output, "[2024-05-01 12:00:00.000]") || !strings.Contains(output, "customer: acme") {
+		t.Errorf("Expected the fixed clock time, got:\n%s", output)
+	}
+	if strings.Index(output, "customer") > strings.Index(output, "order.id") {
+		t.Errorf("Expected attributes sorted by key, got:\n%s", output)
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no colors, got %q", output)
+	}
+}
+
+func TestWithMetricDeterministic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := NewMetricExporter(WithMetricWriter(buf), WithMetricDeterministic(time.Time{}), WithMetricRates(true))
+
+	now := time.Now()
+	points := []metricdata.DataPoint[int64]{
+		{Attributes: attribute.NewSet(attribute.String("status", "ok")), StartTime: now, Time: now.Add(time.Second), Value: 5},
+		{Attributes: attribute.NewSet(attribute.String("status", "error")), StartTime: now, Time: now.Add(time.Second), Value: 1},
+	}
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{
+			Name: "orders",
+			Data: metricdata.Sum[int64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true, DataPoints: points},
+		}},
+	}}}
+	if err := exporter.Export(context.Background(), rm); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "orders: 1 5 ") {
+		t.Errorf("Expected data points sorted by attributes, got:\n%s", buf.String())
+	}
+	if points[0].Value != 5 || !points[0].Time.Equal(now.Add(time.Second)) {
+		t.Errorf("Expected the exported data points to be left unchanged, got %+v", points[0])
+	}
+}
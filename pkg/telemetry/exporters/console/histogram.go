@@ -0,0 +1,239 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// barWidth is the width of the longest bar in the histogram bar chart
+const barWidth = 30
+
+// bucket is a histogram bucket covering the range (lower, upper]
+type bucket struct {
+	lower, upper float64
+	count        uint64
+}
+
+// histogramSummary holds the statistics derived from a histogram data point
+type histogramSummary struct {
+	count    uint64
+	sum      float64
+	min, max float64
+	hasMin   bool
+	hasMax   bool
+	buckets  []bucket
+}
+
+// summarizeHistogram converts an explicit bucket histogram data point.
+// The unbounded outer buckets are closed with the recorded min and max.
+func summarizeHistogram[N int64 | float64](dp metricdata.HistogramDataPoint[N]) histogramSummary {
+	s := histogramSummary{count: dp.Count, sum: float64(dp.Sum)}
+	if v, ok := dp.Min.Value(); ok {
+		s.min, s.hasMin = float64(v), true
+	}
+	if v, ok := dp.Max.Value(); ok {
+		s.max, s.hasMax = float64(v), true
+	}
+
+	for i, n := range dp.BucketCounts {
+		b := bucket{lower: math.Inf(-1), upper: math.Inf(1), count: n}
+		if i > 0 {
+			b.lower = dp.Bounds[i-1]
+		}
+		if i < len(dp.Bounds) {
+			b.upper = dp.Bounds[i]
+		}
+		s.buckets = append(s.buckets, b)
+	}
+	return s
+}
+
+// summarizeExponentialHistogram converts a base-2 exponential histogram
+// data point. Bucket i covers (base^i, base^(i+1)] with base 2^(2^-scale);
+// negative buckets mirror the positive ones and the zero bucket sits between.
+func summarizeExponentialHistogram[N int64 | float64](dp metricdata.ExponentialHistogramDataPoint[N]) histogramSummary {
+	s := histogramSummary{count: dp.Count, sum: float64(dp.Sum)}
+	if v, ok := dp.Min.Value(); ok {
+		s.min, s.hasMin = float64(v), true
+	}
+	if v, ok := dp.Max.Value(); ok {
+		s.max, s.hasMax = float64(v), true
+	}
+
+	base := math.Exp2(math.Exp2(-float64(dp.Scale)))
+	bound := func(index int) float64 {
+		return math.Pow(base, float64(index))
+	}
+
+	neg := dp.NegativeBucket
+	for i := len(neg.Counts) - 1; i >= 0; i-- {
+		index := int(neg.Offset) + i
+		s.buckets = append(s.buckets, bucket{lower: -bound(index + 1), upper: -bound(index), count: neg.Counts[i]})
+	}
+	if dp.ZeroCount > 0 {
+		s.buckets = append(s.buckets, bucket{lower: -dp.ZeroThreshold, upper: dp.ZeroThreshold, count: dp.ZeroCount})
+	}
+	pos := dp.PositiveBucket
+	for i, n := range pos.Counts {
+		index := int(pos.Offset) + i
+		s.buckets = append(s.buckets, bucket{lower: bound(index), upper: bound(index + 1), count: n})
+	}
+	return s
+}
+
+// metricSummary summarizes all data points of a histogram metric of any
+// kind, e.g. the storage time of several queues
+func metricSummary(m metricdata.Metrics) (histogramSummary, bool) {
+	var summaries []histogramSummary
+	switch data := m.Data.(type) {
+	case metricdata.Histogram[int64]:
+		for _, dp := range data.DataPoints {
+			summaries = append(summaries, summarizeHistogram(dp))
+		}
+	case metricdata.Histogram[float64]:
+		for _, dp := range data.DataPoints {
+			summaries = append(summaries, summarizeHistogram(dp))
+		}
+	case metricdata.ExponentialHistogram[int64]:
+		for _, dp := range data.DataPoints {
+			summaries = append(summaries, summarizeExponentialHistogram(dp))
+		}
+	case metricdata.ExponentialHistogram[float64]:
+		for _, dp := range data.DataPoints {
+			summaries = append(summaries, summarizeExponentialHistogram(dp))
+		}
+	default:
+		return histogramSummary{}, false
+	}
+
+	var merged histogramSummary
+	for _, s := range summaries {
+		merged.merge(s)
+	}
+	return merged, true
+}
+
+// merge adds the values of o. Buckets with the same range are combined,
+// the others are kept in order of their upper bound.
+func (s *histogramSummary) merge(o histogramSummary) {
+	if o.count == 0 {
+		return
+	}
+	s.count += o.count
+	s.sum += o.sum
+	if o.hasMin && (!s.hasMin || o.min < s.min) {
+		s.min, s.hasMin = o.min, true
+	}
+	if o.hasMax && (!s.hasMax || o.max > s.max) {
+		s.max, s.hasMax = o.max, true
+	}
+
+	for _, b := range o.buckets {
+		i := sort.Search(len(s.buckets), func(i int) bool { return s.buckets[i].upper >= b.upper })
+		if i < len(s.buckets) && s.buckets[i].upper == b.upper && s.buckets[i].lower == b.lower {
+			s.buckets[i].count += b.count
+			continue
+		}
+		s.buckets = slices.Insert(s.buckets, i, b)
+	}
+}
+
+// avg returns the arithmetic mean of the recorded values
+func (s histogramSummary) avg() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// quantile estimates the q-quantile by linear interpolation within the
+// bucket holding the requested rank, clamped to the recorded min and max
+func (s histogramSummary) quantile(q float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	rank := q * float64(s.count)
+	var seen uint64
+	for _, b := range s.buckets {
+		if b.count == 0 {
+			continue
+		}
+		if float64(seen+b.count) < rank {
+			seen += b.count
+			continue
+		}
+
+		lower, upper := b.lower, b.upper
+		if s.hasMin && (math.IsInf(lower, -1) || lower < s.min) {
+			lower = s.min
+		}
+		if s.hasMax && (math.IsInf(upper, 1) || upper > s.max) {
+			upper = s.max
+		}
+		// Without min or max an unbounded bucket only yields its finite edge
+		if math.IsInf(lower, -1) {
+			return upper
+		}
+		if math.IsInf(upper, 1) {
+			return lower
+		}
+		return lower + (upper-lower)*(rank-float64(seen))/float64(b.count)
+	}
+	if s.hasMax {
+		return s.max
+	}
+	return 0
+}
+
+// formatHistogram writes the summary statistics of a histogram followed by
+// a bar chart of its non-empty buckets
+func (f *defaultMetricFormatter) formatHistogram(builder *bytes.Buffer, s histogramSummary) {
+	fmt.Fprintf(builder, "count: %d  sum: %s  avg: %s", s.count, formatFloat(s.sum), formatFloat(s.avg()))
+	if s.hasMin {
+		fmt.Fprintf(builder, "  min: %s", formatFloat(s.min))
+	}
+	if s.hasMax {
+		fmt.Fprintf(builder, "  max: %s", formatFloat(s.max))
+	}
+	if s.count > 0 {
+		fmt.Fprintf(builder, "  p50: %s  p95: %s  p99: %s",
+			formatFloat(s.quantile(0.5)), formatFloat(s.quantile(0.95)), formatFloat(s.quantile(0.99)))
+	}
+	builder.WriteString("\n")
+
+	var largest uint64
+	for _, b := range s.buckets {
+		largest = max(largest, b.count)
+	}
+	if largest == 0 {
+		return
+	}
+
+	barColor := f.palette.sprint(color.FgCyan)
+	for _, b := range s.buckets {
+		if b.count == 0 {
+			continue
+		}
+		width := int(math.Ceil(float64(b.count) * barWidth / float64(largest)))
+		fmt.Fprintf(builder, "    %12s %s %d\n",
+			"≤ "+formatFloat(b.upper), barColor(strings.Repeat("█", width)), b.count)
+	}
+}
+
+// formatFloat prints a float compactly, without trailing zeros
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return fmt.Sprintf("%.4g", v)
+}
@@ -0,0 +1,117 @@
+package console
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	dp := metricdata.HistogramDataPoint[float64]{
+		Count:        100,
+		Sum:          2500,
+		Bounds:       []float64{10, 20, 50},
+		BucketCounts: []uint64{50, 40, 10, 0},
+		Min:          metricdata.NewExtrema(2.0),
+		Max:          metricdata.NewExtrema(45.0),
+	}
+	s := summarizeHistogram(dp)
+
+	tests := []struct {
+		q        float64
+		expected float64
+	}{
+		{0.5, 10},
+		{0.9, 20},
+		{0.95, 32.5},
+		{0.99, 42.5},
+	}
+	for _, tt := range tests {
+		if got := s.quantile(tt.q); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("Expected p%.0f to be %v, got %v", tt.q*100, tt.expected, got)
+		}
+	}
+
+	if s.avg() != 25 {
+		t.Errorf("Expected avg 25, got %v", s.avg())
+	}
+}
+
+func TestFormatGenericMetric_Histogram(t *testing.T) {
+	formatter := &defaultMetricFormatter{}
+	m := metricdata.Metrics{
+		Name: "http.server.duration",
+		Data: metricdata.Histogram[int64]{
+			DataPoints: []metricdata.HistogramDataPoint[int64]{{
+				Count:        3,
+				Sum:          60,
+				Bounds:       []float64{10, 100},
+				BucketCounts: []uint64{1, 2, 0},
+				Min:          metricdata.NewExtrema[int64](5),
+				Max:          metricdata.NewExtrema[int64](30),
+			}},
+		},
+	}
+
+	var builder bytes.Buffer
+	formatter.formatGenericMetric(&builder, m)
+	output := builder.String()
+
+	for _, want := range []string{"count: 3", "avg: 20", "min: 5", "max: 30", "p99:", "≤ 100"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestExponentialHistogramQuantile(t *testing.T) {
+	// Scale 0 means base 2: buckets (1,2], (2,4], (4,8], (8,16]
+	dp := metricdata.ExponentialHistogramDataPoint[float64]{
+		Count:          10,
+		Sum:            60,
+		Scale:          0,
+		ZeroCount:      0,
+		PositiveBucket: metricdata.ExponentialBucket{Offset: 0, Counts: []uint64{2, 3, 4, 1}},
+		Min:            metricdata.NewExtrema(1.5),
+		Max:            metricdata.NewExtrema(12.0),
+	}
+	s := summarizeExponentialHistogram(dp)
+
+	if len(s.buckets) != 4 {
+		t.Fatalf("Expected 4 buckets, got %d", len(s.buckets))
+	}
+	if s.buckets[2].lower != 4 || s.buckets[2].upper != 8 {
+		t.Errorf("Expected third bucket (4, 8], got (%v, %v]", s.buckets[2].lower, s.buckets[2].upper)
+	}
+	if got := s.quantile(0.5); got != 4 {
+		t.Errorf("Expected p50 to be 4, got %v", got)
+	}
+	if got := s.quantile(0.7); got != 6 {
+		t.Errorf("Expected p70 to be 6, got %v", got)
+	}
+}
+
+func TestFormatGenericMetric_ExponentialHistogram(t *testing.T) {
+	formatter := &defaultMetricFormatter{}
+	m := metricdata.Metrics{
+		Name: "rpc.duration",
+		Data: metricdata.ExponentialHistogram[int64]{
+			DataPoints: []metricdata.ExponentialHistogramDataPoint[int64]{{
+				Count:          3,
+				Sum:            7,
+				Scale:          1,
+				ZeroCount:      1,
+				PositiveBucket: metricdata.ExponentialBucket{Offset: 2, Counts: []uint64{2}},
+			}},
+		},
+	}
+
+	var builder bytes.Buffer
+	formatter.formatGenericMetric(&builder, m)
+	if !strings.Contains(builder.String(), "count: 3") || !strings.Contains(builder.String(), "p50:") {
+		t.Errorf("Expected exponential histogram summary, got:\n%s", builder.String())
+	}
+}
@@ -1,14 +1,19 @@
 package console
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
 	"go.opentelemetry.io/otel/log"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 )
@@ -16,24 +21,51 @@ import (
 // LogExporter implements a console log exporter
 type LogExporter struct {
 	writer    io.Writer
+	out       *lockedWriter
 	formatter LogFormatter
+	color     *bool
+
+	minSeverity log.Severity
+	attributes  []string
+	timestamps  timeFormat
+	maxLength   int
+	normalizer  *normalizer
+	clock       clock.Clock
 }
 
 // LogFormatter formats log records for console output
 type LogFormatter interface {
-	Format(records []sdklog.Record) string
+	Format(w io.Writer, records []sdklog.Record) error
 }
 
 // NewLogExporter creates a new console log exporter
 func NewLogExporter(opts ...LogExporterOption) *LogExporter {
 	exporter := &LogExporter{
-		writer:    os.Stdout,
-		formatter: &defaultLogFormatter{},
+		writer: os.Stdout,
 	}
 
 	for _, opt := range opts {
 		opt(exporter)
 	}
+	if exporter.normalizer != nil {
+		// Deterministic output must not depend on the terminal or time zone
+		disabled := false
+		exporter.color = &disabled
+		exporter.timestamps.utc = true
+		if exporter.clock == nil {
+			exporter.clock = clock.NewFixed(exporter.normalizer.start)
+		}
+	}
+
+	if exporter.formatter == nil {
+		exporter.formatter = &defaultLogFormatter{
+			palette:    newPalette(exporter.color, exporter.writer),
+			timestamps: exporter.timestamps,
+			maxLength:  exporter.maxLength,
+			clock:      exporter.clock,
+		}
+	}
+	exporter.out = newLockedWriter(exporter.writer)
 
 	return exporter
 }
@@ -41,13 +73,19 @@ func NewLogExporter(opts ...LogExporterOption) *LogExporter {
 // LogExporterOption configures a LogExporter
 type LogExporterOption func(*LogExporter)
 
-// WithLogWriter sets the writer for the exporter
+// WithLogWriter sets the writer for the exporter, os.Stdout by default
 func WithLogWriter(w io.Writer) LogExporterOption {
 	return func(e *LogExporter) {
 		e.writer = w
 	}
 }
 
+// WithLogStderr writes to os.Stderr, keeping stdout free for the
+// application
+func WithLogStderr() LogExporterOption {
+	return WithLogWriter(os.Stderr)
+}
+
 // WithLogFormatter sets the formatter for the exporter
 func WithLogFormatter(f LogFormatter) LogExporterOption {
 	return func(e *LogExporter) {
@@ -55,15 +93,116 @@ func WithLogFormatter(f LogFormatter) LogExporterOption {
 	}
 }
 
+// WithLogColor enables or disables ANSI colors of the default formatter,
+// overriding the NO_COLOR, FORCE_COLOR and terminal detection
+func WithLogColor(enabled bool) LogExporterOption {
+	return func(e *LogExporter) {
+		e.color = &enabled
+	}
+}
+
+// WithMinSeverity suppresses records below the given severity on the
+// console. Records without a severity are always printed.
+func WithMinSeverity(level log.Severity) LogExporterOption {
+	return func(e *LogExporter) {
+		e.minSeverity = level
+	}
+}
+
+// WithAttributeFilter prints only the record attributes matching one of
+// the glob patterns, e.g. "http.*"
+func WithAttributeFilter(keys ...string) LogExporterOption {
+	return func(e *LogExporter) {
+		e.attributes = append([]string{}, keys...)
+	}
+}
+
+// WithLogTimeLayout sets the timestamp layout of the default formatter,
+// "2006-01-02 15:04:05.000" by default
+func WithLogTimeLayout(layout string) LogExporterOption {
+	return func(e *LogExporter) {
+		e.timestamps.layout = layout
+	}
+}
+
+// WithLogUTC prints timestamps in UTC rather than local time
+func WithLogUTC(enabled bool) LogExporterOption {
+	return func(e *LogExporter) {
+		e.timestamps.utc = enabled
+	}
+}
+
+// WithLogMaxAttributeLength cuts attribute values printed by the default
+// formatter to n characters
+func WithLogMaxAttributeLength(n int) LogExporterOption {
+	return func(e *LogExporter) {
+		e.maxLength = n
+	}
+}
+
+// WithLogClock sets the clock the default formatter takes the time of
+// records without a timestamp from, the system clock by default
+func WithLogClock(c clock.Clock) LogExporterOption {
+	return func(e *LogExporter) {
+		e.clock = c
+	}
+}
+
+// WithLogDeterministic makes the output the same on every run, as
+// WithDeterministic does for spans: timestamps are replaced by a clock
+// starting at start, trace context is numbered, attributes are sorted by
+// key, colors are off and times are printed in UTC
+func WithLogDeterministic(start time.Time) LogExporterOption {
+	return func(e *LogExporter) {
+		e.normalizer = newNormalizer(start)
+	}
+}
+
 // Export exports log records to the console
 func (e *LogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	records = e.filter(records)
 	if len(records) == 0 {
 		return nil
 	}
+	if e.normalizer != nil {
+		_, records = e.normalizer.normalize(nil, records)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := e.formatter.Format(buf, records); err != nil {
+		return fmt.Errorf("failed to format log records: %w", err)
+	}
+	return e.out.write(buf.Bytes())
+}
+
+// filter drops records below the minimum severity and strips the
+// attributes not matching the attribute filter
+func (e *LogExporter) filter(records []sdklog.Record) []sdklog.Record {
+	if e.minSeverity == log.SeverityUndefined && e.attributes == nil {
+		return records
+	}
 
-	output := e.formatter.Format(records)
-	_, err := fmt.Fprint(e.writer, output)
-	return err
+	filtered := make([]sdklog.Record, 0, len(records))
+	for _, record := range records {
+		if severity := record.Severity(); severity != log.SeverityUndefined && severity < e.minSeverity {
+			continue
+		}
+		if e.attributes != nil {
+			// Records are shared with other exporters, so modify a copy
+			record = record.Clone()
+			var kept []log.KeyValue
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				if matchAttribute(e.attributes, kv.Key) {
+					kept = append(kept, kv)
+				}
+				return true
+			})
+			record.SetAttributes(kept...)
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered
 }
 
 // Shutdown shuts down the exporter
@@ -77,14 +216,19 @@ func (e *LogExporter) ForceFlush(ctx context.Context) error {
 }
 
 // defaultLogFormatter provides the default log formatting
-type defaultLogFormatter struct{}
+type defaultLogFormatter struct {
+	palette    palette
+	timestamps timeFormat
+	maxLength  int
+	clock      clock.Clock
+}
 
 // Format formats log records in a structured, readable format
-func (f *defaultLogFormatter) Format(records []sdklog.Record) string {
-	var builder strings.Builder
+func (f *defaultLogFormatter) Format(w io.Writer, records []sdklog.Record) error {
+	builder, flush := bufferFor(w)
 
 	// Color for header
-	headerColor := color.New(color.FgCyan, color.Bold).SprintFunc()
+	headerColor := f.palette.sprint(color.FgCyan, color.Bold)
 
 	builder.WriteString("\n")
 	builder.WriteString(headerColor("╔══════════════════════════════════════════════════════════════════════════════╗\n"))
@@ -95,64 +239,96 @@ func (f *defaultLogFormatter) Format(records []sdklog.Record) string {
 		if i > 0 {
 			builder.WriteString("\n")
 		}
-		f.formatLogRecord(&builder, record)
+		f.formatLogRecord(builder, record)
 	}
 
 	builder.WriteString("\n")
-	return builder.String()
+	return flush()
 }
 
 // formatLogRecord formats a single log record
-func (f *defaultLogFormatter) formatLogRecord(builder *strings.Builder, record sdklog.Record) {
+func (f *defaultLogFormatter) formatLogRecord(builder *bytes.Buffer, record sdklog.Record) {
 	// Define colors
-	timestampColor := color.New(color.FgHiBlack).SprintFunc()
-	attributeKeyColor := color.New(color.FgCyan).SprintFunc()
-	traceColor := color.New(color.FgMagenta).SprintFunc()
-	treeColor := color.New(color.FgHiBlack).SprintFunc()
+	timestampColor := f.palette.sprint(color.FgHiBlack)
+	attributeKeyColor := f.palette.sprint(color.FgCyan)
+	traceColor := f.palette.sprint(color.FgMagenta)
+	treeColor := f.palette.sprint(color.FgHiBlack)
 
 	// Format timestamp
-	timestamp := record.Timestamp()
-	timeStr := timestamp.Format("2006-01-02 15:04:05.000")
+	timestamp := recordTime(record, f.clock)
+	timeStr := f.timestamps.format(timestamp, "2006-01-02 15:04:05.000")
 
 	// Get severity level
 	severity := record.Severity()
 	severityStr := f.formatSeverity(severity)
 
-	// Get log body
-	body := record.Body().AsString()
-
-	// Format: [timestamp] LEVEL: message
-	builder.WriteString(fmt.Sprintf("[%s] %s: %s\n", timestampColor(timeStr), severityStr, body))
+	// Format: [timestamp] LEVEL: message. Structured bodies follow below
+	// the header, one entry per line.
+	body := record.Body()
+	switch body.Kind() {
+	case log.KindMap, log.KindSlice:
+		fmt.Fprintf(builder, "[%s] %s:\n", timestampColor(timeStr), severityStr)
+		f.formatStructuredValue(builder, body, treeColor("  │  "))
+	default:
+		fmt.Fprintf(builder, "[%s] %s: %s\n", timestampColor(timeStr), severityStr, inlineValue(body))
+	}
 
 	// Add trace context if present
 	if record.TraceID().IsValid() {
-		builder.WriteString(fmt.Sprintf("%s Trace ID: %s\n", treeColor("  ├─"), traceColor(record.TraceID().String())))
+		fmt.Fprintf(builder, "%s Trace ID: %s\n", treeColor("  ├─"), traceColor(record.TraceID().String()))
 	}
 	if record.SpanID().IsValid() {
-		builder.WriteString(fmt.Sprintf("%s Span ID:  %s\n", treeColor("  ├─"), traceColor(record.SpanID().String())))
+		fmt.Fprintf(builder, "%s Span ID:  %s\n", treeColor("  ├─"), traceColor(record.SpanID().String()))
 	}
 
 	// Add attributes
 	hasAttributes := false
 	record.WalkAttributes(func(kv log.KeyValue) bool {
 		if !hasAttributes {
-			builder.WriteString(fmt.Sprintf("%s Attributes:\n", treeColor("  ├─")))
+			fmt.Fprintf(builder, "%s Attributes:\n", treeColor("  ├─"))
 			hasAttributes = true
 		}
 		// Use String() method which handles all types
-		builder.WriteString(fmt.Sprintf("%s %s: %v\n", treeColor("  │  •"), attributeKeyColor(kv.Key), kv.Value.String()))
+		fmt.Fprintf(builder, "%s %s: %v\n", treeColor("  │  •"), attributeKeyColor(kv.Key), truncate(kv.Value.String(), f.maxLength))
 		return true
 	})
 }
 
+// formatStructuredValue writes the entries of a map or slice one per line,
+// nested maps and slices indented below their key
+func (f *defaultLogFormatter) formatStructuredValue(builder *bytes.Buffer, v log.Value, indent string) {
+	keyColor := f.palette.sprint(color.FgCyan)
+
+	write := func(label string, item log.Value) {
+		switch item.Kind() {
+		case log.KindMap, log.KindSlice:
+			fmt.Fprintf(builder, "%s%s\n", indent, label)
+			f.formatStructuredValue(builder, item, indent+"  ")
+		default:
+			fmt.Fprintf(builder, "%s%s %s\n", indent, label, inlineValue(item))
+		}
+	}
+
+	switch v.Kind() {
+	case log.KindMap:
+		for _, kv := range v.AsMap() {
+			write(keyColor(kv.Key)+":", kv.Value)
+		}
+	case log.KindSlice:
+		for _, item := range v.AsSlice() {
+			write("-", item)
+		}
+	}
+}
+
 // formatSeverity formats severity level with emoji indicators and colors
 func (f *defaultLogFormatter) formatSeverity(severity log.Severity) string {
 	// Define colors
-	red := color.New(color.FgRed, color.Bold).SprintFunc()
-	yellow := color.New(color.FgYellow, color.Bold).SprintFunc()
-	cyan := color.New(color.FgCyan, color.Bold).SprintFunc()
-	gray := color.New(color.FgHiBlack).SprintFunc()
-	magenta := color.New(color.FgMagenta).SprintFunc()
+	red := f.palette.sprint(color.FgRed, color.Bold)
+	yellow := f.palette.sprint(color.FgYellow, color.Bold)
+	cyan := f.palette.sprint(color.FgCyan, color.Bold)
+	gray := f.palette.sprint(color.FgHiBlack)
+	magenta := f.palette.sprint(color.FgMagenta)
 
 	switch {
 	case severity >= log.SeverityFatal:
@@ -170,32 +346,39 @@ func (f *defaultLogFormatter) formatSeverity(severity log.Severity) string {
 	}
 }
 
-// CompactLogFormatter provides a compact, single-line format
-type CompactLogFormatter struct{}
+// CompactLogFormatter provides a compact, single-line format. TimeLayout
+// defaults to "15:04:05.000", UTC prints timestamps in UTC and Clock
+// provides the time of records without a timestamp.
+type CompactLogFormatter struct {
+	TimeLayout string
+	UTC        bool
+	Clock      clock.Clock
+}
 
 // Format formats log records in a compact format
-func (f *CompactLogFormatter) Format(records []sdklog.Record) string {
-	var builder strings.Builder
+func (f *CompactLogFormatter) Format(w io.Writer, records []sdklog.Record) error {
+	builder, flush := bufferFor(w)
 
 	for _, record := range records {
-		timestamp := record.Timestamp().Format("15:04:05.000")
-		severity := f.formatSeverity(record.Severity())
-		body := record.Body().AsString()
+		timestamp := timeFormat{layout: f.TimeLayout, utc: f.UTC}.format(recordTime(record, f.Clock), "15:04:05.000")
+		severity := severityCode(record.Severity())
+		body := inlineValue(record.Body())
 
-		builder.WriteString(fmt.Sprintf("%s %s %s", timestamp, severity, body))
+		fmt.Fprintf(builder, "%s %s %s", timestamp, severity, body)
 
 		// Add trace context inline if present
 		if record.TraceID().IsValid() {
-			builder.WriteString(fmt.Sprintf(" [trace=%s]", record.TraceID().String()[:8]))
+			fmt.Fprintf(builder, " [trace=%s]", record.TraceID().String()[:8])
 		}
 
 		builder.WriteString("\n")
 	}
 
-	return builder.String()
+	return flush()
 }
 
-func (f *CompactLogFormatter) formatSeverity(severity log.Severity) string {
+// severityCode returns the three letter code of a severity
+func severityCode(severity log.Severity) string {
 	switch {
 	case severity >= log.SeverityFatal:
 		return "FTL"
@@ -212,51 +395,137 @@ func (f *CompactLogFormatter) formatSeverity(severity log.Severity) string {
 	}
 }
 
-// JSONLogFormatter provides JSON-formatted output
-type JSONLogFormatter struct{}
+// inlineValue renders a log value on a single line. Maps and slices are
+// rendered recursively as {key=value ...} and [a, b], for which AsString
+// would return nothing.
+func inlineValue(v log.Value) string {
+	switch v.Kind() {
+	case log.KindString:
+		return v.AsString()
+	case log.KindMap:
+		kvs := v.AsMap()
+		parts := make([]string, len(kvs))
+		for i, kv := range kvs {
+			parts[i] = kv.Key + "=" + inlineValue(kv.Value)
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case log.KindSlice:
+		items := v.AsSlice()
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = inlineValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case log.KindEmpty:
+		return ""
+	default:
+		return v.String()
+	}
+}
 
-// Format formats log records as JSON
-func (f *JSONLogFormatter) Format(records []sdklog.Record) string {
-	var builder strings.Builder
+// JSONLogFormatter provides JSON-formatted output. By default a batch is
+// written as an indented JSON array; with NDJSON set every record is written
+// as a single-line object, as expected by log collectors. Clock provides
+// the time of records without a timestamp.
+type JSONLogFormatter struct {
+	NDJSON bool
+	Clock  clock.Clock
+}
 
-	builder.WriteString("[\n")
-	for i, record := range records {
-		if i > 0 {
-			builder.WriteString(",\n")
-		}
-		builder.WriteString("  {\n")
-		builder.WriteString(fmt.Sprintf("    \"timestamp\": \"%s\",\n", record.Timestamp().Format(time.RFC3339Nano)))
-		builder.WriteString(fmt.Sprintf("    \"severity\": \"%s\",\n", record.Severity().String()))
-		builder.WriteString(fmt.Sprintf("    \"body\": %q", record.Body().AsString()))
+// jsonLogRecord is the JSON representation of a log record
+type jsonLogRecord struct {
+	Timestamp      string         `json:"timestamp"`
+	Severity       string         `json:"severity"`
+	SeverityNumber int            `json:"severityNumber"`
+	Body           any            `json:"body"`
+	TraceID        string         `json:"traceId,omitempty"`
+	SpanID         string         `json:"spanId,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+}
 
-		if record.TraceID().IsValid() {
-			builder.WriteString(",\n")
-			builder.WriteString(fmt.Sprintf("    \"traceId\": \"%s\"", record.TraceID().String()))
-		}
-		if record.SpanID().IsValid() {
-			builder.WriteString(",\n")
-			builder.WriteString(fmt.Sprintf("    \"spanId\": \"%s\"", record.SpanID().String()))
+// Format formats log records as JSON
+func (f *JSONLogFormatter) Format(w io.Writer, records []sdklog.Record) error {
+	builder, flush := bufferFor(w)
+	encoder := json.NewEncoder(builder)
+	encoder.SetEscapeHTML(false)
+
+	if f.NDJSON {
+		for _, record := range records {
+			if err := encoder.Encode(jsonEntry(record, f.Clock)); err != nil {
+				return fmt.Errorf("failed to encode log record: %w", err)
+			}
 		}
+		return flush()
+	}
+
+	entries := make([]jsonLogRecord, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, jsonEntry(record, f.Clock))
+	}
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return fmt.Errorf("failed to encode log records: %w", err)
+	}
+	return flush()
+}
 
-		// Add attributes as JSON object
-		hasAttributes := false
+// jsonEntry converts a log record into its JSON representation
+func jsonEntry(record sdklog.Record, c clock.Clock) jsonLogRecord {
+	entry := jsonLogRecord{
+		Timestamp:      recordTime(record, c).Format(time.RFC3339Nano),
+		Severity:       record.Severity().String(),
+		SeverityNumber: int(record.Severity()),
+		Body:           jsonValue(record.Body()),
+	}
+	if record.TraceID().IsValid() {
+		entry.TraceID = record.TraceID().String()
+	}
+	if record.SpanID().IsValid() {
+		entry.SpanID = record.SpanID().String()
+	}
+	if record.AttributesLen() > 0 {
+		entry.Attributes = make(map[string]any, record.AttributesLen())
 		record.WalkAttributes(func(kv log.KeyValue) bool {
-			if !hasAttributes {
-				builder.WriteString(",\n    \"attributes\": {\n")
-				hasAttributes = true
-			} else {
-				builder.WriteString(",\n")
-			}
-			builder.WriteString(fmt.Sprintf("      %q: %q", kv.Key, kv.Value.String()))
+			entry.Attributes[kv.Key] = jsonValue(kv.Value)
 			return true
 		})
-		if hasAttributes {
-			builder.WriteString("\n    }")
-		}
-
-		builder.WriteString("\n  }")
 	}
-	builder.WriteString("\n]\n")
+	return entry
+}
 
-	return builder.String()
+// jsonValue converts a log value into its typed JSON representation. Byte
+// slices are base64 encoded, non-finite floats become strings.
+func jsonValue(v log.Value) any {
+	switch v.Kind() {
+	case log.KindBool:
+		return v.AsBool()
+	case log.KindInt64:
+		return v.AsInt64()
+	case log.KindFloat64:
+		f := v.AsFloat64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return f
+	case log.KindString:
+		return v.AsString()
+	case log.KindBytes:
+		return v.AsBytes()
+	case log.KindSlice:
+		items := v.AsSlice()
+		result := make([]any, len(items))
+		for i, item := range items {
+			result[i] = jsonValue(item)
+		}
+		return result
+	case log.KindMap:
+		kvs := v.AsMap()
+		result := make(map[string]any, len(kvs))
+		for _, kv := range kvs {
+			result[kv.Key] = jsonValue(kv.Value)
+		}
+		return result
+	default:
+		return nil
+	}
 }
@@ -3,10 +3,12 @@ package console
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
 	"go.opentelemetry.io/otel/log"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/trace"
@@ -42,7 +44,7 @@ func TestCompactLogFormatter(t *testing.T) {
 		createTestLogRecord(log.SeverityInfo, "Compact test"),
 	}
 
-	output := formatter.Format(records)
+	output := formatLogs(t, formatter, records)
 	if !strings.Contains(output, "INF") {
 		t.Error("Compact format doesn't contain severity")
 	}
@@ -57,7 +59,7 @@ func TestJSONLogFormatter(t *testing.T) {
 		createTestLogRecord(log.SeverityWarn, "JSON test"),
 	}
 
-	output := formatter.Format(records)
+	output := formatLogs(t, formatter, records)
 	if !strings.Contains(output, `"severity"`) {
 		t.Error("JSON format doesn't contain severity field")
 	}
@@ -66,6 +68,58 @@ func TestJSONLogFormatter(t *testing.T) {
 	}
 }
 
+func TestJSONLogFormatter_TypedValues(t *testing.T) {
+	record := createTestLogRecord(log.SeverityInfo, `say "hello"`)
+	record.AddAttributes(
+		log.Int("http.status_code", 200),
+		log.Bool("cached", true),
+		log.Map("user", log.Int("id", 42)),
+	)
+
+	var entries []map[string]any
+	output := formatLogs(t, &JSONLogFormatter{}, []sdklog.Record{record})
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, output)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry["body"] != `say "hello"` {
+		t.Errorf("Expected body to be preserved, got %v", entry["body"])
+	}
+	attrs := entry["attributes"].(map[string]any)
+	if attrs["http.status_code"] != float64(200) {
+		t.Errorf("Expected numeric status code, got %#v", attrs["http.status_code"])
+	}
+	if attrs["cached"] != true {
+		t.Errorf("Expected boolean attribute, got %#v", attrs["cached"])
+	}
+	if user, ok := attrs["user"].(map[string]any); !ok || user["id"] != float64(42) {
+		t.Errorf("Expected nested map attribute, got %#v", attrs["user"])
+	}
+}
+
+func TestJSONLogFormatter_NDJSON(t *testing.T) {
+	records := []sdklog.Record{
+		createTestLogRecord(log.SeverityInfo, "first"),
+		createTestLogRecord(log.SeverityWarn, "second"),
+	}
+
+	output := formatLogs(t, &JSONLogFormatter{NDJSON: true}, records)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d:\n%s", len(lines), output)
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Errorf("Expected each line to be a JSON object, got %v: %s", err, line)
+		}
+	}
+}
+
 func TestLogExporter_WithTraceContext(t *testing.T) {
 	buf := &bytes.Buffer{}
 	exporter := NewLogExporter(WithLogWriter(buf))
@@ -155,3 +209,109 @@ func createTestLogRecord(severity log.Severity, message string) sdklog.Record {
 	)
 	return record
 }
+
+func TestLogFormatters_StructuredBody(t *testing.T) {
+	record := createTestLogRecord(log.SeverityInfo, "")
+	record.SetBody(log.MapValue(
+		log.Int("order", 7),
+		log.Slice("items", log.Int64Value(1), log.Int64Value(2)),
+		log.Map("customer", log.Bool("vip", true)),
+	))
+
+	compact := formatLogs(t, &CompactLogFormatter{}, []sdklog.Record{record})
+	if !strings.Contains(compact, "{order=7 items=[1, 2] customer={vip=true}}") {
+		t.Errorf("Expected inline structured body, got %q", compact)
+	}
+
+	output := formatLogs(t, &defaultLogFormatter{}, []sdklog.Record{record})
+	for _, want := range []string{"order: 7", "items:\n", "  - 1", "customer:\n", "  vip: true"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestLogExporter_Filters(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := NewLogExporter(
+		WithLogWriter(buf),
+		WithLogFormatter(&JSONLogFormatter{NDJSON: true}),
+		WithMinSeverity(log.SeverityWarn),
+		WithAttributeFilter("http.*"),
+	)
+
+	debug := createTestLogRecord(log.SeverityDebug, "noisy")
+	warn := createTestLogRecord(log.SeverityWarn, "important")
+	warn.AddAttributes(log.Int("http.status_code", 503), log.Int("retry", 3))
+	records := []sdklog.Record{debug, warn}
+
+	if err := exporter.Export(context.Background(), records); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "noisy") {
+		t.Error("Expected debug record to be suppressed")
+	}
+	if !strings.Contains(output, "important") || !strings.Contains(output, "http.status_code") {
+		t.Errorf("Expected warn record with http attributes, got %s", output)
+	}
+	if strings.Contains(output, "retry") || strings.Contains(output, "test.key") {
+		t.Errorf("Expected other attributes to be filtered, got %s", output)
+	}
+	if records[1].AttributesLen() != 3 {
+		t.Error("Expected the exported record to be left unchanged")
+	}
+}
+
+func TestLogExporter_Timestamps(t *testing.T) {
+	record := createTestLogRecord(log.SeverityInfo, "timed")
+	record.SetTimestamp(time.Date(2024, 3, 1, 12, 30, 45, 0, time.FixedZone("CET", 3600)))
+
+	buf := &bytes.Buffer{}
+	exporter := NewLogExporter(WithLogWriter(buf), WithLogTimeLayout(time.RFC3339), WithLogUTC(true))
+	if err := exporter.Export(context.Background(), []sdklog.Record{record}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "2024-03-01T11:30:45Z") {
+		t.Errorf("Expected UTC timestamp in custom layout, got %s", buf.String())
+	}
+
+	compact := formatLogs(t, &CompactLogFormatter{TimeLayout: time.Kitchen, UTC: true}, []sdklog.Record{record})
+	if !strings.HasPrefix(compact, "11:30AM") {
+		t.Errorf("Expected compact timestamp in custom layout, got %s", compact)
+	}
+}
+
+func TestLogExporter_Clock(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	var record sdklog.Record
+	record.SetSeverity(log.SeverityInfo)
+	record.SetBody(log.StringValue("untimed"))
+
+	buf := &bytes.Buffer{}
+	exporter := NewLogExporter(WithLogWriter(buf), WithLogUTC(true), WithLogClock(clock.NewFixed(now)))
+	if err := exporter.Export(context.Background(), []sdklog.Record{record}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "[2024-03-01 12:30:45.000]") {
+		t.Errorf("Expected the clock time for a record without timestamp, got %s", buf.String())
+	}
+
+	// The observed timestamp takes precedence over the clock
+	record.SetObservedTimestamp(now.Add(time.Minute))
+	compact := formatLogs(t, &CompactLogFormatter{UTC: true, Clock: clock.NewFixed(now)}, []sdklog.Record{record})
+	if !strings.HasPrefix(compact, "12:31:45.000") {
+		t.Errorf("Expected the observed timestamp, got %s", compact)
+	}
+}
+
+// formatLogs runs formatter and returns its output
+func formatLogs(t *testing.T, formatter LogFormatter, records []sdklog.Record) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, records); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	return buf.String()
+}
@@ -1,36 +1,66 @@
 package console
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 // MetricExporter implements a console metric exporter
 type MetricExporter struct {
-	writer    Writer
-	formatter MetricFormatter
+	writer      io.Writer
+	out         *lockedWriter
+	formatter   MetricFormatter
+	color       *bool
+	resource    bool
+	rates       bool
+	temporality metric.TemporalitySelector
+	aggregation metric.AggregationSelector
+	normalizer  *normalizer
 }
 
 // MetricFormatter formats metrics for console output
 type MetricFormatter interface {
-	Format(metrics *metricdata.ResourceMetrics) string
+	Format(w io.Writer, metrics *metricdata.ResourceMetrics) error
 }
 
 // NewMetricExporter creates a new console metric exporter
 func NewMetricExporter(opts ...MetricExporterOption) *MetricExporter {
 	exporter := &MetricExporter{
-		writer:    &defaultWriter{},
-		formatter: &defaultMetricFormatter{},
+		writer:      os.Stdout,
+		temporality: metric.DefaultTemporalitySelector,
+		aggregation: metric.DefaultAggregationSelector,
 	}
 
 	for _, opt := range opts {
 		opt(exporter)
 	}
+	if exporter.normalizer != nil {
+		// Deterministic output must not depend on the terminal
+		disabled := false
+		exporter.color = &disabled
+	}
+
+	if exporter.formatter == nil {
+		formatter := &defaultMetricFormatter{
+			palette:  newPalette(exporter.color, exporter.writer),
+			resource: exporter.resource,
+		}
+		if exporter.rates {
+			formatter.rates = newRateTracker()
+		}
+		exporter.formatter = formatter
+	}
+	exporter.out = newLockedWriter(exporter.writer)
 
 	return exporter
 }
@@ -38,13 +68,19 @@ func NewMetricExporter(opts ...MetricExporterOption) *MetricExporter {
 // MetricExporterOption configures a MetricExporter
 type MetricExporterOption func(*MetricExporter)
 
-// WithMetricWriter sets the writer for the exporter
-func WithMetricWriter(w Writer) MetricExporterOption {
+// WithMetricWriter sets the writer for the exporter, os.Stdout by default
+func WithMetricWriter(w io.Writer) MetricExporterOption {
 	return func(e *MetricExporter) {
 		e.writer = w
 	}
 }
 
+// WithMetricStderr writes to os.Stderr, keeping stdout free for the
+// application
+func WithMetricStderr() MetricExporterOption {
+	return WithMetricWriter(os.Stderr)
+}
+
 // WithMetricFormatter sets the formatter for the exporter
 func WithMetricFormatter(f MetricFormatter) MetricExporterOption {
 	return func(e *MetricExporter) {
@@ -52,14 +88,70 @@ func WithMetricFormatter(f MetricFormatter) MetricExporterOption {
 	}
 }
 
+// WithTemporalitySelector sets the temporality per instrument kind,
+// cumulative by default
+func WithTemporalitySelector(s metric.TemporalitySelector) MetricExporterOption {
+	return func(e *MetricExporter) {
+		e.temporality = s
+	}
+}
+
+// WithAggregationSelector sets the aggregation per instrument kind
+func WithAggregationSelector(s metric.AggregationSelector) MetricExporterOption {
+	return func(e *MetricExporter) {
+		e.aggregation = s
+	}
+}
+
+// WithMetricColor enables or disables ANSI colors of the default formatter,
+// overriding the NO_COLOR, FORCE_COLOR and terminal detection
+func WithMetricColor(enabled bool) MetricExporterOption {
+	return func(e *MetricExporter) {
+		e.color = &enabled
+	}
+}
+
+// WithMetricResourceInfo prints the resource attributes of each export and
+// groups custom metrics by instrumentation scope in the default formatter
+func WithMetricResourceInfo(enabled bool) MetricExporterOption {
+	return func(e *MetricExporter) {
+		e.resource = enabled
+	}
+}
+
+// WithMetricRates makes the default formatter remember the previous export
+// and print the change and rate per second of sums, e.g. requests/s,
+// next to their cumulative totals
+func WithMetricRates(enabled bool) MetricExporterOption {
+	return func(e *MetricExporter) {
+		e.rates = enabled
+	}
+}
+
+// WithMetricDeterministic makes the output the same on every run, as
+// WithDeterministic does for spans: scopes, metrics and data points are
+// sorted, data point times are replaced by a clock starting at start and
+// colors are off
+func WithMetricDeterministic(start time.Time) MetricExporterOption {
+	return func(e *MetricExporter) {
+		e.normalizer = newNormalizer(start)
+	}
+}
+
 // Export exports metrics to the console
 func (e *MetricExporter) Export(ctx context.Context, metrics *metricdata.ResourceMetrics) error {
-	output := e.formatter.Format(metrics)
-	if output != "" {
-		_, err := e.writer.Write([]byte(output))
-		return err
+	if e.normalizer != nil {
+		metrics = e.normalizer.metrics(metrics)
 	}
-	return nil
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := e.formatter.Format(buf, metrics); err != nil {
+		return fmt.Errorf("failed to format metrics: %w", err)
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	return e.out.write(buf.Bytes())
 }
 
 // ForceFlush forces a flush of the exporter
@@ -74,83 +166,106 @@ func (e *MetricExporter) Shutdown(ctx context.Context) error {
 
 // Temporality returns the temporality preference for the exporter
 func (e *MetricExporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
-	return metricdata.CumulativeTemporality
+	return e.temporality(kind)
 }
 
 // Aggregation returns the aggregation preference for the exporter
 func (e *MetricExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
-	return metric.DefaultAggregationSelector(kind)
+	return e.aggregation(kind)
 }
 
 // defaultMetricFormatter provides the default metric formatting
-type defaultMetricFormatter struct{}
+type defaultMetricFormatter struct {
+	palette  palette
+	resource bool
+	rates    *rateTracker
+}
+
+// scopeMetrics are the custom metrics recorded by one instrumentation scope
+type scopeMetrics struct {
+	scope   instrumentation.Scope
+	metrics []metricdata.Metrics
+}
 
 // Format formats metrics in a human-readable format similar to the JS version
-func (f *defaultMetricFormatter) Format(rm *metricdata.ResourceMetrics) string {
+func (f *defaultMetricFormatter) Format(w io.Writer, rm *metricdata.ResourceMetrics) error {
 	if rm == nil || len(rm.ScopeMetrics) == 0 {
-		return ""
+		return nil
 	}
 
-	var builder strings.Builder
+	builder, flush := bufferFor(w)
 
 	// Group metrics by type for better presentation
 	hostMetrics := make([]metricdata.Metrics, 0)
 	dbPoolMetrics := make([]metricdata.Metrics, 0)
 	queueMetrics := make([]metricdata.Metrics, 0)
-	customMetrics := make([]metricdata.Metrics, 0)
+	customMetrics := make([]scopeMetrics, 0)
 
 	for _, sm := range rm.ScopeMetrics {
+		custom := scopeMetrics{scope: sm.Scope}
 		for _, m := range sm.Metrics {
 			switch {
-			case strings.HasPrefix(m.Name, "process.") || strings.HasPrefix(m.Name, "runtime."):
+			case strings.HasPrefix(m.Name, "process.") || strings.HasPrefix(m.Name, "runtime.") || strings.HasPrefix(m.Name, "system."):
 				hostMetrics = append(hostMetrics, m)
 			case strings.HasPrefix(m.Name, "db.pool"):
 				dbPoolMetrics = append(dbPoolMetrics, m)
 			case strings.HasPrefix(m.Name, "queue"):
 				queueMetrics = append(queueMetrics, m)
 			default:
-				customMetrics = append(customMetrics, m)
+				custom.metrics = append(custom.metrics, m)
 			}
 		}
+		if len(custom.metrics) > 0 {
+			customMetrics = append(customMetrics, custom)
+		}
 	}
 
 	// Define colors
-	labelColor := color.New(color.FgGreen, color.Bold).SprintFunc()
-	sectionColor := color.New(color.FgCyan, color.Bold).SprintFunc()
+	labelColor := f.palette.sprint(color.FgGreen, color.Bold)
+	sectionColor := f.palette.sprint(color.FgCyan, color.Bold)
+
+	if f.resource {
+		formatResource(builder, f.palette, rm.Resource)
+	}
 
 	// Format host metrics
 	if len(hostMetrics) > 0 {
-		builder.WriteString(fmt.Sprintf("%s - %s:\n", labelColor("[telemetry]"), sectionColor("host metrics")))
-		f.formatHostMetrics(&builder, hostMetrics)
+		fmt.Fprintf(builder, "%s - %s:\n", labelColor("[telemetry]"), sectionColor("host metrics"))
+		f.formatHostMetrics(builder, hostMetrics)
 		builder.WriteString("\n")
 	}
 
 	// Format DB pool metrics
 	if len(dbPoolMetrics) > 0 {
-		builder.WriteString(fmt.Sprintf("%s - %s:\n", labelColor("[telemetry]"), sectionColor("db.pool")))
-		f.formatDBPoolMetrics(&builder, dbPoolMetrics)
+		fmt.Fprintf(builder, "%s - %s:\n", labelColor("[telemetry]"), sectionColor("db.pool"))
+		f.formatDBPoolMetrics(builder, dbPoolMetrics)
 		builder.WriteString("\n")
 	}
 
 	// Format queue metrics
 	if len(queueMetrics) > 0 {
-		builder.WriteString(fmt.Sprintf("%s - %s:\n", labelColor("[telemetry]"), sectionColor("queue")))
-		f.formatQueueMetrics(&builder, queueMetrics)
+		fmt.Fprintf(builder, "%s - %s:\n", labelColor("[telemetry]"), sectionColor("queue"))
+		f.formatQueueMetrics(builder, queueMetrics)
 		builder.WriteString("\n")
 	}
 
 	// Format custom metrics
 	if len(customMetrics) > 0 {
-		builder.WriteString(fmt.Sprintf("%s - %s:\n", labelColor("[telemetry]"), sectionColor("custom metrics")))
-		f.formatCustomMetrics(&builder, customMetrics)
+		fmt.Fprintf(builder, "%s - %s:\n", labelColor("[telemetry]"), sectionColor("custom metrics"))
+		for _, sm := range customMetrics {
+			if f.resource {
+				fmt.Fprintf(builder, " scope: %s\n", scopeName(sm.scope))
+			}
+			f.formatCustomMetrics(builder, sm.metrics)
+		}
 		builder.WriteString("\n")
 	}
 
-	return builder.String()
+	return flush()
 }
 
 // formatHostMetrics formats host-related metrics
-func (f *defaultMetricFormatter) formatHostMetrics(builder *strings.Builder, metrics []metricdata.Metrics) {
+func (f *defaultMetricFormatter) formatHostMetrics(builder *bytes.Buffer, metrics []metricdata.Metrics) {
 	for _, m := range metrics {
 		switch m.Name {
 		case "process.cpu.time":
@@ -159,6 +274,10 @@ func (f *defaultMetricFormatter) formatHostMetrics(builder *strings.Builder, met
 			f.formatMemoryUsage(builder, m)
 		case "runtime.go.gc.count":
 			f.formatGCCount(builder, m)
+		case "runtime.go.goroutines":
+			f.formatGoroutines(builder, m)
+		case "runtime.go.mem.heap_alloc":
+			f.formatHeapAlloc(builder, m)
 		default:
 			f.formatGenericMetric(builder, m)
 		}
@@ -166,7 +285,7 @@ func (f *defaultMetricFormatter) formatHostMetrics(builder *strings.Builder, met
 }
 
 // formatCPUTime formats CPU time metrics
-func (f *defaultMetricFormatter) formatCPUTime(builder *strings.Builder, m metricdata.Metrics) {
+func (f *defaultMetricFormatter) formatCPUTime(builder *bytes.Buffer, m metricdata.Metrics) {
 	if sum, ok := m.Data.(metricdata.Sum[float64]); ok {
 		userTime, systemTime := 0.0, 0.0
 		for _, dp := range sum.DataPoints {
@@ -180,71 +299,93 @@ func (f *defaultMetricFormatter) formatCPUTime(builder *strings.Builder, m metri
 				}
 			}
 		}
-		builder.WriteString(fmt.Sprintf("  Process Cpu time in seconds: { user: %.3f, system: %.3f }\n",
-			userTime, systemTime))
+		fmt.Fprintf(builder, "  Process Cpu time in seconds: { user: %.3f, system: %.3f }\n",
+			userTime, systemTime)
 	}
 }
 
 // formatMemoryUsage formats memory usage metrics
-func (f *defaultMetricFormatter) formatMemoryUsage(builder *strings.Builder, m metricdata.Metrics) {
+func (f *defaultMetricFormatter) formatMemoryUsage(builder *bytes.Buffer, m metricdata.Metrics) {
 	if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
 		for _, dp := range gauge.DataPoints {
-			builder.WriteString(fmt.Sprintf("  Process Memory usage in bytes: %d\n", dp.Value))
+			fmt.Fprintf(builder, "  Process Memory usage in bytes: %d\n", dp.Value)
 		}
 	}
 }
 
 // formatGCCount formats garbage collection count
-func (f *defaultMetricFormatter) formatGCCount(builder *strings.Builder, m metricdata.Metrics) {
+func (f *defaultMetricFormatter) formatGCCount(builder *bytes.Buffer, m metricdata.Metrics) {
+	if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+		for _, dp := range sum.DataPoints {
+			fmt.Fprintf(builder, "  Runtime GC count: %d\n", dp.Value)
+		}
+	}
+}
+
+// formatGoroutines formats the goroutine count
+func (f *defaultMetricFormatter) formatGoroutines(builder *bytes.Buffer, m metricdata.Metrics) {
 	if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
 		for _, dp := range sum.DataPoints {
-			builder.WriteString(fmt.Sprintf("  Runtime GC count: %d\n", dp.Value))
+			fmt.Fprintf(builder, "  Runtime goroutines: %d\n", dp.Value)
 		}
 	}
 }
 
-// formatDBPoolMetrics formats database pool metrics
-func (f *defaultMetricFormatter) formatDBPoolMetrics(builder *strings.Builder, metrics []metricdata.Metrics) {
+// formatHeapAlloc formats the allocated heap size
+func (f *defaultMetricFormatter) formatHeapAlloc(builder *bytes.Buffer, m metricdata.Metrics) {
+	if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
+		for _, dp := range gauge.DataPoints {
+			fmt.Fprintf(builder, "  Runtime heap allocated in bytes: %d\n", dp.Value)
+		}
+	}
+}
+
+// formatDBPoolMetrics formats database pool metrics. The pool size is
+// shown against db.pool.max and the available connections against the size;
+// histograms like the acquire time follow the table.
+func (f *defaultMetricFormatter) formatDBPoolMetrics(builder *bytes.Buffer, metrics []metricdata.Metrics) {
 	// Define colors
-	headerColor := color.New(color.FgYellow, color.Bold).SprintFunc()
-	valueColor := color.New(color.FgCyan).SprintFunc()
+	headerColor := f.palette.sprint(color.FgYellow, color.Bold)
+	valueColor := f.palette.sprint(color.FgCyan)
 
 	// Example format:     size | available | pending
 	//                      1/1 |       1/1 |       0
-	builder.WriteString(fmt.Sprintf("     %s | %s | %s\n",
-		headerColor("size"), headerColor("available"), headerColor("pending")))
-
-	size, available, pending := "0/0", "0/0", "0"
+	fmt.Fprintf(builder, "     %s | %s | %s\n",
+		headerColor("size"), headerColor("available"), headerColor("pending"))
 
+	values := make(map[string]float64)
+	var histograms []metricdata.Metrics
 	for _, m := range metrics {
-		switch m.Name {
-		case "db.pool.size":
-			if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
-				for _, dp := range gauge.DataPoints {
-					size = fmt.Sprintf("%d/%d", dp.Value, dp.Value) // Current/Max
-				}
-			}
-		case "db.pool.available":
-			if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
-				for _, dp := range gauge.DataPoints {
-					available = fmt.Sprintf("%d/%d", dp.Value, dp.Value)
-				}
-			}
-		case "db.pool.pending":
-			if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
-				for _, dp := range gauge.DataPoints {
-					pending = fmt.Sprintf("%d", dp.Value)
-				}
-			}
+		if _, ok := metricSummary(m); ok {
+			histograms = append(histograms, m)
+			continue
+		}
+		if v, ok := metricValue(m); ok {
+			values[strings.TrimPrefix(m.Name, "db.pool.")] = v
 		}
 	}
 
-	builder.WriteString(fmt.Sprintf("     %s |      %s |      %s\n",
-		valueColor(size), valueColor(available), valueColor(pending)))
+	size, hasSize := values["size"]
+	max, hasMax := values["max"]
+	if !hasMax {
+		max = size
+	}
+	capacity := size
+	if !hasSize {
+		capacity = max
+	}
+	fmt.Fprintf(builder, "     %4s |      %4s |      %s\n",
+		valueColor(fmt.Sprintf("%s/%s", formatFloat(size), formatFloat(max))),
+		valueColor(fmt.Sprintf("%s/%s", formatFloat(values["available"]), formatFloat(capacity))),
+		valueColor(formatFloat(values["pending"])))
+
+	for _, m := range histograms {
+		f.formatGenericMetric(builder, m)
+	}
 }
 
 // formatQueueMetrics formats queue metrics
-func (f *defaultMetricFormatter) formatQueueMetrics(builder *strings.Builder, metrics []metricdata.Metrics) {
+func (f *defaultMetricFormatter) formatQueueMetrics(builder *bytes.Buffer, metrics []metricdata.Metrics) {
 	// Example format: cold | remaining | min storage time | med storage time | max storage time | incoming | outgoing
 	//                   2  |       32  |                2 |               16 |              128 |      256 |      512
 	builder.WriteString("     cold | remaining | min storage time | med storage time | max storage time | incoming | outgoing\n")
@@ -254,58 +395,107 @@ func (f *defaultMetricFormatter) formatQueueMetrics(builder *strings.Builder, me
 	}
 
 	for _, m := range metrics {
-		if gauge, ok := m.Data.(metricdata.Gauge[int64]); ok {
-			for _, dp := range gauge.DataPoints {
-				switch m.Name {
-				case "queue.cold":
-					values["cold"] = fmt.Sprintf("%d", dp.Value)
-				case "queue.remaining":
-					values["remaining"] = fmt.Sprintf("%d", dp.Value)
-				case "queue.incoming":
-					values["incoming"] = fmt.Sprintf("%d", dp.Value)
-				case "queue.outgoing":
-					values["outgoing"] = fmt.Sprintf("%d", dp.Value)
+		if m.Name == "queue.storage_time" {
+			if s, ok := metricSummary(m); ok && s.count > 0 {
+				if s.hasMin {
+					values["min"] = fmt.Sprintf("%.0f", s.min)
+				}
+				values["med"] = fmt.Sprintf("%.0f", s.quantile(0.5))
+				if s.hasMax {
+					values["max"] = fmt.Sprintf("%.0f", s.max)
 				}
 			}
+			continue
+		}
+		if v, ok := metricValue(m); ok {
+			switch name := strings.TrimPrefix(m.Name, "queue."); name {
+			case "cold", "remaining", "incoming", "outgoing":
+				values[name] = fmt.Sprintf("%.0f", v)
+			}
 		}
 	}
 
-	builder.WriteString(fmt.Sprintf("     %4s |      %4s |             %4s |             %4s |             %4s |     %4s |     %4s\n",
-		values["cold"], values["remaining"], values["min"], values["med"], values["max"], values["incoming"], values["outgoing"]))
+	fmt.Fprintf(builder, "     %4s |      %4s |             %4s |             %4s |             %4s |     %4s |     %4s\n",
+		values["cold"], values["remaining"], values["min"], values["med"], values["max"], values["incoming"], values["outgoing"])
+}
+
+// metricValue sums the data points of a gauge or sum metric, e.g. the
+// sizes of several pools
+func metricValue(m metricdata.Metrics) (float64, bool) {
+	var total float64
+	switch data := m.Data.(type) {
+	case metricdata.Gauge[int64]:
+		for _, dp := range data.DataPoints {
+			total += float64(dp.Value)
+		}
+	case metricdata.Gauge[float64]:
+		for _, dp := range data.DataPoints {
+			total += dp.Value
+		}
+	case metricdata.Sum[int64]:
+		for _, dp := range data.DataPoints {
+			total += float64(dp.Value)
+		}
+	case metricdata.Sum[float64]:
+		for _, dp := range data.DataPoints {
+			total += dp.Value
+		}
+	default:
+		return 0, false
+	}
+	return total, true
 }
 
 // formatCustomMetrics formats custom application metrics
-func (f *defaultMetricFormatter) formatCustomMetrics(builder *strings.Builder, metrics []metricdata.Metrics) {
+func (f *defaultMetricFormatter) formatCustomMetrics(builder *bytes.Buffer, metrics []metricdata.Metrics) {
 	for _, m := range metrics {
 		f.formatGenericMetric(builder, m)
 	}
 }
 
 // formatGenericMetric formats any metric in a generic way
-func (f *defaultMetricFormatter) formatGenericMetric(builder *strings.Builder, m metricdata.Metrics) {
-	builder.WriteString(fmt.Sprintf("  %s: ", m.Name))
+func (f *defaultMetricFormatter) formatGenericMetric(builder *bytes.Buffer, m metricdata.Metrics) {
+	fmt.Fprintf(builder, "  %s: ", m.Name)
 
 	switch data := m.Data.(type) {
 	case metricdata.Gauge[int64]:
 		for _, dp := range data.DataPoints {
-			builder.WriteString(fmt.Sprintf("%d ", dp.Value))
+			fmt.Fprintf(builder, "%d ", dp.Value)
 		}
 	case metricdata.Gauge[float64]:
 		for _, dp := range data.DataPoints {
-			builder.WriteString(fmt.Sprintf("%.3f ", dp.Value))
+			fmt.Fprintf(builder, "%.3f ", dp.Value)
 		}
 	case metricdata.Sum[int64]:
 		for _, dp := range data.DataPoints {
-			builder.WriteString(fmt.Sprintf("%d ", dp.Value))
+			text := f.formatSumDataPoint(m.Name, data.Temporality, dp.Attributes, dp.StartTime, dp.Time, float64(dp.Value), fmt.Sprintf("%d", dp.Value))
+			fmt.Fprintf(builder, "%s ", text)
 		}
 	case metricdata.Sum[float64]:
 		for _, dp := range data.DataPoints {
-			builder.WriteString(fmt.Sprintf("%.3f ", dp.Value))
+			text := f.formatSumDataPoint(m.Name, data.Temporality, dp.Attributes, dp.StartTime, dp.Time, dp.Value, fmt.Sprintf("%.3f", dp.Value))
+			fmt.Fprintf(builder, "%s ", text)
 		}
 	case metricdata.Histogram[int64]:
-		builder.WriteString(fmt.Sprintf("count: %d ", data.DataPoints[0].Count))
+		for _, dp := range data.DataPoints {
+			f.formatHistogram(builder, summarizeHistogram(dp))
+		}
+		return
 	case metricdata.Histogram[float64]:
-		builder.WriteString(fmt.Sprintf("count: %d ", data.DataPoints[0].Count))
+		for _, dp := range data.DataPoints {
+			f.formatHistogram(builder, summarizeHistogram(dp))
+		}
+		return
+	case metricdata.ExponentialHistogram[int64]:
+		for _, dp := range data.DataPoints {
+			f.formatHistogram(builder, summarizeExponentialHistogram(dp))
+		}
+		return
+	case metricdata.ExponentialHistogram[float64]:
+		for _, dp := range data.DataPoints {
+			f.formatHistogram(builder, summarizeExponentialHistogram(dp))
+		}
+		return
 	}
 
 	builder.WriteString("\n")
@@ -0,0 +1,112 @@
+package console
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func gauge(name string, value int64) metricdata.Metrics {
+	return metricdata.Metrics{
+		Name: name,
+		Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{{Value: value}}},
+	}
+}
+
+func TestFormatDBPoolMetrics(t *testing.T) {
+	formatter := &defaultMetricFormatter{}
+	metrics := []metricdata.Metrics{
+		gauge("db.pool.size", 3),
+		gauge("db.pool.max", 10),
+		gauge("db.pool.available", 2),
+		gauge("db.pool.pending", 1),
+		{
+			Name: "db.pool.acquire_time",
+			Data: metricdata.Histogram[float64]{DataPoints: []metricdata.HistogramDataPoint[float64]{{
+				Count: 2, Sum: 6, Bounds: []float64{5}, BucketCounts: []uint64{2, 0},
+			}}},
+		},
+	}
+
+	var builder bytes.Buffer
+	formatter.formatDBPoolMetrics(&builder, metrics)
+	output := builder.String()
+
+	for _, want := range []string{"3/10", "2/3", "|      1\n", "db.pool.acquire_time: count: 2"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestFormatQueueMetrics(t *testing.T) {
+	formatter := &defaultMetricFormatter{}
+	storage := metricdata.Metrics{
+		Name: "queue.storage_time",
+		Data: metricdata.Histogram[int64]{DataPoints: []metricdata.HistogramDataPoint[int64]{
+			{Count: 2, Sum: 30, Bounds: []float64{10, 100}, BucketCounts: []uint64{1, 1, 0},
+				Min: metricdata.NewExtrema[int64](5), Max: metricdata.NewExtrema[int64](25)},
+			{Count: 2, Sum: 300, Bounds: []float64{10, 100}, BucketCounts: []uint64{0, 1, 1},
+				Min: metricdata.NewExtrema[int64](50), Max: metricdata.NewExtrema[int64](250)},
+		}},
+	}
+
+	var builder bytes.Buffer
+	formatter.formatQueueMetrics(&builder, []metricdata.Metrics{storage, gauge("queue.remaining", 32)})
+	fields := strings.Split(strings.Split(builder.String(), "\n")[1], "|")
+	got := make([]string, len(fields))
+	for i, field := range fields {
+		got[i] = strings.TrimSpace(field)
+	}
+
+	expected := []string{"0", "32", "5", "55", "250", "0", "0"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected queue row %v, got %v", expected, got)
+	}
+}
+
+func TestFormatGenericMetric_Rates(t *testing.T) {
+	formatter := &defaultMetricFormatter{rates: newRateTracker()}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	counter := func(value int64, ts time.Time) metricdata.Metrics {
+		return metricdata.Metrics{
+			Name: "http.requests",
+			Data: metricdata.Sum[int64]{
+				Temporality: metricdata.CumulativeTemporality,
+				IsMonotonic: true,
+				DataPoints:  []metricdata.DataPoint[int64]{{StartTime: start, Time: ts, Value: value}},
+			},
+		}
+	}
+
+	var first bytes.Buffer
+	formatter.formatGenericMetric(&first, counter(100, start.Add(10*time.Second)))
+	if got := first.String(); got != "  http.requests: 100 \n" {
+		t.Errorf("Expected plain total on the first export, got %q", got)
+	}
+
+	var second bytes.Buffer
+	formatter.formatGenericMetric(&second, counter(150, start.Add(20*time.Second)))
+	if got := second.String(); got != "  http.requests: 150 (+50, 5/s) \n" {
+		t.Errorf("Expected delta and rate, got %q", got)
+	}
+
+	// A restarted counter counts from its new start time
+	var reset bytes.Buffer
+	formatter.formatGenericMetric(&reset, counter(20, start.Add(30*time.Second)))
+	if got := reset.String(); got != "  http.requests: 20 (+20, 0.6667/s) \n" {
+		t.Errorf("Expected reset to be detected, got %q", got)
+	}
+}
+
+func TestRateTracker_Delta(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	delta, rate, ok := newRateTracker().observe("jobs", metricdata.DeltaTemporality, attribute.NewSet(), start, start.Add(4*time.Second), 8)
+	if !ok || delta != 8 || rate != 2 {
+		t.Errorf("Expected delta 8 at 2/s, got %v at %v/s (ok=%v)", delta, rate, ok)
+	}
+}
@@ -0,0 +1,81 @@
+package console
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// seriesKey identifies a data point across exports
+type seriesKey struct {
+	metric string
+	attrs  attribute.Distinct
+}
+
+// sample is the last seen value of a cumulative series
+type sample struct {
+	start time.Time
+	time  time.Time
+	value float64
+}
+
+// rateTracker remembers the previous export to turn sums into per-interval
+// deltas and rates
+type rateTracker struct {
+	mu       sync.Mutex
+	previous map[seriesKey]sample
+}
+
+// newRateTracker creates an empty rate tracker
+func newRateTracker() *rateTracker {
+	return &rateTracker{previous: make(map[seriesKey]sample)}
+}
+
+// observe records a sum data point and returns its change since the last
+// export and the change per second. Delta sums already hold the change of
+// their interval. ok is false for the first export of a cumulative series.
+func (r *rateTracker) observe(name string, temporality metricdata.Temporality, attrs attribute.Set, start, ts time.Time, value float64) (delta, rate float64, ok bool) {
+	if temporality == metricdata.DeltaTemporality {
+		return value, perSecond(value, ts.Sub(start)), true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := seriesKey{metric: name, attrs: attrs.Equivalent()}
+	prev, seen := r.previous[key]
+	r.previous[key] = sample{start: start, time: ts, value: value}
+	if !seen {
+		return 0, 0, false
+	}
+	// A new start time or a smaller value means the series was reset
+	if !start.Equal(prev.start) || value < prev.value {
+		return value, perSecond(value, ts.Sub(start)), true
+	}
+	delta = value - prev.value
+	return delta, perSecond(delta, ts.Sub(prev.time)), true
+}
+
+// perSecond divides v by the length of the interval d
+func perSecond(v float64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return v / d.Seconds()
+}
+
+// formatSumDataPoint prints a sum value followed by its delta and rate
+// when rates are enabled
+func (f *defaultMetricFormatter) formatSumDataPoint(name string, temporality metricdata.Temporality, attrs attribute.Set, start, ts time.Time, value float64, text string) string {
+	if f.rates == nil {
+		return text
+	}
+	delta, rate, ok := f.rates.observe(name, temporality, attrs, start, ts, value)
+	if !ok {
+		return text
+	}
+	return fmt.Sprintf("%s (+%s, %s/s)", text, formatFloat(delta), formatFloat(rate))
+}
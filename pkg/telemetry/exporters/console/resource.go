@@ -0,0 +1,37 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/fatih/color"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// formatResource writes the header block listing the resource attributes,
+// e.g. service.name and service.version
+func formatResource(builder *bytes.Buffer, p palette, res *resource.Resource) {
+	if res == nil || res.Len() == 0 {
+		return
+	}
+
+	labelColor := p.sprint(color.FgGreen, color.Bold)
+	sectionColor := p.sprint(color.FgCyan, color.Bold)
+	keyColor := p.sprint(color.FgMagenta)
+
+	fmt.Fprintf(builder, "%s - %s:\n", labelColor("[telemetry]"), sectionColor("resource"))
+	for iter := res.Iter(); iter.Next(); {
+		attr := iter.Attribute()
+		fmt.Fprintf(builder, "  %s: %s\n", keyColor(string(attr.Key)), attr.Value.Emit())
+	}
+	builder.WriteString("\n")
+}
+
+// scopeName renders an instrumentation scope as name@version
+func scopeName(scope instrumentation.Scope) string {
+	if scope.Version == "" {
+		return scope.Name
+	}
+	return scope.Name + "@" + scope.Version
+}
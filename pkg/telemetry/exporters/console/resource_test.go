@@ -0,0 +1,84 @@
+package console
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanExporter_WithResourceInfo(t *testing.T) {
+	res := resource.NewSchemaless(attribute.String("service.name", "bookshop"))
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder), sdktrace.WithResource(res))
+
+	_, span := tp.Tracer("orders", trace.WithInstrumentationVersion("1.2.0")).Start(context.Background(), "place order")
+	span.End()
+
+	var buf strings.Builder
+	exporter := NewSpanExporter(WithWriter(&buf), WithColor(false), WithResourceInfo(true))
+	if err := exporter.ExportSpans(context.Background(), recorder.Ended()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "service.name: bookshop") {
+		t.Errorf("Expected resource attributes in output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "scope: orders@1.2.0") {
+		t.Errorf("Expected instrumentation scope in output, got:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := NewSpanExporter(WithWriter(&buf), WithColor(false)).ExportSpans(context.Background(), recorder.Ended()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "service.name") {
+		t.Error("Expected no resource information by default")
+	}
+}
+
+func TestMetricExporter_WithResourceInfo(t *testing.T) {
+	rm := &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(attribute.String("service.name", "bookshop")),
+		ScopeMetrics: []metricdata.ScopeMetrics{
+			{
+				Scope: instrumentation.Scope{Name: "orders"},
+				Metrics: []metricdata.Metrics{{
+					Name: "orders.placed",
+					Data: metricdata.Sum[int64]{DataPoints: []metricdata.DataPoint[int64]{{Value: 3}}},
+				}},
+			},
+			{
+				Scope: instrumentation.Scope{Name: "payments"},
+				Metrics: []metricdata.Metrics{{
+					Name: "payments.failed",
+					Data: metricdata.Sum[int64]{DataPoints: []metricdata.DataPoint[int64]{{Value: 1}}},
+				}},
+			},
+		},
+	}
+
+	var buf strings.Builder
+	exporter := NewMetricExporter(WithMetricWriter(&buf), WithMetricColor(false), WithMetricResourceInfo(true))
+	if err := exporter.Export(context.Background(), rm); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "service.name: bookshop") {
+		t.Errorf("Expected resource attributes in output, got:\n%s", output)
+	}
+	orders := strings.Index(output, "scope: orders")
+	payments := strings.Index(output, "scope: payments")
+	if orders < 0 || payments < orders || !strings.Contains(output[orders:payments], "orders.placed") {
+		t.Errorf("Expected metrics grouped by scope, got:\n%s", output)
+	}
+}
@@ -1,40 +1,82 @@
 package console
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 // SpanExporter implements a console span exporter that mimics the JavaScript version
 type SpanExporter struct {
-	writer    Writer
+	writer    io.Writer
+	out       *lockedWriter
 	formatter SpanFormatter
+	color     *bool
+
+	slowThreshold time.Duration
+	attributes    []string
+	resource      bool
+	timestamps    timeFormat
+	minDuration   time.Duration
+	maxLength     int
+	normalizer    *normalizer
+	clock         clock.Clock
 }
 
-// Writer interface for output
-type Writer interface {
-	Write([]byte) (int, error)
-}
-
-// SpanFormatter formats spans for console output
+// SpanFormatter formats spans for console output. Exporters pass a pooled
+// buffer that is written to the console in one call after Format returns.
 type SpanFormatter interface {
-	Format(spans []trace.ReadOnlySpan) string
+	Format(w io.Writer, spans []trace.ReadOnlySpan) error
 }
 
 // NewSpanExporter creates a new console span exporter
 func NewSpanExporter(opts ...SpanExporterOption) *SpanExporter {
 	exporter := &SpanExporter{
-		writer:    &defaultWriter{},
-		formatter: &defaultSpanFormatter{},
+		writer: os.Stdout,
 	}
 
 	for _, opt := range opts {
 		opt(exporter)
 	}
+	if exporter.normalizer != nil {
+		// Deterministic output must not depend on the terminal or time zone
+		disabled := false
+		exporter.color = &disabled
+		exporter.timestamps.utc = true
+		if exporter.clock == nil {
+			exporter.clock = clock.NewFixed(exporter.normalizer.start)
+		}
+	}
+
+	if exporter.formatter == nil {
+		exporter.formatter = &defaultSpanFormatter{
+			palette:       newPalette(exporter.color, exporter.writer),
+			slowThreshold: exporter.slowThreshold,
+			attributes:    exporter.attributes,
+			resource:      exporter.resource,
+			timestamps:    exporter.timestamps,
+			minDuration:   exporter.minDuration,
+			maxLength:     exporter.maxLength,
+			clock:         exporter.clock,
+		}
+	}
+	exporter.out = newLockedWriter(exporter.writer)
 
 	return exporter
 }
@@ -42,13 +84,18 @@ func NewSpanExporter(opts ...SpanExporterOption) *SpanExporter {
 // SpanExporterOption configures a SpanExporter
 type SpanExporterOption func(*SpanExporter)
 
-// WithWriter sets the writer for the exporter
-func WithWriter(w Writer) SpanExporterOption {
+// WithWriter sets the writer for the exporter, os.Stdout by default
+func WithWriter(w io.Writer) SpanExporterOption {
 	return func(e *SpanExporter) {
 		e.writer = w
 	}
 }
 
+// WithStderr writes to os.Stderr, keeping stdout free for the application
+func WithStderr() SpanExporterOption {
+	return WithWriter(os.Stderr)
+}
+
 // WithSpanFormatter sets the formatter for the exporter
 func WithSpanFormatter(f SpanFormatter) SpanExporterOption {
 	return func(e *SpanExporter) {
@@ -56,15 +103,110 @@ func WithSpanFormatter(f SpanFormatter) SpanExporterOption {
 	}
 }
 
+// WithColor enables or disables ANSI colors of the default formatter,
+// overriding the NO_COLOR, FORCE_COLOR and terminal detection
+func WithColor(enabled bool) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.color = &enabled
+	}
+}
+
+// WithSlowThreshold highlights spans taking at least d in the default
+// formatter. Zero disables the highlighting.
+func WithSlowThreshold(d time.Duration) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.slowThreshold = d
+	}
+}
+
+// WithImportantAttributes sets the glob patterns of the span attributes
+// shown by the default formatter, "*" shows all of them. Without this
+// option DefaultImportantAttributes are shown.
+func WithImportantAttributes(patterns ...string) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.attributes = append([]string{}, patterns...)
+	}
+}
+
+// WithResourceInfo prints the resource attributes of each export batch and
+// the instrumentation scope of each span in the default formatter
+func WithResourceInfo(enabled bool) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.resource = enabled
+	}
+}
+
+// WithTimeLayout prints the absolute start and end time of spans in the
+// given layout, e.g. time.TimeOnly, instead of the offset in milliseconds
+// from the start of the trace
+func WithTimeLayout(layout string) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.timestamps.layout = layout
+	}
+}
+
+// WithUTC prints absolute span times in UTC rather than local time
+func WithUTC(enabled bool) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.timestamps.utc = enabled
+	}
+}
+
+// WithMinDuration hides spans shorter than d in the default formatter. The
+// number of hidden spans is reported in a summary line per export.
+func WithMinDuration(d time.Duration) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.minDuration = d
+	}
+}
+
+// WithMaxAttributeLength cuts attribute values printed by the default
+// formatter to n characters, e.g. long db.statement values
+func WithMaxAttributeLength(n int) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.maxLength = n
+	}
+}
+
+// WithClock sets the clock the default formatter takes the time of log
+// records without a timestamp from, the system clock by default
+func WithClock(c clock.Clock) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.clock = c
+	}
+}
+
+// WithDeterministic makes the output the same on every run, e.g. for
+// golden-file tests of a span formatter: span times are replaced by a clock
+// starting at start, DeterministicStart if zero, that ticks a millisecond
+// per distinct timestamp. Trace and span IDs are numbered in order of
+// appearance, attributes are sorted by key, colors are off and times are
+// printed in UTC. Custom formatters receive the rewritten spans.
+//
+// The clock only orders timestamps within an export, so use a syncer or
+// flush once to keep the durations of parents exported after their
+// children meaningful.
+func WithDeterministic(start time.Time) SpanExporterOption {
+	return func(e *SpanExporter) {
+		e.normalizer = newNormalizer(start)
+	}
+}
+
 // ExportSpans exports spans to the console
 func (e *SpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
 	if len(spans) == 0 {
 		return nil
 	}
+	if e.normalizer != nil {
+		spans, _ = e.normalizer.normalize(spans, nil)
+	}
 
-	output := e.formatter.Format(spans)
-	_, err := e.writer.Write([]byte(output))
-	return err
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := e.formatter.Format(buf, spans); err != nil {
+		return fmt.Errorf("failed to format spans: %w", err)
+	}
+	return e.out.write(buf.Bytes())
 }
 
 // Shutdown shuts down the exporter
@@ -73,123 +215,385 @@ func (e *SpanExporter) Shutdown(ctx context.Context) error {
 }
 
 // defaultSpanFormatter provides the default span formatting
-type defaultSpanFormatter struct{}
+type defaultSpanFormatter struct {
+	palette       palette
+	slowThreshold time.Duration
+	attributes    []string
+	resource      bool
+	timestamps    timeFormat
+	minDuration   time.Duration
+	maxLength     int
+	clock         clock.Clock
+}
 
 // Format formats spans in a tree-like structure similar to the JS version
-func (f *defaultSpanFormatter) Format(spans []trace.ReadOnlySpan) string {
+func (f *defaultSpanFormatter) Format(w io.Writer, spans []trace.ReadOnlySpan) error {
 	if len(spans) == 0 {
-		return ""
+		return nil
 	}
+	return f.format(w, spans, nil)
+}
 
-	var builder strings.Builder
+// format writes the span trees of spans with the log records of the same
+// traces nested under the span they were emitted in
+func (f *defaultSpanFormatter) format(w io.Writer, spans []trace.ReadOnlySpan, records []sdklog.Record) error {
+	builder, flush := bufferFor(w)
+	if f.resource && len(spans) > 0 {
+		formatResource(builder, f.palette, spans[0].Resource())
+	}
 
-	// Group spans by trace ID and build hierarchy
-	traceGroups := make(map[string][]trace.ReadOnlySpan)
+	// Group spans by trace ID. Elapsed times are relative to the start of
+	// the earliest span of a trace, including hidden ones.
+	traceGroups := make(map[oteltrace.TraceID][]trace.ReadOnlySpan)
+	origins := make(map[oteltrace.TraceID]time.Time)
+	hidden := 0
 	for _, span := range spans {
-		traceID := span.SpanContext().TraceID().String()
+		traceID := span.SpanContext().TraceID()
+		if origin, ok := origins[traceID]; !ok || span.StartTime().Before(origin) {
+			origins[traceID] = span.StartTime()
+		}
+		if f.minDuration > 0 && span.EndTime().Sub(span.StartTime()) < f.minDuration {
+			hidden++
+			continue
+		}
 		traceGroups[traceID] = append(traceGroups[traceID], span)
 	}
+	logGroups := make(map[oteltrace.TraceID][]sdklog.Record)
+	for _, record := range records {
+		traceID := record.TraceID()
+		if _, ok := traceGroups[traceID]; !ok {
+			ts := recordTime(record, f.clock)
+			if origin, ok := origins[traceID]; !ok || ts.Before(origin) {
+				origins[traceID] = ts
+			}
+		}
+		logGroups[traceID] = append(logGroups[traceID], record)
+	}
+
+	trees := make([]traceTree, 0, len(traceGroups))
+	for traceID, traceSpans := range traceGroups {
+		tree := newTraceTree(traceID, origins[traceID], traceSpans)
+		tree.addLogs(logGroups[traceID])
+		trees = append(trees, tree)
+	}
+	for traceID, traceRecords := range logGroups {
+		if _, ok := traceGroups[traceID]; !ok {
+			tree := newTraceTree(traceID, origins[traceID], nil)
+			tree.addLogs(traceRecords)
+			trees = append(trees, tree)
+		}
+	}
+	// Traces are listed by the start of their earliest root, so the output
+	// is the same on every run
+	sort.SliceStable(trees, func(i, j int) bool {
+		a, b := trees[i].start(), trees[j].start()
+		if a.Equal(b) {
+			return trees[i].id.String() < trees[j].id.String()
+		}
+		return a.Before(b)
+	})
 
 	// Define colors
-	labelColor := color.New(color.FgGreen, color.Bold).SprintFunc()
-	traceIDColor := color.New(color.FgMagenta).SprintFunc()
+	labelColor := f.palette.sprint(color.FgGreen, color.Bold)
+	traceIDColor := f.palette.sprint(color.FgMagenta)
 
-	for traceID, traceSpans := range traceGroups {
-		builder.WriteString(fmt.Sprintf("%s - %s (trace: %s):\n",
+	for _, tree := range trees {
+		fmt.Fprintf(builder, "%s - %s (trace: %s):\n",
 			labelColor("[telemetry]"),
-			color.GreenString("elapsed times"),
-			traceIDColor(traceID[:8])))
-
-		// Sort spans by start time
-		sortedSpans := sortSpansByStartTime(traceSpans)
+			f.palette.sprint(color.FgGreen)("elapsed times"),
+			traceIDColor(tree.id.String()[:8]))
 
-		// Find the root span (the one with the earliest start time)
-		if len(sortedSpans) > 0 {
-			f.formatSpanHierarchy(&builder, sortedSpans, 0)
+		for _, root := range tree.roots {
+			f.formatSpanHierarchy(builder, root, tree, "", "")
+		}
+		// Logs whose span is not part of the output follow the tree
+		for _, record := range tree.orphans {
+			f.formatSpanLog(builder, record, time.Time{}, "")
 		}
 
 		builder.WriteString("\n")
 	}
 
-	return builder.String()
+	if hidden > 0 {
+		fmt.Fprintf(builder, "%s - %d spans shorter than %s hidden\n\n",
+			labelColor("[telemetry]"), hidden, f.minDuration)
+	}
+
+	return flush()
 }
 
-// formatSpanHierarchy formats spans in a hierarchical manner
-func (f *defaultSpanFormatter) formatSpanHierarchy(builder *strings.Builder, spans []trace.ReadOnlySpan, depth int) {
+// formatSpanHierarchy formats a span and, indented below it, its children.
+// prefix is written before the span itself and childPrefix before the lines
+// that belong to its subtree.
+func (f *defaultSpanFormatter) formatSpanHierarchy(builder *bytes.Buffer, span trace.ReadOnlySpan, tree traceTree, prefix, childPrefix string) {
 	// Define colors
-	timeColor := color.New(color.FgHiBlack).SprintFunc()
-	durationColor := color.New(color.FgYellow, color.Bold).SprintFunc()
-	spanNameColor := color.New(color.FgCyan).SprintFunc()
-	attributeKeyColor := color.New(color.FgMagenta).SprintFunc()
+	timeColor := f.palette.sprint(color.FgHiBlack)
+	durationColor := f.palette.sprint(color.FgYellow, color.Bold)
+	spanNameColor := f.palette.sprint(color.FgCyan)
+	attributeKeyColor := f.palette.sprint(color.FgMagenta)
+	treeColor := f.palette.sprint(color.FgHiBlack)
+
+	// Format: start → end = duration ms  operation_name, where start and
+	// end are offsets from the start of the trace or absolute times
+	var start, end string
+	if f.timestamps.layout != "" {
+		start = f.timestamps.format(span.StartTime(), f.timestamps.layout)
+		end = f.timestamps.format(span.EndTime(), f.timestamps.layout)
+	} else {
+		start = fmt.Sprintf("%8.2f", float64(span.StartTime().Sub(tree.origin).Nanoseconds())/1e6)
+		end = fmt.Sprintf("%8.2f", float64(span.EndTime().Sub(tree.origin).Nanoseconds())/1e6)
+	}
+	duration := span.EndTime().Sub(span.StartTime())
+	durationMs := float64(duration.Nanoseconds()) / 1e6
 
-	for _, span := range spans {
-		indent := strings.Repeat("  ", depth)
-		duration := span.EndTime().Sub(span.StartTime())
-
-		// Format: start → end = duration ms  operation_name
-		startMs := float64(span.StartTime().UnixNano()) / 1e6
-		endMs := float64(span.EndTime().UnixNano()) / 1e6
-		durationMs := float64(duration.Nanoseconds()) / 1e6
-
-		// Use modulo with int conversion for display
-		builder.WriteString(fmt.Sprintf("%s%s → %s = %s  %s\n",
-			indent,
-			timeColor(fmt.Sprintf("%8.2f", float64(int64(startMs)%10000))),
-			timeColor(fmt.Sprintf("%8.2f", float64(int64(endMs)%10000))),
-			durationColor(fmt.Sprintf("%8.2f ms", durationMs)),
-			spanNameColor(span.Name())))
-
-		// Add attributes if present
-		attrs := span.Attributes()
-		for _, attr := range attrs {
-			if isImportantAttribute(string(attr.Key)) {
-				builder.WriteString(fmt.Sprintf("%s    %s: %v\n",
-					indent, attributeKeyColor(string(attr.Key)), attr.Value.AsString()))
+	// Error spans are red, slow spans get a red duration
+	status := span.Status()
+	if status.Code == codes.Error {
+		spanNameColor = f.palette.sprint(color.FgRed, color.Bold)
+	}
+	slow := f.slowThreshold > 0 && duration >= f.slowThreshold
+	if slow {
+		durationColor = f.palette.sprint(color.FgRed, color.Bold)
+	}
+
+	fmt.Fprintf(builder, "%s → %s = %s  %s%s%s\n",
+		timeColor(start),
+		timeColor(end),
+		durationColor(fmt.Sprintf("%8.2f ms", durationMs)),
+		treeColor(prefix),
+		spanNameColor(span.Name()),
+		f.formatStatus(status, slow))
+
+	// Attributes are aligned with the span name, behind the tree connectors
+	kids := tree.children[span.SpanContext().SpanID()]
+	attrPrefix := childPrefix
+	if len(kids) > 0 {
+		attrPrefix += "│  "
+	} else {
+		attrPrefix += "   "
+	}
+	padding := strings.Repeat(" ", utf8.RuneCountInString(start)+utf8.RuneCountInString(end)+19)
+	if f.resource {
+		fmt.Fprintf(builder, "%s%s  %s\n",
+			padding, treeColor(attrPrefix), timeColor("scope: "+scopeName(span.InstrumentationScope())))
+	}
+	for _, attr := range span.Attributes() {
+		if f.isImportantAttribute(string(attr.Key)) {
+			fmt.Fprintf(builder, "%s%s  %s: %v\n",
+				padding, treeColor(attrPrefix), attributeKeyColor(string(attr.Key)), truncate(attr.Value.Emit(), f.maxLength))
+		}
+	}
+
+	// Events (e.g. recorded exceptions) and links follow the attributes
+	eventColor := f.palette.sprint(color.FgBlue)
+	for _, event := range span.Events() {
+		offsetMs := float64(event.Time.Sub(span.StartTime()).Nanoseconds()) / 1e6
+		fmt.Fprintf(builder, "%s%s  %s %s\n",
+			padding, treeColor(attrPrefix), eventColor("◆ "+event.Name), timeColor(fmt.Sprintf("+%.2f ms", offsetMs)))
+		for _, attr := range event.Attributes {
+			if attr.Key == semconv.ExceptionStacktraceKey {
+				// Stack traces are printed line by line, below the event
+				for _, line := range strings.Split(strings.TrimRight(attr.Value.AsString(), "\n"), "\n") {
+					fmt.Fprintf(builder, "%s%s      %s\n", padding, treeColor(attrPrefix), timeColor(line))
+				}
+				continue
 			}
+			fmt.Fprintf(builder, "%s%s    %s: %v\n",
+				padding, treeColor(attrPrefix), attributeKeyColor(string(attr.Key)), truncate(attr.Value.Emit(), f.maxLength))
 		}
 	}
+	for _, link := range span.Links() {
+		fmt.Fprintf(builder, "%s%s  %s trace: %s span: %s\n",
+			padding, treeColor(attrPrefix), eventColor("↪ link"),
+			link.SpanContext.TraceID().String()[:8], link.SpanContext.SpanID())
+		for _, attr := range link.Attributes {
+			fmt.Fprintf(builder, "%s%s    %s: %v\n",
+				padding, treeColor(attrPrefix), attributeKeyColor(string(attr.Key)), truncate(attr.Value.Emit(), f.maxLength))
+		}
+	}
+
+	for _, record := range tree.logs[span.SpanContext().SpanID()] {
+		f.formatSpanLog(builder, record, span.StartTime(), padding+treeColor(attrPrefix)+"  ")
+	}
+
+	for i, child := range kids {
+		if i == len(kids)-1 {
+			f.formatSpanHierarchy(builder, child, tree, childPrefix+"└─ ", childPrefix+"   ")
+		} else {
+			f.formatSpanHierarchy(builder, child, tree, childPrefix+"├─ ", childPrefix+"│  ")
+		}
+	}
+}
+
+// formatSpanLog writes a log record on one line below its span, with the
+// offset from the span start, or with its timestamp if spanStart is zero
+func (f *defaultSpanFormatter) formatSpanLog(builder *bytes.Buffer, record sdklog.Record, spanStart time.Time, indent string) {
+	timeColor := f.palette.sprint(color.FgHiBlack)
+	severityColor := f.palette.sprint(color.FgCyan)
+	switch severity := record.Severity(); {
+	case severity >= log.SeverityError:
+		severityColor = f.palette.sprint(color.FgRed, color.Bold)
+	case severity >= log.SeverityWarn:
+		severityColor = f.palette.sprint(color.FgYellow)
+	}
+
+	ts := recordTime(record, f.clock)
+	when := f.timestamps.format(ts, "15:04:05.000")
+	if !spanStart.IsZero() {
+		when = fmt.Sprintf("+%.2f ms", float64(ts.Sub(spanStart).Nanoseconds())/1e6)
+	}
+
+	fmt.Fprintf(builder, "%s%s %s %s", indent, severityColor("▸ "+severityCode(record.Severity())),
+		truncate(inlineValue(record.Body()), f.maxLength), timeColor(when))
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		fmt.Fprintf(builder, " %s", timeColor(kv.Key+"="+truncate(inlineValue(kv.Value), f.maxLength)))
+		return true
+	})
+	builder.WriteString("\n")
 }
 
-// isImportantAttribute determines if an attribute should be displayed
-func isImportantAttribute(key string) bool {
-	importantKeys := []string{
-		"http.method",
-		"http.url",
-		"http.status_code",
-		"db.statement",
-		"db.system",
-		"error",
+// formatStatus renders the status of a span and the slow marker, if any
+func (f *defaultSpanFormatter) formatStatus(status trace.Status, slow bool) string {
+	var result string
+	switch status.Code {
+	case codes.Ok:
+		result = "  " + f.palette.sprint(color.FgGreen)("✓ OK")
+	case codes.Error:
+		label := "✗ ERROR"
+		if status.Description != "" {
+			label += ": " + status.Description
+		}
+		result = "  " + f.palette.sprint(color.FgRed, color.Bold)(label)
+	}
+	if slow {
+		result += "  " + f.palette.sprint(color.FgRed)("(slow)")
 	}
+	return result
+}
 
-	keyStr := string(key)
-	for _, important := range importantKeys {
-		if keyStr == important {
+// DefaultImportantAttributes are the span attributes shown by the default
+// formatter unless WithImportantAttributes is given
+var DefaultImportantAttributes = []string{
+	"http.method",
+	"http.url",
+	"http.status_code",
+	"db.statement",
+	"db.system",
+	"error",
+}
+
+// isImportantAttribute determines if an attribute should be displayed.
+// Patterns are globs, so "db.*" matches all db attributes and "*" any key.
+func (f *defaultSpanFormatter) isImportantAttribute(key string) bool {
+	patterns := f.attributes
+	if patterns == nil {
+		patterns = DefaultImportantAttributes
+	}
+
+	return matchAttribute(patterns, key)
+}
+
+// matchAttribute reports whether key equals or matches one of the glob
+// patterns
+func matchAttribute(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if pattern == key {
+			return true
+		}
+		if ok, _ := path.Match(pattern, key); ok {
 			return true
 		}
 	}
 	return false
 }
 
-// sortSpansByStartTime sorts spans by their start time
-func sortSpansByStartTime(spans []trace.ReadOnlySpan) []trace.ReadOnlySpan {
-	sorted := make([]trace.ReadOnlySpan, len(spans))
-	copy(sorted, spans)
+// traceTree is the span hierarchy of one trace in an export batch, with
+// the log records of the correlated view attached to their spans
+type traceTree struct {
+	id       oteltrace.TraceID
+	origin   time.Time
+	roots    []trace.ReadOnlySpan
+	children map[oteltrace.SpanID][]trace.ReadOnlySpan
+	logs     map[oteltrace.SpanID][]sdklog.Record
+	orphans  []sdklog.Record
+}
 
-	// Simple bubble sort - good enough for console output
-	for i := 0; i < len(sorted)-1; i++ {
-		for j := 0; j < len(sorted)-i-1; j++ {
-			if sorted[j].StartTime().After(sorted[j+1].StartTime()) {
-				sorted[j], sorted[j+1] = sorted[j+1], sorted[j]
-			}
+// start returns the start of the earliest root, or the origin of a trace
+// consisting of log records only
+func (t traceTree) start() time.Time {
+	if len(t.roots) > 0 {
+		return t.roots[0].StartTime()
+	}
+	return t.origin
+}
+
+// addLogs attaches log records to the spans they were emitted in. Records
+// of spans that are not part of the tree are kept as orphans.
+func (t *traceTree) addLogs(records []sdklog.Record) {
+	if len(records) == 0 {
+		return
+	}
+	spans := make(map[oteltrace.SpanID]bool)
+	for _, root := range t.roots {
+		spans[root.SpanContext().SpanID()] = true
+	}
+	for _, kids := range t.children {
+		for _, kid := range kids {
+			spans[kid.SpanContext().SpanID()] = true
 		}
 	}
 
-	return sorted
+	sorted := make([]sdklog.Record, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp().Before(sorted[j].Timestamp())
+	})
+
+	t.logs = make(map[oteltrace.SpanID][]sdklog.Record)
+	for _, record := range sorted {
+		if spans[record.SpanID()] {
+			t.logs[record.SpanID()] = append(t.logs[record.SpanID()], record)
+			continue
+		}
+		t.orphans = append(t.orphans, record)
+	}
+}
+
+// newTraceTree links the spans of a trace to their parents. Spans whose
+// parent is not part of the batch are rendered as roots.
+func newTraceTree(id oteltrace.TraceID, origin time.Time, spans []trace.ReadOnlySpan) traceTree {
+	// Sort spans by start time, so siblings are listed in order
+	sorted := sortSpansByStartTime(spans)
+
+	ids := make(map[oteltrace.SpanID]bool, len(sorted))
+	for _, span := range sorted {
+		ids[span.SpanContext().SpanID()] = true
+	}
+
+	tree := traceTree{
+		id:       id,
+		origin:   origin,
+		children: make(map[oteltrace.SpanID][]trace.ReadOnlySpan),
+	}
+	for _, span := range sorted {
+		parent := span.Parent()
+		if parent.IsValid() && parent.TraceID() == id && ids[parent.SpanID()] {
+			tree.children[parent.SpanID()] = append(tree.children[parent.SpanID()], span)
+			continue
+		}
+		tree.roots = append(tree.roots, span)
+	}
+	return tree
 }
 
-// defaultWriter writes to stdout
-type defaultWriter struct{}
+// sortSpansByStartTime sorts spans by their start time, keeping the order
+// of spans that started at the same time
+func sortSpansByStartTime(spans []trace.ReadOnlySpan) []trace.ReadOnlySpan {
+	sorted := make([]trace.ReadOnlySpan, len(spans))
+	copy(sorted, spans)
 
-func (w *defaultWriter) Write(p []byte) (int, error) {
-	return fmt.Print(string(p))
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTime().Before(sorted[j].StartTime())
+	})
+	return sorted
 }
@@ -0,0 +1,237 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDefaultSpanFormatter_Tree(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /odata/v4/Books")
+	childCtx, child := tracer.Start(ctx, "SELECT Books")
+	_, grandchild := tracer.Start(childCtx, "db.query")
+	grandchild.End()
+	child.End()
+	_, sibling := tracer.Start(ctx, "serialize")
+	sibling.End()
+	root.End()
+
+	formatter := &defaultSpanFormatter{}
+	output := formatSpans(t, formatter, recorder.Ended())
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected header and 4 span lines, got %d:\n%s", len(lines), output)
+	}
+
+	expected := []string{
+		"  GET /odata/v4/Books",
+		"  ├─ SELECT Books",
+		"  │  └─ db.query",
+		"  └─ serialize",
+	}
+	for i, suffix := range expected {
+		if !strings.HasSuffix(lines[i+1], suffix) {
+			t.Errorf("Expected line %d to end with %q, got %q", i+1, suffix, lines[i+1])
+		}
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(lines[1]), "0.00 →") {
+		t.Errorf("Expected root span to start at 0.00, got %q", lines[1])
+	}
+}
+
+func TestDefaultSpanFormatter_EventsAndLinks(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("test")
+
+	_, producer := tracer.Start(context.Background(), "publish")
+	producer.End()
+
+	_, span := tracer.Start(context.Background(), "process",
+		trace.WithLinks(trace.Link{SpanContext: producer.SpanContext()}))
+	span.RecordError(errors.New("boom"), trace.WithStackTrace(true))
+	span.End()
+
+	formatter := &defaultSpanFormatter{}
+	output := formatSpans(t, formatter, recorder.Ended()[1:])
+
+	for _, want := range []string{
+		"◆ exception +",
+		"exception.message: boom",
+		"TestDefaultSpanFormatter_EventsAndLinks",
+		"↪ link trace: " + producer.SpanContext().TraceID().String()[:8],
+		"span: " + producer.SpanContext().SpanID().String(),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestDefaultSpanFormatter_StatusAndSlow(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("test")
+
+	start := time.Now()
+	_, failed := tracer.Start(context.Background(), "failed", trace.WithTimestamp(start))
+	failed.SetStatus(codes.Error, "connection refused")
+	failed.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+
+	formatter := &defaultSpanFormatter{slowThreshold: time.Second}
+	output := formatSpans(t, formatter, recorder.Ended())
+
+	if !strings.Contains(output, "✗ ERROR: connection refused") {
+		t.Errorf("Expected error status in output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "(slow)") {
+		t.Errorf("Expected slow marker in output, got:\n%s", output)
+	}
+}
+
+func TestDefaultSpanFormatter_ImportantAttributes(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		key      string
+		expected bool
+	}{
+		{nil, "http.method", true},
+		{nil, "db.operation", false},
+		{[]string{"db.*"}, "db.operation", true},
+		{[]string{"db.*"}, "http.method", false},
+		{[]string{"*"}, "anything.at.all", true},
+		{[]string{}, "http.method", false},
+	}
+
+	for _, tt := range tests {
+		formatter := &defaultSpanFormatter{attributes: tt.patterns}
+		if got := formatter.isImportantAttribute(tt.key); got != tt.expected {
+			t.Errorf("isImportantAttribute(%q) with %v = %v, want %v", tt.key, tt.patterns, got, tt.expected)
+		}
+	}
+}
+
+func TestDefaultSpanFormatter_AbsoluteTimes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	start := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	_, span := tp.Tracer("test").Start(context.Background(), "job", trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	formatter := &defaultSpanFormatter{timestamps: timeFormat{layout: time.TimeOnly, utc: true}}
+	output := formatSpans(t, formatter, recorder.Ended())
+	if !strings.Contains(output, "12:30:45 → 12:30:46 =") {
+		t.Errorf("Expected absolute span times, got:\n%s", output)
+	}
+}
+
+func TestDefaultSpanFormatter_MinDuration(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("test")
+
+	start := time.Now()
+	ctx, root := tracer.Start(context.Background(), "request", trace.WithTimestamp(start))
+	_, fast := tracer.Start(ctx, "cache lookup", trace.WithTimestamp(start))
+	fast.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+	_, slow := tracer.Start(ctx, "db query", trace.WithTimestamp(start.Add(time.Millisecond)))
+	slow.End(trace.WithTimestamp(start.Add(80 * time.Millisecond)))
+	root.End(trace.WithTimestamp(start.Add(100 * time.Millisecond)))
+
+	formatter := &defaultSpanFormatter{minDuration: 10 * time.Millisecond}
+	output := formatSpans(t, formatter, recorder.Ended())
+
+	if strings.Contains(output, "cache lookup") {
+		t.Errorf("Expected short span to be hidden, got:\n%s", output)
+	}
+	if !strings.Contains(output, "└─ db query") {
+		t.Errorf("Expected slow span below its parent, got:\n%s", output)
+	}
+	if !strings.Contains(output, "1 spans shorter than 10ms hidden") {
+		t.Errorf("Expected summary of hidden spans, got:\n%s", output)
+	}
+	if !strings.Contains(output, "    1.00 →    80.00") {
+		t.Errorf("Expected offsets relative to the trace start, got:\n%s", output)
+	}
+}
+
+// formatSpans runs formatter and returns its output
+func formatSpans(t *testing.T, formatter SpanFormatter, spans []sdktrace.ReadOnlySpan) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, spans); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestDefaultSpanFormatter_TraceOrder(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("test")
+
+	start := time.Now()
+	_, first := tracer.Start(context.Background(), "first request", trace.WithTimestamp(start))
+	_, second := tracer.Start(context.Background(), "second request", trace.WithTimestamp(start.Add(time.Millisecond)))
+	// The later request ends first, so it is exported first
+	second.End()
+	first.End()
+
+	formatter := &defaultSpanFormatter{}
+	for i := 0; i < 5; i++ {
+		output := formatSpans(t, formatter, recorder.Ended())
+		if strings.Index(output, "first request") > strings.Index(output, "second request") {
+			t.Fatalf("Expected traces ordered by root start time, got:\n%s", output)
+		}
+	}
+}
+
+func TestDefaultSpanFormatter_MaxLength(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "query")
+	span.SetAttributes(attribute.String("db.statement", "SELECT id, name FROM users WHERE id = 1"))
+	span.End()
+
+	formatter := &defaultSpanFormatter{attributes: []string{"db.*"}, maxLength: 6}
+	output := formatSpans(t, formatter, recorder.Ended())
+	if !strings.Contains(output, "SELECT…") || strings.Contains(output, "FROM") {
+		t.Errorf("Expected truncated db.statement, got:\n%s", output)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in       string
+		max      int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated", 5, "trunc…"},
+		{"grüße aus köln", 5, "grüße…"},
+		{"unlimited", 0, "unlimited"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.max); got != tt.expected {
+			t.Errorf("Expected truncate(%q, %d) = %q, got %q", tt.in, tt.max, tt.expected, got)
+		}
+	}
+}
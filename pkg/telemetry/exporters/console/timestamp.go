@@ -0,0 +1,41 @@
+package console
+
+import (
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// timeFormat renders timestamps in a layout and time zone. The zero value
+// uses the formatter's default layout in local time.
+type timeFormat struct {
+	layout string
+	utc    bool
+}
+
+// format renders ts, using fallback unless a layout is configured
+func (t timeFormat) format(ts time.Time, fallback string) string {
+	layout := t.layout
+	if layout == "" {
+		layout = fallback
+	}
+	if t.utc {
+		ts = ts.UTC()
+	} else {
+		ts = ts.Local()
+	}
+	return ts.Format(layout)
+}
+
+// recordTime returns the timestamp of record, falling back to the time it
+// was observed and then to the current time of c for records without one
+func recordTime(record sdklog.Record, c clock.Clock) time.Time {
+	if ts := record.Timestamp(); !ts.IsZero() {
+		return ts
+	}
+	if ts := record.ObservedTimestamp(); !ts.IsZero() {
+		return ts
+	}
+	return clock.OrSystem(c).Now()
+}
@@ -0,0 +1,19 @@
+package console
+
+import "unicode/utf8"
+
+// truncate cuts s to max characters, marking the cut with an ellipsis;
+// max <= 0 keeps s unchanged
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	n := 0
+	for i := range s {
+		if n == max {
+			return s[:i] + "…"
+		}
+		n++
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+package console
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// stdoutMu and stderrMu serialize the writes of all exporters sharing the
+// standard streams, so spans, metrics and logs exported concurrently don't
+// interleave
+var (
+	stdoutMu sync.Mutex
+	stderrMu sync.Mutex
+)
+
+// lockedWriter writes each export with a single, serialized Write call
+type lockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+// newLockedWriter guards w with the lock of its standard stream, or a lock
+// of its own for any other writer
+func newLockedWriter(w io.Writer) *lockedWriter {
+	switch w {
+	case os.Stdout:
+		return &lockedWriter{w: w, mu: &stdoutMu}
+	case os.Stderr:
+		return &lockedWriter{w: w, mu: &stderrMu}
+	default:
+		return &lockedWriter{w: w, mu: &sync.Mutex{}}
+	}
+}
+
+// write writes p while holding the lock
+func (l *lockedWriter) write(p []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.w.Write(p)
+	return err
+}
@@ -0,0 +1,67 @@
+package console
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// overlapWriter records whether two writes ever ran at the same time
+type overlapWriter struct {
+	active  atomic.Int32
+	overlap atomic.Bool
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if w.active.Add(1) > 1 {
+		w.overlap.Store(true)
+	}
+	time.Sleep(time.Millisecond)
+	w.active.Add(-1)
+	return len(p), nil
+}
+
+func TestLogExporter_ConcurrentExports(t *testing.T) {
+	w := &overlapWriter{}
+	exporter := NewLogExporter(WithLogWriter(w))
+	records := []sdklog.Record{createTestLogRecord(log.SeverityInfo, "concurrent")}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := exporter.Export(context.Background(), records); err != nil {
+				t.Errorf("Export failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if w.overlap.Load() {
+		t.Error("Expected exports to be written one at a time")
+	}
+}
+
+func TestWithStderr(t *testing.T) {
+	if NewSpanExporter(WithStderr()).writer != os.Stderr {
+		t.Error("Expected span exporter to write to stderr")
+	}
+	if NewMetricExporter(WithMetricStderr()).writer != os.Stderr {
+		t.Error("Expected metric exporter to write to stderr")
+	}
+	if NewLogExporter(WithLogStderr()).writer != os.Stderr {
+		t.Error("Expected log exporter to write to stderr")
+	}
+
+	// Exporters sharing a standard stream share its lock
+	if NewSpanExporter().out.mu != NewLogExporter().out.mu {
+		t.Error("Expected exporters writing to stdout to share a lock")
+	}
+}
@@ -0,0 +1,323 @@
+// Package pushgateway provides a metric exporter pushing to a Prometheus
+// Pushgateway.
+//
+// Batch jobs and CLIs end before a scraper or a periodic export picks up
+// their metrics. The exporter pushes the cumulative state of all metrics in
+// the Prometheus text format on each export, the last one when the meter
+// provider shuts down, replacing the metrics of the job's grouping key.
+package pushgateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// ContentType is the Prometheus text exposition format pushed
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter pushes metrics to a Pushgateway
+type Exporter struct {
+	url    string
+	client *http.Client
+}
+
+// config holds the exporter settings
+type config struct {
+	job      string
+	grouping map[string]string
+	client   *http.Client
+}
+
+// Option configures the exporter
+type Option func(*config)
+
+// WithJob sets the job of the grouping key; defaults to "job"
+func WithJob(job string) Option {
+	return func(c *config) {
+		c.job = job
+	}
+}
+
+// WithGrouping adds labels to the grouping key, e.g. an instance
+func WithGrouping(labels map[string]string) Option {
+	return func(c *config) {
+		if c.grouping == nil {
+			c.grouping = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			c.grouping[k] = v
+		}
+	}
+}
+
+// WithHTTPClient sets the client pushing the metrics
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// New creates an exporter pushing to the Pushgateway at endpoint, e.g.
+// "http://localhost:9091"
+func New(endpoint string, opts ...Option) (*Exporter, error) {
+	cfg := &config{job: "job", client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pushgateway URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("pushgateway URL %q must use http or https", endpoint)
+	}
+	if cfg.job == "" {
+		return nil, fmt.Errorf("pushgateway job must not be empty")
+	}
+
+	// The grouping key is the path below /metrics
+	path := strings.TrimSuffix(endpoint, "/") + "/metrics/job/" + url.PathEscape(cfg.job)
+	keys := make([]string, 0, len(cfg.grouping))
+	for k := range cfg.grouping {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		path += "/" + sanitizeName(k) + "/" + url.PathEscape(cfg.grouping[k])
+	}
+	return &Exporter{url: path, client: cfg.client}, nil
+}
+
+// Temporality returns cumulative temporality, Prometheus expects totals
+func (e *Exporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	return metricdata.CumulativeTemporality
+}
+
+// Aggregation returns the default aggregation
+func (e *Exporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(kind)
+}
+
+// Export replaces the metrics of the grouping key with rm
+func (e *Exporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, e.url, bytes.NewReader(Encode(rm)))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to push metrics: pushgateway returned %s", resp.Status)
+	}
+	return nil
+}
+
+// ForceFlush does nothing, metrics are pushed synchronously
+func (e *Exporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Shutdown does nothing
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// family collects the samples of one metric name
+type family struct {
+	help    string
+	typ     string
+	samples []string
+}
+
+// Encode renders rm in the Prometheus text format. Monotonic sums become
+// counters with a _total suffix, other sums and gauges gauges; exponential
+// histograms and summaries are skipped.
+func Encode(rm *metricdata.ResourceMetrics) []byte {
+	families := make(map[string]*family)
+	add := func(name, typ, help string) *family {
+		f, ok := families[name]
+		if !ok {
+			f = &family{help: help, typ: typ}
+			families[name] = f
+		}
+		return f
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			name := sanitizeName(m.Name)
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				f := add(sumName(name, data.IsMonotonic), sumType(data.IsMonotonic), m.Description)
+				for _, dp := range data.DataPoints {
+					f.samples = append(f.samples, sample(sumName(name, data.IsMonotonic), dp.Attributes, nil, float64(dp.Value)))
+				}
+			case metricdata.Sum[float64]:
+				f := add(sumName(name, data.IsMonotonic), sumType(data.IsMonotonic), m.Description)
+				for _, dp := range data.DataPoints {
+					f.samples = append(f.samples, sample(sumName(name, data.IsMonotonic), dp.Attributes, nil, dp.Value))
+				}
+			case metricdata.Gauge[int64]:
+				f := add(name, "gauge", m.Description)
+				for _, dp := range data.DataPoints {
+					f.samples = append(f.samples, sample(name, dp.Attributes, nil, float64(dp.Value)))
+				}
+			case metricdata.Gauge[float64]:
+				f := add(name, "gauge", m.Description)
+				for _, dp := range data.DataPoints {
+					f.samples = append(f.samples, sample(name, dp.Attributes, nil, dp.Value))
+				}
+			case metricdata.Histogram[int64]:
+				f := add(name, "histogram", m.Description)
+				for _, dp := range data.DataPoints {
+					f.samples = append(f.samples, histogramSamples(name, dp.Attributes, dp.Bounds, dp.BucketCounts, float64(dp.Sum), dp.Count)...)
+				}
+			case metricdata.Histogram[float64]:
+				f := add(name, "histogram", m.Description)
+				for _, dp := range data.DataPoints {
+					f.samples = append(f.samples, histogramSamples(name, dp.Attributes, dp.Bounds, dp.BucketCounts, dp.Sum, dp.Count)...)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		f := families[name]
+		if f.help != "" {
+			fmt.Fprintf(&buf, "# HELP %s %s\n", name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", name, f.typ)
+		for _, s := range f.samples {
+			buf.WriteString(s)
+		}
+	}
+	return buf.Bytes()
+}
+
+// sumName adds the counter suffix to monotonic sums
+func sumName(name string, monotonic bool) string {
+	if monotonic && !strings.HasSuffix(name, "_total") {
+		return name + "_total"
+	}
+	return name
+}
+
+// sumType maps a sum to its Prometheus type
+func sumType(monotonic bool) string {
+	if monotonic {
+		return "counter"
+	}
+	return "gauge"
+}
+
+// histogramSamples renders the cumulative buckets, sum and count of a
+// histogram data point
+func histogramSamples(name string, set attribute.Set, bounds []float64, counts []uint64, sum float64, count uint64) []string {
+	samples := make([]string, 0, len(bounds)+3)
+	var cumulative uint64
+	for i, bound := range bounds {
+		if i < len(counts) {
+			cumulative += counts[i]
+		}
+		le := attribute.String("le", formatFloat(bound))
+		samples = append(samples, sample(name+"_bucket", set, &le, float64(cumulative)))
+	}
+	inf := attribute.String("le", "+Inf")
+	samples = append(samples,
+		sample(name+"_bucket", set, &inf, float64(count)),
+		sample(name+"_sum", set, nil, sum),
+		sample(name+"_count", set, nil, float64(count)),
+	)
+	return samples
+}
+
+// sample renders one sample line, extra is appended to the labels of set
+func sample(name string, set attribute.Set, extra *attribute.KeyValue, value float64) string {
+	var b strings.Builder
+	b.WriteString(name)
+	labels := set.ToSlice()
+	if extra != nil {
+		labels = append(labels, *extra)
+	}
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, kv := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", sanitizeName(string(kv.Key)), escapeLabel(kv.Value.Emit()))
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// formatFloat renders a sample value
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sanitizeName replaces the characters Prometheus does not allow in metric
+// and label names with underscores
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// escapeLabel escapes a label value
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// escapeHelp escapes a help text
+func escapeHelp(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(v)
+}
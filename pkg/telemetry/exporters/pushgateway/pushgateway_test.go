@@ -0,0 +1,99 @@
+package pushgateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestEncode(t *testing.T) {
+	reader := metric.NewManualReader()
+	meter := metric.NewMeterProvider(metric.WithReader(reader)).Meter("test")
+	ctx := context.Background()
+
+	orders, _ := meter.Int64Counter("orders.created", otelmetric.WithDescription("Orders created"))
+	orders.Add(ctx, 3, otelmetric.WithAttributes(attribute.String("channel", `web "shop"`)))
+	open, _ := meter.Int64UpDownCounter("orders.open")
+	open.Add(ctx, -2)
+	duration, _ := meter.Float64Histogram("job.duration", otelmetric.WithExplicitBucketBoundaries(1, 5))
+	duration.Record(ctx, 0.5)
+	duration.Record(ctx, 3)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+
+	expected := `# TYPE job_duration histogram
+job_duration_bucket{le="1"} 1
+job_duration_bucket{le="5"} 2
+job_duration_bucket{le="+Inf"} 2
+job_duration_sum 3.5
+job_duration_count 2
+# HELP orders_created_total Orders created
+# TYPE orders_created_total counter
+orders_created_total{channel="web \"shop\""} 3
+# TYPE orders_open gauge
+orders_open -2
+`
+	if got := string(Encode(&rm)); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	var method, path, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, contentType = r.Method, r.URL.EscapedPath(), r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	exporter, err := New(server.URL+"/", WithJob("nightly import"), WithGrouping(map[string]string{"instance": "worker-1"}))
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+	provider := metric.NewMeterProvider(metric.WithReader(metric.NewPeriodicReader(exporter)))
+	counter, _ := provider.Meter("test").Int64Counter("rows")
+	counter.Add(context.Background(), 42)
+
+	// Shutting down pushes the final state
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/nightly%20import/instance/worker-1" {
+		t.Errorf("Unexpected request %s %s", method, path)
+	}
+	if contentType != ContentType {
+		t.Errorf("Unexpected content type %q", contentType)
+	}
+	if body != "# TYPE rows_total counter\nrows_total 42\n" {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
+
+func TestExporter_Errors(t *testing.T) {
+	if _, err := New("localhost:9091"); err == nil {
+		t.Error("Expected an error for a URL without scheme")
+	}
+	if _, err := New("http://localhost:9091", WithJob("")); err == nil {
+		t.Error("Expected an error for an empty job")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad metrics", http.StatusBadRequest)
+	}))
+	defer server.Close()
+	exporter, _ := New(server.URL)
+	if err := exporter.Export(context.Background(), &metricdata.ResourceMetrics{}); err == nil {
+		t.Error("Expected an error for a rejected push")
+	}
+}
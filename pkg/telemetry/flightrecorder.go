@@ -0,0 +1,109 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/console"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/flightrecorder"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// initFlightRecorder creates the recorder, added to the providers as they
+// are initialized
+func (t *Telemetry) initFlightRecorder() {
+	cfg := t.config.FlightRecorder
+	t.flightRecorder = flightrecorder.New(
+		flightrecorder.WithMaxSpans(cfg.MaxSpans),
+		flightrecorder.WithMaxLogs(cfg.MaxLogs),
+	)
+}
+
+// FlightRecorder returns the flight recorder, nil if flight_recorder.enabled
+// is not set. Its Dump sends the recording to any exporter.
+func (t *Telemetry) FlightRecorder() *flightrecorder.Recorder {
+	return t.flightRecorder
+}
+
+// WriteFlightRecording writes the recorded spans and logs to w in the
+// console format, with the configured redaction applied
+func (t *Telemetry) WriteFlightRecording(ctx context.Context, w io.Writer) error {
+	if t.flightRecorder == nil {
+		return fmt.Errorf("flight recorder is not enabled")
+	}
+	var spans trace.SpanExporter = console.NewSpanExporter(console.WithWriter(w), console.WithColor(false))
+	if t.redactor != nil {
+		spans = t.redactor.SpanExporter(spans)
+	}
+	logs := console.NewLogExporter(console.WithLogWriter(w), console.WithLogColor(false))
+	return t.flightRecorder.Dump(ctx, spans, logs)
+}
+
+// DumpFlightRecording writes the recording to a new file in
+// flight_recorder.dir and returns its path
+func (t *Telemetry) DumpFlightRecording(ctx context.Context) (string, error) {
+	if t.flightRecorder == nil {
+		return "", fmt.Errorf("flight recorder is not enabled")
+	}
+	dir := t.config.FlightRecorder.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create flight recording directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.WriteFlightRecording(ctx, &buf); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("flight-recording-%s-%s.log", t.config.ServiceName, t.Clock().Now().UTC().Format("20060102T150405.000000000"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write flight recording: %w", err)
+	}
+	t.logger.Printf("flight recording written to %s", path)
+	return path, nil
+}
+
+// FlightRecorderHandler serves the recording on GET and writes it to a
+// file on POST, responding with its path. It responds not found if the
+// flight recorder is disabled. Mount it on an internal port or behind
+// authentication, the recording contains request details.
+func (t *Telemetry) FlightRecorderHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if t.flightRecorder == nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			var buf bytes.Buffer
+			if err := t.WriteFlightRecording(r.Context(), &buf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write(buf.Bytes())
+		case http.MethodPost:
+			path, err := t.DumpFlightRecording(r.Context())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			data, _ := json.Marshal(map[string]string{"file": path})
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
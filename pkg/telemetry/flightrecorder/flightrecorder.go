@@ -0,0 +1,158 @@
+// Package flightrecorder keeps the most recent spans and log records in
+// bounded in-memory ring buffers, to dump them when an incident occurs.
+//
+// The Recorder is a span and log processor. Unlike the exporting
+// processors it also records spans which were not sampled, if the sampler
+// records them (see sampling.Dynamic.SetRecordDropped), and spans dropped
+// by drop rules, so a dump shows what happened right before an incident
+// even at low sampling ratios, similar to a JFR flight recording.
+package flightrecorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Defaults of the ring buffers
+const (
+	DefaultMaxSpans = 10000
+	DefaultMaxLogs  = 10000
+)
+
+// ring is a fixed-size buffer overwriting its oldest item when full
+type ring[T any] struct {
+	items []T
+	next  int
+}
+
+// newRing creates a ring holding up to size items
+func newRing[T any](size int) ring[T] {
+	return ring[T]{items: make([]T, 0, size)}
+}
+
+// add appends item, overwriting the oldest one if the ring is full
+func (r *ring[T]) add(item T) {
+	if len(r.items) < cap(r.items) {
+		r.items = append(r.items, item)
+	} else {
+		r.items[r.next] = item
+	}
+	r.next = (r.next + 1) % cap(r.items)
+}
+
+// snapshot returns the items, oldest first
+func (r *ring[T]) snapshot() []T {
+	items := make([]T, 0, len(r.items))
+	if len(r.items) == cap(r.items) {
+		items = append(items, r.items[r.next:]...)
+		return append(items, r.items[:r.next]...)
+	}
+	return append(items, r.items...)
+}
+
+// Recorder records ended spans and emitted log records into ring buffers
+type Recorder struct {
+	maxSpans int
+	maxLogs  int
+
+	mu    sync.Mutex
+	spans ring[sdktrace.ReadOnlySpan]
+	logs  ring[sdklog.Record]
+}
+
+// Compile-time checks that the recorder can be added to the providers
+var (
+	_ sdktrace.SpanProcessor = (*Recorder)(nil)
+	_ sdklog.Processor       = (*Recorder)(nil)
+)
+
+// Option configures the recorder
+type Option func(*Recorder)
+
+// WithMaxSpans sets the number of spans kept, DefaultMaxSpans if unset
+func WithMaxSpans(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.maxSpans = n
+		}
+	}
+}
+
+// WithMaxLogs sets the number of log records kept, DefaultMaxLogs if unset
+func WithMaxLogs(n int) Option {
+	return func(r *Recorder) {
+		if n > 0 {
+			r.maxLogs = n
+		}
+	}
+}
+
+// New creates a recorder
+func New(opts ...Option) *Recorder {
+	r := &Recorder{maxSpans: DefaultMaxSpans, maxLogs: DefaultMaxLogs}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.spans = newRing[sdktrace.ReadOnlySpan](r.maxSpans)
+	r.logs = newRing[sdklog.Record](r.maxLogs)
+	return r
+}
+
+// OnStart does nothing, spans are recorded when they end
+func (r *Recorder) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+// OnEnd records the span
+func (r *Recorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans.add(s)
+}
+
+// OnEmit records a copy of the log record
+func (r *Recorder) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	clone := record.Clone()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs.add(clone)
+	return nil
+}
+
+// Shutdown does nothing, the recording stays available for a final dump
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (r *Recorder) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Snapshot returns the recorded spans and log records, oldest first
+func (r *Recorder) Snapshot() ([]sdktrace.ReadOnlySpan, []sdklog.Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.spans.snapshot(), r.logs.snapshot()
+}
+
+// Dump exports a snapshot of the recording; a nil exporter skips its
+// signal. The recording is kept, so consecutive dumps overlap.
+func (r *Recorder) Dump(ctx context.Context, spanExporter sdktrace.SpanExporter, logExporter sdklog.Exporter) error {
+	spans, logs := r.Snapshot()
+	var errs []error
+	if spanExporter != nil && len(spans) > 0 {
+		if err := spanExporter.ExportSpans(ctx, spans); err != nil {
+			errs = append(errs, fmt.Errorf("failed to dump spans: %w", err))
+		}
+	}
+	if logExporter != nil && len(logs) > 0 {
+		if err := logExporter.Export(ctx, logs); err != nil {
+			errs = append(errs, fmt.Errorf("failed to dump logs: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,116 @@
+package flightrecorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// logExporter records the exported log records
+type logExporter struct {
+	records []sdklog.Record
+	err     error
+}
+
+func (e *logExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.records = append(e.records, records...)
+	return e.err
+}
+
+func (e *logExporter) Shutdown(ctx context.Context) error   { return nil }
+func (e *logExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func TestRing(t *testing.T) {
+	r := newRing[int](3)
+	if got := r.snapshot(); len(got) != 0 {
+		t.Errorf("Expected an empty snapshot, got %v", got)
+	}
+	for i := 1; i <= 5; i++ {
+		r.add(i)
+	}
+	if got := fmt.Sprint(r.snapshot()); got != "[3 4 5]" {
+		t.Errorf("Expected the newest items oldest first, got %s", got)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := New(WithMaxSpans(2), WithMaxLogs(2))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()), sdktrace.WithSpanProcessor(r))
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(r))
+
+	// Not recorded, the sampler drops the span
+	_, span := tp.Tracer("test").Start(context.Background(), "dropped")
+	span.End()
+
+	recording := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(recordOnly{}),
+		sdktrace.WithSpanProcessor(r),
+	)
+	for i := 0; i < 3; i++ {
+		_, span := recording.Tracer("test").Start(context.Background(), fmt.Sprintf("span-%d", i))
+		span.End()
+
+		var record log.Record
+		record.SetBody(log.StringValue(fmt.Sprintf("message %d", i)))
+		lp.Logger("test").Emit(context.Background(), record)
+	}
+
+	spans, logs := r.Snapshot()
+	if len(spans) != 2 || spans[0].Name() != "span-1" || spans[1].Name() != "span-2" {
+		t.Errorf("Expected the last 2 spans, got %v", spans)
+	}
+	if spans[0].SpanContext().IsSampled() {
+		t.Error("Expected unsampled spans to be recorded")
+	}
+	if len(logs) != 2 || logs[0].Body().AsString() != "message 1" || logs[1].Body().AsString() != "message 2" {
+		t.Errorf("Expected the last 2 logs, got %v", logs)
+	}
+}
+
+// recordOnly records but does not sample spans
+type recordOnly struct{}
+
+func (recordOnly) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordOnly}
+}
+
+func (recordOnly) Description() string { return "RecordOnly" }
+
+func TestDump(t *testing.T) {
+	r := New()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(r))
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(r))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.End()
+	var record log.Record
+	record.SetBody(log.StringValue("failed"))
+	lp.Logger("test").Emit(context.Background(), record)
+
+	spans := tracetest.NewInMemoryExporter()
+	logs := &logExporter{}
+	if err := r.Dump(context.Background(), spans, logs); err != nil {
+		t.Fatalf("Failed to dump: %v", err)
+	}
+	if len(spans.GetSpans()) != 1 || len(logs.records) != 1 {
+		t.Errorf("Expected 1 span and 1 log dumped, got %d and %d", len(spans.GetSpans()), len(logs.records))
+	}
+
+	// The recording is kept
+	if err := r.Dump(context.Background(), nil, logs); err != nil {
+		t.Fatalf("Failed to dump: %v", err)
+	}
+	if len(logs.records) != 2 {
+		t.Errorf("Expected the log to be dumped again, got %d", len(logs.records))
+	}
+
+	logs.err = errors.New("unavailable")
+	if err := r.Dump(context.Background(), nil, logs); err == nil {
+		t.Error("Expected the export error to be returned")
+	}
+}
@@ -0,0 +1,190 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+	"go.opentelemetry.io/otel"
+	otellog "go.opentelemetry.io/otel/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// lastMetrics keeps the last exported metrics
+type lastMetrics struct {
+	rm *metricdata.ResourceMetrics
+}
+
+func (e *lastMetrics) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (e *lastMetrics) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *lastMetrics) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.rm = rm
+	return nil
+}
+
+func (e *lastMetrics) ForceFlush(ctx context.Context) error { return nil }
+func (e *lastMetrics) Shutdown(ctx context.Context) error   { return nil }
+
+// sum returns the sum of the data points of the named counter
+func (e *lastMetrics) sum(name string) int64 {
+	var total int64
+	if e.rm == nil {
+		return 0
+	}
+	for _, sm := range e.rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if data, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == name {
+				for _, dp := range data.DataPoints {
+					total += dp.Value
+				}
+			}
+		}
+	}
+	return total
+}
+
+func TestFlightRecorder(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	dir := t.TempDir()
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.StartupReport = false
+	cfg.Logging.Level = "info"
+	cfg.Tracing.Sampler = &config.SamplerConfig{Kind: "AlwaysOffSampler"}
+	cfg.FlightRecorder = &config.FlightRecorderConfig{Enabled: true, Dir: dir}
+	spans := keptSpans{tracetest.NewInMemoryExporter()}
+	tel, err := New(WithConfig(cfg), WithSpanExporter(spans), WithLogExporter(discardLogs{}), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	ctx, span, end := Start(context.Background(), "GET /books")
+	_, _, endChild := Start(ctx, "SELECT books")
+	endChild(nil)
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("cache miss"))
+	record.SetSeverity(otellog.SeverityDebug)
+	tel.LoggerProvider().Logger("test").Emit(ctx, record)
+	end(nil)
+
+	if span.SpanContext().IsSampled() {
+		t.Error("Expected the span not to be sampled")
+	}
+	recorded, logs := tel.FlightRecorder().Snapshot()
+	if len(recorded) != 2 || len(logs) != 1 {
+		t.Fatalf("Expected 2 unsampled spans and the debug log to be recorded, got %d and %d", len(recorded), len(logs))
+	}
+	if err := tel.TracerProvider().ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	if len(spans.GetSpans()) != 0 {
+		t.Errorf("Expected unsampled spans not to be exported, got %d", len(spans.GetSpans()))
+	}
+
+	handler := tel.FlightRecorderHandler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/flight-recording", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "SELECT books") || !strings.Contains(rec.Body.String(), "cache miss") {
+		t.Errorf("Expected the recording, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/flight-recording", nil))
+	var body struct {
+		File string `json:"file"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, rec.Body.String())
+	}
+	if !strings.HasPrefix(body.File, dir) || !strings.Contains(body.File, "flight-recording-bookshop-") {
+		t.Errorf("Expected a file in %s, got %s", dir, body.File)
+	}
+	data, err := os.ReadFile(body.File)
+	if err != nil || !strings.Contains(string(data), "GET /books") {
+		t.Errorf("Expected the recording in the file, got %v %s", err, data)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/debug/flight-recording", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", rec.Code)
+	}
+}
+
+func TestFlightRecorderDisabled(t *testing.T) {
+	tel := &Telemetry{config: config.NewDefaultConfig(), logger: log.New(io.Discard, "", 0)}
+
+	if _, err := tel.DumpFlightRecording(context.Background()); err == nil {
+		t.Error("Expected an error if the flight recorder is disabled")
+	}
+	rec := httptest.NewRecorder()
+	tel.FlightRecorderHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/flight-recording", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 if the flight recorder is disabled, got %d", rec.Code)
+	}
+}
+
+func TestFlightRecorderKeepsSignals(t *testing.T) {
+	previousTracer, previousMeter := otel.GetTracerProvider(), otel.GetMeterProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousTracer)
+		otel.SetMeterProvider(previousMeter)
+	})
+
+	// run records an unsampled and a forced span, returning the span metrics
+	// calls and the traces on the dashboard
+	run := func(flightRecorder bool) (int64, int) {
+		cfg := config.NewDefaultConfig()
+		cfg.Metrics.HostMetrics = false
+		cfg.Metrics.RuntimeMetrics = false
+		cfg.Tracing.SpanMetrics = true
+		cfg.Tracing.Sampler = &config.SamplerConfig{Kind: "AlwaysOffSampler", DebugHeader: "X-Debug-Trace"}
+		cfg.Dashboard = &config.DashboardConfig{Enabled: true, MaxTraces: 10}
+		cfg.FlightRecorder = &config.FlightRecorderConfig{Enabled: flightRecorder, Dir: t.TempDir()}
+		metrics := &lastMetrics{}
+		tel, err := New(WithConfig(cfg), WithSpanExporter(keptSpans{tracetest.NewInMemoryExporter()}),
+			WithMetricExporter(metrics), WithLogger(log.New(io.Discard, "", 0)))
+		if err != nil {
+			t.Fatalf("failed to create telemetry: %v", err)
+		}
+		defer tel.Shutdown(context.Background())
+
+		_, _, end := Start(context.Background(), "GET /books")
+		end(nil)
+		_, _, end = Start(sampling.ContextWithForceSampling(context.Background()), "GET /authors")
+		end(nil)
+		if err := tel.ForceFlush(context.Background()); err != nil {
+			t.Fatalf("failed to flush: %v", err)
+		}
+		return metrics.sum("traces.span.metrics.calls"), len(tel.dashboard.Traces())
+	}
+
+	calls, traces := run(false)
+	if calls != 1 || traces != 1 {
+		t.Fatalf("Expected only the forced span without flight recorder, got %d calls and %d traces", calls, traces)
+	}
+	if calls, traces := run(true); calls != 1 || traces != 1 {
+		t.Errorf("Expected the flight recorder not to change span metrics and the dashboard, got %d calls and %d traces", calls, traces)
+	}
+}
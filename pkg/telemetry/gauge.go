@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// gauges tracks the current callback registration of each gauge name
+var gauges = struct {
+	mu         sync.Mutex
+	registered map[instrumentKey]*GaugeRegistration
+}{registered: make(map[instrumentKey]*GaugeRegistration)}
+
+// GaugeRegistration is the handle of a gauge callback registered with
+// RegisterGauge
+type GaugeRegistration struct {
+	key          instrumentKey
+	registration metric.Registration
+}
+
+// RegisterGauge registers callback to observe the float64 observable gauge
+// name of the global meter provider on each collection:
+//
+//	reg := telemetry.RegisterGauge("db.pool.idle", func(ctx context.Context) float64 { return float64(db.Stats().Idle) })
+//	defer reg.Unregister()
+//
+// Registering a gauge name again replaces the previous callback. Errors are
+// reported to the global error handler; the gauge is then not observed.
+func RegisterGauge(name string, callback func(ctx context.Context) float64, opts ...metric.Float64ObservableGaugeOption) *GaugeRegistration {
+	provider := otel.GetMeterProvider()
+	r := &GaugeRegistration{key: instrumentKey{provider: provider, kind: "observable_gauge", name: name}}
+
+	meter := provider.Meter(MeterScopeName)
+	gauge, err := meter.Float64ObservableGauge(name, opts...)
+	if err != nil {
+		otel.Handle(fmt.Errorf("failed to create observable gauge %q: %w", name, err))
+		return r
+	}
+
+	gauges.mu.Lock()
+	defer gauges.mu.Unlock()
+	r.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		o.ObserveFloat64(gauge, callback(ctx))
+		return nil
+	}, gauge)
+	if err != nil {
+		otel.Handle(fmt.Errorf("failed to register observable gauge %q: %w", name, err))
+		return r
+	}
+	if previous, ok := gauges.registered[r.key]; ok {
+		if err := previous.registration.Unregister(); err != nil {
+			otel.Handle(fmt.Errorf("failed to unregister observable gauge %q: %w", name, err))
+		}
+	}
+	gauges.registered[r.key] = r
+	return r
+}
+
+// Unregister stops observing the gauge. It does nothing if the callback
+// was already unregistered or replaced.
+func (r *GaugeRegistration) Unregister() error {
+	gauges.mu.Lock()
+	defer gauges.mu.Unlock()
+	if gauges.registered[r.key] != r {
+		return nil
+	}
+	delete(gauges.registered, r.key)
+	if err := r.registration.Unregister(); err != nil {
+		return fmt.Errorf("failed to unregister observable gauge %q: %w", r.key.name, err)
+	}
+	return nil
+}
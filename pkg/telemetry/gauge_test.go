@@ -0,0 +1,43 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterGauge(t *testing.T) {
+	reader := recordGlobalMetrics(t)
+	gaugeValue := func() (float64, bool) {
+		data, ok := collectMetrics(t, reader)["cache.entries"]
+		if !ok || len(data.(metricdata.Gauge[float64]).DataPoints) == 0 {
+			return 0, false
+		}
+		return data.(metricdata.Gauge[float64]).DataPoints[0].Value, true
+	}
+
+	first := RegisterGauge("cache.entries", func(ctx context.Context) float64 { return 10 })
+	if v, ok := gaugeValue(); !ok || v != 10 {
+		t.Errorf("Expected 10 entries, got %v", v)
+	}
+
+	// Registering again replaces the callback
+	second := RegisterGauge("cache.entries", func(ctx context.Context) float64 { return 20 })
+	if v, ok := gaugeValue(); !ok || v != 20 {
+		t.Errorf("Expected 20 entries, got %v", v)
+	}
+	if err := first.Unregister(); err != nil {
+		t.Errorf("Expected no error for a replaced registration, got %v", err)
+	}
+	if v, ok := gaugeValue(); !ok || v != 20 {
+		t.Errorf("Expected the replacing callback to stay registered, got %v", v)
+	}
+
+	if err := second.Unregister(); err != nil {
+		t.Fatalf("failed to unregister: %v", err)
+	}
+	if v, ok := gaugeValue(); ok {
+		t.Errorf("Expected no observation after unregistering, got %v", v)
+	}
+}
@@ -0,0 +1,23 @@
+package telemetry
+
+import (
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/gcevents"
+)
+
+// initGCEvents starts logging GC pauses and memory pressure through the
+// logger provider; with span events, the watcher tracks the active spans
+func (t *Telemetry) initGCEvents() {
+	cfg := t.config.Logging.RuntimeEvents
+	opts := []gcevents.Option{
+		gcevents.WithLoggerProvider(t.loggerProvider),
+		gcevents.WithPauseThreshold(cfg.GetGCPauseThreshold()),
+		gcevents.WithSpanEvents(cfg.SpanEvents),
+	}
+	if cfg.HeapWatermarkBytes > 0 {
+		opts = append(opts, gcevents.WithHeapWatermark(uint64(cfg.HeapWatermarkBytes)))
+	}
+	t.gcWatcher = gcevents.Start(opts...)
+	if t.tracerProvider != nil {
+		t.tracerProvider.RegisterSpanProcessor(t.gcWatcher)
+	}
+}
@@ -0,0 +1,338 @@
+// Package gcevents emits log records when a garbage collection pause
+// exceeds a threshold or the live heap grows beyond a watermark,
+// complementing the runtime gauges with events at the moment they happen.
+//
+// The Watcher is woken after every garbage collection. A pause is not
+// caused by a particular request, so the records carry the trace context
+// only while a single local root span is active; as a span processor the
+// watcher can additionally add the events to all active local root spans.
+package gcevents
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the records
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/gcevents"
+
+// Event names of the records and span events
+const (
+	EventGCPause       = "runtime.go.gc.pause"
+	EventHeapWatermark = "runtime.go.heap.watermark"
+)
+
+// Attribute keys of the events
+const (
+	GCCountKey       = "runtime.go.gc.count"
+	GCPauseKey       = "runtime.go.gc.pause_ns"
+	GCThresholdKey   = "runtime.go.gc.pause_threshold_ns"
+	HeapLiveKey      = "runtime.go.mem.heap_live"
+	HeapWatermarkKey = "runtime.go.mem.heap_watermark"
+)
+
+// Defaults of the watcher
+const (
+	DefaultPauseThreshold = 10 * time.Millisecond
+	DefaultMaxSpans       = 1000
+	// DefaultMemoryLimitRatio sets the default watermark relative to the
+	// soft memory limit (GOMEMLIMIT), if one is set
+	DefaultMemoryLimitRatio = 0.9
+)
+
+// heapLive is the runtime/metrics heap marked live by the last collection
+const heapLive = "/gc/heap/live:bytes"
+
+// Watcher emits GC pause and heap watermark events
+type Watcher struct {
+	logger         log.Logger
+	pauseThreshold time.Duration
+	watermark      uint64
+	spanEvents     bool
+	maxSpans       int
+
+	gc      chan struct{}
+	done    chan struct{}
+	stopped atomic.Bool
+	wg      sync.WaitGroup
+
+	// lastNumGC and aboveWatermark are only used by the watch goroutine
+	lastNumGC      int64
+	aboveWatermark bool
+	stats          debug.GCStats
+	// readHeapLive returns the live heap bytes
+	readHeapLive func() uint64
+
+	mu    sync.Mutex
+	spans map[trace.SpanID]sdktrace.ReadWriteSpan
+}
+
+// Compile-time check that the watcher can be added to a tracer provider
+var _ sdktrace.SpanProcessor = (*Watcher)(nil)
+
+// config holds the watcher settings
+type config struct {
+	loggerProvider log.LoggerProvider
+	pauseThreshold time.Duration
+	watermark      uint64
+	spanEvents     bool
+	maxSpans       int
+}
+
+// Option configures the watcher
+type Option func(*config)
+
+// WithLoggerProvider sets the logger provider the records are emitted through
+func WithLoggerProvider(lp log.LoggerProvider) Option {
+	return func(c *config) {
+		c.loggerProvider = lp
+	}
+}
+
+// WithPauseThreshold sets the GC pause duration above which an event is
+// emitted, DefaultPauseThreshold if unset
+func WithPauseThreshold(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.pauseThreshold = d
+		}
+	}
+}
+
+// WithHeapWatermark sets the live heap bytes above which an event is
+// emitted. It defaults to DefaultMemoryLimitRatio of the soft memory limit;
+// without limit, heap events are only emitted with a watermark.
+func WithHeapWatermark(bytes uint64) Option {
+	return func(c *config) {
+		c.watermark = bytes
+	}
+}
+
+// WithSpanEvents adds the events to the active local root spans as well
+func WithSpanEvents(enabled bool) Option {
+	return func(c *config) {
+		c.spanEvents = enabled
+	}
+}
+
+// WithMaxSpans sets the number of active spans tracked for span events,
+// DefaultMaxSpans if unset
+func WithMaxSpans(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxSpans = n
+		}
+	}
+}
+
+// Start starts watching garbage collections
+func Start(opts ...Option) *Watcher {
+	cfg := &config{
+		pauseThreshold: DefaultPauseThreshold,
+		maxSpans:       DefaultMaxSpans,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.loggerProvider == nil {
+		cfg.loggerProvider = global.GetLoggerProvider()
+	}
+	if cfg.watermark == 0 {
+		if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+			cfg.watermark = uint64(float64(limit) * DefaultMemoryLimitRatio)
+		}
+	}
+
+	w := newWatcher(cfg)
+	w.wg.Add(1)
+	go w.watch()
+	w.arm()
+	return w
+}
+
+// newWatcher creates a watcher which is not yet notified of collections
+func newWatcher(cfg *config) *Watcher {
+	w := &Watcher{
+		logger:         cfg.loggerProvider.Logger(ScopeName),
+		pauseThreshold: cfg.pauseThreshold,
+		watermark:      cfg.watermark,
+		spanEvents:     cfg.spanEvents,
+		maxSpans:       cfg.maxSpans,
+		gc:             make(chan struct{}, 1),
+		done:           make(chan struct{}),
+		readHeapLive:   readHeapLive,
+		spans:          make(map[trace.SpanID]sdktrace.ReadWriteSpan),
+	}
+	debug.ReadGCStats(&w.stats)
+	w.lastNumGC = w.stats.NumGC
+	return w
+}
+
+// sentinel is finalized by the next garbage collection. It holds a pointer
+// so that it is not batched with other tiny allocations.
+type sentinel struct {
+	_ *int
+}
+
+// arm sets up the notification of the next garbage collection
+func (w *Watcher) arm() {
+	runtime.SetFinalizer(&sentinel{}, func(*sentinel) {
+		if w.stopped.Load() {
+			return
+		}
+		select {
+		case w.gc <- struct{}{}:
+		default:
+		}
+		w.arm()
+	})
+}
+
+// watch checks the collections as they are notified
+func (w *Watcher) watch() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.gc:
+			w.check()
+		}
+	}
+}
+
+// check emits the events of the collections since the previous check
+func (w *Watcher) check() {
+	debug.ReadGCStats(&w.stats)
+	// Pause lists the most recent pauses first
+	n := int(min(w.stats.NumGC-w.lastNumGC, int64(len(w.stats.Pause))))
+	for i := n - 1; i >= 0; i-- {
+		if pause := w.stats.Pause[i]; pause > w.pauseThreshold {
+			w.emit(EventGCPause, log.SeverityWarn,
+				fmt.Sprintf("GC pause of %s exceeded %s", pause, w.pauseThreshold),
+				log.Int64(GCCountKey, w.stats.NumGC-int64(i)),
+				log.Int64(GCPauseKey, pause.Nanoseconds()),
+				log.Int64(GCThresholdKey, w.pauseThreshold.Nanoseconds()),
+			)
+		}
+	}
+	w.lastNumGC = w.stats.NumGC
+
+	if w.watermark == 0 {
+		return
+	}
+	live := w.readHeapLive()
+	switch {
+	case live > w.watermark && !w.aboveWatermark:
+		w.aboveWatermark = true
+		w.emit(EventHeapWatermark, log.SeverityWarn,
+			fmt.Sprintf("live heap of %d bytes exceeded the watermark of %d bytes", live, w.watermark),
+			log.Int64(GCCountKey, w.stats.NumGC),
+			log.Int64(HeapLiveKey, int64(live)),
+			log.Int64(HeapWatermarkKey, int64(w.watermark)),
+		)
+	case live <= w.watermark:
+		w.aboveWatermark = false
+	}
+}
+
+// emit emits a record, in the trace of the only active local root span if
+// there is one, and adds the event to the active spans with span events
+func (w *Watcher) emit(event string, severity log.Severity, body string, attrs ...log.KeyValue) {
+	ctx := context.Background()
+	w.mu.Lock()
+	spans := make([]sdktrace.ReadWriteSpan, 0, len(w.spans))
+	for _, span := range w.spans {
+		spans = append(spans, span)
+	}
+	w.mu.Unlock()
+	if len(spans) == 1 {
+		ctx = trace.ContextWithSpan(ctx, spans[0])
+	}
+
+	var r log.Record
+	r.SetEventName(event)
+	r.SetSeverity(severity)
+	r.SetBody(log.StringValue(body))
+	r.AddAttributes(attrs...)
+	w.logger.Emit(ctx, r)
+
+	if !w.spanEvents {
+		return
+	}
+	opt := trace.WithAttributes(spanAttributes(attrs)...)
+	for _, span := range spans {
+		span.AddEvent(event, opt)
+	}
+}
+
+// OnStart tracks local root spans while they are active
+func (w *Watcher) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if !s.IsRecording() {
+		return
+	}
+	if p := trace.SpanContextFromContext(parent); p.IsValid() && !p.IsRemote() {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.spans) < w.maxSpans {
+		w.spans[s.SpanContext().SpanID()] = s
+	}
+}
+
+// OnEnd stops tracking the span
+func (w *Watcher) OnEnd(s sdktrace.ReadOnlySpan) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.spans, s.SpanContext().SpanID())
+}
+
+// ForceFlush does nothing
+func (w *Watcher) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Shutdown stops watching garbage collections
+func (w *Watcher) Shutdown(ctx context.Context) error {
+	if w.stopped.Swap(true) {
+		return nil
+	}
+	close(w.done)
+	w.wg.Wait()
+	return nil
+}
+
+// spanAttributes converts the integer attributes of a record to span attributes
+func spanAttributes(attrs []log.KeyValue) []attribute.KeyValue {
+	converted := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		if kv.Value.Kind() == log.KindInt64 {
+			converted = append(converted, attribute.Int64(kv.Key, kv.Value.AsInt64()))
+		}
+	}
+	return converted
+}
+
+// readHeapLive reads the live heap bytes from runtime/metrics
+func readHeapLive() uint64 {
+	sample := []metrics.Sample{{Name: heapLive}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
@@ -0,0 +1,173 @@
+package gcevents
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recorder records the emitted log records
+type recorder struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (r *recorder) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record.Clone())
+	return nil
+}
+
+func (r *recorder) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool { return true }
+func (r *recorder) Shutdown(ctx context.Context) error                               { return nil }
+func (r *recorder) ForceFlush(ctx context.Context) error                             { return nil }
+
+// events returns the recorded records with the given event name
+func (r *recorder) events(name string) []sdklog.Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var records []sdklog.Record
+	for _, record := range r.records {
+		if record.EventName() == name {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// attr returns the int64 attribute key of record
+func attr(record sdklog.Record, key string) int64 {
+	var value int64
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == key {
+			value = kv.Value.AsInt64()
+			return false
+		}
+		return true
+	})
+	return value
+}
+
+func TestWatcherGCPause(t *testing.T) {
+	records := &recorder{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(records))
+	w := Start(WithLoggerProvider(lp), WithPauseThreshold(time.Nanosecond))
+	defer w.Shutdown(context.Background())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(records.events(EventGCPause)) == 0 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	events := records.events(EventGCPause)
+	if len(events) == 0 {
+		t.Fatal("Expected a GC pause event")
+	}
+	if events[0].Severity() != log.SeverityWarn {
+		t.Errorf("Expected severity WARN, got %v", events[0].Severity())
+	}
+	if attr(events[0], GCPauseKey) <= 0 || attr(events[0], GCCountKey) <= 0 {
+		t.Errorf("Expected the pause and GC count attributes, got %v", events[0])
+	}
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a second shutdown to succeed, got %v", err)
+	}
+}
+
+func TestWatcherHeapWatermark(t *testing.T) {
+	records := &recorder{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(records))
+	w := newWatcher(&config{
+		loggerProvider: lp,
+		pauseThreshold: time.Hour,
+		watermark:      100,
+		maxSpans:       DefaultMaxSpans,
+	})
+	live := uint64(50)
+	w.readHeapLive = func() uint64 { return live }
+
+	w.check()
+	live = 150
+	w.check()
+	w.check()
+	if events := records.events(EventHeapWatermark); len(events) != 1 {
+		t.Fatalf("Expected 1 event while above the watermark, got %d", len(events))
+	}
+	event := records.events(EventHeapWatermark)[0]
+	if attr(event, HeapLiveKey) != 150 || attr(event, HeapWatermarkKey) != 100 {
+		t.Errorf("Expected the live heap and watermark attributes, got %v", event)
+	}
+
+	live = 80
+	w.check()
+	live = 120
+	w.check()
+	if events := records.events(EventHeapWatermark); len(events) != 2 {
+		t.Errorf("Expected another event after dropping below the watermark, got %d", len(events))
+	}
+	if events := records.events(EventGCPause); len(events) != 0 {
+		t.Errorf("Expected no pause events below the threshold, got %d", len(events))
+	}
+}
+
+func TestWatcherSpanCorrelation(t *testing.T) {
+	records := &recorder{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(records))
+	w := newWatcher(&config{
+		loggerProvider: lp,
+		pauseThreshold: time.Hour,
+		watermark:      100,
+		spanEvents:     true,
+		maxSpans:       DefaultMaxSpans,
+	})
+	w.readHeapLive = func() uint64 { return 150 }
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(w), sdktrace.WithSpanProcessor(spans))
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /orders")
+	_, child := tracer.Start(ctx, "SELECT orders")
+	w.check()
+	child.End()
+	root.End()
+
+	event := records.events(EventHeapWatermark)[0]
+	if event.TraceID() != root.SpanContext().TraceID() {
+		t.Error("Expected the record in the trace of the only active root span")
+	}
+	for _, span := range spans.Ended() {
+		want := 0
+		if span.Name() == "GET /orders" {
+			want = 1
+		}
+		if len(span.Events()) != want {
+			t.Errorf("Expected %d events on %s, got %d", want, span.Name(), len(span.Events()))
+		}
+	}
+
+	// With several active traces the record is not correlated
+	w.aboveWatermark = false
+	_, first := tracer.Start(context.Background(), "first")
+	_, second := tracer.Start(context.Background(), "second")
+	w.check()
+	first.End()
+	second.End()
+	if event := records.events(EventHeapWatermark)[1]; event.TraceID().IsValid() {
+		t.Error("Expected no trace context with several active traces")
+	}
+	if len(w.spans) != 0 {
+		t.Errorf("Expected ended spans not to be tracked, got %d", len(w.spans))
+	}
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"log"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/gcevents"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestGCEvents(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.RuntimeEvents = &config.RuntimeEventsConfig{Enabled: true, HeapWatermarkBytes: 1, SpanEvents: true}
+	spans := keptSpans{tracetest.NewInMemoryExporter()}
+	logs := &recordingLogExporter{}
+	tel, err := New(WithConfig(cfg), WithSpanExporter(spans), WithLogExporter(logs), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	_, span, end := Start(context.Background(), "POST /imports")
+	found := func() bool {
+		if err := tel.LoggerProvider().ForceFlush(context.Background()); err != nil {
+			t.Fatalf("failed to flush: %v", err)
+		}
+		logs.mu.Lock()
+		defer logs.mu.Unlock()
+		for _, r := range logs.records {
+			if r.EventName() == gcevents.EventHeapWatermark {
+				return r.TraceID() == span.SpanContext().TraceID()
+			}
+		}
+		return false
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !found() && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	end(nil)
+	if !found() {
+		t.Fatal("Expected a heap watermark event in the trace of the active span")
+	}
+
+	if err := tel.TracerProvider().ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	exported := spans.GetSpans()
+	if len(exported) != 1 || len(exported[0].Events) == 0 || exported[0].Events[0].Name != gcevents.EventHeapWatermark {
+		t.Errorf("Expected the event on the active span, got %v", exported)
+	}
+}
@@ -0,0 +1,43 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// installGlobals sets the providers and propagator of the enabled signals as
+// the OpenTelemetry globals. The returned function restores the previous ones.
+func (t *Telemetry) installGlobals() (restore func()) {
+	tracerProvider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	meterProvider := otel.GetMeterProvider()
+	loggerProvider := global.GetLoggerProvider()
+
+	if t.globalTracer != nil {
+		otel.SetTracerProvider(t.globalTracer)
+	}
+	if t.propagator != nil {
+		otel.SetTextMapPropagator(t.propagator)
+	}
+	if t.meterProvider != nil {
+		otel.SetMeterProvider(t.meterProvider)
+	}
+	if t.loggerProvider != nil {
+		global.SetLoggerProvider(t.loggerProvider)
+	}
+
+	return func() {
+		if t.globalTracer != nil {
+			otel.SetTracerProvider(tracerProvider)
+		}
+		if t.propagator != nil {
+			otel.SetTextMapPropagator(propagator)
+		}
+		if t.meterProvider != nil {
+			otel.SetMeterProvider(meterProvider)
+		}
+		if t.loggerProvider != nil {
+			global.SetLoggerProvider(loggerProvider)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Health states of the telemetry
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// Health reports whether all signals export as configured
+type Health struct {
+	Status string `json:"status"`
+	// Degraded lists the signals which fell back to the console exporter
+	Degraded []DegradedSignal `json:"degraded,omitempty"`
+}
+
+// DegradedSignal is a signal whose exporter could not be created on startup
+type DegradedSignal struct {
+	Signal   string `json:"signal"`
+	Error    string `json:"error"`
+	Fallback string `json:"fallback"`
+}
+
+// Health returns whether the signals export as configured; with fail_open,
+// exporter errors on startup degrade the affected signals instead of
+// failing New
+func (t *Telemetry) Health() Health {
+	if len(t.degraded) == 0 {
+		return Health{Status: HealthOK}
+	}
+	return Health{
+		Status:   HealthDegraded,
+		Degraded: append([]DegradedSignal(nil), t.degraded...),
+	}
+}
+
+// HealthHandler serves Health as JSON. It responds OK while degraded as
+// well, so that missing telemetry doesn't fail the liveness of the
+// application; check the status in the document.
+func (t *Telemetry) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		data, err := json.Marshal(t.Health())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(data)
+	})
+}
+
+// failOpen reports whether New continues after the exporter of signal
+// failed with err, and records the signal as degraded if so
+func (t *Telemetry) failOpen(signal string, err error) bool {
+	if !t.config.IsFailOpen() {
+		return false
+	}
+	t.logger.Printf("failed to create %s exporter, falling back to console: %v", signal, err)
+	t.degraded = append(t.degraded, DegradedSignal{
+		Signal:   signal,
+		Error:    err.Error(),
+		Fallback: "console",
+	})
+	return true
+}
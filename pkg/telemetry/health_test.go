@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+func TestFailOpen(t *testing.T) {
+	previousTracer, previousMeter, previousLogger := otel.GetTracerProvider(), otel.GetMeterProvider(), global.GetLoggerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousTracer)
+		otel.SetMeterProvider(previousMeter)
+		global.SetLoggerProvider(previousLogger)
+	})
+
+	cfg := config.NewDefaultConfig()
+	cfg.Kind = "telemetry-to-otlp"
+	cfg.ServiceName = "bookshop"
+	cfg.Tracing.Exporter = &config.ExporterConfig{Module: "otlp-env"}
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.StartupReport = false
+	cfg.Logging.Exporter = &config.ExporterConfig{Module: "console"}
+	tel, err := New(WithConfig(cfg), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("Expected New to fail open, got %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	health := tel.Health()
+	if health.Status != HealthDegraded || len(health.Degraded) != 1 {
+		t.Fatalf("Expected degraded tracing, got %+v", health)
+	}
+	if d := health.Degraded[0]; d.Signal != "tracing" || d.Fallback != "console" || d.Error != "unsupported trace exporter: otlp-env" {
+		t.Errorf("Expected tracing to fall back to console, got %+v", d)
+	}
+	if tel.TracerProvider() == nil {
+		t.Error("Expected a tracer provider with the fallback exporter")
+	}
+
+	rec := httptest.NewRecorder()
+	tel.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/telemetry", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 while degraded, got %d", rec.Code)
+	}
+	var body Health
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if body.Status != HealthDegraded || len(body.Degraded) != 1 {
+		t.Errorf("Expected the degraded signal in the response, got %s", rec.Body.String())
+	}
+}
+
+func TestFailClosed(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Kind = "telemetry-to-otlp"
+	cfg.Tracing.Exporter = &config.ExporterConfig{Module: "otlp-env"}
+	cfg.Metrics.Enabled = false
+	failOpen := false
+	cfg.FailOpen = &failOpen
+	if _, err := New(WithConfig(cfg), WithLogger(log.New(io.Discard, "", 0))); err == nil {
+		t.Error("Expected New to fail without fail_open")
+	}
+
+	cfg.FailOpen = nil
+	cfg.Kind = config.DevelopmentKind
+	if _, err := New(WithConfig(cfg), WithLogger(log.New(io.Discard, "", 0))); err == nil {
+		t.Error("Expected New to fail for telemetry-to-console by default")
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	tel := &Telemetry{config: config.NewDefaultConfig(), logger: log.New(io.Discard, "", 0)}
+
+	rec := httptest.NewRecorder()
+	tel.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/telemetry", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"status":"ok"}` {
+		t.Errorf("Expected ok, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	tel.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/health/telemetry", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/heartbeat"
+)
+
+// initHeartbeat starts the heartbeat monitor with a probe per configured URL
+func (t *Telemetry) initHeartbeat() error {
+	cfg := t.config.Heartbeat
+	opts := []heartbeat.Option{
+		heartbeat.WithInterval(cfg.GetInterval()),
+		heartbeat.WithTimeout(cfg.GetTimeout()),
+	}
+	if t.tracerProvider != nil {
+		opts = append(opts, heartbeat.WithTracerProvider(t.tracerProvider))
+	}
+	if t.meterProvider != nil {
+		opts = append(opts, heartbeat.WithMeterProvider(t.meterProvider))
+	}
+
+	names := make([]string, 0, len(cfg.URLs))
+	for name, u := range cfg.URLs {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("invalid heartbeat URL of probe %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	monitor, err := heartbeat.Start(opts...)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := monitor.Register(name, heartbeat.HTTPProbe(nil, cfg.URLs[name])); err != nil {
+			return err
+		}
+	}
+	t.heartbeat = monitor
+	return nil
+}
+
+// Heartbeat returns the heartbeat monitor, nil if heartbeat.enabled is not
+// set. Register probes of the dependencies on it:
+//
+//	tel.Heartbeat().Register("db", heartbeat.PingProbe(db))
+func (t *Telemetry) Heartbeat() *heartbeat.Monitor {
+	return t.heartbeat
+}
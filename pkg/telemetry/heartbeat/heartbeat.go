@@ -0,0 +1,339 @@
+// Package heartbeat runs synthetic transactions: registered probes, such as
+// an HTTP ping or a database query, are executed periodically, each run is
+// traced as a root span and recorded in availability metrics, which gives
+// basic uptime SLO data without extra tooling.
+//
+// The availability of a probe over a period is the ratio of successful runs
+// in heartbeat.probe.runs; heartbeat.probe.up holds the result of its last
+// run.
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans and metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/heartbeat"
+
+// Attribute keys of the spans and metrics
+const (
+	ProbeNameKey = "heartbeat.probe.name"
+	OutcomeKey   = "heartbeat.probe.outcome"
+	// SyntheticTypeKey marks the spans as synthetic, as semantic
+	// conventions do for synthetic HTTP requests
+	SyntheticTypeKey = "user_agent.synthetic.type"
+)
+
+// Outcomes of a probe run
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Defaults of the monitor
+const (
+	DefaultInterval = time.Minute
+	DefaultTimeout  = 10 * time.Second
+)
+
+// Probe checks a dependency or the service itself; a returned error marks
+// the run as failed. It must respect the deadline of ctx.
+type Probe func(ctx context.Context) error
+
+// Result is the outcome of the last run of a probe
+type Result struct {
+	Name     string        `json:"name"`
+	Up       bool          `json:"up"`
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// probe is a registered probe and its last result
+type probe struct {
+	name   string
+	run    Probe
+	result Result
+	ran    bool
+}
+
+// Monitor runs the registered probes every interval until Shutdown
+type Monitor struct {
+	tracer       trace.Tracer
+	runs         metric.Int64Counter
+	duration     metric.Float64Histogram
+	registration metric.Registration
+	interval     time.Duration
+	timeout      time.Duration
+
+	mu     sync.Mutex
+	probes map[string]*probe
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// config holds the monitor settings
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	interval       time.Duration
+	timeout        time.Duration
+}
+
+// Option configures the monitor
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider the runs are traced with
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider the instruments are registered on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithInterval sets how often the probes run, DefaultInterval if unset
+func WithInterval(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// WithTimeout sets the deadline of a probe run, DefaultTimeout if unset; it
+// is capped to the interval
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// Start registers the instruments and starts running probes; probes are
+// added with Register
+func Start(opts ...Option) (*Monitor, error) {
+	cfg := &config{
+		interval: DefaultInterval,
+		timeout:  DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	m, err := newMonitor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	go m.run()
+	return m, nil
+}
+
+// newMonitor creates a monitor which does not run the probes yet
+func newMonitor(cfg *config) (*Monitor, error) {
+	m := &Monitor{
+		tracer:   cfg.tracerProvider.Tracer(ScopeName),
+		interval: cfg.interval,
+		timeout:  min(cfg.timeout, cfg.interval),
+		probes:   make(map[string]*probe),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	var err error
+	m.runs, err = meter.Int64Counter("heartbeat.probe.runs",
+		metric.WithDescription("Runs of the heartbeat probes by outcome"),
+		metric.WithUnit("{run}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heartbeat.probe.runs: %w", err)
+	}
+	m.duration, err = meter.Float64Histogram("heartbeat.probe.duration",
+		metric.WithDescription("Duration of the heartbeat probe runs"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heartbeat.probe.duration: %w", err)
+	}
+	up, err := meter.Int64ObservableGauge("heartbeat.probe.up",
+		metric.WithDescription("Whether the last run of the heartbeat probe succeeded (1) or failed (0)"),
+		metric.WithUnit("1"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heartbeat.probe.up: %w", err)
+	}
+	m.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, r := range m.Results() {
+			value := int64(0)
+			if r.Up {
+				value = 1
+			}
+			o.ObserveInt64(up, value, metric.WithAttributes(attribute.String(ProbeNameKey, r.Name)))
+		}
+		return nil
+	}, up)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register heartbeat callback: %w", err)
+	}
+	return m, nil
+}
+
+// Register adds a probe, first run on the next tick of the monitor
+func (m *Monitor) Register(name string, p Probe) error {
+	if name == "" {
+		return errors.New("probe name must not be empty")
+	}
+	if p == nil {
+		return fmt.Errorf("probe %s must not be nil", name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.probes[name]; ok {
+		return fmt.Errorf("probe %s already registered", name)
+	}
+	m.probes[name] = &probe{name: name, run: p}
+	return nil
+}
+
+// Unregister removes a probe
+func (m *Monitor) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.probes, name)
+}
+
+// Results returns the last results of the probes which ran, sorted by name
+func (m *Monitor) Results() []Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make([]Result, 0, len(m.probes))
+	for _, p := range m.probes {
+		if p.ran {
+			results = append(results, p.result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// run runs the probes every interval until Shutdown
+func (m *Monitor) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.runProbes(context.Background())
+		}
+	}
+}
+
+// runProbes runs all registered probes concurrently and waits for them
+func (m *Monitor) runProbes(ctx context.Context) {
+	m.mu.Lock()
+	probes := make([]*probe, 0, len(m.probes))
+	for _, p := range m.probes {
+		probes = append(probes, p)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.runProbe(ctx, p)
+		}()
+	}
+	wg.Wait()
+}
+
+// runProbe runs a probe as synthetic transaction and records its result
+func (m *Monitor) runProbe(ctx context.Context, p *probe) {
+	name := attribute.String(ProbeNameKey, p.name)
+	ctx, span := m.tracer.Start(ctx, "heartbeat "+p.name,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(name, attribute.String(SyntheticTypeKey, "test")))
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	start := time.Now()
+	err := m.call(ctx, p.run)
+	elapsed := time.Since(start)
+	cancel()
+
+	result := Result{Name: p.name, Up: err == nil, Time: start, Duration: elapsed}
+	outcome := OutcomeSuccess
+	if err != nil {
+		result.Error = err.Error()
+		outcome = OutcomeFailure
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(attribute.String(OutcomeKey, outcome))
+	span.End()
+
+	attrs := metric.WithAttributes(name, attribute.String(OutcomeKey, outcome))
+	m.runs.Add(ctx, 1, attrs)
+	m.duration.Record(ctx, elapsed.Seconds(), attrs)
+
+	m.mu.Lock()
+	p.result, p.ran = result, true
+	m.mu.Unlock()
+}
+
+// call runs a probe, turning a panic into a failed run
+func (m *Monitor) call(ctx context.Context, run Probe) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("probe panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// Shutdown stops running probes and waits for a running round
+func (m *Monitor) Shutdown(ctx context.Context) error {
+	stopped := false
+	m.once.Do(func() {
+		close(m.stop)
+		stopped = true
+	})
+	if !stopped {
+		return errors.New("heartbeat monitor already shut down")
+	}
+	if err := m.registration.Unregister(); err != nil {
+		return fmt.Errorf("failed to unregister heartbeat callback: %w", err)
+	}
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
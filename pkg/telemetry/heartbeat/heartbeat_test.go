@@ -0,0 +1,157 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestMonitor creates a monitor recording to in-memory providers
+func newTestMonitor(t *testing.T) (*Monitor, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	m, err := newMonitor(&config{
+		tracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)),
+		meterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		interval:       time.Minute,
+		timeout:        time.Second,
+	})
+	if err != nil {
+		t.Fatalf("failed to create monitor: %v", err)
+	}
+	return m, spans, reader
+}
+
+func TestMonitor(t *testing.T) {
+	m, spans, reader := newTestMonitor(t)
+	if err := m.Register("db", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("failed to register probe: %v", err)
+	}
+	if err := m.Register("orders", func(ctx context.Context) error { return errors.New("connection refused") }); err != nil {
+		t.Fatalf("failed to register probe: %v", err)
+	}
+	if err := m.Register("db", func(ctx context.Context) error { return nil }); err == nil {
+		t.Error("Expected an error registering a probe twice")
+	}
+
+	m.runProbes(context.Background())
+	m.runProbes(context.Background())
+
+	results := m.Results()
+	if len(results) != 2 || results[0].Name != "db" || !results[0].Up || results[1].Up || results[1].Error != "connection refused" {
+		t.Errorf("Expected db up and orders down, got %+v", results)
+	}
+
+	ended := spans.GetSpans()
+	if len(ended) != 4 {
+		t.Fatalf("Expected a span per run, got %d", len(ended))
+	}
+	for _, s := range ended {
+		if s.Parent.IsValid() {
+			t.Errorf("Expected root spans, got parent %v", s.Parent)
+		}
+		if s.Name == "heartbeat orders" && s.Status.Code != codes.Error {
+			t.Errorf("Expected the failed run to have error status, got %v", s.Status)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	runs := map[string]int64{}
+	up := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			switch data := metric.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					name, _ := dp.Attributes.Value(ProbeNameKey)
+					outcome, _ := dp.Attributes.Value(OutcomeKey)
+					runs[name.AsString()+" "+outcome.AsString()] = dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					name, _ := dp.Attributes.Value(ProbeNameKey)
+					up[name.AsString()] = dp.Value
+				}
+			}
+		}
+	}
+	if runs["db success"] != 2 || runs["orders failure"] != 2 {
+		t.Errorf("Expected 2 runs per probe, got %v", runs)
+	}
+	if up["db"] != 1 || up["orders"] != 0 || len(up) != 2 {
+		t.Errorf("Expected db up and orders down, got %v", up)
+	}
+}
+
+func TestMonitorTimeoutAndPanic(t *testing.T) {
+	m, _, _ := newTestMonitor(t)
+	m.timeout = 10 * time.Millisecond
+	m.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	m.Register("broken", func(ctx context.Context) error {
+		panic("nil map")
+	})
+
+	m.runProbes(context.Background())
+	for _, r := range m.Results() {
+		if r.Up {
+			t.Errorf("Expected %s to fail, got %+v", r.Name, r)
+		}
+	}
+
+	m.Unregister("slow")
+	if results := m.Results(); len(results) != 1 || results[0].Name != "broken" {
+		t.Errorf("Expected only broken after unregistering slow, got %+v", results)
+	}
+}
+
+func TestHTTPProbe(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	probe := HTTPProbe(nil, server.URL)
+	if err := probe(context.Background()); err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := probe(context.Background()); err == nil {
+		t.Error("Expected failure on 503")
+	}
+}
+
+func TestPingProbe(t *testing.T) {
+	down := pinger{errors.New("database is closed")}
+	if err := PingProbe(down)(context.Background()); err == nil || err.Error() != "database is closed" {
+		t.Errorf("Expected the ping error, got %v", err)
+	}
+	if err := PingProbe(pinger{})(context.Background()); err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+}
+
+// pinger is a Pinger returning err
+type pinger struct {
+	err error
+}
+
+func (p pinger) PingContext(ctx context.Context) error {
+	return p.err
+}
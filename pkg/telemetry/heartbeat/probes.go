@@ -0,0 +1,65 @@
+package heartbeat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// HTTPProbe requests url with GET and fails on transport errors and status
+// codes of 400 and above. The trace context is propagated, so the handling
+// of the request shows up in the trace of the run. A nil client uses
+// http.DefaultClient.
+func HTTPProbe(client *http.Client, url string) Probe {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// Pinger is implemented by *sql.DB and most database clients
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingProbe pings a database
+func PingProbe(db Pinger) Probe {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// QueryProbe runs query, e.g. "SELECT 1", and fails if it returns an error
+func QueryProbe(db *sql.DB, query string) Probe {
+	return func(ctx context.Context) error {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		return rows.Err()
+	}
+}
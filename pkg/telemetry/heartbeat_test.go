@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHeartbeat(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Metrics.Enabled = false
+	cfg.Heartbeat = &config.HeartbeatConfig{
+		Enabled:        true,
+		IntervalMillis: 10,
+		URLs:           map[string]string{"self": server.URL},
+	}
+	spans := keptSpans{tracetest.NewInMemoryExporter()}
+	tel, err := New(WithConfig(cfg), WithSpanExporter(spans), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(tel.Heartbeat().Results()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	results := tel.Heartbeat().Results()
+	if len(results) != 1 || results[0].Name != "self" || !results[0].Up {
+		t.Fatalf("Expected the configured URL to be up, got %+v", results)
+	}
+
+	if err := tel.TracerProvider().ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	var found bool
+	for _, s := range spans.GetSpans() {
+		found = found || s.Name == "heartbeat self"
+	}
+	if !found {
+		t.Error("Expected the probe runs to be traced")
+	}
+}
+
+func TestHeartbeatInvalidURL(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Metrics.Enabled = false
+	cfg.Tracing.Enabled = false
+	cfg.Heartbeat = &config.HeartbeatConfig{Enabled: true, URLs: map[string]string{"orders": "orders/health"}}
+	if _, err := New(WithConfig(cfg), WithLogger(log.New(io.Discard, "", 0))); err == nil {
+		t.Error("Expected an error for a URL without scheme and host")
+	}
+}
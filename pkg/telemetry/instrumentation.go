@@ -0,0 +1,114 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+// Instrumentation is a running instrumentation created from the
+// instrumentations config section
+type Instrumentation interface {
+	// Shutdown releases any resources held by the instrumentation
+	Shutdown(ctx context.Context) error
+}
+
+// InstrumentationFactory creates an instrumentation from its configuration.
+// The telemetry instance is fully initialized (providers, resource) when the
+// factory is invoked.
+type InstrumentationFactory func(t *Telemetry, cfg *config.InstrumentationConfig) (Instrumentation, error)
+
+var (
+	instrumentationsMu sync.RWMutex
+	instrumentations   = make(map[string]InstrumentationFactory)
+)
+
+// RegisterInstrumentation registers an instrumentation factory under the given
+// name. Entries of the instrumentations config map are matched against the
+// registry by their module, falling back to the map key.
+// Registering the same name twice replaces the previous factory.
+func RegisterInstrumentation(name string, factory InstrumentationFactory) {
+	if factory == nil {
+		panic("telemetry: RegisterInstrumentation factory is nil")
+	}
+
+	instrumentationsMu.Lock()
+	defer instrumentationsMu.Unlock()
+	instrumentations[name] = factory
+}
+
+// RegisteredInstrumentations returns the sorted names of all registered instrumentations
+func RegisteredInstrumentations() []string {
+	instrumentationsMu.RLock()
+	defer instrumentationsMu.RUnlock()
+
+	names := make([]string, 0, len(instrumentations))
+	for name := range instrumentations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupInstrumentation finds the factory for a config entry
+func lookupInstrumentation(name string, cfg *config.InstrumentationConfig) (InstrumentationFactory, bool) {
+	instrumentationsMu.RLock()
+	defer instrumentationsMu.RUnlock()
+
+	if cfg.Module != "" {
+		if factory, ok := instrumentations[cfg.Module]; ok {
+			return factory, true
+		}
+	}
+	factory, ok := instrumentations[name]
+	return factory, ok
+}
+
+// initInstrumentations instantiates all enabled instrumentations from the config
+func (t *Telemetry) initInstrumentations() error {
+	// Iterate in a stable order so startup is reproducible
+	names := make([]string, 0, len(t.config.Instrumentations))
+	for name := range t.config.Instrumentations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg := t.config.Instrumentations[name]
+		if cfg == nil {
+			continue
+		}
+		component := "instrumentation." + name
+		if !cfg.Enabled {
+			if env := config.InstrumentationEnv(name); os.Getenv(env) != "" {
+				t.logger.Printf("instrumentation %s disabled by %s", name, env)
+				t.addStartupComponent(component, false, "disabled by %s", env)
+			} else {
+				t.addStartupComponent(component, false, "instrumentations.%s.enabled is false", name)
+			}
+			continue
+		}
+
+		factory, ok := lookupInstrumentation(name, cfg)
+		if !ok {
+			t.logger.Printf("no instrumentation registered for %s (module: %s), skipping", name, cfg.Module)
+			t.addStartupComponent(component, false, "no instrumentation registered, import its package")
+			continue
+		}
+
+		inst, err := factory(t, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create instrumentation %s: %w", name, err)
+		}
+		if inst != nil {
+			t.instrumentations = append(t.instrumentations, inst)
+		}
+		t.addStartupComponent(component, true, "enabled")
+	}
+
+	return nil
+}
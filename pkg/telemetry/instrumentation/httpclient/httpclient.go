@@ -0,0 +1,458 @@
+// Package httpclient instruments outgoing HTTP requests.
+//
+// The Transport starts a client span per request, propagates the trace
+// context in the request headers and records the HTTP client metrics of
+// the semantic conventions. With timing spans, the DNS lookup, connection,
+// TLS handshake and time to first byte become sub-spans of the client
+// span, to diagnose slow outbound calls, e.g. to SAP APIs.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope used for client spans and metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/instrumentation/httpclient"
+
+// Settings of transports not configured by options, set by the
+// http_client instrumentation
+var (
+	defaultTimingSpans atomic.Bool
+	defaultMetrics     atomic.Bool
+)
+
+func init() {
+	defaultMetrics.Store(true)
+}
+
+// Transport is an http.RoundTripper recording spans and metrics of the
+// requests sent through the wrapped transport
+type Transport struct {
+	base        http.RoundTripper
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+	timingSpans *bool
+	metrics     *bool
+
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+	active       metric.Int64UpDownCounter
+}
+
+// config holds the transport settings
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+	timingSpans    *bool
+	metrics        *bool
+}
+
+// Option configures the transport
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider used to create spans
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider the metrics are recorded on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators injecting the trace context into
+// the request headers
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// WithTimingSpans records the DNS lookup, connection, TLS handshake and
+// time to first byte as sub-spans; defaults to the timing_spans setting of
+// the http_client instrumentation, off if it is not configured
+func WithTimingSpans(enabled bool) Option {
+	return func(c *config) {
+		c.timingSpans = &enabled
+	}
+}
+
+// WithMetrics records the client metrics; defaults to the metrics setting
+// of the http_client instrumentation, on if it is not configured
+func WithMetrics(enabled bool) Option {
+	return func(c *config) {
+		c.metrics = &enabled
+	}
+}
+
+// NewTransport wraps base, http.DefaultTransport if nil
+func NewTransport(base http.RoundTripper, opts ...Option) *Transport {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+
+	t := &Transport{
+		base:        base,
+		tracer:      cfg.tracerProvider.Tracer(ScopeName),
+		propagators: cfg.propagators,
+		timingSpans: cfg.timingSpans,
+		metrics:     cfg.metrics,
+	}
+
+	// Instruments which cannot be created are reported and not recorded
+	meter := cfg.meterProvider.Meter(ScopeName)
+	var err error
+	if t.duration, err = meter.Float64Histogram("http.client.request.duration",
+		metric.WithDescription("Duration of HTTP client requests"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(telemetry.DurationBuckets...)); err != nil {
+		otel.Handle(fmt.Errorf("failed to create request duration histogram: %w", err))
+		t.duration = noop.Float64Histogram{}
+	}
+	if t.requestSize, err = meter.Int64Histogram("http.client.request.body.size",
+		metric.WithDescription("Size of HTTP client request bodies"),
+		metric.WithUnit("By")); err != nil {
+		otel.Handle(fmt.Errorf("failed to create request size histogram: %w", err))
+		t.requestSize = noop.Int64Histogram{}
+	}
+	if t.responseSize, err = meter.Int64Histogram("http.client.response.body.size",
+		metric.WithDescription("Size of HTTP client response bodies"),
+		metric.WithUnit("By")); err != nil {
+		otel.Handle(fmt.Errorf("failed to create response size histogram: %w", err))
+		t.responseSize = noop.Int64Histogram{}
+	}
+	if t.active, err = meter.Int64UpDownCounter("http.client.active_requests",
+		metric.WithDescription("Number of active HTTP client requests"),
+		metric.WithUnit("{request}")); err != nil {
+		otel.Handle(fmt.Errorf("failed to create active requests counter: %w", err))
+		t.active = noop.Int64UpDownCounter{}
+	}
+	return t
+}
+
+// NewClient returns a client sending requests through a Transport wrapping
+// http.DefaultTransport
+func NewClient(opts ...Option) *http.Client {
+	return &http.Client{Transport: NewTransport(nil, opts...)}
+}
+
+// timing reports whether timing spans are recorded
+func (t *Transport) timing() bool {
+	if t.timingSpans != nil {
+		return *t.timingSpans
+	}
+	return defaultTimingSpans.Load()
+}
+
+// measured reports whether metrics are recorded
+func (t *Transport) measured() bool {
+	if t.metrics != nil {
+		return *t.metrics
+	}
+	return defaultMetrics.Load()
+}
+
+// RoundTrip sends the request in a client span. The span ends when the
+// response headers are received.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	attrs := requestAttributes(req)
+	name := attrs[0].Value.AsString()
+	if name == "_OTHER" {
+		name = "HTTP"
+	}
+	ctx, span := t.tracer.Start(req.Context(), name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(semconv.URLFull(redactedURL(req))),
+	)
+	defer span.End()
+
+	var ct *clientTrace
+	if t.timing() {
+		ct = newClientTrace(ctx, t.tracer)
+		ctx = httptrace.WithClientTrace(ctx, ct.hooks())
+	}
+
+	// Requests must not be modified, inject into a copy
+	req = req.Clone(ctx)
+	t.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	measured := t.measured()
+	activeAttrs := metric.WithAttributeSet(attribute.NewSet(attrs...))
+	if measured {
+		t.active.Add(ctx, 1, activeAttrs)
+		defer t.active.Add(ctx, -1, activeAttrs)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if ct != nil {
+		ct.end(err)
+	}
+
+	var errorType string
+	switch {
+	case err != nil:
+		errorType = fmt.Sprintf("%T", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp.StatusCode >= http.StatusBadRequest:
+		// Client errors are failures from the client's point of view
+		errorType = strconv.Itoa(resp.StatusCode)
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	if err == nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+	if errorType != "" {
+		span.SetAttributes(semconv.ErrorTypeKey.String(errorType))
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errorType))
+	}
+
+	if measured {
+		opt := metric.WithAttributeSet(attribute.NewSet(attrs...))
+		t.duration.Record(ctx, time.Since(start).Seconds(), opt)
+		if req.ContentLength >= 0 {
+			t.requestSize.Record(ctx, req.ContentLength, opt)
+		}
+		if err == nil && resp.ContentLength >= 0 {
+			t.responseSize.Record(ctx, resp.ContentLength, opt)
+		}
+	}
+	return resp, err
+}
+
+// knownMethods are the methods recorded as is, others as _OTHER to keep
+// the cardinality of the metrics bounded
+var knownMethods = map[string]bool{
+	http.MethodConnect: true,
+	http.MethodDelete:  true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPatch:   true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodTrace:   true,
+}
+
+// requestAttributes returns the low-cardinality attributes of a request,
+// shared by the span and the metrics
+func requestAttributes(req *http.Request) []attribute.KeyValue {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !knownMethods[method] {
+		method = "_OTHER"
+	}
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLScheme(req.URL.Scheme),
+	}
+	host, port := req.URL.Hostname(), req.URL.Port()
+	if host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+	}
+	if port == "" {
+		switch req.URL.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(p))
+	}
+	return attrs
+}
+
+// redactedQueryValue replaces query values in url.full, as recommended by
+// the semantic conventions
+const redactedQueryValue = "REDACTED"
+
+// redactedURL returns the request URL without user credentials and with
+// the query values replaced, since they may carry tokens or signatures.
+// Query keys are kept.
+func redactedURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+	u.Fragment, u.RawFragment = "", ""
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			if key, _, ok := strings.Cut(param, "="); ok {
+				params[i] = key + "=" + redactedQueryValue
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	return u.String()
+}
+
+// clientTrace records the phases of a request as sub-spans of its client
+// span. Connections may be dialed to several addresses in parallel, so
+// the connect spans are kept per address.
+type clientTrace struct {
+	ctx    context.Context
+	tracer trace.Tracer
+
+	mu      sync.Mutex
+	ended   bool
+	dns     trace.Span
+	connect map[string]trace.Span
+	tls     trace.Span
+	ttfb    trace.Span
+}
+
+// newClientTrace creates a trace recording sub-spans of the span in ctx
+func newClientTrace(ctx context.Context, tracer trace.Tracer) *clientTrace {
+	return &clientTrace{ctx: ctx, tracer: tracer, connect: make(map[string]trace.Span)}
+}
+
+// hooks returns the httptrace hooks starting and ending the sub-spans
+func (ct *clientTrace) hooks() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.dns = ct.start("http.dns", semconv.ServerAddress(info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.dns = finish(ct.dns, info.Err)
+		},
+		ConnectStart: func(network, addr string) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.connect[network+addr] = ct.start("http.connect", peerAttributes(network, addr)...)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			finish(ct.connect[network+addr], err)
+			delete(ct.connect, network+addr)
+		},
+		TLSHandshakeStart: func() {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.tls = ct.start("http.tls")
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.tls = finish(ct.tls, err)
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			if info.Err == nil {
+				ct.ttfb = ct.start("http.ttfb")
+			}
+		},
+		GotFirstResponseByte: func() {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+			ct.ttfb = finish(ct.ttfb, nil)
+		},
+	}
+}
+
+// end ends the sub-spans still open when the round trip returned with err,
+// e.g. after a failure, a cancellation or an abandoned parallel dial. Hooks
+// called afterwards are ignored.
+func (ct *clientTrace) end(err error) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.ended = true
+	ct.dns = finish(ct.dns, err)
+	for addr, span := range ct.connect {
+		finish(span, err)
+		delete(ct.connect, addr)
+	}
+	ct.tls = finish(ct.tls, err)
+	ct.ttfb = finish(ct.ttfb, err)
+}
+
+// start starts a sub-span of the client span, or returns nil once the
+// round trip returned
+func (ct *clientTrace) start(name string, attrs ...attribute.KeyValue) trace.Span {
+	if ct.ended {
+		return nil
+	}
+	_, span := ct.tracer.Start(ct.ctx, name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+	return span
+}
+
+// finish ends span, if started, recording err; it returns nil so the
+// phase can be started again
+func finish(span trace.Span, err error) trace.Span {
+	if span == nil {
+		return nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return nil
+}
+
+// peerAttributes returns the network attributes of a dialed address
+func peerAttributes(network, addr string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.NetworkTransportKey.String(strings.TrimRight(network, "46"))}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return append(attrs, semconv.NetworkPeerAddress(addr))
+	}
+	attrs = append(attrs, semconv.NetworkPeerAddress(host))
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.NetworkPeerPort(p))
+	}
+	return attrs
+}
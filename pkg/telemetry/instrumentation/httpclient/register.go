@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+	telemetryconfig "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+// InstrumentationName is the key of the instrumentations config entry
+// setting the defaults of all transports:
+//
+//	instrumentations:
+//	  http_client:
+//	    enabled: true
+//	    config:
+//	      timing_spans: true
+//	      metrics: true
+const InstrumentationName = "http_client"
+
+func init() {
+	telemetry.RegisterInstrumentation(InstrumentationName, newInstrumentation)
+}
+
+// instrumentation applies the configured defaults until shutdown
+type instrumentation struct{}
+
+// newInstrumentation sets the defaults of transports not configured by
+// options from the instrumentation config
+func newInstrumentation(t *telemetry.Telemetry, cfg *telemetryconfig.InstrumentationConfig) (telemetry.Instrumentation, error) {
+	timing, err := boolSetting(cfg.Config, "timing_spans", false)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := boolSetting(cfg.Config, "metrics", true)
+	if err != nil {
+		return nil, err
+	}
+	defaultTimingSpans.Store(timing)
+	defaultMetrics.Store(metrics)
+	return instrumentation{}, nil
+}
+
+// Shutdown restores the defaults
+func (instrumentation) Shutdown(ctx context.Context) error {
+	defaultTimingSpans.Store(false)
+	defaultMetrics.Store(true)
+	return nil
+}
+
+// boolSetting reads a boolean setting, which is a string if set by an
+// environment variable
+func boolSetting(settings map[string]interface{}, key string, fallback bool) (bool, error) {
+	v, ok := settings[key]
+	if !ok {
+		return fallback, nil
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("invalid %s: %v is not a boolean", key, v)
+	}
+}
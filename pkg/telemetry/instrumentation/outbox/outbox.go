@@ -0,0 +1,242 @@
+// Package outbox instruments the transactional outbox pattern used for CAP
+// event handling.
+//
+// Enqueuing a message starts a producer span and stores its trace context
+// in the message headers. Processing the message later starts a consumer
+// span in a new trace, linked to the producer, so both sides can be
+// navigated without one trace spanning hours of queueing.
+//
+// The queue.* metrics match what the console metric exporter renders in
+// its queue section: queue.cold, queue.remaining, queue.incoming,
+// queue.outgoing and the queue.storage_time histogram.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope used for outbox spans and metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/instrumentation/outbox"
+
+// Outbox records spans and metrics of an outbox queue
+type Outbox struct {
+	name        string
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+
+	storageTime  metric.Float64Histogram
+	registration metric.Registration
+
+	cold      atomic.Int64
+	remaining atomic.Int64
+	incoming  atomic.Int64
+	outgoing  atomic.Int64
+}
+
+// config holds the outbox settings
+type config struct {
+	name           string
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option configures the outbox instrumentation
+type Option func(*config)
+
+// WithName sets the queue name recorded as messaging.destination.name;
+// defaults to "outbox"
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithTracerProvider sets the tracer provider used to create spans
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider the instruments are registered on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators used to store the trace context in
+// message headers
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// New creates the outbox instrumentation and registers the queue metrics
+func New(opts ...Option) (*Outbox, error) {
+	cfg := &config{name: "outbox"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+
+	o := &Outbox{
+		name:        cfg.name,
+		tracer:      cfg.tracerProvider.Tracer(ScopeName),
+		propagators: cfg.propagators,
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	var err error
+	o.storageTime, err = meter.Float64Histogram("queue.storage_time",
+		metric.WithDescription("Time messages spent in the queue before processing"),
+		metric.WithUnit("ms"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage time histogram: %w", err)
+	}
+
+	cold, err := meter.Int64ObservableGauge("queue.cold",
+		metric.WithDescription("Messages that exhausted their retries"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cold gauge: %w", err)
+	}
+	remaining, err := meter.Int64ObservableGauge("queue.remaining",
+		metric.WithDescription("Messages waiting to be processed"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remaining gauge: %w", err)
+	}
+	// The totals since start only grow, so they are counters, letting
+	// backends compute rates and exporters report deltas
+	incoming, err := meter.Int64ObservableCounter("queue.incoming",
+		metric.WithDescription("Messages enqueued since start"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create incoming counter: %w", err)
+	}
+	outgoing, err := meter.Int64ObservableCounter("queue.outgoing",
+		metric.WithDescription("Messages processed since start"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outgoing counter: %w", err)
+	}
+
+	queue := metric.WithAttributes(semconv.MessagingDestinationName(o.name))
+	o.registration, err = meter.RegisterCallback(func(ctx context.Context, obs metric.Observer) error {
+		obs.ObserveInt64(cold, o.cold.Load(), queue)
+		obs.ObserveInt64(remaining, o.remaining.Load(), queue)
+		obs.ObserveInt64(incoming, o.incoming.Load(), queue)
+		obs.ObserveInt64(outgoing, o.outgoing.Load(), queue)
+		return nil
+	}, cold, remaining, incoming, outgoing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register queue metrics callback: %w", err)
+	}
+
+	return o, nil
+}
+
+// attributes returns the messaging attributes of a span
+func (o *Outbox) attributes(operation attribute.KeyValue, event string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("outbox"),
+		semconv.MessagingDestinationName(o.name),
+		semconv.MessagingOperationName(event),
+		operation,
+	}
+}
+
+// Enqueue starts a producer span for storing a message with the given
+// event name and injects its trace context into headers, which must be
+// persisted with the message. End the span once the message is stored.
+func (o *Outbox) Enqueue(ctx context.Context, event string, headers propagation.TextMapCarrier) (context.Context, trace.Span) {
+	ctx, span := o.tracer.Start(ctx, fmt.Sprintf("send %s", event),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(o.attributes(semconv.MessagingOperationTypeSend, event)...),
+	)
+	o.propagators.Inject(ctx, headers)
+
+	o.incoming.Add(1)
+	o.remaining.Add(1)
+	return ctx, span
+}
+
+// Delivery is a message being processed
+type Delivery struct {
+	outbox *Outbox
+	span   trace.Span
+}
+
+// Dequeue starts a consumer span for processing a message read from the
+// outbox. The span starts a new trace linked to the producer span found in
+// headers. enqueuedAt is recorded as the storage time, unless zero.
+func (o *Outbox) Dequeue(ctx context.Context, event string, headers propagation.TextMapCarrier, enqueuedAt time.Time) (context.Context, *Delivery) {
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(o.attributes(semconv.MessagingOperationTypeProcess, event)...),
+	}
+	if producer := trace.SpanContextFromContext(o.propagators.Extract(context.Background(), headers)); producer.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+	ctx, span := o.tracer.Start(ctx, fmt.Sprintf("process %s", event), opts...)
+
+	if !enqueuedAt.IsZero() {
+		o.storageTime.Record(ctx, float64(time.Since(enqueuedAt))/float64(time.Millisecond),
+			metric.WithAttributes(semconv.MessagingDestinationName(o.name)))
+	}
+	return ctx, &Delivery{outbox: o, span: span}
+}
+
+// Span returns the consumer span
+func (d *Delivery) Span() trace.Span {
+	return d.span
+}
+
+// End ends the consumer span. A nil error removes the message from the
+// queue; otherwise the error is recorded and the message stays for a retry.
+func (d *Delivery) End(err error) {
+	if err != nil {
+		d.span.RecordError(err)
+		d.span.SetStatus(codes.Error, err.Error())
+	} else {
+		d.outbox.outgoing.Add(1)
+		d.outbox.remaining.Add(-1)
+	}
+	d.span.End()
+}
+
+// Abandon ends the consumer span for a message that exhausted its retries
+// and is kept as cold (dead) entry
+func (d *Delivery) Abandon(err error) {
+	if err != nil {
+		d.span.RecordError(err)
+	}
+	d.span.SetStatus(codes.Error, "message abandoned")
+	d.outbox.cold.Add(1)
+	d.outbox.remaining.Add(-1)
+	d.span.End()
+}
+
+// Shutdown unregisters the queue metrics callback
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	return o.registration.Unregister()
+}
@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/console"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOutbox(t *testing.T) {
+	spans := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	o, err := New(WithTracerProvider(tp), WithMeterProvider(mp), WithPropagators(propagation.TraceContext{}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer o.Shutdown(context.Background())
+
+	// Enqueue three messages within a request trace
+	ctx, request := tp.Tracer("test").Start(context.Background(), "POST /orders")
+	headers := make([]propagation.MapCarrier, 3)
+	for i := range headers {
+		headers[i] = propagation.MapCarrier{}
+		_, span := o.Enqueue(ctx, "OrderCreated", headers[i])
+		span.End()
+	}
+	request.End()
+
+	// One processed, one retried, one abandoned
+	enqueuedAt := time.Now().Add(-50 * time.Millisecond)
+	_, d := o.Dequeue(context.Background(), "OrderCreated", headers[0], enqueuedAt)
+	d.End(nil)
+	_, d = o.Dequeue(context.Background(), "OrderCreated", headers[1], enqueuedAt)
+	d.End(errors.New("timeout"))
+	_, d = o.Dequeue(context.Background(), "OrderCreated", headers[2], enqueuedAt)
+	d.Abandon(errors.New("invalid payload"))
+
+	var consumer sdktrace.ReadOnlySpan
+	for _, s := range spans.GetSpans().Snapshots() {
+		if s.SpanKind() == trace.SpanKindConsumer {
+			consumer = s
+			break
+		}
+	}
+	if consumer == nil {
+		t.Fatal("Expected consumer span")
+	}
+	if consumer.Parent().IsValid() || consumer.SpanContext().TraceID() == request.SpanContext().TraceID() {
+		t.Error("Expected consumer span to start a new trace")
+	}
+	if links := consumer.Links(); len(links) != 1 || links[0].SpanContext.TraceID() != request.SpanContext().TraceID() {
+		t.Error("Expected consumer span to link to the producer span")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	gauges := map[string]int64{}
+	counters := map[string]int64{}
+	var storageCount uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				gauges[m.Name] = data.DataPoints[0].Value
+			case metricdata.Sum[int64]:
+				if !data.IsMonotonic {
+					t.Errorf("Expected %s to be a monotonic counter", m.Name)
+				}
+				counters[m.Name] = data.DataPoints[0].Value
+			case metricdata.Histogram[float64]:
+				storageCount = data.DataPoints[0].Count
+			}
+		}
+	}
+	for name, want := range map[string]int64{"queue.remaining": 1, "queue.cold": 1} {
+		if gauges[name] != want {
+			t.Errorf("Expected gauge %s=%d, got %d", name, want, gauges[name])
+		}
+	}
+	for name, want := range map[string]int64{"queue.incoming": 3, "queue.outgoing": 1} {
+		if counters[name] != want {
+			t.Errorf("Expected counter %s=%d, got %d", name, want, counters[name])
+		}
+	}
+	if storageCount != 3 {
+		t.Errorf("Expected 3 storage time samples, got %d", storageCount)
+	}
+
+	// The console exporter renders the storage time columns
+	var out bytes.Buffer
+	console.NewMetricExporter(console.WithMetricWriter(&out)).Export(context.Background(), &rm)
+	if !strings.Contains(out.String(), "queue") || strings.Contains(out.String(), "|                0 |") {
+		t.Errorf("Expected storage times in queue section, got:\n%s", out.String())
+	}
+}
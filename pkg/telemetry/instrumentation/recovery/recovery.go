@@ -0,0 +1,128 @@
+// Package recovery recovers panics of request handlers with telemetry.
+//
+// A recovered panic is recorded on the active span as an exception event
+// with the stack trace of the panic, sets the error status and increments
+// the panics counter. HTTP handlers then respond with 500 or re-panic.
+//
+// Install the middleware inside the tracing middleware, so the server span
+// is active and ends normally:
+//
+//	r.Use(router.Chi(), recovery.Must().Middleware)
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// ScopeName is the instrumentation scope used for the panics counter
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/instrumentation/recovery"
+
+// Recoverer records recovered panics
+type Recoverer struct {
+	panics  metric.Int64Counter
+	repanic bool
+}
+
+// config holds the recovery settings
+type config struct {
+	meterProvider metric.MeterProvider
+	repanic       bool
+}
+
+// Option configures the recovery instrumentation
+type Option func(*config)
+
+// WithMeterProvider sets the meter provider the panics counter is
+// registered on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithRepanic panics again after recording, e.g. to let an outer handler
+// or the runtime deal with it, instead of responding with 500
+func WithRepanic(repanic bool) Option {
+	return func(c *config) {
+		c.repanic = repanic
+	}
+}
+
+// New creates the recovery instrumentation
+func New(opts ...Option) (*Recoverer, error) {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	panics, err := cfg.meterProvider.Meter(ScopeName).Int64Counter("panics",
+		metric.WithDescription("Panics recovered from handlers"),
+		metric.WithUnit("{panic}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create panics counter: %w", err)
+	}
+	return &Recoverer{panics: panics, repanic: cfg.repanic}, nil
+}
+
+// Must is like New but panics if the counter cannot be created
+func Must(opts ...Option) *Recoverer {
+	r, err := New(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Recover records the panic value v, as returned by recover, on the span
+// of ctx and returns it as *telemetry.PanicError. It must be called from
+// the deferred function that recovered, so the stack trace is the one of
+// the panic, e.g. in a gRPC interceptor:
+//
+//	defer func() {
+//		if v := recover(); v != nil {
+//			err = status.Error(codes.Internal, rec.Recover(ctx, v, attrs...).Error())
+//		}
+//	}()
+func (r *Recoverer) Recover(ctx context.Context, v interface{}, attrs ...attribute.KeyValue) *telemetry.PanicError {
+	perr := &telemetry.PanicError{Value: v, Stack: debug.Stack()}
+	telemetry.RecordError(ctx, perr,
+		telemetry.WithErrorCounter(r.panics),
+		telemetry.WithErrorAttributes(attrs...))
+	return perr
+}
+
+// Middleware recovers panics of next and responds with 500, or panics
+// again with WithRepanic. http.ErrAbortHandler is passed through
+// unrecorded, it aborts the response on purpose.
+func (r *Recoverer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+			r.Recover(req.Context(), v, semconv.HTTPRequestMethodKey.String(req.Method))
+			if r.repanic {
+				panic(v)
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,146 @@
+package recovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// setup creates a recoverer with its own meter provider and a tracing
+// middleware for the requests
+func setup(t *testing.T, opts ...Option) (*Recoverer, *tracetest.SpanRecorder, *sdkmetric.ManualReader, func(http.Handler) http.Handler) {
+	reader := sdkmetric.NewManualReader()
+	rec, err := New(append(opts, WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))...)
+	if err != nil {
+		t.Fatalf("failed to create recoverer: %v", err)
+	}
+	spans := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")
+	traced := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracer.Start(r.Context(), "GET /orders")
+			defer span.End()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	return rec, spans, reader, traced
+}
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	panic("out of stock")
+}
+
+func TestMiddleware(t *testing.T) {
+	rec, spans, reader, traced := setup(t)
+	handler := traced(rec.Middleware(http.HandlerFunc(panicking)))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+
+	span := spans.Ended()[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected the error status, got %v", span.Status())
+	}
+	if len(span.Events()) != 1 {
+		t.Fatalf("Expected an exception event, got %d events", len(span.Events()))
+	}
+	attrs := attribute.NewSet(span.Events()[0].Attributes...)
+	if v, _ := attrs.Value(semconv.ExceptionStacktraceKey); !strings.Contains(v.AsString(), "panicking") {
+		t.Errorf("Expected the stack trace of the panic, got %q", v.AsString())
+	}
+	if v, _ := attrs.Value(semconv.ExceptionMessageKey); v.AsString() != "panic: out of stock" {
+		t.Errorf("Unexpected exception message %q", v.AsString())
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	sum := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if rm.ScopeMetrics[0].Metrics[0].Name != "panics" || sum.DataPoints[0].Value != 1 {
+		t.Errorf("Expected 1 panic, got %v", sum.DataPoints)
+	}
+	if v, _ := sum.DataPoints[0].Attributes.Value(semconv.HTTPRequestMethodKey); v.AsString() != http.MethodGet {
+		t.Errorf("Expected the request method, got %q", v.AsString())
+	}
+}
+
+func TestMiddleware_Repanic(t *testing.T) {
+	rec, spans, _, traced := setup(t, WithRepanic(true))
+	handler := traced(rec.Middleware(http.HandlerFunc(panicking)))
+
+	func() {
+		defer func() {
+			if v := recover(); v != "out of stock" {
+				t.Errorf("Expected the panic to be repeated, got %v", v)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	}()
+	// The SDK records the panic passing through span.End as well
+	events := spans.Ended()[0].Events()
+	if len(events) == 0 {
+		t.Fatal("Expected the panic to be recorded before repanicking")
+	}
+	attrs := attribute.NewSet(events[0].Attributes...)
+	if v, _ := attrs.Value(semconv.ErrorTypeKey); v.AsString() != telemetry.ErrorTypePanic {
+		t.Errorf("Expected error.type panic, got %q", v.AsString())
+	}
+}
+
+func TestMiddleware_ErrAbortHandler(t *testing.T) {
+	rec, _, reader, traced := setup(t)
+	handler := traced(rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})))
+
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	}()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	if len(rm.ScopeMetrics) != 0 {
+		t.Errorf("Expected aborts not to be counted, got %v", rm.ScopeMetrics)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	rec, spans, _, _ := setup(t)
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")
+
+	call := func(ctx context.Context) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = rec.Recover(ctx, v)
+			}
+		}()
+		panic("nil order")
+	}
+	ctx, span := tracer.Start(context.Background(), "/orders.Service/Get")
+	err := call(ctx)
+	span.End()
+
+	if _, ok := err.(*telemetry.PanicError); !ok {
+		t.Errorf("Expected a panic error, got %v", err)
+	}
+	if spans.Ended()[0].Status().Code != codes.Error {
+		t.Error("Expected the error status")
+	}
+}
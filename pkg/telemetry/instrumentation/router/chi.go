@@ -0,0 +1,100 @@
+package router
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Chi returns a chi middleware that traces every request.
+// Chi only knows the full route pattern once routing into sub-routers has
+// completed, so the span is renamed after the handler returns.
+func Chi(opts ...Option) func(http.Handler) http.Handler {
+	t := newTracer(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !t.shouldTrace(r.Method, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, span := t.start(r.Context(), propagation.HeaderCarrier(r.Header),
+				r.Method, "", r.URL.Path, r.UserAgent())
+			m := t.startMeasurement(ctx, r.Method, requestScheme(r), r.ContentLength)
+			rw := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+
+			route := ""
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+			t.endMeasurement(ctx, m, route, rw.Status(), rw.written, nil)
+			end(span, r.Method, route, rw.Status(), nil)
+		})
+	}
+}
+
+// statusRecorder captures the status code and body size written by a
+// net/http handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+// WriteHeader records the status code before delegating
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write records an implicit 200 and the written bytes
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.written += int64(n)
+	return n, err
+}
+
+// Status returns the recorded status, defaulting to 200
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Flush forwards to the underlying writer so streamed responses still flush
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.status == 0 {
+			r.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack forwards to the underlying writer so websocket upgrades keep working
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
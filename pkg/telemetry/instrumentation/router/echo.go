@@ -0,0 +1,38 @@
+package router
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Echo returns an echo middleware that traces every request.
+// Register it with e.Use (not e.Pre) so that c.Path() holds the matched route.
+func Echo(opts ...Option) echo.MiddlewareFunc {
+	t := newTracer(opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !t.shouldTrace(req.Method, req.URL.Path) {
+				return next(c)
+			}
+
+			route := c.Path()
+			ctx, span := t.start(req.Context(), propagation.HeaderCarrier(req.Header),
+				req.Method, route, req.URL.Path, req.UserAgent())
+			c.SetRequest(req.WithContext(ctx))
+			m := t.startMeasurement(ctx, req.Method, requestScheme(req), req.ContentLength)
+
+			err := next(c)
+			if err != nil {
+				// Let echo write the error response so the status code is final.
+				// The default error handler skips committed responses, so
+				// returning the error afterwards does not write it twice.
+				c.Error(err)
+			}
+
+			t.endMeasurement(ctx, m, route, c.Response().Status, c.Response().Size, err)
+			end(span, req.Method, route, c.Response().Status, err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Fiber returns a fiber middleware that traces every request.
+// The matched route is read after c.Next(), when fiber has resolved it.
+func Fiber(opts ...Option) fiber.Handler {
+	t := newTracer(opts)
+	return func(c *fiber.Ctx) error {
+		method := c.Method()
+		path := c.Path()
+		if !t.shouldTrace(method, path) {
+			return c.Next()
+		}
+
+		ctx, span := t.start(c.UserContext(), fasthttpCarrier{&c.Request().Header},
+			method, "", path, string(c.Request().Header.UserAgent()))
+		c.SetUserContext(ctx)
+		m := t.startMeasurement(ctx, method, c.Protocol(), int64(c.Request().Header.ContentLength()))
+
+		err := c.Next()
+		if err != nil {
+			// Let fiber write the error response so the status code is final
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// Unmatched requests still report the middleware's own route, so
+		// drop it to avoid naming 404 spans after the mount point
+		status := c.Response().StatusCode()
+		route := ""
+		if status != fiber.StatusNotFound {
+			route = c.Route().Path
+		}
+		t.endMeasurement(ctx, m, route, status, fiberResponseSize(c.Response()), err)
+		end(span, method, route, status, err)
+		return err
+	}
+}
+
+// fiberResponseSize returns the size of a response body, -1 for streamed
+// bodies which would have to be read to know it
+func fiberResponseSize(resp *fasthttp.Response) int64 {
+	if resp.IsBodyStream() {
+		return -1
+	}
+	return int64(len(resp.Body()))
+}
+
+// fasthttpCarrier adapts fasthttp request headers to a TextMapCarrier
+type fasthttpCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+// Get returns the value for the given header
+func (c fasthttpCarrier) Get(key string) string {
+	return string(c.header.Peek(key))
+}
+
+// Set sets the header to the given value
+func (c fasthttpCarrier) Set(key, value string) {
+	c.header.Set(key, value)
+}
+
+// Keys lists all header names
+func (c fasthttpCarrier) Keys() []string {
+	keys := make([]string, 0, c.header.Len())
+	c.header.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Gin returns a gin middleware that traces every request.
+// Gin resolves the route before running middleware, so the span is named
+// after c.FullPath() from the start.
+func Gin(opts ...Option) gin.HandlerFunc {
+	t := newTracer(opts)
+	return func(c *gin.Context) {
+		req := c.Request
+		if !t.shouldTrace(req.Method, req.URL.Path) {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		ctx, span := t.start(req.Context(), propagation.HeaderCarrier(req.Header),
+			req.Method, route, req.URL.Path, req.UserAgent())
+		c.Request = req.WithContext(ctx)
+		m := t.startMeasurement(ctx, req.Method, requestScheme(req), req.ContentLength)
+
+		c.Next()
+
+		// c.Errors.Last() returns a typed nil, so only convert when present
+		var err error
+		if last := c.Errors.Last(); last != nil {
+			err = last
+		}
+		t.endMeasurement(ctx, m, route, c.Writer.Status(), int64(max(c.Writer.Size(), 0)), err)
+		end(span, req.Method, route, c.Writer.Status(), err)
+	}
+}
@@ -0,0 +1,153 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// serverMetrics holds the HTTP server instruments of the semantic
+// conventions
+type serverMetrics struct {
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+	active       metric.Int64UpDownCounter
+}
+
+// newServerMetrics creates the instruments on mp. Instruments which cannot
+// be created are reported to the global error handler and not recorded.
+func newServerMetrics(mp metric.MeterProvider) *serverMetrics {
+	meter := mp.Meter(ScopeName)
+	m := &serverMetrics{}
+	var err error
+	if m.duration, err = meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(telemetry.DurationBuckets...)); err != nil {
+		otel.Handle(fmt.Errorf("failed to create request duration histogram: %w", err))
+		m.duration = noop.Float64Histogram{}
+	}
+	if m.requestSize, err = meter.Int64Histogram("http.server.request.body.size",
+		metric.WithDescription("Size of HTTP server request bodies"),
+		metric.WithUnit("By")); err != nil {
+		otel.Handle(fmt.Errorf("failed to create request size histogram: %w", err))
+		m.requestSize = noop.Int64Histogram{}
+	}
+	if m.responseSize, err = meter.Int64Histogram("http.server.response.body.size",
+		metric.WithDescription("Size of HTTP server response bodies"),
+		metric.WithUnit("By")); err != nil {
+		otel.Handle(fmt.Errorf("failed to create response size histogram: %w", err))
+		m.responseSize = noop.Int64Histogram{}
+	}
+	if m.active, err = meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithDescription("Number of active HTTP server requests"),
+		metric.WithUnit("{request}")); err != nil {
+		otel.Handle(fmt.Errorf("failed to create active requests counter: %w", err))
+		m.active = noop.Int64UpDownCounter{}
+	}
+	return m
+}
+
+// measurement is a request being measured
+type measurement struct {
+	start       time.Time
+	method      string
+	scheme      string
+	requestSize int64
+	active      metric.MeasurementOption
+}
+
+// startMeasurement counts the request as active. A negative requestSize
+// means the size is unknown.
+func (t *tracer) startMeasurement(ctx context.Context, method, scheme string, requestSize int64) *measurement {
+	m := &measurement{
+		start:       time.Now(),
+		method:      metricMethod(method),
+		scheme:      scheme,
+		requestSize: requestSize,
+	}
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(m.method),
+		semconv.URLScheme(scheme),
+	}
+	if t.cfg.serverName != "" {
+		attrs = append(attrs, semconv.ServerAddress(t.cfg.serverName))
+	}
+	m.active = metric.WithAttributeSet(attribute.NewSet(attrs...))
+	t.metrics.active.Add(ctx, 1, m.active)
+	return m
+}
+
+// endMeasurement records the duration and sizes of the request per route
+// and status. A negative responseSize means the size is unknown.
+func (t *tracer) endMeasurement(ctx context.Context, m *measurement, route string, status int, responseSize int64, err error) {
+	t.metrics.active.Add(ctx, -1, m.active)
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(m.method),
+		semconv.URLScheme(m.scheme),
+	}
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+	if status > 0 {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(status))
+	}
+	if status >= http.StatusInternalServerError {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+	} else if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+	}
+	if t.cfg.serverName != "" {
+		attrs = append(attrs, semconv.ServerAddress(t.cfg.serverName))
+	}
+	opt := metric.WithAttributeSet(attribute.NewSet(attrs...))
+
+	t.metrics.duration.Record(ctx, time.Since(m.start).Seconds(), opt)
+	if m.requestSize >= 0 {
+		t.metrics.requestSize.Record(ctx, m.requestSize, opt)
+	}
+	if responseSize >= 0 {
+		t.metrics.responseSize.Record(ctx, responseSize, opt)
+	}
+}
+
+// knownMethods are the methods recorded as is, others as _OTHER to keep
+// the cardinality of the metrics bounded
+var knownMethods = map[string]bool{
+	http.MethodConnect: true,
+	http.MethodDelete:  true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPatch:   true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodTrace:   true,
+}
+
+// metricMethod returns the method recorded in metrics
+func metricMethod(method string) string {
+	if knownMethods[method] {
+		return method
+	}
+	return "_OTHER"
+}
+
+// requestScheme returns the URL scheme of a net/http request
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,204 @@
+// Package router provides tracing middleware for popular Go HTTP routers.
+//
+// Every adapter names its server span after the low-cardinality route
+// template ("GET /users/:id") rather than the raw request path, so traces
+// and span-derived metrics stay aggregatable regardless of path parameters.
+// The adapters also record the HTTP server metrics of the semantic
+// conventions (http.server.request.duration, request and response body
+// sizes and active requests) per route, method and status.
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope used for router spans and metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/instrumentation/router"
+
+// Filter reports whether a request should be traced
+type Filter func(method, path string) bool
+
+// config holds the settings shared by all router adapters
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+	filters        []Filter
+	serverName     string
+	debugHeader    string
+}
+
+// Option configures a router middleware
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider used to create spans
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider used to record the HTTP server
+// metrics
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators used to extract the incoming trace context
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// WithFilter adds a filter; requests for which any filter returns false are
+// neither traced nor measured
+func WithFilter(f Filter) Option {
+	return func(c *config) {
+		c.filters = append(c.filters, f)
+	}
+}
+
+// WithIgnoredPaths skips tracing and metrics for the given exact request paths
+func WithIgnoredPaths(paths ...string) Option {
+	ignored := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		ignored[p] = struct{}{}
+	}
+	return WithFilter(func(_, path string) bool {
+		_, skip := ignored[path]
+		return !skip
+	})
+}
+
+// WithServerName sets the server.address attribute recorded on every span
+func WithServerName(name string) Option {
+	return func(c *config) {
+		c.serverName = name
+	}
+}
+
+// WithDebugHeader forces sampling of requests carrying the header with a
+// debug value (e.g. "X-Debug-Trace: 1"); requires a sampling.NewForceSampler
+// in the tracer provider. Pass tel.DebugHeader() to use the configured
+// tracing.sampler.debug_header; "" disables it.
+func WithDebugHeader(header string) Option {
+	return func(c *config) {
+		c.debugHeader = header
+	}
+}
+
+// newConfig applies the options on top of the global defaults
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	if c.meterProvider == nil {
+		c.meterProvider = otel.GetMeterProvider()
+	}
+	if c.propagators == nil {
+		c.propagators = otel.GetTextMapPropagator()
+	}
+	return c
+}
+
+// tracer holds the state shared by the framework-specific adapters
+type tracer struct {
+	cfg     *config
+	tracer  trace.Tracer
+	metrics *serverMetrics
+}
+
+// newTracer creates the shared tracer for an adapter
+func newTracer(opts []Option) *tracer {
+	cfg := newConfig(opts)
+	return &tracer{
+		cfg:     cfg,
+		tracer:  cfg.tracerProvider.Tracer(ScopeName),
+		metrics: newServerMetrics(cfg.meterProvider),
+	}
+}
+
+// shouldTrace evaluates the configured filters
+func (t *tracer) shouldTrace(method, path string) bool {
+	for _, f := range t.cfg.filters {
+		if !f(method, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// start extracts the remote context and starts a server span.
+// The route may be empty when it is only known after the handler chain ran.
+func (t *tracer) start(ctx context.Context, carrier propagation.TextMapCarrier, method, route, path, userAgent string) (context.Context, trace.Span) {
+	ctx = t.cfg.propagators.Extract(ctx, carrier)
+	if t.cfg.debugHeader != "" && sampling.IsDebugValue(carrier.Get(t.cfg.debugHeader)) {
+		ctx = sampling.ContextWithForceSampling(ctx)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLPath(path),
+	}
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+	if userAgent != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(userAgent))
+	}
+	if t.cfg.serverName != "" {
+		attrs = append(attrs, semconv.ServerAddress(t.cfg.serverName))
+	}
+
+	return t.tracer.Start(ctx, SpanName(method, route),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// end records the route (if resolved late), status and error, then ends the span
+func end(span trace.Span, method, route string, status int, err error) {
+	if route != "" {
+		span.SetName(SpanName(method, route))
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+	if status > 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	} else if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SpanName returns the span name for a request: "METHOD route", or just the
+// method when no route template matched, to keep cardinality low.
+func SpanName(method, route string) string {
+	if route == "" {
+		return method
+	}
+	return fmt.Sprintf("%s %s", method, route)
+}
@@ -0,0 +1,440 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofiber/fiber/v2"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanName(t *testing.T) {
+	if got := SpanName("GET", "/users/{id}"); got != "GET /users/{id}" {
+		t.Errorf("SpanName() = %q, want %q", got, "GET /users/{id}")
+	}
+	if got := SpanName("GET", ""); got != "GET" {
+		t.Errorf("SpanName() without route = %q, want %q", got, "GET")
+	}
+}
+
+func TestChi_UsesRoutePattern(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	r := chi.NewRouter()
+	r.Use(Chi(WithTracerProvider(tp), WithIgnoredPaths("/health")))
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name() != "GET /users/{id}" {
+		t.Errorf("Expected span name %q, got %q", "GET /users/{id}", spans[0].Name())
+	}
+
+	var status int64
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "http.response.status_code" {
+			status = attr.Value.AsInt64()
+		}
+	}
+	if status != http.StatusTeapot {
+		t.Errorf("Expected status code %d, got %d", http.StatusTeapot, status)
+	}
+}
+
+func TestChi_ForwardsFlush(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+
+	r := chi.NewRouter()
+	r.Use(Chi(WithTracerProvider(tp)))
+	var flushErr error
+	r.Get("/stream", func(w http.ResponseWriter, r *http.Request) {
+		flushErr = http.NewResponseController(w).Flush()
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+	if flushErr != nil {
+		t.Fatalf("Expected Flush to reach the underlying writer, got %v", flushErr)
+	}
+	if !rec.Flushed {
+		t.Error("Expected the recorder to be flushed")
+	}
+}
+
+func TestChi_HijackNotSupported(t *testing.T) {
+	rw := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Expected http.ErrNotSupported, got %v", err)
+	}
+}
+
+func TestEcho_UsesRoutePattern(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	e := echo.New()
+	e.Use(Echo(WithTracerProvider(tp), WithIgnoredPaths("/health")))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusTeapot)
+	})
+	e.GET("/health", func(c echo.Context) error { return nil })
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name() != "GET /users/:id" {
+		t.Errorf("Expected span name %q, got %q", "GET /users/:id", spans[0].Name())
+	}
+
+	var status int64
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "http.response.status_code" {
+			status = attr.Value.AsInt64()
+		}
+	}
+	if status != http.StatusTeapot {
+		t.Errorf("Expected status code %d, got %d", http.StatusTeapot, status)
+	}
+}
+
+func TestEcho_ReturnsHandlerError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	handlerErr := errors.New("boom")
+
+	var seen error
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			seen = next(c)
+			return seen
+		}
+	})
+	e.Use(Echo(WithTracerProvider(tp)))
+	e.GET("/orders", func(c echo.Context) error { return handlerErr })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if !errors.Is(seen, handlerErr) {
+		t.Errorf("Expected the handler error to be returned, got %v", seen)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", spans[0].Status())
+	}
+	if len(spans[0].Events()) != 1 {
+		t.Errorf("Expected the error to be recorded, got %d events", len(spans[0].Events()))
+	}
+}
+
+func TestFiber_UsesRoute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	app := fiber.New()
+	app.Use(Fiber(WithTracerProvider(tp), WithIgnoredPaths("/health")))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusTeapot)
+	})
+	app.Get("/health", func(c *fiber.Ctx) error { return nil })
+
+	for _, path := range []string{"/users/42", "/health"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("Request to %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name() != "GET /users/:id" {
+		t.Errorf("Expected span name %q, got %q", "GET /users/:id", spans[0].Name())
+	}
+
+	var status int64
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "http.response.status_code" {
+			status = attr.Value.AsInt64()
+		}
+	}
+	if status != http.StatusTeapot {
+		t.Errorf("Expected status code %d, got %d", http.StatusTeapot, status)
+	}
+}
+
+func TestFiber_ReturnsHandlerError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	handlerErr := fiber.NewError(http.StatusConflict, "conflict")
+
+	var seen error
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		seen = c.Next()
+		return seen
+	})
+	app.Use(Fiber(WithTracerProvider(tp)))
+	app.Get("/orders", func(c *fiber.Ctx) error { return handlerErr })
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if !errors.Is(seen, handlerErr) {
+		t.Errorf("Expected the handler error to be returned, got %v", seen)
+	}
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", spans[0].Status())
+	}
+}
+
+func TestGin_UsesFullPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	engine := gin.New()
+	engine.Use(Gin(WithTracerProvider(tp)))
+	engine.GET("/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/7", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name() != "GET /orders/:id" {
+		t.Errorf("Expected span name %q, got %q", "GET /orders/:id", spans[0].Name())
+	}
+}
+
+func TestDebugHeader_ForcesSampling(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampling.NewForceSampler(sdktrace.NeverSample())),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+
+	r := chi.NewRouter()
+	r.Use(Chi(WithTracerProvider(tp), WithDebugHeader("X-Debug-Trace")))
+	r.Get("/orders", func(w http.ResponseWriter, r *http.Request) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Debug-Trace", "1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if spans := recorder.Ended(); len(spans) != 1 {
+		t.Errorf("Expected only the debug request to be sampled, got %d spans", len(spans))
+	}
+}
+
+// collectServerMetrics collects the router metrics of reader by name
+func collectServerMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestChi_RecordsServerMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	r := chi.NewRouter()
+	r.Use(Chi(WithMeterProvider(mp), WithTracerProvider(sdktrace.NewTracerProvider()), WithIgnoredPaths("/health")))
+	r.Post("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("unavailable"))
+	})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/42", strings.NewReader(`{"name":"x"}`)))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	metrics := collectServerMetrics(t, reader)
+	duration, ok := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 {
+		t.Fatalf("Expected one duration data point, got %v", metrics["http.server.request.duration"])
+	}
+	want := attribute.NewSet(
+		attribute.String("http.request.method", "POST"),
+		attribute.String("url.scheme", "http"),
+		attribute.String("http.route", "/users/{id}"),
+		attribute.Int("http.response.status_code", http.StatusServiceUnavailable),
+		attribute.String("error.type", "503"),
+	)
+	if got := duration.DataPoints[0].Attributes; !got.Equals(&want) {
+		t.Errorf("Expected attributes %v, got %v", want.Encoded(attribute.DefaultEncoder()), got.Encoded(attribute.DefaultEncoder()))
+	}
+
+	requestSize := metrics["http.server.request.body.size"].(metricdata.Histogram[int64])
+	if len(requestSize.DataPoints) != 1 || requestSize.DataPoints[0].Sum != int64(len(`{"name":"x"}`)) {
+		t.Errorf("Expected the request body size, got %v", requestSize.DataPoints)
+	}
+	responseSize := metrics["http.server.response.body.size"].(metricdata.Histogram[int64])
+	if len(responseSize.DataPoints) != 1 || responseSize.DataPoints[0].Sum != int64(len("unavailable")) {
+		t.Errorf("Expected the response body size, got %v", responseSize.DataPoints)
+	}
+	active := metrics["http.server.active_requests"].(metricdata.Sum[int64])
+	if len(active.DataPoints) != 1 || active.DataPoints[0].Value != 0 {
+		t.Errorf("Expected no active requests, got %v", active.DataPoints)
+	}
+}
+
+func TestGin_RecordsServerMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	engine := gin.New()
+	engine.Use(Gin(WithMeterProvider(mp), WithTracerProvider(sdktrace.NewTracerProvider())))
+	engine.Handle("PURGE", "/orders/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PURGE", "/orders/7", nil))
+
+	metrics := collectServerMetrics(t, reader)
+	duration := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+	if len(duration.DataPoints) != 1 {
+		t.Fatalf("Expected one duration data point, got %d", len(duration.DataPoints))
+	}
+	attrs := duration.DataPoints[0].Attributes
+	if method, _ := attrs.Value("http.request.method"); method.AsString() != "_OTHER" {
+		t.Errorf("Expected unknown methods as _OTHER, got %s", method.AsString())
+	}
+	if route, _ := attrs.Value("http.route"); route.AsString() != "/orders/:id" {
+		t.Errorf("Expected route /orders/:id, got %s", route.AsString())
+	}
+	if _, ok := attrs.Value("error.type"); ok {
+		t.Error("Expected no error.type for successful requests")
+	}
+	responseSize := metrics["http.server.response.body.size"].(metricdata.Histogram[int64])
+	if responseSize.DataPoints[0].Sum != 2 {
+		t.Errorf("Expected response size 2, got %d", responseSize.DataPoints[0].Sum)
+	}
+}
+
+func TestEcho_RecordsServerMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	e := echo.New()
+	e.Use(Echo(WithMeterProvider(mp), WithTracerProvider(sdktrace.NewTracerProvider())))
+	e.POST("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusServiceUnavailable, "unavailable")
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/42", strings.NewReader(`{"name":"x"}`)))
+
+	metrics := collectServerMetrics(t, reader)
+	duration, ok := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 {
+		t.Fatalf("Expected one duration data point, got %v", metrics["http.server.request.duration"])
+	}
+	want := attribute.NewSet(
+		attribute.String("http.request.method", "POST"),
+		attribute.String("url.scheme", "http"),
+		attribute.String("http.route", "/users/:id"),
+		attribute.Int("http.response.status_code", http.StatusServiceUnavailable),
+		attribute.String("error.type", "503"),
+	)
+	if got := duration.DataPoints[0].Attributes; !got.Equals(&want) {
+		t.Errorf("Expected attributes %v, got %v", want.Encoded(attribute.DefaultEncoder()), got.Encoded(attribute.DefaultEncoder()))
+	}
+	responseSize := metrics["http.server.response.body.size"].(metricdata.Histogram[int64])
+	if len(responseSize.DataPoints) != 1 || responseSize.DataPoints[0].Sum != int64(len("unavailable")) {
+		t.Errorf("Expected the response body size, got %v", responseSize.DataPoints)
+	}
+}
+
+func TestFiber_RecordsServerMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	app := fiber.New()
+	app.Use(Fiber(WithMeterProvider(mp), WithTracerProvider(sdktrace.NewTracerProvider())))
+	app.Post("/users/:id", func(c *fiber.Ctx) error {
+		return c.Status(http.StatusServiceUnavailable).SendString("unavailable")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/users/42", strings.NewReader(`{"name":"x"}`)))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	metrics := collectServerMetrics(t, reader)
+	duration, ok := metrics["http.server.request.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 {
+		t.Fatalf("Expected one duration data point, got %v", metrics["http.server.request.duration"])
+	}
+	attrs := duration.DataPoints[0].Attributes
+	if route, _ := attrs.Value("http.route"); route.AsString() != "/users/:id" {
+		t.Errorf("Expected route /users/:id, got %s", route.AsString())
+	}
+	if errType, _ := attrs.Value("error.type"); errType.AsString() != "503" {
+		t.Errorf("Expected error.type 503, got %s", errType.AsString())
+	}
+	responseSize := metrics["http.server.response.body.size"].(metricdata.Histogram[int64])
+	if len(responseSize.DataPoints) != 1 || responseSize.DataPoints[0].Sum != int64(len("unavailable")) {
+		t.Errorf("Expected the response body size, got %v", responseSize.DataPoints)
+	}
+}
@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+type testInstrumentation struct {
+	shutdown bool
+}
+
+func (i *testInstrumentation) Shutdown(ctx context.Context) error {
+	i.shutdown = true
+	return nil
+}
+
+func TestRegisterInstrumentation(t *testing.T) {
+	inst := &testInstrumentation{}
+	var gotConfig *config.InstrumentationConfig
+	RegisterInstrumentation("test-module", func(t *Telemetry, cfg *config.InstrumentationConfig) (Instrumentation, error) {
+		gotConfig = cfg
+		return inst, nil
+	})
+
+	cfg := config.NewDefaultConfig()
+	cfg.Instrumentations["custom"] = &config.InstrumentationConfig{
+		Module:  "test-module",
+		Enabled: true,
+		Config:  map[string]interface{}{"key": "value"},
+	}
+	cfg.Instrumentations["disabled"] = &config.InstrumentationConfig{
+		Module:  "test-module",
+		Enabled: false,
+	}
+
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+	if err := tel.initInstrumentations(); err != nil {
+		t.Fatalf("initInstrumentations failed: %v", err)
+	}
+
+	if len(tel.instrumentations) != 1 {
+		t.Fatalf("Expected 1 instrumentation, got %d", len(tel.instrumentations))
+	}
+	if gotConfig == nil || gotConfig.Config["key"] != "value" {
+		t.Error("Expected factory to receive the instrumentation config")
+	}
+
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !inst.shutdown {
+		t.Error("Expected instrumentation to be shut down")
+	}
+}
+
+func TestRegisteredInstrumentations(t *testing.T) {
+	RegisterInstrumentation("zz-test", func(t *Telemetry, cfg *config.InstrumentationConfig) (Instrumentation, error) {
+		return nil, nil
+	})
+
+	found := false
+	for _, name := range RegisteredInstrumentations() {
+		if name == "zz-test" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected zz-test to be registered")
+	}
+}
+
+func TestInstrumentationKillSwitchLogged(t *testing.T) {
+	t.Setenv("TELEMETRY_INSTRUMENTATION_OUTBOX_ENABLED", "false")
+	cfg := config.NewDefaultConfig()
+	cfg.Instrumentations["outbox"] = &config.InstrumentationConfig{Enabled: false}
+
+	var out bytes.Buffer
+	tel := &Telemetry{config: cfg, logger: log.New(&out, "", 0)}
+	if err := tel.initInstrumentations(); err != nil {
+		t.Fatalf("initInstrumentations failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "instrumentation outbox disabled by TELEMETRY_INSTRUMENTATION_OUTBOX_ENABLED") {
+		t.Errorf("Expected the kill switch to be logged, got %q", out.String())
+	}
+}
@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// MeterScopeName is the instrumentation scope of instruments created by
+// Counter, Histogram and Gauge
+const MeterScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+
+// instruments caches the instruments created by Counter, Histogram and Gauge
+var instruments sync.Map
+
+// instrumentKey identifies a cached instrument. Instruments are cached per
+// meter provider, so replacing the global provider creates them anew.
+type instrumentKey struct {
+	provider metric.MeterProvider
+	kind     string
+	name     string
+}
+
+// cachedInstrument returns the instrument of kind and name of the global
+// meter provider, created on first use. An instrument which cannot be
+// created is reported to the global error handler and replaced by fallback.
+func cachedInstrument[T any](kind, name string, create func(metric.Meter) (T, error), fallback T) T {
+	provider := otel.GetMeterProvider()
+	key := instrumentKey{provider: provider, kind: kind, name: name}
+	if inst, ok := instruments.Load(key); ok {
+		return inst.(T)
+	}
+
+	inst, err := create(provider.Meter(MeterScopeName))
+	if err != nil {
+		otel.Handle(fmt.Errorf("failed to create %s %q: %w", kind, name, err))
+		inst = fallback
+	}
+	actual, _ := instruments.LoadOrStore(key, inst)
+	return actual.(T)
+}
+
+// CounterInstrument is a cached int64 counter
+type CounterInstrument struct {
+	counter metric.Int64Counter
+}
+
+// Counter returns the int64 counter name, created on first use. Options
+// only apply when the counter is created.
+//
+//	telemetry.Counter("orders.created").Add(ctx, 1, attribute.String("channel", "web"))
+func Counter(name string, opts ...metric.Int64CounterOption) *CounterInstrument {
+	return cachedInstrument("counter", name, func(m metric.Meter) (*CounterInstrument, error) {
+		c, err := m.Int64Counter(name, opts...)
+		return &CounterInstrument{counter: c}, err
+	}, &CounterInstrument{counter: noop.Int64Counter{}})
+}
+
+// Add adds incr to the counter
+func (c *CounterInstrument) Add(ctx context.Context, incr int64, attrs ...attribute.KeyValue) {
+	c.counter.Add(ctx, incr, metric.WithAttributes(attrs...))
+}
+
+// HistogramInstrument is a cached float64 histogram
+type HistogramInstrument struct {
+	histogram metric.Float64Histogram
+}
+
+// Histogram returns the float64 histogram name, created on first use.
+// Options only apply when the histogram is created.
+func Histogram(name string, opts ...metric.Float64HistogramOption) *HistogramInstrument {
+	return cachedInstrument("histogram", name, func(m metric.Meter) (*HistogramInstrument, error) {
+		h, err := m.Float64Histogram(name, opts...)
+		return &HistogramInstrument{histogram: h}, err
+	}, &HistogramInstrument{histogram: noop.Float64Histogram{}})
+}
+
+// Record records value in the histogram
+func (h *HistogramInstrument) Record(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	h.histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+}
+
+// GaugeInstrument is a cached synchronous float64 gauge
+type GaugeInstrument struct {
+	gauge metric.Float64Gauge
+}
+
+// Gauge returns the float64 gauge name, created on first use. Options only
+// apply when the gauge is created.
+func Gauge(name string, opts ...metric.Float64GaugeOption) *GaugeInstrument {
+	return cachedInstrument("gauge", name, func(m metric.Meter) (*GaugeInstrument, error) {
+		g, err := m.Float64Gauge(name, opts...)
+		return &GaugeInstrument{gauge: g}, err
+	}, &GaugeInstrument{gauge: noop.Float64Gauge{}})
+}
+
+// Record sets the gauge to value
+func (g *GaugeInstrument) Record(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	g.gauge.Record(ctx, value, metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// recordGlobalMetrics installs a meter provider with a manual reader for
+// the test
+func recordGlobalMetrics(t *testing.T) *sdkmetric.ManualReader {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+	return reader
+}
+
+// collectMetrics returns the metrics of reader by name
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestCachedInstruments(t *testing.T) {
+	reader := recordGlobalMetrics(t)
+	ctx := context.Background()
+
+	if Counter("orders.created") != Counter("orders.created") {
+		t.Error("Expected the counter to be cached")
+	}
+	Counter("orders.created").Add(ctx, 1, attribute.String("channel", "web"))
+	Counter("orders.created").Add(ctx, 2, attribute.String("channel", "web"))
+	Histogram("orders.value").Record(ctx, 12.5)
+	Gauge("orders.open").Record(ctx, 3)
+	Gauge("orders.open").Record(ctx, 4)
+
+	metrics := collectMetrics(t, reader)
+	if got := metrics["orders.created"].(metricdata.Sum[int64]).DataPoints[0].Value; got != 3 {
+		t.Errorf("Expected 3 orders, got %d", got)
+	}
+	if got := metrics["orders.value"].(metricdata.Histogram[float64]).DataPoints[0].Sum; got != 12.5 {
+		t.Errorf("Expected a sum of 12.5, got %v", got)
+	}
+	if got := metrics["orders.open"].(metricdata.Gauge[float64]).DataPoints[0].Value; got != 4 {
+		t.Errorf("Expected the last gauge value, got %v", got)
+	}
+
+	// A new global provider gets instruments of its own
+	reader = recordGlobalMetrics(t)
+	Counter("orders.created").Add(ctx, 1)
+	if got := collectMetrics(t, reader)["orders.created"].(metricdata.Sum[int64]).DataPoints[0].Value; got != 1 {
+		t.Errorf("Expected 1 order on the new provider, got %d", got)
+	}
+}
+
+func TestCachedInstruments_InvalidName(t *testing.T) {
+	recordGlobalMetrics(t)
+	previous := otel.GetErrorHandler()
+	var handled error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) { handled = err }))
+	t.Cleanup(func() { otel.SetErrorHandler(previous) })
+
+	// Invalid instruments are replaced by no-ops
+	Counter("1-invalid").Add(context.Background(), 1)
+	if handled == nil {
+		t.Error("Expected the error to be reported")
+	}
+}
@@ -0,0 +1,82 @@
+// Package baggageattr carries single values in the baggage and stamps them
+// onto spans and log records as attributes of the same key. It backs the
+// correlation ID and the tenant.
+package baggageattr
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// FromContext returns the value of the baggage member key of ctx
+func FromContext(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// ContextWithValue stores value as baggage member key of ctx
+func ContextWithValue(ctx context.Context, key, value string) context.Context {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// Processor stamps a baggage member of the current context onto spans (on
+// start) and log records (on emit). It is both a span and a log processor.
+type Processor struct {
+	key string
+}
+
+var (
+	_ sdktrace.SpanProcessor = Processor{}
+	_ sdklog.Processor       = Processor{}
+)
+
+// NewProcessor creates a processor stamping the baggage member key
+func NewProcessor(key string) Processor {
+	return Processor{key: key}
+}
+
+// OnStart adds the member as span attribute
+func (p Processor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if v := FromContext(parent, p.key); v != "" {
+		s.SetAttributes(attribute.String(p.key, v))
+	}
+}
+
+// OnEnd does nothing
+func (Processor) OnEnd(s sdktrace.ReadOnlySpan) {}
+
+// Enabled returns false: the processor only adds an attribute for the
+// processors registered after it, which decide whether records are needed
+func (p Processor) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return false
+}
+
+// OnEmit adds the member as record attribute
+func (p Processor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if v := FromContext(ctx, p.key); v != "" {
+		record.AddAttributes(log.String(p.key, v))
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (Processor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (Processor) ForceFlush(ctx context.Context) error {
+	return nil
+}
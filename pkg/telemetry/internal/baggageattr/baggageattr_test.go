@@ -0,0 +1,30 @@
+package baggageattr
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProcessor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewProcessor("k")), sdktrace.WithSyncer(exporter))
+	tracer := tp.Tracer("test")
+
+	_, span := tracer.Start(ContextWithValue(context.Background(), "k", "v"), "with")
+	span.End()
+	_, span = tracer.Start(ContextWithValue(context.Background(), "other", "v"), "without")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if attrs := spans[0].Attributes; len(attrs) != 1 || attrs[0].Key != "k" || attrs[0].Value.AsString() != "v" {
+		t.Errorf("Expected the member as attribute, got %v", attrs)
+	}
+	for _, s := range spans[1:] {
+		if len(s.Attributes) != 0 {
+			t.Errorf("Expected no attribute on %s, got %v", s.Name, s.Attributes)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// jobShutdownTimeout bounds the final export of RunJob
+const jobShutdownTimeout = 30 * time.Second
+
+// ForceFlush exports all spans, metrics and log records recorded so far,
+// e.g. before a CLI command returns without shutting telemetry down
+func (t *Telemetry) ForceFlush(ctx context.Context) error {
+	var errors []error
+
+	if t.tracerProvider != nil {
+		if err := t.tracerProvider.ForceFlush(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to flush tracer provider: %w", err))
+		}
+	}
+
+	if t.meterProvider != nil {
+		if err := t.meterProvider.ForceFlush(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to flush meter provider: %w", err))
+		}
+	}
+
+	if t.loggerProvider != nil {
+		if err := t.loggerProvider.ForceFlush(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to flush logger provider: %w", err))
+		}
+	}
+
+	if t.auditProvider != nil {
+		if err := t.auditProvider.ForceFlush(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to flush audit logger provider: %w", err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("flush errors: %v", errors)
+	}
+	return nil
+}
+
+// RunJob initializes telemetry for a short-lived job, runs fn in a span
+// named name and shuts telemetry down, so everything recorded is exported
+// even if fn fails, panics or ctx was canceled. A panic of fn is returned as
+// *PanicError.
+//
+//	err := telemetry.RunJob(ctx, "nightly import", importCatalog)
+func RunJob(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...Option) error {
+	tel, err := New(opts...)
+	if err != nil {
+		return fmt.Errorf("failed to initialize telemetry: %w", err)
+	}
+
+	err = Trace(ctx, name, fn)
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobShutdownTimeout)
+	defer cancel()
+	if serr := tel.Shutdown(shutdownCtx); serr != nil {
+		return errors.Join(err, fmt.Errorf("failed to shutdown telemetry: %w", serr))
+	}
+	return err
+}
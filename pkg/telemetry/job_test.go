@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// keptSpans keeps the exported spans on shutdown, unlike the in-memory
+// exporter
+type keptSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+// Shutdown does nothing
+func (e keptSpans) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestRunJob(t *testing.T) {
+	previousTracer, previousMeter := otel.GetTracerProvider(), otel.GetMeterProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousTracer)
+		otel.SetMeterProvider(previousMeter)
+	})
+
+	var mu sync.Mutex
+	var pushed []string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushed = append(pushed, r.URL.Path+"\n"+string(body))
+		mu.Unlock()
+	}))
+	defer gateway.Close()
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "importer"
+	cfg.Logging.Enabled = false
+	cfg.Metrics.HostMetrics = false
+	cfg.Metrics.RuntimeMetrics = false
+	cfg.Metrics.Exporter = &config.ExporterConfig{
+		Module: "pushgateway",
+		Config: map[string]interface{}{"url": gateway.URL},
+	}
+	spans := keptSpans{tracetest.NewInMemoryExporter()}
+	errFailed := errors.New("source unavailable")
+
+	err := RunJob(context.Background(), "import", func(ctx context.Context) error {
+		Counter("rows.imported").Add(ctx, 7)
+		return errFailed
+	}, WithConfig(cfg), WithSpanExporter(spans), WithLogger(log.New(io.Discard, "", 0)))
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Expected the error of the job, got %v", err)
+	}
+
+	if got := spans.GetSpans(); len(got) != 1 || got[0].Name != "import" {
+		t.Errorf("Expected the job span to be exported, got %v", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushed) == 0 {
+		t.Fatal("Expected the metrics to be pushed on shutdown")
+	}
+	last := pushed[len(pushed)-1]
+	if !strings.HasPrefix(last, "/metrics/job/importer\n") || !strings.Contains(last, "rows_imported_total 7") {
+		t.Errorf("Unexpected push %q", last)
+	}
+}
+
+func TestForceFlush(t *testing.T) {
+	spans := tracetest.NewInMemoryExporter()
+	tel := &Telemetry{tracerProvider: sdktrace.NewTracerProvider(sdktrace.WithBatcher(spans))}
+	_, span := tel.tracerProvider.Tracer("test").Start(context.Background(), "command")
+	span.End()
+
+	if err := tel.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	if len(spans.GetSpans()) != 1 {
+		t.Errorf("Expected the span to be exported, got %d", len(spans.GetSpans()))
+	}
+}
@@ -0,0 +1,143 @@
+// Package logbridge forwards entries from third-party logging libraries
+// (zap, logrus) into the OpenTelemetry log pipeline, so existing services
+// get exported, trace-correlated logs without rewriting their log calls.
+package logbridge
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// ScopeName is the default instrumentation scope of bridged records
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/logbridge"
+
+// config holds the settings shared by all bridges
+type config struct {
+	loggerProvider log.LoggerProvider
+	scope          string
+	clock          clock.Clock
+}
+
+// Option configures a log bridge
+type Option func(*config)
+
+// WithLoggerProvider sets the logger provider records are emitted through.
+// Defaults to the global logger provider installed by telemetry.New.
+func WithLoggerProvider(lp log.LoggerProvider) Option {
+	return func(c *config) {
+		c.loggerProvider = lp
+	}
+}
+
+// WithScope sets the instrumentation scope name of the emitted records
+func WithScope(name string) Option {
+	return func(c *config) {
+		c.scope = name
+	}
+}
+
+// WithClock sets the clock of the observed timestamps of records, the
+// system clock by default
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// newLogger applies the options and returns the OpenTelemetry logger and
+// the clock of observed timestamps
+func newLogger(opts []Option) (log.Logger, clock.Clock) {
+	cfg := &config{scope: ScopeName}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.loggerProvider == nil {
+		cfg.loggerProvider = global.GetLoggerProvider()
+	}
+	return cfg.loggerProvider.Logger(cfg.scope), clock.OrSystem(cfg.clock)
+}
+
+// fieldsToKeyValues converts a field map into attributes sorted by key,
+// so the attribute order is stable across emits
+func fieldsToKeyValues(fields map[string]interface{}) []log.KeyValue {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]log.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, log.KeyValue{Key: k, Value: anyValue(fields[k])})
+	}
+	return kvs
+}
+
+// anyValue converts an arbitrary field value into a log value
+func anyValue(v interface{}) log.Value {
+	switch x := v.(type) {
+	case nil:
+		return log.Value{}
+	case string:
+		return log.StringValue(x)
+	case bool:
+		return log.BoolValue(x)
+	case int:
+		return log.IntValue(x)
+	case int8:
+		return log.Int64Value(int64(x))
+	case int16:
+		return log.Int64Value(int64(x))
+	case int32:
+		return log.Int64Value(int64(x))
+	case int64:
+		return log.Int64Value(x)
+	case uint8:
+		return log.Int64Value(int64(x))
+	case uint16:
+		return log.Int64Value(int64(x))
+	case uint32:
+		return log.Int64Value(int64(x))
+	case uint:
+		return uintValue(uint64(x))
+	case uint64:
+		return uintValue(x)
+	case float32:
+		return log.Float64Value(float64(x))
+	case float64:
+		return log.Float64Value(x)
+	case []byte:
+		return log.BytesValue(x)
+	case time.Time:
+		return log.StringValue(x.Format(time.RFC3339Nano))
+	case time.Duration:
+		return log.Int64Value(x.Nanoseconds())
+	case error:
+		return log.StringValue(x.Error())
+	case fmt.Stringer:
+		return log.StringValue(x.String())
+	case []interface{}:
+		values := make([]log.Value, 0, len(x))
+		for _, item := range x {
+			values = append(values, anyValue(item))
+		}
+		return log.SliceValue(values...)
+	case map[string]interface{}:
+		return log.MapValue(fieldsToKeyValues(x)...)
+	default:
+		return log.StringValue(fmt.Sprintf("%+v", x))
+	}
+}
+
+// uintValue converts unsigned values, falling back to a string on overflow
+func uintValue(u uint64) log.Value {
+	if u > uint64(1<<63-1) {
+		return log.StringValue(fmt.Sprint(u))
+	}
+	return log.Int64Value(int64(u))
+}
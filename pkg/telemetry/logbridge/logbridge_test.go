@@ -0,0 +1,104 @@
+package logbridge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingExporter keeps exported log records in memory
+type recordingExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func newTestProvider() (*sdklog.LoggerProvider, *recordingExporter) {
+	exporter := &recordingExporter{}
+	return sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter))), exporter
+}
+
+func attributes(r sdklog.Record) map[string]string {
+	attrs := make(map[string]string)
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+	return attrs
+}
+
+func TestZapCore(t *testing.T) {
+	lp, exporter := newTestProvider()
+	logger := zap.New(NewZapCore(zapcore.InfoLevel, WithLoggerProvider(lp))).With(zap.String("service", "orders"))
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "op")
+	logger.Debug("dropped")
+	logger.Error("payment failed", zap.Int("attempt", 3), zap.Error(errors.New("timeout")), ZapContext(ctx))
+	span.End()
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(exporter.records))
+	}
+	record := exporter.records[0]
+
+	if record.Severity() != log.SeverityError {
+		t.Errorf("Expected severity ERROR, got %v", record.Severity())
+	}
+	if record.Body().AsString() != "payment failed" {
+		t.Errorf("Unexpected body %q", record.Body().AsString())
+	}
+	if record.TraceID() != span.SpanContext().TraceID() {
+		t.Error("Expected record to carry the trace ID from ZapContext")
+	}
+
+	attrs := attributes(record)
+	for key, want := range map[string]string{"service": "orders", "attempt": "3", "error": "timeout"} {
+		if attrs[key] != want {
+			t.Errorf("Expected attribute %s=%s, got %q", key, want, attrs[key])
+		}
+	}
+	if _, ok := attrs[contextFieldKey]; ok {
+		t.Error("Context field should not be exported as an attribute")
+	}
+}
+
+func TestLogrusHook(t *testing.T) {
+	lp, exporter := newTestProvider()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(NewLogrusHook(nil, WithLoggerProvider(lp)))
+
+	logger.WithField("user", "alice").Warn("quota almost exceeded")
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(exporter.records))
+	}
+	record := exporter.records[0]
+
+	if record.Severity() != log.SeverityWarn {
+		t.Errorf("Expected severity WARN, got %v", record.Severity())
+	}
+	if attributes(record)["user"] != "alice" {
+		t.Error("Expected logrus fields to become attributes")
+	}
+}
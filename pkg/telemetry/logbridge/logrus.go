@@ -0,0 +1,83 @@
+package logbridge
+
+import (
+	"context"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/log"
+)
+
+// LogrusHook is a logrus hook that emits entries as OpenTelemetry log records
+type LogrusHook struct {
+	logger log.Logger
+	clock  clock.Clock
+	levels []logrus.Level
+}
+
+// NewLogrusHook creates a logrus hook bridging into the OpenTelemetry log
+// pipeline for the given levels (all levels when none are given).
+// Install it with logger.AddHook; use logger.WithContext(ctx) to correlate
+// entries with the active span.
+func NewLogrusHook(levels []logrus.Level, opts ...Option) *LogrusHook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	logger, c := newLogger(opts)
+	return &LogrusHook{
+		logger: logger,
+		clock:  c,
+		levels: levels,
+	}
+}
+
+// Levels returns the levels the hook fires for
+func (h *LogrusHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire converts the entry and emits the record
+func (h *LogrusHook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var record log.Record
+	record.SetTimestamp(entry.Time)
+	record.SetObservedTimestamp(h.clock.Now())
+	record.SetSeverity(logrusSeverity(entry.Level))
+	record.SetSeverityText(entry.Level.String())
+	record.SetBody(log.StringValue(entry.Message))
+	if entry.HasCaller() {
+		record.AddAttributes(
+			log.String("code.file.path", entry.Caller.File),
+			log.Int("code.line.number", entry.Caller.Line),
+			log.String("code.function.name", entry.Caller.Function),
+		)
+	}
+	record.AddAttributes(fieldsToKeyValues(entry.Data)...)
+
+	h.logger.Emit(ctx, record)
+	return nil
+}
+
+// logrusSeverity maps logrus levels onto OpenTelemetry severities
+func logrusSeverity(level logrus.Level) log.Severity {
+	switch level {
+	case logrus.TraceLevel:
+		return log.SeverityTrace
+	case logrus.DebugLevel:
+		return log.SeverityDebug
+	case logrus.InfoLevel:
+		return log.SeverityInfo
+	case logrus.WarnLevel:
+		return log.SeverityWarn
+	case logrus.ErrorLevel:
+		return log.SeverityError
+	case logrus.FatalLevel:
+		return log.SeverityFatal
+	default:
+		return log.SeverityFatal4
+	}
+}
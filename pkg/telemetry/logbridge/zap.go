@@ -0,0 +1,127 @@
+package logbridge
+
+import (
+	"context"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"go.opentelemetry.io/otel/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// contextFieldKey is the key of the field carrying the request context
+const contextFieldKey = "context"
+
+// ZapCore is a zapcore.Core that emits entries as OpenTelemetry log records.
+// Combine it with an existing core via zapcore.NewTee to keep local output.
+type ZapCore struct {
+	zapcore.LevelEnabler
+	logger log.Logger
+	clock  clock.Clock
+	fields []zapcore.Field
+}
+
+// NewZapCore creates a zap core bridging into the OpenTelemetry log pipeline.
+// Entries below the given level are dropped.
+func NewZapCore(level zapcore.LevelEnabler, opts ...Option) *ZapCore {
+	logger, c := newLogger(opts)
+	return &ZapCore{
+		LevelEnabler: level,
+		logger:       logger,
+		clock:        c,
+	}
+}
+
+// ZapContext returns a field that carries ctx to the bridge, so records are
+// correlated with the active span. Encoders other than the bridge ignore it.
+func ZapContext(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextFieldKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// With returns a core that adds the given fields to every entry
+func (c *ZapCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	clone.fields = append(clone.fields, c.fields...)
+	clone.fields = append(clone.fields, fields...)
+	return &clone
+}
+
+// Check adds the core to the checked entry if the level is enabled
+func (c *ZapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.logger.Enabled(context.Background(), log.EnabledParameters{Severity: zapSeverity(ent.Level)}) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Write converts the entry and its fields and emits the record
+func (c *ZapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ctx := context.Background()
+	enc := zapcore.NewMapObjectEncoder()
+
+	for _, set := range [][]zapcore.Field{c.fields, fields} {
+		for _, f := range set {
+			if f.Type == zapcore.SkipType {
+				if fctx, ok := f.Interface.(context.Context); ok {
+					ctx = fctx
+				}
+				continue
+			}
+			f.AddTo(enc)
+		}
+	}
+
+	var record log.Record
+	record.SetTimestamp(ent.Time)
+	record.SetObservedTimestamp(c.clock.Now())
+	record.SetSeverity(zapSeverity(ent.Level))
+	record.SetSeverityText(ent.Level.CapitalString())
+	record.SetBody(log.StringValue(ent.Message))
+	if ent.LoggerName != "" {
+		record.AddAttributes(log.String("logger.name", ent.LoggerName))
+	}
+	if ent.Caller.Defined {
+		record.AddAttributes(
+			log.String("code.file.path", ent.Caller.File),
+			log.Int("code.line.number", ent.Caller.Line),
+		)
+	}
+	record.AddAttributes(fieldsToKeyValues(enc.Fields)...)
+
+	c.logger.Emit(ctx, record)
+	return nil
+}
+
+// Sync is a no-op; flushing is handled by the logger provider
+func (c *ZapCore) Sync() error {
+	return nil
+}
+
+// zapSeverity maps zap levels onto OpenTelemetry severities
+func zapSeverity(level zapcore.Level) log.Severity {
+	switch level {
+	case zapcore.DebugLevel:
+		return log.SeverityDebug
+	case zapcore.InfoLevel:
+		return log.SeverityInfo
+	case zapcore.WarnLevel:
+		return log.SeverityWarn
+	case zapcore.ErrorLevel:
+		return log.SeverityError
+	case zapcore.DPanicLevel:
+		return log.SeverityFatal1
+	case zapcore.PanicLevel:
+		return log.SeverityFatal2
+	case zapcore.FatalLevel:
+		return log.SeverityFatal3
+	default:
+		if level < zapcore.DebugLevel {
+			return log.SeverityTrace
+		}
+		return log.SeverityFatal4
+	}
+}
@@ -0,0 +1,188 @@
+// Package messaging provides propagation carriers for the headers of
+// common messaging clients, so producers and consumers can pass the trace
+// context along with their messages.
+//
+// The carriers match the header types of the clients structurally, without
+// depending on the client libraries:
+//
+//	// segmentio/kafka-go and confluent-kafka-go
+//	messaging.InjectIntoHeaders(ctx, messaging.KafkaCarrier[kafka.Header]{Headers: &msg.Headers})
+//	// IBM/sarama
+//	messaging.InjectIntoHeaders(ctx, messaging.SaramaCarrier[sarama.RecordHeader]{Headers: &msg.Headers})
+//	// rabbitmq/amqp091-go
+//	ctx = messaging.ExtractFromHeaders(ctx, messaging.TableCarrier(delivery.Headers))
+//	// nats-io/nats.go
+//	ctx = messaging.ExtractFromHeaders(ctx, messaging.NATSCarrier(msg.Header))
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InjectIntoHeaders stores the trace context and baggage of ctx in carrier
+// with the global propagator
+func InjectIntoHeaders(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// ExtractFromHeaders returns ctx with the trace context and baggage read
+// from carrier with the global propagator
+func ExtractFromHeaders(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// MapCarrier carries the context in a map[string]string, e.g. message
+// attributes or a JSON envelope
+type MapCarrier = propagation.MapCarrier
+
+// KafkaCarrier carries the context in Kafka headers with string keys, e.g.
+// kafka.Header of segmentio/kafka-go or confluent-kafka-go
+type KafkaCarrier[H ~struct {
+	Key   string
+	Value []byte
+}] struct {
+	Headers *[]H
+}
+
+// Get returns the value of the first header key
+func (c KafkaCarrier[H]) Get(key string) string {
+	for _, h := range *c.Headers {
+		if kh := kafkaHeader(h); kh.Key == key {
+			return string(kh.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the headers key with value
+func (c KafkaCarrier[H]) Set(key, value string) {
+	headers := (*c.Headers)[:0]
+	for _, h := range *c.Headers {
+		if kafkaHeader(h).Key != key {
+			headers = append(headers, h)
+		}
+	}
+	*c.Headers = append(headers, H(kafkaHeader{Key: key, Value: []byte(value)}))
+}
+
+// Keys returns the header keys
+func (c KafkaCarrier[H]) Keys() []string {
+	keys := make([]string, 0, len(*c.Headers))
+	for _, h := range *c.Headers {
+		keys = append(keys, kafkaHeader(h).Key)
+	}
+	return keys
+}
+
+// kafkaHeader is the structure of Kafka headers with string keys
+type kafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// SaramaCarrier carries the context in Kafka headers with byte keys, e.g.
+// sarama.RecordHeader of the produced messages
+type SaramaCarrier[H ~struct {
+	Key   []byte
+	Value []byte
+}] struct {
+	Headers *[]H
+}
+
+// Get returns the value of the first header key
+func (c SaramaCarrier[H]) Get(key string) string {
+	for _, h := range *c.Headers {
+		if rh := recordHeader(h); string(rh.Key) == key {
+			return string(rh.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the headers key with value
+func (c SaramaCarrier[H]) Set(key, value string) {
+	headers := (*c.Headers)[:0]
+	for _, h := range *c.Headers {
+		if string(recordHeader(h).Key) != key {
+			headers = append(headers, h)
+		}
+	}
+	*c.Headers = append(headers, H(recordHeader{Key: []byte(key), Value: []byte(value)}))
+}
+
+// Keys returns the header keys
+func (c SaramaCarrier[H]) Keys() []string {
+	keys := make([]string, 0, len(*c.Headers))
+	for _, h := range *c.Headers {
+		keys = append(keys, string(recordHeader(h).Key))
+	}
+	return keys
+}
+
+// recordHeader is the structure of Kafka headers with byte keys
+type recordHeader struct {
+	Key   []byte
+	Value []byte
+}
+
+// TableCarrier carries the context in an AMQP table, e.g. amqp091.Table
+type TableCarrier map[string]interface{}
+
+// Get returns the value of key if it is a string or bytes
+func (c TableCarrier) Get(key string) string {
+	switch v := c[key].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Set sets key to value
+func (c TableCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys returns the keys of the table
+func (c TableCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// NATSCarrier carries the context in NATS headers, e.g. nats.Header. Unlike
+// propagation.HeaderCarrier it keeps the case of the keys, NATS headers are
+// case-sensitive.
+type NATSCarrier map[string][]string
+
+// Get returns the first value of key
+func (c NATSCarrier) Get(key string) string {
+	if values := c[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set replaces the values of key with value
+func (c NATSCarrier) Set(key, value string) {
+	c[key] = []string{value}
+}
+
+// Keys returns the header keys
+func (c NATSCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
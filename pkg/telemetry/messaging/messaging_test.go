@@ -0,0 +1,80 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header types of the messaging clients
+type (
+	kafkaGoHeader struct {
+		Key   string
+		Value []byte
+	}
+	saramaRecordHeader struct {
+		Key   []byte
+		Value []byte
+	}
+	amqpTable  map[string]interface{}
+	natsHeader map[string][]string
+)
+
+func TestCarriers(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	kafkaHeaders := []kafkaGoHeader{{Key: "traceparent", Value: []byte("stale")}, {Key: "order", Value: []byte("42")}}
+	saramaHeaders := []saramaRecordHeader{{Key: []byte("order"), Value: []byte("42")}}
+	carriers := map[string]propagation.TextMapCarrier{
+		"map":    MapCarrier{},
+		"kafka":  KafkaCarrier[kafkaGoHeader]{Headers: &kafkaHeaders},
+		"sarama": SaramaCarrier[saramaRecordHeader]{Headers: &saramaHeaders},
+		"amqp":   TableCarrier(amqpTable{}),
+		"nats":   NATSCarrier(natsHeader{}),
+	}
+	for name, carrier := range carriers {
+		InjectIntoHeaders(ctx, carrier)
+		got := trace.SpanContextFromContext(ExtractFromHeaders(context.Background(), carrier))
+		if got.TraceID() != sc.TraceID() || got.SpanID() != sc.SpanID() || !got.IsRemote() {
+			t.Errorf("%s: Expected the span context to round-trip, got %v", name, got)
+		}
+		if keys := carrier.Keys(); len(keys) == 0 {
+			t.Errorf("%s: Expected keys", name)
+		}
+	}
+
+	// Set replaces existing headers and keeps the others
+	if len(kafkaHeaders) != 2 || kafkaHeaders[0].Key != "order" {
+		t.Errorf("Expected the stale traceparent to be replaced, got %v", kafkaHeaders)
+	}
+	if len(saramaHeaders) != 2 || string(saramaHeaders[0].Key) != "order" {
+		t.Errorf("Expected the other headers to be kept, got %v", saramaHeaders)
+	}
+}
+
+func TestTableCarrier_Get(t *testing.T) {
+	c := TableCarrier{"bytes": []byte("b"), "number": 7}
+	if c.Get("bytes") != "b" || c.Get("number") != "7" || c.Get("missing") != "" {
+		t.Errorf("Unexpected values %q %q %q", c.Get("bytes"), c.Get("number"), c.Get("missing"))
+	}
+}
+
+func TestNATSCarrier_Case(t *testing.T) {
+	c := NATSCarrier{}
+	c.Set("traceparent", "v")
+	if _, ok := c["traceparent"]; !ok || c.Get("Traceparent") != "" {
+		t.Errorf("Expected the key to keep its case, got %v", c)
+	}
+}
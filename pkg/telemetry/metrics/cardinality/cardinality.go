@@ -0,0 +1,138 @@
+// Package cardinality guards metric backends against unbounded attribute
+// values, such as user IDs in a path.
+//
+// The SDK enforces the limit: once an instrument reaches the configured
+// number of distinct attribute sets, further measurements are collapsed
+// into a single overflow data point carrying otel.metric.overflow=true.
+// This package wraps the metric exporter to detect those overflow data
+// points, log a warning once per instrument and count the overflows in the
+// telemetry.metrics.cardinality_overflows metric.
+package cardinality
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// ScopeName is the instrumentation scope used for the overflow metric
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/cardinality"
+
+// DefaultLimit is the per-instrument limit recommended by the OpenTelemetry specification
+const DefaultLimit = 2000
+
+// OverflowKey marks the data point collecting measurements beyond the limit
+const OverflowKey = attribute.Key("otel.metric.overflow")
+
+// Exporter reports instruments that exceeded the cardinality limit
+type Exporter struct {
+	sdkmetric.Exporter
+	logger *log.Logger
+
+	mu        sync.Mutex
+	overflows map[string]int64
+}
+
+// NewExporter wraps next. Warnings are written to logger if not nil.
+func NewExporter(next sdkmetric.Exporter, logger *log.Logger) *Exporter {
+	return &Exporter{
+		Exporter:  next,
+		logger:    logger,
+		overflows: make(map[string]int64),
+	}
+}
+
+// Export records overflowing instruments and exports the metrics
+func (e *Exporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if overflowed(m.Data) {
+				e.record(m.Name)
+			}
+		}
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+// record counts an overflow and warns on the first one of an instrument
+func (e *Exporter) record(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.overflows[name] == 0 && e.logger != nil {
+		e.logger.Printf("metric %s exceeded its cardinality limit, further attribute sets are collapsed into %s=true", name, OverflowKey)
+	}
+	e.overflows[name]++
+}
+
+// Overflows returns the number of exports in which each instrument overflowed
+func (e *Exporter) Overflows() map[string]int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make(map[string]int64, len(e.overflows))
+	for k, v := range e.overflows {
+		out[k] = v
+	}
+	return out
+}
+
+// RegisterMetrics registers the telemetry.metrics.cardinality_overflows
+// counter on mp. It is separate from NewExporter because the exporter must
+// exist before the meter provider.
+func (e *Exporter) RegisterMetrics(mp metric.MeterProvider) (metric.Registration, error) {
+	meter := mp.Meter(ScopeName)
+	counter, err := meter.Int64ObservableCounter("telemetry.metrics.cardinality_overflows",
+		metric.WithDescription("Number of exports in which an instrument exceeded its cardinality limit"),
+		metric.WithUnit("{export}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for name, n := range e.Overflows() {
+			o.ObserveInt64(counter, n, metric.WithAttributes(attribute.String("metric.name", name)))
+		}
+		return nil
+	}, counter)
+}
+
+// overflowed reports whether the aggregation contains an overflow data point
+func overflowed(data metricdata.Aggregation) bool {
+	switch a := data.(type) {
+	case metricdata.Gauge[int64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.DataPoint[int64]) attribute.Set { return dp.Attributes })
+	case metricdata.Gauge[float64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.DataPoint[float64]) attribute.Set { return dp.Attributes })
+	case metricdata.Sum[int64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.DataPoint[int64]) attribute.Set { return dp.Attributes })
+	case metricdata.Sum[float64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.DataPoint[float64]) attribute.Set { return dp.Attributes })
+	case metricdata.Histogram[int64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.HistogramDataPoint[int64]) attribute.Set { return dp.Attributes })
+	case metricdata.Histogram[float64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.HistogramDataPoint[float64]) attribute.Set { return dp.Attributes })
+	case metricdata.ExponentialHistogram[int64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.ExponentialHistogramDataPoint[int64]) attribute.Set { return dp.Attributes })
+	case metricdata.ExponentialHistogram[float64]:
+		return anyOverflow(a.DataPoints, func(dp metricdata.ExponentialHistogramDataPoint[float64]) attribute.Set { return dp.Attributes })
+	}
+	return false
+}
+
+// anyOverflow checks the data points for the overflow attribute
+func anyOverflow[T any](points []T, attrs func(T) attribute.Set) bool {
+	for _, dp := range points {
+		set := attrs(dp)
+		if v, ok := set.Value(OverflowKey); ok && v.AsBool() {
+			return true
+		}
+	}
+	return false
+}
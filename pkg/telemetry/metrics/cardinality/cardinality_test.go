@@ -0,0 +1,63 @@
+package cardinality
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// discardExporter drops all metrics
+type discardExporter struct {
+	sdkmetric.Exporter
+}
+
+func (discardExporter) Export(context.Context, *metricdata.ResourceMetrics) error { return nil }
+
+func TestExporter(t *testing.T) {
+	var logs bytes.Buffer
+	exporter := NewExporter(discardExporter{}, log.New(&logs, "", 0))
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithCardinalityLimit(3))
+	if _, err := exporter.RegisterMetrics(mp); err != nil {
+		t.Fatalf("RegisterMetrics failed: %v", err)
+	}
+
+	counter, _ := mp.Meter("test").Int64Counter("requests")
+	for i := 0; i < 10; i++ {
+		counter.Add(context.Background(), 1, metric.WithAttributes(attribute.String("user.id", fmt.Sprint(i))))
+	}
+
+	var rm metricdata.ResourceMetrics
+	reader.Collect(context.Background(), &rm)
+	exporter.Export(context.Background(), &rm)
+	exporter.Export(context.Background(), &rm)
+
+	if got := exporter.Overflows()["requests"]; got != 2 {
+		t.Errorf("Expected 2 overflowing exports, got %d", got)
+	}
+	if strings.Count(logs.String(), "exceeded its cardinality limit") != 1 {
+		t.Errorf("Expected a single warning, got %q", logs.String())
+	}
+
+	reader.Collect(context.Background(), &rm)
+	found := false
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "telemetry.metrics.cardinality_overflows" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected overflow metric to be reported")
+	}
+}
@@ -0,0 +1,146 @@
+// Package expvar bridges the numeric variables published with the standard
+// library's expvar package into OpenTelemetry metrics, for dependencies
+// that still publish their statistics that way.
+//
+// The Producer is registered on a metric reader and reports each numeric
+// variable as a gauge named after it with the "expvar." prefix. Numeric
+// entries of expvar maps become data points with the entry as the key
+// attribute. Other variables, like memstats and cmdline, are skipped.
+package expvar
+
+import (
+	"context"
+	"encoding/json"
+	goexpvar "expvar"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// ScopeName is the instrumentation scope of the bridged metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/expvar"
+
+// DefaultPrefix prefixes the names of the bridged metrics
+const DefaultPrefix = "expvar."
+
+// KeyAttribute is the attribute holding the entry of an expvar map
+const KeyAttribute = attribute.Key("expvar.key")
+
+// Producer produces the numeric expvar variables as gauges
+type Producer struct {
+	prefix string
+	names  map[string]struct{}
+}
+
+// Option configures the producer
+type Option func(*Producer)
+
+// WithPrefix sets the prefix of the metric names, DefaultPrefix if unset
+func WithPrefix(prefix string) Option {
+	return func(p *Producer) {
+		p.prefix = prefix
+	}
+}
+
+// WithNames bridges only the given variables
+func WithNames(names ...string) Option {
+	return func(p *Producer) {
+		if p.names == nil {
+			p.names = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			p.names[name] = struct{}{}
+		}
+	}
+}
+
+// NewProducer creates a producer, e.g. for sdkmetric.WithProducer
+func NewProducer(opts ...Option) *Producer {
+	p := &Producer{prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+var _ sdkmetric.Producer = (*Producer)(nil)
+
+// Produce returns the current values of the numeric variables
+func (p *Producer) Produce(ctx context.Context) ([]metricdata.ScopeMetrics, error) {
+	now := time.Now()
+	var metrics []metricdata.Metrics
+	goexpvar.Do(func(kv goexpvar.KeyValue) {
+		if p.names != nil {
+			if _, ok := p.names[kv.Key]; !ok {
+				return
+			}
+		}
+		if m, ok := p.metric(kv, now); ok {
+			metrics = append(metrics, m)
+		}
+	})
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+	return []metricdata.ScopeMetrics{{
+		Scope:   instrumentation.Scope{Name: ScopeName},
+		Metrics: metrics,
+	}}, nil
+}
+
+// metric converts a variable into a gauge, false if it is not numeric
+func (p *Producer) metric(kv goexpvar.KeyValue, now time.Time) (metricdata.Metrics, bool) {
+	name := p.prefix + kv.Key
+	switch v := kv.Value.(type) {
+	case *goexpvar.Int:
+		return metricdata.Metrics{Name: name, Data: metricdata.Gauge[int64]{
+			DataPoints: []metricdata.DataPoint[int64]{{Time: now, Value: v.Value()}},
+		}}, true
+	case *goexpvar.Map:
+		var points []metricdata.DataPoint[float64]
+		v.Do(func(entry goexpvar.KeyValue) {
+			if value, ok := number(entry.Value); ok {
+				points = append(points, metricdata.DataPoint[float64]{
+					Attributes: attribute.NewSet(KeyAttribute.String(entry.Key)),
+					Time:       now,
+					Value:      value,
+				})
+			}
+		})
+		if len(points) == 0 {
+			return metricdata.Metrics{}, false
+		}
+		sort.Slice(points, func(i, j int) bool {
+			a, _ := points[i].Attributes.Value(KeyAttribute)
+			b, _ := points[j].Attributes.Value(KeyAttribute)
+			return a.AsString() < b.AsString()
+		})
+		return metricdata.Metrics{Name: name, Data: metricdata.Gauge[float64]{DataPoints: points}}, true
+	default:
+		value, ok := number(kv.Value)
+		if !ok {
+			return metricdata.Metrics{}, false
+		}
+		return metricdata.Metrics{Name: name, Data: metricdata.Gauge[float64]{
+			DataPoints: []metricdata.DataPoint[float64]{{Time: now, Value: value}},
+		}}, true
+	}
+}
+
+// number parses the JSON of a variable, false if it is not a number
+func number(v goexpvar.Var) (float64, bool) {
+	s := strings.TrimSpace(v.String())
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return 0, false
+	}
+	var f float64
+	if err := json.Unmarshal([]byte(s), &f); err != nil {
+		return 0, false
+	}
+	return f, true
+}
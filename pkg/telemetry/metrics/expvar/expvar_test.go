@@ -0,0 +1,69 @@
+package expvar
+
+import (
+	"context"
+	goexpvar "expvar"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func init() {
+	goexpvar.NewInt("test_requests").Set(42)
+	goexpvar.NewFloat("test_load").Set(0.75)
+	goexpvar.NewString("test_version").Set("1.2.3")
+	cache := goexpvar.NewMap("test_cache")
+	cache.Add("hits", 9)
+	cache.Add("misses", 1)
+	cache.Set("name", new(goexpvar.String))
+	goexpvar.Publish("test_func", goexpvar.Func(func() any { return 3 }))
+}
+
+func TestProducer(t *testing.T) {
+	reader := sdkmetric.NewManualReader(sdkmetric.WithProducer(NewProducer()))
+	sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	if got := metrics["expvar.test_requests"].(metricdata.Gauge[int64]).DataPoints[0].Value; got != 42 {
+		t.Errorf("Expected 42 requests, got %d", got)
+	}
+	if got := metrics["expvar.test_load"].(metricdata.Gauge[float64]).DataPoints[0].Value; got != 0.75 {
+		t.Errorf("Expected a load of 0.75, got %v", got)
+	}
+	if got := metrics["expvar.test_func"].(metricdata.Gauge[float64]).DataPoints[0].Value; got != 3 {
+		t.Errorf("Expected 3 from the func, got %v", got)
+	}
+	cache := metrics["expvar.test_cache"].(metricdata.Gauge[float64]).DataPoints
+	if len(cache) != 2 {
+		t.Fatalf("Expected the numeric map entries, got %d", len(cache))
+	}
+	if key, _ := cache[0].Attributes.Value(KeyAttribute); key.AsString() != "hits" || cache[0].Value != 9 {
+		t.Errorf("Expected 9 hits, got %s=%v", key.AsString(), cache[0].Value)
+	}
+	for _, skipped := range []string{"expvar.test_version", "expvar.memstats", "expvar.cmdline"} {
+		if _, ok := metrics[skipped]; ok {
+			t.Errorf("Expected %s to be skipped", skipped)
+		}
+	}
+}
+
+func TestProducer_Options(t *testing.T) {
+	sms, err := NewProducer(WithPrefix("legacy."), WithNames("test_requests")).Produce(context.Background())
+	if err != nil {
+		t.Fatalf("failed to produce: %v", err)
+	}
+	if len(sms) != 1 || len(sms[0].Metrics) != 1 || sms[0].Metrics[0].Name != "legacy.test_requests" {
+		t.Errorf("Expected only legacy.test_requests, got %v", sms)
+	}
+}
@@ -0,0 +1,392 @@
+// Package goroutines collects goroutine diagnostics to catch leaks in
+// long-running services: the change of the goroutine count, goroutines
+// blocked longer than a threshold, goroutines per runtime/pprof label and
+// the scheduler latency.
+//
+// The goroutine profile is read at most once per minimum profile interval,
+// as it briefly stops the world. The runtime reports wait times in whole
+// minutes only, so the blocked threshold has a granularity of a minute.
+//
+// Goroutines are attributed to a label when started below pprof.Do:
+//
+//	pprof.Do(ctx, pprof.Labels("worker", "outbox"), func(ctx context.Context) {
+//		go processOutbox(ctx)
+//	})
+package goroutines
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	goruntime "runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope used for goroutine diagnostics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/goroutines"
+
+// Defaults of the collector
+const (
+	DefaultBlockedThreshold       = 5 * time.Minute
+	DefaultMinimumProfileInterval = 15 * time.Second
+)
+
+// schedLatencies is the runtime/metrics histogram of the time goroutines
+// spent runnable before running
+const schedLatencies = "/sched/latencies:seconds"
+
+// latencyQuantiles are the reported quantiles of the scheduler latency
+var latencyQuantiles = []float64{0.5, 0.99}
+
+// snapshot is the result of one profile read
+type snapshot struct {
+	count   int64
+	delta   int64
+	blocked map[string]int64
+	labeled map[attribute.Set]int64
+	latency []float64
+}
+
+// Metrics is a running goroutine diagnostics collector
+type Metrics struct {
+	registration metric.Registration
+
+	blockedThreshold time.Duration
+	labels           []string
+	minInterval      time.Duration
+
+	mu        sync.Mutex
+	lastRead  time.Time
+	last      snapshot
+	latencies *metrics.Float64Histogram
+	// writeProfile writes the goroutine profile in the given debug format
+	writeProfile func(w *bytes.Buffer, debug int) error
+}
+
+// config holds the collector settings
+type config struct {
+	meterProvider    metric.MeterProvider
+	blockedThreshold time.Duration
+	labels           []string
+	minInterval      time.Duration
+}
+
+// Option configures the goroutine diagnostics collector
+type Option func(*config)
+
+// WithMeterProvider sets the meter provider the instruments are registered on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithBlockedThreshold sets how long a goroutine waits before it counts as
+// blocked, DefaultBlockedThreshold if unset; thresholds below a minute act
+// as one minute
+func WithBlockedThreshold(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.blockedThreshold = d
+		}
+	}
+}
+
+// WithLabels sets the runtime/pprof label keys goroutines are counted by
+func WithLabels(keys ...string) Option {
+	return func(c *config) {
+		c.labels = append(c.labels, keys...)
+	}
+}
+
+// WithMinimumProfileInterval sets the minimum interval between two reads of
+// the goroutine profile; collections in between reuse the last values
+func WithMinimumProfileInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.minInterval = d
+	}
+}
+
+// Start registers the diagnostics instruments and returns the running collector
+func Start(opts ...Option) (*Metrics, error) {
+	cfg := &config{
+		blockedThreshold: DefaultBlockedThreshold,
+		minInterval:      DefaultMinimumProfileInterval,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	m := &Metrics{
+		blockedThreshold: cfg.blockedThreshold,
+		labels:           cfg.labels,
+		minInterval:      cfg.minInterval,
+		writeProfile: func(w *bytes.Buffer, debug int) error {
+			return pprof.Lookup("goroutine").WriteTo(w, debug)
+		},
+	}
+	if err := m.register(cfg.meterProvider.Meter(ScopeName)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register creates the observable instruments and their shared callback
+func (m *Metrics) register(meter metric.Meter) error {
+	delta, err := meter.Int64ObservableGauge("runtime.go.goroutines.delta",
+		metric.WithDescription("Change of the goroutine count since the previous profile read"),
+		metric.WithUnit("{goroutine}"))
+	if err != nil {
+		return fmt.Errorf("failed to create goroutine delta instrument: %w", err)
+	}
+
+	blocked, err := meter.Int64ObservableGauge("runtime.go.goroutines.blocked",
+		metric.WithDescription("Number of goroutines waiting longer than the blocked threshold, per wait reason"),
+		metric.WithUnit("{goroutine}"))
+	if err != nil {
+		return fmt.Errorf("failed to create blocked goroutines instrument: %w", err)
+	}
+
+	labeled, err := meter.Int64ObservableGauge("runtime.go.goroutines.labeled",
+		metric.WithDescription("Number of goroutines per runtime/pprof label"),
+		metric.WithUnit("{goroutine}"))
+	if err != nil {
+		return fmt.Errorf("failed to create labeled goroutines instrument: %w", err)
+	}
+
+	latency, err := meter.Float64ObservableGauge("runtime.go.sched.latency",
+		metric.WithDescription("Time goroutines spent runnable before running since the previous profile read"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return fmt.Errorf("failed to create scheduler latency instrument: %w", err)
+	}
+
+	m.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		s, err := m.snapshot()
+		if err != nil {
+			return err
+		}
+
+		o.ObserveInt64(delta, s.delta)
+		for reason, n := range s.blocked {
+			o.ObserveInt64(blocked, n, metric.WithAttributes(attribute.String("runtime.go.wait_reason", reason)))
+		}
+		for set, n := range s.labeled {
+			o.ObserveInt64(labeled, n, metric.WithAttributeSet(set))
+		}
+		for i, q := range latencyQuantiles {
+			if i < len(s.latency) {
+				o.ObserveFloat64(latency, s.latency[i], metric.WithAttributes(attribute.Float64("quantile", q)))
+			}
+		}
+		return nil
+	}, delta, blocked, labeled, latency)
+	if err != nil {
+		return fmt.Errorf("failed to register goroutine diagnostics callback: %w", err)
+	}
+
+	return nil
+}
+
+// snapshot returns the last read values, reading the profile again when
+// the minimum profile interval has elapsed
+func (m *Metrics) snapshot() (snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if !m.lastRead.IsZero() && now.Sub(m.lastRead) < m.minInterval {
+		return m.last, nil
+	}
+
+	s := snapshot{count: int64(goruntime.NumGoroutine())}
+	if !m.lastRead.IsZero() {
+		s.delta = s.count - m.last.count
+	}
+
+	var buf bytes.Buffer
+	if err := m.writeProfile(&buf, 2); err != nil {
+		return m.last, fmt.Errorf("failed to read goroutine stacks: %w", err)
+	}
+	s.blocked = blockedGoroutines(buf.Bytes(), m.blockedThreshold)
+
+	if len(m.labels) > 0 {
+		buf.Reset()
+		if err := m.writeProfile(&buf, 1); err != nil {
+			return m.last, fmt.Errorf("failed to read goroutine profile: %w", err)
+		}
+		s.labeled = labeledGoroutines(buf.Bytes(), m.labels)
+	}
+
+	s.latency = m.schedLatency()
+
+	m.last = s
+	m.lastRead = now
+	return s, nil
+}
+
+// schedLatency returns the latency quantiles of the goroutines scheduled
+// since the previous read
+func (m *Metrics) schedLatency() []float64 {
+	sample := []metrics.Sample{{Name: schedLatencies}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil
+	}
+	current := sample[0].Value.Float64Histogram()
+
+	counts := append([]uint64(nil), current.Counts...)
+	if m.latencies != nil && len(m.latencies.Counts) == len(counts) {
+		for i := range counts {
+			counts[i] -= m.latencies.Counts[i]
+		}
+	}
+	m.latencies = &metrics.Float64Histogram{Counts: append([]uint64(nil), current.Counts...), Buckets: current.Buckets}
+
+	quantiles := make([]float64, len(latencyQuantiles))
+	for i, q := range latencyQuantiles {
+		quantiles[i] = quantile(counts, current.Buckets, q)
+	}
+	return quantiles
+}
+
+// quantile returns the upper bound of the bucket holding quantile q, or
+// its lower bound for the unbounded last bucket; 0 without samples
+func quantile(counts []uint64, buckets []float64, q float64) float64 {
+	var total uint64
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(total))
+	var seen uint64
+	for i, c := range counts {
+		seen += c
+		if seen > rank || seen == total {
+			if upper := buckets[i+1]; !math.IsInf(upper, 1) {
+				return upper
+			}
+			return buckets[i]
+		}
+	}
+	return 0
+}
+
+// blockedGoroutines counts the goroutines of the stack dump waiting at
+// least threshold, per wait reason. Goroutine headers look like
+// "goroutine 7 [chan receive, 12 minutes]:".
+func blockedGoroutines(stacks []byte, threshold time.Duration) map[string]int64 {
+	blocked := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(stacks))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "goroutine ") || !strings.HasSuffix(line, "]:") {
+			continue
+		}
+		start := strings.IndexByte(line, '[')
+		if start < 0 {
+			continue
+		}
+		parts := strings.Split(line[start+1:len(line)-2], ", ")
+		for _, part := range parts[1:] {
+			minutes, ok := strings.CutSuffix(part, " minutes")
+			if !ok {
+				continue
+			}
+			if n, err := strconv.Atoi(minutes); err == nil && time.Duration(n)*time.Minute >= threshold {
+				blocked[parts[0]]++
+			}
+		}
+	}
+	return blocked
+}
+
+// labeledGoroutines counts the goroutines of the debug=1 goroutine profile
+// per value of the label keys; goroutines without any of them are skipped.
+// Entries look like "3 @ 0x1 0x2" followed by `# labels: {"k":"v"}`.
+func labeledGoroutines(profile []byte, keys []string) map[attribute.Set]int64 {
+	labeled := make(map[attribute.Set]int64)
+	var count int64
+	scanner := bufio.NewScanner(bytes.NewReader(profile))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if n, _, ok := strings.Cut(line, " @ "); ok {
+			count, _ = strconv.ParseInt(n, 10, 64)
+			continue
+		}
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok {
+			continue
+		}
+		values := parseLabels(labels)
+		var attrs []attribute.KeyValue
+		for _, key := range keys {
+			if v, ok := values[key]; ok {
+				attrs = append(attrs, attribute.String(key, v))
+			}
+		}
+		if len(attrs) > 0 {
+			labeled[attribute.NewSet(attrs...)] += count
+		}
+	}
+	return labeled
+}
+
+// parseLabels parses the label set of a profile entry, formatted as
+// {"key":"value", "key2":"value2"} with Go quoted strings
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	for s != "" {
+		key, rest, ok := unquotePrefix(s)
+		if !ok || !strings.HasPrefix(rest, ":") {
+			break
+		}
+		value, rest, ok := unquotePrefix(rest[1:])
+		if !ok {
+			break
+		}
+		labels[key] = value
+		s = strings.TrimPrefix(rest, ", ")
+	}
+	return labels
+}
+
+// unquotePrefix unquotes the quoted string s starts with and returns the rest
+func unquotePrefix(s string) (string, string, bool) {
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", false
+	}
+	value, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", "", false
+	}
+	return value, s[len(quoted):], true
+}
+
+// Shutdown unregisters the goroutine diagnostics callback
+func (m *Metrics) Shutdown(ctx context.Context) error {
+	if m.registration == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
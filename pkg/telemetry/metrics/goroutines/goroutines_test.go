@@ -0,0 +1,196 @@
+package goroutines
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const stacks = `goroutine 1 [running]:
+main.main()
+	/app/main.go:10 +0x1d
+
+goroutine 7 [chan receive, 12 minutes]:
+main.worker()
+	/app/main.go:20 +0x19
+
+goroutine 8 [chan receive (nil chan), 6 minutes]:
+main.leak()
+	/app/main.go:30 +0x19
+
+goroutine 9 [select, 2 minutes, locked to thread]:
+main.poll()
+	/app/main.go:40 +0x19
+
+goroutine 10 [IO wait, 30 minutes]:
+internal/poll.runtime_pollWait()
+	/usr/local/go/src/runtime/netpoll.go:351 +0x85
+`
+
+const profile = `goroutine profile: total 6
+3 @ 0x47d82a 0x41512e
+# labels: {"handler":"orders", "tenant":"t1"}
+#	0x4e1458	main.worker+0x18	/app/main.go:20
+
+2 @ 0x47d82a 0x41512f
+# labels: {"handler":"books"}
+#	0x4e1459	main.worker+0x18	/app/main.go:20
+
+1 @ 0x47d82a 0x415130
+#	0x4e1460	main.main+0x18	/app/main.go:10
+`
+
+func TestBlockedGoroutines(t *testing.T) {
+	blocked := blockedGoroutines([]byte(stacks), 5*time.Minute)
+	if len(blocked) != 3 {
+		t.Fatalf("Expected 3 wait reasons, got %v", blocked)
+	}
+	if blocked["chan receive"] != 1 || blocked["chan receive (nil chan)"] != 1 || blocked["IO wait"] != 1 {
+		t.Errorf("Expected the goroutines waiting 5 minutes or more, got %v", blocked)
+	}
+	if blocked := blockedGoroutines([]byte(stacks), time.Minute); blocked["select"] != 1 {
+		t.Errorf("Expected the select goroutine above 1 minute, got %v", blocked)
+	}
+}
+
+func TestLabeledGoroutines(t *testing.T) {
+	labeled := labeledGoroutines([]byte(profile), []string{"handler"})
+	if len(labeled) != 2 {
+		t.Fatalf("Expected 2 label values, got %v", labeled)
+	}
+	if n := labeled[attribute.NewSet(attribute.String("handler", "orders"))]; n != 3 {
+		t.Errorf("Expected 3 orders goroutines, got %d", n)
+	}
+	if n := labeled[attribute.NewSet(attribute.String("handler", "books"))]; n != 2 {
+		t.Errorf("Expected 2 books goroutines, got %d", n)
+	}
+
+	labeled = labeledGoroutines([]byte(profile), []string{"handler", "tenant"})
+	if n := labeled[attribute.NewSet(attribute.String("handler", "orders"), attribute.String("tenant", "t1"))]; n != 3 {
+		t.Errorf("Expected 3 goroutines of tenant t1, got %v", labeled)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	labels := parseLabels(`{"job":"nightly, full", "quote":"a\"b"}`)
+	if labels["job"] != "nightly, full" || labels["quote"] != `a"b` {
+		t.Errorf("Expected the unquoted labels, got %v", labels)
+	}
+	if labels := parseLabels("{}"); len(labels) != 0 {
+		t.Errorf("Expected no labels, got %v", labels)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	buckets := []float64{0, 0.001, 0.01, 0.1}
+	counts := []uint64{90, 9, 1}
+	if q := quantile(counts, buckets, 0.5); q != 0.001 {
+		t.Errorf("Expected median 0.001, got %v", q)
+	}
+	if q := quantile(counts, buckets, 0.99); q != 0.1 {
+		t.Errorf("Expected p99 0.1, got %v", q)
+	}
+	if q := quantile([]uint64{0, 0, 0}, buckets, 0.5); q != 0 {
+		t.Errorf("Expected 0 without samples, got %v", q)
+	}
+}
+
+// collect returns the collected metrics by name
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	names := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = m
+		}
+	}
+	return names
+}
+
+func TestStart(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m, err := Start(WithMeterProvider(mp), WithLabels("handler"), WithMinimumProfileInterval(0))
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	m.writeProfile = func(w *bytes.Buffer, debug int) error {
+		if debug == 1 {
+			w.WriteString(profile)
+		} else {
+			w.WriteString(stacks)
+		}
+		return nil
+	}
+
+	names := collect(t, reader)
+	for _, name := range []string{
+		"runtime.go.goroutines.delta",
+		"runtime.go.goroutines.blocked",
+		"runtime.go.goroutines.labeled",
+		"runtime.go.sched.latency",
+	} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("Expected metric %s to be collected", name)
+		}
+	}
+	blocked := names["runtime.go.goroutines.blocked"].Data.(metricdata.Gauge[int64])
+	if len(blocked.DataPoints) != 3 {
+		t.Errorf("Expected 3 blocked wait reasons, got %d", len(blocked.DataPoints))
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < 5; i++ {
+		go func() { <-done }()
+	}
+	names = collect(t, reader)
+	delta := names["runtime.go.goroutines.delta"].Data.(metricdata.Gauge[int64])
+	if delta.DataPoints[0].Value < 5 {
+		t.Errorf("Expected a delta of at least 5 goroutines, got %d", delta.DataPoints[0].Value)
+	}
+
+	m.writeProfile = func(w *bytes.Buffer, debug int) error {
+		return errors.New("unavailable")
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err == nil {
+		t.Error("Expected the profile error to be reported")
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
+
+func TestMinimumProfileInterval(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	m, err := Start(WithMeterProvider(mp), WithMinimumProfileInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer m.Shutdown(context.Background())
+
+	reads := 0
+	m.writeProfile = func(w *bytes.Buffer, debug int) error {
+		reads++
+		return nil
+	}
+	collect(t, reader)
+	collect(t, reader)
+	if reads != 1 {
+		t.Errorf("Expected 1 profile read within the interval, got %d", reads)
+	}
+}
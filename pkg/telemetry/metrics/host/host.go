@@ -0,0 +1,166 @@
+// Package host collects process and host metrics (CPU time, memory, open
+// file descriptors, disk and network IO) via gopsutil.
+//
+// The series names match what the console metric exporter renders in its
+// host metrics section, e.g. process.cpu.time and process.memory.usage.
+package host
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope used for host metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/host"
+
+var (
+	stateUser   = metric.WithAttributes(attribute.String("state", "user"))
+	stateSystem = metric.WithAttributes(attribute.String("state", "system"))
+
+	directionRead     = metric.WithAttributes(attribute.String("disk.io.direction", "read"))
+	directionWrite    = metric.WithAttributes(attribute.String("disk.io.direction", "write"))
+	directionReceive  = metric.WithAttributes(attribute.String("network.io.direction", "receive"))
+	directionTransmit = metric.WithAttributes(attribute.String("network.io.direction", "transmit"))
+)
+
+// Metrics is a running host metrics collector
+type Metrics struct {
+	proc         *process.Process
+	registration metric.Registration
+}
+
+// config holds the collector settings
+type config struct {
+	meterProvider metric.MeterProvider
+	pid           int32
+}
+
+// Option configures the host metrics collector
+type Option func(*config)
+
+// WithMeterProvider sets the meter provider the instruments are registered on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPID sets the process to observe; defaults to the current process
+func WithPID(pid int32) Option {
+	return func(c *config) {
+		c.pid = pid
+	}
+}
+
+// Start registers the host instruments and returns the running collector
+func Start(opts ...Option) (*Metrics, error) {
+	cfg := &config{
+		pid: int32(os.Getpid()),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	proc, err := process.NewProcess(cfg.pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect process %d: %w", cfg.pid, err)
+	}
+
+	m := &Metrics{proc: proc}
+	if err := m.register(cfg.meterProvider.Meter(ScopeName)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register creates the observable instruments and their shared callback
+func (m *Metrics) register(meter metric.Meter) error {
+	cpuTime, err := meter.Float64ObservableCounter("process.cpu.time",
+		metric.WithDescription("Total CPU seconds broken down by state"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return fmt.Errorf("failed to create cpu time instrument: %w", err)
+	}
+
+	memoryUsage, err := meter.Int64ObservableGauge("process.memory.usage",
+		metric.WithDescription("Resident set size of the process"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create memory usage instrument: %w", err)
+	}
+
+	memoryVirtual, err := meter.Int64ObservableGauge("process.memory.virtual",
+		metric.WithDescription("Virtual memory size of the process"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create virtual memory instrument: %w", err)
+	}
+
+	openFDs, err := meter.Int64ObservableUpDownCounter("process.unix.file_descriptor.count",
+		metric.WithDescription("Number of file descriptors in use by the process"))
+	if err != nil {
+		return fmt.Errorf("failed to create file descriptor instrument: %w", err)
+	}
+
+	diskIO, err := meter.Int64ObservableCounter("process.disk.io",
+		metric.WithDescription("Disk bytes transferred by the process"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create disk io instrument: %w", err)
+	}
+
+	networkIO, err := meter.Int64ObservableCounter("system.network.io",
+		metric.WithDescription("Network bytes transferred by the host"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create network io instrument: %w", err)
+	}
+
+	// Individual readings are best effort: not every platform supports
+	// every counter, and a failing one must not suppress the others
+	m.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		if times, err := m.proc.TimesWithContext(ctx); err == nil {
+			o.ObserveFloat64(cpuTime, times.User, stateUser)
+			o.ObserveFloat64(cpuTime, times.System, stateSystem)
+		}
+		if mem, err := m.proc.MemoryInfoWithContext(ctx); err == nil {
+			o.ObserveInt64(memoryUsage, int64(mem.RSS))
+			o.ObserveInt64(memoryVirtual, int64(mem.VMS))
+		}
+		if fds, err := m.proc.NumFDsWithContext(ctx); err == nil {
+			o.ObserveInt64(openFDs, int64(fds))
+		}
+		if io, err := m.proc.IOCountersWithContext(ctx); err == nil {
+			o.ObserveInt64(diskIO, int64(io.ReadBytes), directionRead)
+			o.ObserveInt64(diskIO, int64(io.WriteBytes), directionWrite)
+		}
+		if counters, err := net.IOCountersWithContext(ctx, false); err == nil && len(counters) > 0 {
+			o.ObserveInt64(networkIO, int64(counters[0].BytesRecv), directionReceive)
+			o.ObserveInt64(networkIO, int64(counters[0].BytesSent), directionTransmit)
+		}
+		return nil
+	}, cpuTime, memoryUsage, memoryVirtual, openFDs, diskIO, networkIO)
+	if err != nil {
+		return fmt.Errorf("failed to register host metrics callback: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown unregisters the host metrics callback
+func (m *Metrics) Shutdown(ctx context.Context) error {
+	if m.registration == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
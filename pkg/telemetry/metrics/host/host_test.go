@@ -0,0 +1,45 @@
+package host
+
+import (
+	"context"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStart_CollectsProcessMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m, err := Start(WithMeterProvider(mp))
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer m.Shutdown(context.Background())
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	var cpu metricdata.Sum[float64]
+	var memory metricdata.Gauge[int64]
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			switch metric.Name {
+			case "process.cpu.time":
+				cpu, _ = metric.Data.(metricdata.Sum[float64])
+			case "process.memory.usage":
+				memory, _ = metric.Data.(metricdata.Gauge[int64])
+			}
+		}
+	}
+
+	if len(cpu.DataPoints) != 2 {
+		t.Errorf("Expected user and system cpu time data points, got %d", len(cpu.DataPoints))
+	}
+	if len(memory.DataPoints) != 1 || memory.DataPoints[0].Value <= 0 {
+		t.Error("Expected a positive process.memory.usage value")
+	}
+}
@@ -0,0 +1,187 @@
+// Package runtime collects Go runtime metrics (goroutines, GC, heap and
+// stack usage, cgo calls) as observable instruments on a meter provider.
+//
+// All instruments use the "runtime.go." prefix so the console metric
+// exporter renders them in its host metrics section.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	goruntime "runtime"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope used for runtime metrics
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/runtime"
+
+// DefaultMinimumReadMemStatsInterval limits how often runtime.ReadMemStats,
+// which stops the world, is called when several readers collect at once
+const DefaultMinimumReadMemStatsInterval = 15 * time.Second
+
+// Metrics is a running runtime metrics collector
+type Metrics struct {
+	registration metric.Registration
+
+	mu           sync.Mutex
+	memStats     goruntime.MemStats
+	lastRead     time.Time
+	minInterval  time.Duration
+	readMemStats func(*goruntime.MemStats)
+}
+
+// config holds the collector settings
+type config struct {
+	meterProvider metric.MeterProvider
+	minInterval   time.Duration
+}
+
+// Option configures the runtime metrics collector
+type Option func(*config)
+
+// WithMeterProvider sets the meter provider the instruments are registered on
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithMinimumReadMemStatsInterval sets the minimum interval between two
+// runtime.ReadMemStats calls; collections in between reuse the cached values
+func WithMinimumReadMemStatsInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.minInterval = d
+	}
+}
+
+// Start registers the runtime instruments and returns the running collector
+func Start(opts ...Option) (*Metrics, error) {
+	cfg := &config{
+		minInterval: DefaultMinimumReadMemStatsInterval,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	m := &Metrics{
+		minInterval:  cfg.minInterval,
+		readMemStats: goruntime.ReadMemStats,
+	}
+	if err := m.register(cfg.meterProvider.Meter(ScopeName)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register creates the observable instruments and their shared callback
+func (m *Metrics) register(meter metric.Meter) error {
+	goroutines, err := meter.Int64ObservableUpDownCounter("runtime.go.goroutines",
+		metric.WithDescription("Number of goroutines that currently exist"))
+	if err != nil {
+		return fmt.Errorf("failed to create goroutines instrument: %w", err)
+	}
+
+	cgoCalls, err := meter.Int64ObservableCounter("runtime.go.cgo.calls",
+		metric.WithDescription("Number of cgo calls made by the current process"))
+	if err != nil {
+		return fmt.Errorf("failed to create cgo calls instrument: %w", err)
+	}
+
+	gcCount, err := meter.Int64ObservableCounter("runtime.go.gc.count",
+		metric.WithDescription("Number of completed garbage collection cycles"))
+	if err != nil {
+		return fmt.Errorf("failed to create gc count instrument: %w", err)
+	}
+
+	gcPauseTotal, err := meter.Int64ObservableCounter("runtime.go.gc.pause_total_ns",
+		metric.WithDescription("Cumulative nanoseconds in GC stop-the-world pauses"),
+		metric.WithUnit("ns"))
+	if err != nil {
+		return fmt.Errorf("failed to create gc pause instrument: %w", err)
+	}
+
+	gcLastPause, err := meter.Int64ObservableGauge("runtime.go.gc.pause_ns",
+		metric.WithDescription("Duration of the most recent GC stop-the-world pause"),
+		metric.WithUnit("ns"))
+	if err != nil {
+		return fmt.Errorf("failed to create gc last pause instrument: %w", err)
+	}
+
+	heapAlloc, err := meter.Int64ObservableGauge("runtime.go.mem.heap_alloc",
+		metric.WithDescription("Bytes of allocated heap objects"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create heap alloc instrument: %w", err)
+	}
+
+	heapInuse, err := meter.Int64ObservableGauge("runtime.go.mem.heap_inuse",
+		metric.WithDescription("Bytes in in-use heap spans"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create heap inuse instrument: %w", err)
+	}
+
+	heapObjects, err := meter.Int64ObservableGauge("runtime.go.mem.heap_objects",
+		metric.WithDescription("Number of allocated heap objects"))
+	if err != nil {
+		return fmt.Errorf("failed to create heap objects instrument: %w", err)
+	}
+
+	stackInuse, err := meter.Int64ObservableGauge("runtime.go.mem.stack_inuse",
+		metric.WithDescription("Bytes in stack spans"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create stack inuse instrument: %w", err)
+	}
+
+	m.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		ms := m.memStatsSnapshot()
+
+		o.ObserveInt64(goroutines, int64(goruntime.NumGoroutine()))
+		o.ObserveInt64(cgoCalls, goruntime.NumCgoCall())
+		o.ObserveInt64(gcCount, int64(ms.NumGC))
+		o.ObserveInt64(gcPauseTotal, int64(ms.PauseTotalNs))
+		if ms.NumGC > 0 {
+			o.ObserveInt64(gcLastPause, int64(ms.PauseNs[(ms.NumGC+255)%256]))
+		}
+		o.ObserveInt64(heapAlloc, int64(ms.HeapAlloc))
+		o.ObserveInt64(heapInuse, int64(ms.HeapInuse))
+		o.ObserveInt64(heapObjects, int64(ms.HeapObjects))
+		o.ObserveInt64(stackInuse, int64(ms.StackInuse))
+		return nil
+	}, goroutines, cgoCalls, gcCount, gcPauseTotal, gcLastPause, heapAlloc, heapInuse, heapObjects, stackInuse)
+	if err != nil {
+		return fmt.Errorf("failed to register runtime metrics callback: %w", err)
+	}
+
+	return nil
+}
+
+// memStatsSnapshot returns the cached memory statistics, refreshing them
+// when the minimum read interval has elapsed
+func (m *Metrics) memStatsSnapshot() goruntime.MemStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.lastRead.IsZero() || now.Sub(m.lastRead) >= m.minInterval {
+		m.readMemStats(&m.memStats)
+		m.lastRead = now
+	}
+	return m.memStats
+}
+
+// Shutdown unregisters the runtime metrics callback
+func (m *Metrics) Shutdown(ctx context.Context) error {
+	if m.registration == nil {
+		return nil
+	}
+	return m.registration.Unregister()
+}
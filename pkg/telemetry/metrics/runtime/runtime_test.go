@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStart_RegistersInstruments(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m, err := Start(WithMeterProvider(mp))
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	names := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			names[metric.Name] = metric
+		}
+	}
+
+	for _, name := range []string{
+		"runtime.go.goroutines",
+		"runtime.go.gc.count",
+		"runtime.go.mem.heap_alloc",
+		"runtime.go.mem.stack_inuse",
+		"runtime.go.cgo.calls",
+	} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("Expected metric %s to be collected", name)
+		}
+	}
+
+	goroutines, ok := names["runtime.go.goroutines"].Data.(metricdata.Sum[int64])
+	if !ok || len(goroutines.DataPoints) == 0 || goroutines.DataPoints[0].Value < 1 {
+		t.Error("Expected a positive goroutine count")
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+// Package scheduled provides a metric reader exporting on a jittered or
+// wall-clock aligned schedule.
+//
+// The SDK's periodic reader exports at fixed intervals from the moment it
+// is created, so instances started together by a rollout all export in the
+// same second. This reader can delay each export by a random jitter and
+// align exports to multiples of the interval, e.g. full minutes, so that
+// series of different instances line up in the backend.
+package scheduled
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// DefaultTimeout bounds each export, as for the SDK's periodic reader
+const DefaultTimeout = 30 * time.Second
+
+// Reader collects and exports metrics on its schedule. It is registered on
+// a meter provider like any other reader.
+type Reader struct {
+	*sdkmetric.ManualReader
+	exporter sdkmetric.Exporter
+
+	interval time.Duration
+	jitter   time.Duration
+	align    bool
+	timeout  time.Duration
+	random   func(n int64) int64
+
+	producers []sdkmetric.Producer
+
+	// mu serializes exports, as exporters need not be concurrency-safe
+	mu sync.Mutex
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Option configures the reader
+type Option func(*Reader)
+
+// WithJitter delays each export by a random duration below jitter
+func WithJitter(jitter time.Duration) Option {
+	return func(r *Reader) {
+		if jitter > 0 {
+			r.jitter = jitter
+		}
+	}
+}
+
+// WithAlignment aligns exports to multiples of the interval on the wall
+// clock, e.g. full minutes for an interval of one minute. A jitter is
+// added after the aligned time.
+func WithAlignment() Option {
+	return func(r *Reader) {
+		r.align = true
+	}
+}
+
+// WithTimeout bounds each export, DefaultTimeout if unset
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *Reader) {
+		if timeout > 0 {
+			r.timeout = timeout
+		}
+	}
+}
+
+// WithProducer adds the metrics of an external producer, e.g. a bridge, to
+// each export
+func WithProducer(producer sdkmetric.Producer) Option {
+	return func(r *Reader) {
+		r.producers = append(r.producers, producer)
+	}
+}
+
+// NewReader creates a reader exporting every interval and starts its
+// schedule. The temporality and aggregation follow the exporter.
+func NewReader(exporter sdkmetric.Exporter, interval time.Duration, opts ...Option) *Reader {
+	r := &Reader{
+		exporter: exporter,
+		interval: interval,
+		timeout:  DefaultTimeout,
+		random:   rand.Int64N,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	readerOpts := []sdkmetric.ManualReaderOption{
+		sdkmetric.WithTemporalitySelector(exporter.Temporality),
+		sdkmetric.WithAggregationSelector(exporter.Aggregation),
+	}
+	for _, producer := range r.producers {
+		readerOpts = append(readerOpts, sdkmetric.WithProducer(producer))
+	}
+	r.ManualReader = sdkmetric.NewManualReader(readerOpts...)
+	go r.run()
+	return r
+}
+
+// run exports on the schedule until Shutdown
+func (r *Reader) run() {
+	defer close(r.done)
+
+	base := r.first(time.Now())
+	timer := time.NewTimer(time.Until(base.Add(r.randomJitter())))
+	defer timer.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-timer.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+			if err := r.export(ctx); err != nil {
+				otel.Handle(err)
+			}
+			cancel()
+
+			base = r.following(base, time.Now())
+			timer.Reset(time.Until(base.Add(r.randomJitter())))
+		}
+	}
+}
+
+// first returns the scheduled time of the first export, before jitter
+func (r *Reader) first(now time.Time) time.Time {
+	if r.align {
+		return now.Truncate(r.interval).Add(r.interval)
+	}
+	return now.Add(r.interval)
+}
+
+// following returns the scheduled time of the export after the one
+// scheduled at base. Exports missed because an export took too long are
+// skipped.
+func (r *Reader) following(base, now time.Time) time.Time {
+	next := base.Add(r.interval)
+	if next.After(now) {
+		return next
+	}
+	return r.first(now)
+}
+
+// randomJitter returns a random duration below the configured jitter
+func (r *Reader) randomJitter() time.Duration {
+	if r.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(r.random(int64(r.jitter)))
+}
+
+// export collects and exports the metrics once
+func (r *Reader) export(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.Collect(ctx, &rm); err != nil {
+		return fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	if err := r.exporter.Export(ctx, &rm); err != nil {
+		return fmt.Errorf("failed to export metrics: %w", err)
+	}
+	return nil
+}
+
+// ForceFlush exports the metrics right away and flushes the exporter
+func (r *Reader) ForceFlush(ctx context.Context) error {
+	err := r.export(ctx)
+	if errors.Is(err, sdkmetric.ErrReaderShutdown) {
+		return nil
+	}
+	return errors.Join(err, r.exporter.ForceFlush(ctx))
+}
+
+// Shutdown stops the schedule, exports the metrics a last time and shuts
+// down the exporter
+func (r *Reader) Shutdown(ctx context.Context) error {
+	stopped := false
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		stopped = true
+	})
+	if !stopped {
+		return sdkmetric.ErrReaderShutdown
+	}
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	err := r.export(ctx)
+	return errors.Join(err, r.exporter.Shutdown(ctx), r.ManualReader.Shutdown(ctx))
+}
@@ -0,0 +1,148 @@
+package scheduled
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// recordingExporter records the times of its exports
+type recordingExporter struct {
+	sdkmetric.Exporter
+
+	mu       sync.Mutex
+	exports  []time.Time
+	shutdown bool
+}
+
+func newRecordingExporter() *recordingExporter {
+	return &recordingExporter{Exporter: defaultSelectors{}}
+}
+
+func (e *recordingExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exports = append(e.exports, time.Now())
+	return nil
+}
+
+func (e *recordingExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown = true
+	return nil
+}
+
+func (e *recordingExporter) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.exports)
+}
+
+// defaultSelectors provides the default temporality and aggregation
+type defaultSelectors struct {
+	sdkmetric.Exporter
+}
+
+func (defaultSelectors) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+func (defaultSelectors) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 23, 0, time.UTC)
+
+	r := &Reader{interval: time.Minute, random: func(n int64) int64 { return n / 2 }}
+	if got := r.first(now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected first export one interval after start, got %v", got)
+	}
+	if r.randomJitter() != 0 {
+		t.Error("Expected no jitter by default")
+	}
+
+	r.align = true
+	r.jitter = 10 * time.Second
+	first := r.first(now)
+	if want := time.Date(2026, 10, 16, 12, 1, 0, 0, time.UTC); !first.Equal(want) {
+		t.Errorf("Expected aligned export at %v, got %v", want, first)
+	}
+	if r.randomJitter() != 5*time.Second {
+		t.Errorf("Expected jitter from the random source, got %v", r.randomJitter())
+	}
+
+	// The schedule advances from the scheduled time, not the jittered one
+	next := r.following(first, first.Add(5*time.Second))
+	if want := first.Add(time.Minute); !next.Equal(want) {
+		t.Errorf("Expected next export at %v, got %v", want, next)
+	}
+
+	// Exports missed by a slow export are skipped
+	next = r.following(first, first.Add(150*time.Second))
+	if want := first.Add(3 * time.Minute); !next.Equal(want) {
+		t.Errorf("Expected next aligned export at %v, got %v", want, next)
+	}
+}
+
+func TestReader(t *testing.T) {
+	exporter := newRecordingExporter()
+	reader := NewReader(exporter, 20*time.Millisecond, WithJitter(5*time.Millisecond), WithAlignment())
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	counter, _ := mp.Meter("test").Int64Counter("requests")
+	counter.Add(context.Background(), 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for exporter.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if exporter.count() < 2 {
+		t.Fatalf("Expected scheduled exports, got %d", exporter.count())
+	}
+
+	if err := mp.ForceFlush(context.Background()); err != nil {
+		t.Errorf("ForceFlush failed: %v", err)
+	}
+
+	exports := exporter.count()
+	if err := mp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if exporter.count() != exports+1 || !exporter.shutdown {
+		t.Errorf("Expected a last export and the exporter shut down, got %d exports", exporter.count()-exports)
+	}
+	if err := reader.Shutdown(context.Background()); err != sdkmetric.ErrReaderShutdown {
+		t.Errorf("Expected ErrReaderShutdown on second shutdown, got %v", err)
+	}
+}
+
+// staticProducer produces a single metric
+type staticProducer struct{}
+
+func (staticProducer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	return []metricdata.ScopeMetrics{{Metrics: []metricdata.Metrics{{Name: "bridged"}}}}, nil
+}
+
+func TestReader_Producer(t *testing.T) {
+	r := NewReader(newRecordingExporter(), time.Hour, WithProducer(staticProducer{}))
+	defer r.Shutdown(context.Background())
+	sdkmetric.NewMeterProvider(sdkmetric.WithReader(r))
+
+	var rm metricdata.ResourceMetrics
+	if err := r.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect: %v", err)
+	}
+	if len(rm.ScopeMetrics) != 1 || rm.ScopeMetrics[0].Metrics[0].Name != "bridged" {
+		t.Errorf("Expected the produced metric, got %v", rm.ScopeMetrics)
+	}
+}
@@ -0,0 +1,115 @@
+package processors
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// AttributeFilter removes span attributes before export, to reduce payload
+// size and keep internal attributes away from the backend
+type AttributeFilter struct {
+	allow []string
+	deny  []string
+}
+
+// AttributeFilterOption configures the attribute filter
+type AttributeFilterOption func(*AttributeFilter)
+
+// WithAllowedAttributes keeps only the attributes matching one of the
+// patterns. Patterns are attribute keys or path.Match globs such as "http.*".
+func WithAllowedAttributes(patterns ...string) AttributeFilterOption {
+	return func(f *AttributeFilter) {
+		f.allow = append(f.allow, patterns...)
+	}
+}
+
+// WithDeniedAttributes removes the attributes matching one of the patterns.
+// It applies after the allow list.
+func WithDeniedAttributes(patterns ...string) AttributeFilterOption {
+	return func(f *AttributeFilter) {
+		f.deny = append(f.deny, patterns...)
+	}
+}
+
+// NewAttributeFilter creates an attribute filter; without options it keeps
+// all attributes
+func NewAttributeFilter(opts ...AttributeFilterOption) *AttributeFilter {
+	f := &AttributeFilter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Keep reports whether an attribute with this key is exported
+func (f *AttributeFilter) Keep(key attribute.Key) bool {
+	if len(f.allow) > 0 && !matchAny(f.allow, string(key)) {
+		return false
+	}
+	return !matchAny(f.deny, string(key))
+}
+
+// Attributes returns the kept attributes and whether any was removed
+func (f *AttributeFilter) Attributes(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		if f.Keep(kv.Key) {
+			if out != nil {
+				out = append(out, kv)
+			}
+			continue
+		}
+		// Copy lazily on the first removed attribute
+		if out == nil {
+			out = make([]attribute.KeyValue, i, len(attrs))
+			copy(out, attrs[:i])
+		}
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+// SpanExporter wraps next so that span attributes are filtered before export
+func (f *AttributeFilter) SpanExporter(next sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &filteringSpanExporter{SpanExporter: next, filter: f}
+}
+
+// filteringSpanExporter filters spans before handing them to the wrapped exporter
+type filteringSpanExporter struct {
+	sdktrace.SpanExporter
+	filter *AttributeFilter
+}
+
+// ExportSpans exports the filtered spans
+func (e *filteringSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	filtered := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		filtered[i] = s
+		if attrs, changed := e.filter.Attributes(s.Attributes()); changed {
+			filtered[i] = &filteredSpan{ReadOnlySpan: s, attrs: attrs}
+		}
+	}
+	return e.SpanExporter.ExportSpans(ctx, filtered)
+}
+
+// filteredSpan overrides the attributes of an ended span
+type filteredSpan struct {
+	sdktrace.ReadOnlySpan
+	attrs []attribute.KeyValue
+}
+
+func (s *filteredSpan) Attributes() []attribute.KeyValue { return s.attrs }
+
+// matchAny reports whether key matches one of the patterns
+func matchAny(patterns []string, key string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestAttributeFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []AttributeFilterOption
+		expected []string
+	}{
+		{"no lists", nil, []string{"http.method", "http.route", "internal.tenant", "user.id"}},
+		{"deny", []AttributeFilterOption{WithDeniedAttributes("internal.*", "user.id")}, []string{"http.method", "http.route"}},
+		{"allow", []AttributeFilterOption{WithAllowedAttributes("http.*")}, []string{"http.method", "http.route"}},
+		{"allow and deny", []AttributeFilterOption{WithAllowedAttributes("http.*", "user.id"), WithDeniedAttributes("http.route")}, []string{"http.method", "user.id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			filter := NewAttributeFilter(tt.opts...)
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(filter.SpanExporter(exporter)))
+
+			_, span := tp.Tracer("test").Start(context.Background(), "op")
+			span.SetAttributes(
+				attribute.String("http.method", "GET"),
+				attribute.String("http.route", "/orders"),
+				attribute.String("internal.tenant", "t1"),
+				attribute.String("user.id", "42"),
+			)
+			span.End()
+
+			attrs := exporter.GetSpans()[0].Attributes
+			if len(attrs) != len(tt.expected) {
+				t.Fatalf("Expected %d attributes, got %v", len(tt.expected), attrs)
+			}
+			for i, key := range tt.expected {
+				if string(attrs[i].Key) != key {
+					t.Errorf("Expected attribute %s at %d, got %s", key, i, attrs[i].Key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package processors
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// BaggageAttributes is a span processor that copies allow-listed baggage
+// members of the parent context onto every started span, so context set at
+// the edge (tenant, user, feature flags) shows up on all downstream spans
+type BaggageAttributes struct {
+	keys map[string]struct{}
+	all  bool
+}
+
+// NewBaggageAttributes creates a processor copying the given baggage keys.
+// The key "*" copies all baggage members; only use it for trusted baggage.
+func NewBaggageAttributes(keys ...string) *BaggageAttributes {
+	p := &BaggageAttributes{keys: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		if k == "*" {
+			p.all = true
+		}
+		p.keys[k] = struct{}{}
+	}
+	return p
+}
+
+// OnStart adds the allow-listed baggage members as span attributes
+func (p *BaggageAttributes) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	members := baggage.FromContext(parent).Members()
+	if len(members) == 0 {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(members))
+	for _, m := range members {
+		if _, ok := p.keys[m.Key()]; ok || p.all {
+			attrs = append(attrs, attribute.String(m.Key(), m.Value()))
+		}
+	}
+	s.SetAttributes(attrs...)
+}
+
+// OnEnd does nothing
+func (p *BaggageAttributes) OnEnd(s sdktrace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (p *BaggageAttributes) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *BaggageAttributes) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestBaggageAttributes(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(NewBaggageAttributes("tenant", "feature.flag")),
+		sdktrace.WithSyncer(exporter),
+	)
+
+	bag, _ := baggage.Parse("tenant=t1,feature.flag=new-checkout,session=secret")
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	ctx, parent := tp.Tracer("test").Start(ctx, "parent")
+	_, child := tp.Tracer("test").Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	for _, span := range exporter.GetSpans() {
+		values := map[string]string{}
+		for _, kv := range span.Attributes {
+			values[string(kv.Key)] = kv.Value.AsString()
+		}
+		if values["tenant"] != "t1" || values["feature.flag"] != "new-checkout" {
+			t.Errorf("Expected allow-listed baggage on span %s, got %v", span.Name, values)
+		}
+		if _, ok := values["session"]; ok {
+			t.Errorf("Expected non allow-listed baggage to be skipped on span %s", span.Name)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package processors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// OccurrencesKey is the attribute holding the number of identical
+// consecutive records a deduplicated record stands for
+const OccurrencesKey = "occurrences"
+
+// defaultDedupWindow is the time identical records are collapsed for
+const defaultDedupWindow = time.Second
+
+// Deduplicator is a log processor that collapses identical consecutive
+// records (same severity, body, trace and attributes) into a single record
+// with the OccurrencesKey attribute, e.g. the same error logged by a retry
+// loop. The collapsed record keeps the timestamp of its first occurrence.
+//
+// Every record, including unique ones, is held back until a different
+// record arrives, the window since its first occurrence elapsed, or on
+// flush, so records are forwarded up to one window late.
+type Deduplicator struct {
+	next   sdklog.Processor
+	window time.Duration
+	now    func() time.Time
+
+	mu          sync.Mutex
+	pending     *sdklog.Record
+	first       time.Time
+	occurrences int
+	timer       *time.Timer
+}
+
+// DeduplicatorOption configures a Deduplicator
+type DeduplicatorOption func(*Deduplicator)
+
+// WithDedupWindow sets how long identical consecutive records are
+// collapsed, one second if unset
+func WithDedupWindow(d time.Duration) DeduplicatorOption {
+	return func(p *Deduplicator) {
+		if d > 0 {
+			p.window = d
+		}
+	}
+}
+
+// NewDeduplicator wraps next so that identical consecutive log records
+// are forwarded once
+func NewDeduplicator(next sdklog.Processor, opts ...DeduplicatorOption) *Deduplicator {
+	p := &Deduplicator{
+		next:   next,
+		window: defaultDedupWindow,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Enabled forwards to the wrapped processor, which receives the records
+// that are not collapsed
+func (p *Deduplicator) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	if fp, ok := p.next.(sdklog.FilterProcessor); ok {
+		return fp.Enabled(ctx, param)
+	}
+	return true
+}
+
+// OnEmit counts the record if it repeats the pending one, otherwise it
+// forwards the pending record and holds back this one
+func (p *Deduplicator) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	now := p.now()
+
+	p.mu.Lock()
+	if p.pending != nil && now.Sub(p.first) < p.window && sameRecord(p.pending, record) {
+		p.occurrences++
+		p.mu.Unlock()
+		return nil
+	}
+	previous := p.takeLocked()
+	pending := record.Clone()
+	p.pending, p.first, p.occurrences = &pending, now, 1
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.window, p.expire)
+	} else {
+		p.timer.Reset(p.window)
+	}
+	p.mu.Unlock()
+
+	if previous == nil {
+		return nil
+	}
+	return p.next.OnEmit(ctx, previous)
+}
+
+// expire forwards the pending record once its window elapsed
+func (p *Deduplicator) expire() {
+	p.mu.Lock()
+	var record *sdklog.Record
+	if p.pending != nil && p.now().Sub(p.first) >= p.window {
+		record = p.takeLocked()
+	}
+	p.mu.Unlock()
+
+	if record != nil {
+		// Errors of the wrapped processor are reported by the SDK on export
+		_ = p.next.OnEmit(context.Background(), record)
+	}
+}
+
+// takeLocked returns the pending record with its occurrences, nil if there
+// is none, and clears it
+func (p *Deduplicator) takeLocked() *sdklog.Record {
+	record := p.pending
+	if record == nil {
+		return nil
+	}
+	if p.occurrences > 1 {
+		record.AddAttributes(log.Int(OccurrencesKey, p.occurrences))
+	}
+	p.pending, p.occurrences = nil, 0
+	return record
+}
+
+// flushPending forwards the pending record
+func (p *Deduplicator) flushPending(ctx context.Context) error {
+	p.mu.Lock()
+	record := p.takeLocked()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.mu.Unlock()
+
+	if record == nil {
+		return nil
+	}
+	return p.next.OnEmit(ctx, record)
+}
+
+// Shutdown forwards the pending record and shuts down the wrapped processor
+func (p *Deduplicator) Shutdown(ctx context.Context) error {
+	if err := p.flushPending(ctx); err != nil {
+		return err
+	}
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush forwards the pending record and flushes the wrapped processor
+func (p *Deduplicator) ForceFlush(ctx context.Context) error {
+	if err := p.flushPending(ctx); err != nil {
+		return err
+	}
+	return p.next.ForceFlush(ctx)
+}
+
+// sameRecord reports whether b repeats a: same severity, body, trace and
+// attributes
+func sameRecord(a, b *sdklog.Record) bool {
+	return a.Severity() == b.Severity() &&
+		a.TraceID() == b.TraceID() &&
+		a.Body().Equal(b.Body()) &&
+		sameAttributes(a, b)
+}
+
+// sameAttributes reports whether a and b have equal attributes, in any order
+func sameAttributes(a, b *sdklog.Record) bool {
+	if a.AttributesLen() != b.AttributesLen() {
+		return false
+	}
+	if a.AttributesLen() == 0 {
+		return true
+	}
+
+	values := make(map[string]log.Value, a.AttributesLen())
+	a.WalkAttributes(func(kv log.KeyValue) bool {
+		values[kv.Key] = kv.Value
+		return true
+	})
+	same := true
+	b.WalkAttributes(func(kv log.KeyValue) bool {
+		v, ok := values[kv.Key]
+		same = ok && v.Equal(kv.Value)
+		return same
+	})
+	return same
+}
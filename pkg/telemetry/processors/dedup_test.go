@@ -0,0 +1,154 @@
+package processors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// occurrences returns the OccurrencesKey attribute of r, 0 if unset
+func occurrences(r sdklog.Record) int64 {
+	count := int64(0)
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == OccurrencesKey {
+			count = kv.Value.AsInt64()
+		}
+		return true
+	})
+	return count
+}
+
+func TestDeduplicator(t *testing.T) {
+	capture := &captureProcessor{}
+	dedup := NewDeduplicator(capture, WithDedupWindow(time.Hour))
+	now := time.Unix(1700000000, 0)
+	dedup.now = func() time.Time { return now }
+
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(dedup))
+	logger := provider.Logger("test")
+	emit := func(severity log.Severity, body string) {
+		var r log.Record
+		r.SetSeverity(severity)
+		r.SetBody(log.StringValue(body))
+		logger.Emit(context.Background(), r)
+	}
+
+	for i := 0; i < 100; i++ {
+		emit(log.SeverityError, "connection refused")
+	}
+	if len(capture.records) != 0 {
+		t.Fatalf("Expected the repeated record to be held back, got %d records", len(capture.records))
+	}
+
+	// A different record ends the run
+	emit(log.SeverityWarn, "connection refused")
+	if len(capture.records) != 1 {
+		t.Fatalf("Expected the collapsed record, got %d records", len(capture.records))
+	}
+	if got := capture.records[0].Body().AsString(); got != "connection refused" {
+		t.Errorf("Expected the body to be kept, got %q", got)
+	}
+	if got := occurrences(capture.records[0]); got != 100 {
+		t.Errorf("Expected 100 occurrences, got %d", got)
+	}
+
+	// A repetition after the window starts a new run
+	now = now.Add(2 * time.Hour)
+	emit(log.SeverityWarn, "connection refused")
+	if len(capture.records) != 2 {
+		t.Fatalf("Expected the previous run to be forwarded, got %d records", len(capture.records))
+	}
+	if got := occurrences(capture.records[1]); got != 0 {
+		t.Errorf("Expected no occurrences attribute on a single record, got %d", got)
+	}
+
+	// The pending record is forwarded on shutdown
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if len(capture.records) != 3 {
+		t.Fatalf("Expected the pending record on shutdown, got %d records", len(capture.records))
+	}
+}
+
+func TestDeduplicatorAttributes(t *testing.T) {
+	capture := &captureProcessor{}
+	dedup := NewDeduplicator(capture, WithDedupWindow(time.Hour))
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(dedup))
+	logger := provider.Logger("test")
+	emit := func(attrs ...log.KeyValue) {
+		var r log.Record
+		r.SetSeverity(log.SeverityError)
+		r.SetBody(log.StringValue("order failed"))
+		r.AddAttributes(attrs...)
+		logger.Emit(context.Background(), r)
+	}
+
+	emit(log.String("order.id", "1"), log.String("http.route", "/orders"))
+	emit(log.String("http.route", "/orders"), log.String("order.id", "1"))
+	emit(log.String("order.id", "2"), log.String("http.route", "/orders"))
+	emit(log.String("order.id", "2"))
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if len(capture.records) != 3 {
+		t.Fatalf("Expected records with different attributes to be kept, got %d records", len(capture.records))
+	}
+	if got := occurrences(capture.records[0]); got != 2 {
+		t.Errorf("Expected attributes in a different order to collapse, got %d occurrences", got)
+	}
+	for _, r := range capture.records[1:] {
+		if got := occurrences(r); got != 0 {
+			t.Errorf("Expected no occurrences attribute on a distinct record, got %d", got)
+		}
+	}
+}
+
+// lockedCapture keeps records forwarded from several goroutines
+type lockedCapture struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (p *lockedCapture) OnEmit(_ context.Context, record *sdklog.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = append(p.records, record.Clone())
+	return nil
+}
+
+func (p *lockedCapture) Shutdown(context.Context) error   { return nil }
+func (p *lockedCapture) ForceFlush(context.Context) error { return nil }
+
+func (p *lockedCapture) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.records)
+}
+
+func TestDeduplicatorWindowExpiry(t *testing.T) {
+	capture := &lockedCapture{}
+	dedup := NewDeduplicator(capture, WithDedupWindow(10*time.Millisecond))
+	logger := sdklog.NewLoggerProvider(sdklog.WithProcessor(dedup)).Logger("test")
+
+	var r log.Record
+	r.SetSeverity(log.SeverityError)
+	r.SetBody(log.StringValue("connection refused"))
+	logger.Emit(context.Background(), r)
+	logger.Emit(context.Background(), r)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for capture.len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	if len(capture.records) != 1 || occurrences(capture.records[0]) != 2 {
+		t.Errorf("Expected the collapsed record once the window elapsed, got %d records", len(capture.records))
+	}
+}
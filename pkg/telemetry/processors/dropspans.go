@@ -0,0 +1,98 @@
+package processors
+
+import (
+	"context"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DropRule matches noise spans, such as health checks or connection probes.
+// All non-empty criteria must match.
+type DropRule struct {
+	// Name is the span name; path.Match globs are supported
+	Name string
+	// SpanKind restricts the rule to spans of that kind; unspecified matches all
+	SpanKind trace.SpanKind
+	// Attributes must all be present on the span; values support globs,
+	// e.g. {"db.statement": "SELECT 1"} or {"url.path": "/health*"}
+	Attributes map[string]string
+}
+
+// SpanDropper is a span processor that forwards ended spans to the wrapped
+// processor unless they match a drop rule. Unlike sampling rules it sees
+// the final span, so it also catches spans created by third-party
+// libraries that set their attributes after start. Children of a dropped
+// span are still exported and reference a missing parent.
+type SpanDropper struct {
+	next  sdktrace.SpanProcessor
+	rules []DropRule
+}
+
+// NewSpanDropper wraps next so that spans matching one of the rules are dropped
+func NewSpanDropper(next sdktrace.SpanProcessor, rules ...DropRule) *SpanDropper {
+	return &SpanDropper{next: next, rules: rules}
+}
+
+// OnStart forwards the span
+func (p *SpanDropper) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd forwards the span unless it matches a drop rule
+func (p *SpanDropper) OnEnd(s sdktrace.ReadOnlySpan) {
+	for i := range p.rules {
+		if p.rules[i].matches(s) {
+			return
+		}
+	}
+	p.next.OnEnd(s)
+}
+
+// Shutdown shuts down the wrapped processor
+func (p *SpanDropper) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the wrapped processor
+func (p *SpanDropper) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// matches reports whether all criteria of the rule hold for the span
+func (r *DropRule) matches(s sdktrace.ReadOnlySpan) bool {
+	if r.SpanKind != trace.SpanKindUnspecified && r.SpanKind != s.SpanKind() {
+		return false
+	}
+	if r.Name != "" && !matchGlob(r.Name, s.Name()) {
+		return false
+	}
+	for key, want := range r.Attributes {
+		got, ok := attributeValue(s.Attributes(), attribute.Key(key))
+		if !ok || !matchGlob(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// attributeValue returns the string form of an attribute value
+func attributeValue(attrs []attribute.KeyValue, key attribute.Key) (string, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value.Emit(), true
+		}
+	}
+	return "", false
+}
+
+// matchGlob matches literally or as a path.Match glob
+func matchGlob(pattern, s string) bool {
+	if pattern == s {
+		return true
+	}
+	matched, err := path.Match(pattern, s)
+	return err == nil && matched
+}
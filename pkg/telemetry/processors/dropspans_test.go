@@ -0,0 +1,42 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanDropper(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	dropper := NewSpanDropper(sdktrace.NewSimpleSpanProcessor(exporter),
+		DropRule{Attributes: map[string]string{"url.path": "/health*"}, SpanKind: trace.SpanKindServer},
+		DropRule{Name: "db.*", Attributes: map[string]string{"db.statement": "SELECT 1"}},
+	)
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(dropper)).Tracer("test")
+
+	start := func(name string, kind trace.SpanKind, attrs ...attribute.KeyValue) {
+		_, span := tracer.Start(context.Background(), name, trace.WithSpanKind(kind))
+		// Set after start, as instrumentation libraries often do
+		span.SetAttributes(attrs...)
+		span.End()
+	}
+	start("GET", trace.SpanKindServer, attribute.String("url.path", "/healthz"))
+	start("GET", trace.SpanKindClient, attribute.String("url.path", "/health"))
+	start("db.query", trace.SpanKindClient, attribute.String("db.statement", "SELECT 1"))
+	start("db.query", trace.SpanKindClient, attribute.String("db.statement", "SELECT * FROM orders"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(spans))
+	}
+	if spans[0].SpanKind != trace.SpanKindClient || spans[0].Name != "GET" {
+		t.Error("Expected client health check to be kept")
+	}
+	if spans[1].Attributes[0].Value.AsString() != "SELECT * FROM orders" {
+		t.Error("Expected regular query to be kept")
+	}
+}
@@ -0,0 +1,54 @@
+package processors
+
+import (
+	"context"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanStartHook is called for every started span with the parent context,
+// e.g. to attach attributes such as tenant, region or deployment color
+type SpanStartHook func(ctx context.Context, span trace.Span)
+
+// SpanEndHook is called for every ended sampled span. The span is read-only.
+type SpanEndHook func(span sdktrace.ReadOnlySpan)
+
+// Hooks is a span processor invoking application supplied callbacks
+type Hooks struct {
+	start []SpanStartHook
+	end   []SpanEndHook
+}
+
+// NewHooks creates a span processor invoking the hooks in order
+func NewHooks(start []SpanStartHook, end []SpanEndHook) *Hooks {
+	return &Hooks{start: start, end: end}
+}
+
+// OnStart invokes the start hooks
+func (p *Hooks) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	for _, hook := range p.start {
+		hook(parent, s)
+	}
+}
+
+// OnEnd invokes the end hooks; spans recorded but not sampled, e.g. for the
+// flight recorder, are left out
+func (p *Hooks) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+	for _, hook := range p.end {
+		hook(s)
+	}
+}
+
+// Shutdown does nothing
+func (p *Hooks) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *Hooks) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHooks(t *testing.T) {
+	var ended []string
+	hooks := NewHooks(
+		[]SpanStartHook{func(ctx context.Context, span trace.Span) {
+			span.SetAttributes(attribute.String("deployment.color", "blue"))
+		}},
+		[]SpanEndHook{func(span sdktrace.ReadOnlySpan) {
+			ended = append(ended, span.Name())
+		}},
+	)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(hooks), sdktrace.WithSyncer(exporter))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	attrs := exporter.GetSpans()[0].Attributes
+	if len(attrs) != 1 || attrs[0].Value.AsString() != "blue" {
+		t.Errorf("Expected start hook attribute, got %v", attrs)
+	}
+	if len(ended) != 1 || ended[0] != "op" {
+		t.Errorf("Expected end hook to be called once, got %v", ended)
+	}
+}
@@ -0,0 +1,96 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Retimer moves the timestamps of spans and log records onto a monotonic
+// clock, so they keep their order and distances when the wall clock of the
+// host is adjusted during the lifetime of the process
+type Retimer struct {
+	clock *clock.Monotonic
+}
+
+// NewRetimer creates a retimer converting timestamps to c
+func NewRetimer(c *clock.Monotonic) *Retimer {
+	return &Retimer{clock: c}
+}
+
+// Enabled returns false: the retimer only modifies records for the
+// processors registered after it, which decide whether they are needed
+func (r *Retimer) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return false
+}
+
+// OnEmit converts the timestamps of the record. Register the retimer
+// before the exporting processor.
+func (r *Retimer) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	record.SetTimestamp(r.clock.Time(record.Timestamp()))
+	record.SetObservedTimestamp(r.clock.Time(record.ObservedTimestamp()))
+	return nil
+}
+
+// Shutdown does nothing
+func (r *Retimer) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (r *Retimer) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// SpanExporter wraps next so that span and event times are converted
+// before export
+func (r *Retimer) SpanExporter(next sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &retimingSpanExporter{SpanExporter: next, retimer: r}
+}
+
+// retimingSpanExporter converts span times before handing the spans to the
+// wrapped exporter
+type retimingSpanExporter struct {
+	sdktrace.SpanExporter
+	retimer *Retimer
+}
+
+// ExportSpans exports the converted spans
+func (e *retimingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	retimed := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		retimed[i] = e.retimer.span(s)
+	}
+	return e.SpanExporter.ExportSpans(ctx, retimed)
+}
+
+// retimedSpan overrides the times of a span
+type retimedSpan struct {
+	sdktrace.ReadOnlySpan
+	start, end time.Time
+	events     []sdktrace.Event
+}
+
+func (s *retimedSpan) StartTime() time.Time     { return s.start }
+func (s *retimedSpan) EndTime() time.Time       { return s.end }
+func (s *retimedSpan) Events() []sdktrace.Event { return s.events }
+
+// span returns s with its start, end and event times converted
+func (r *Retimer) span(s sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	events := s.Events()
+	if len(events) > 0 {
+		events = append([]sdktrace.Event(nil), events...)
+		for i := range events {
+			events[i].Time = r.clock.Time(events[i].Time)
+		}
+	}
+	return &retimedSpan{
+		ReadOnlySpan: s,
+		start:        r.clock.Time(s.StartTime()),
+		end:          r.clock.Time(s.EndTime()),
+		events:       events,
+	}
+}
@@ -0,0 +1,64 @@
+package processors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRetimer_SpanExporter(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	retimer := NewRetimer(clock.NewMonotonic())
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(retimer.SpanExporter(exporter)))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "query")
+	span.AddEvent("retry")
+	time.Sleep(time.Millisecond)
+	span.End()
+
+	parsed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	_, received := tp.Tracer("test").Start(context.Background(), "received", trace.WithTimestamp(parsed))
+	received.End(trace.WithTimestamp(parsed.Add(time.Second)))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	got := spans[0]
+	if d := got.EndTime.Sub(got.StartTime); d < time.Millisecond {
+		t.Errorf("Expected the duration to be kept, got %v", d)
+	}
+	if strings.Contains(got.StartTime.String(), "m=") {
+		t.Errorf("Expected a converted start time without monotonic reading, got %v", got.StartTime)
+	}
+	if ev := got.Events[0].Time; ev.Before(got.StartTime) || ev.After(got.EndTime) {
+		t.Errorf("Expected the event within the span, got %v", ev)
+	}
+	if !spans[1].StartTime.Equal(parsed) || spans[1].EndTime.Sub(spans[1].StartTime) != time.Second {
+		t.Errorf("Expected explicit timestamps to keep their instant, got %v - %v", spans[1].StartTime, spans[1].EndTime)
+	}
+}
+
+func TestRetimer_OnEmit(t *testing.T) {
+	retimer := NewRetimer(clock.NewMonotonic())
+
+	var record sdklog.Record
+	now := time.Now()
+	record.SetTimestamp(now)
+	if err := retimer.OnEmit(context.Background(), &record); err != nil {
+		t.Fatalf("OnEmit failed: %v", err)
+	}
+	if d := record.Timestamp().Sub(now); d < -time.Second || d > time.Second {
+		t.Errorf("Expected the timestamp near the wall time, off by %v", d)
+	}
+	if !record.ObservedTimestamp().IsZero() {
+		t.Errorf("Expected the missing observed timestamp to stay zero, got %v", record.ObservedTimestamp())
+	}
+}
@@ -0,0 +1,222 @@
+package processors
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// SuppressedCountKey is the attribute holding the number of suppressed
+// records on a summary record
+const SuppressedCountKey = "log.suppressed_count"
+
+// defaultMaxRateLimitKeys bounds the number of tracked messages
+const defaultMaxRateLimitKeys = 10000
+
+// RateLimiter is a log processor that limits how often identical messages
+// (same severity and body) are forwarded to the wrapped processor, using a
+// token bucket per message. Once a message is allowed again, or on flush, a
+// summary record reports how many similar messages were suppressed.
+type RateLimiter struct {
+	next    sdklog.Processor
+	rate    float64
+	burst   float64
+	maxKeys int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[uint64]*list.Element
+	lru     *list.List // most recently used bucket first
+}
+
+// rateBucket is the token bucket of one message
+type rateBucket struct {
+	key        uint64
+	tokens     float64
+	last       time.Time
+	suppressed int
+	sample     sdklog.Record
+}
+
+// RateLimiterOption configures a RateLimiter
+type RateLimiterOption func(*RateLimiter)
+
+// WithRateLimit sets the sustained rate of identical messages per second
+// and the burst allowed on top of it
+func WithRateLimit(perSecond float64, burst int) RateLimiterOption {
+	return func(p *RateLimiter) {
+		p.rate = perSecond
+		p.burst = float64(burst)
+	}
+}
+
+// WithMaxRateLimitKeys bounds the number of distinct messages tracked.
+// When exceeded, the least recently seen message is dropped, reporting its
+// pending suppressions first.
+func WithMaxRateLimitKeys(n int) RateLimiterOption {
+	return func(p *RateLimiter) {
+		p.maxKeys = n
+	}
+}
+
+// NewRateLimiter wraps next so that floods of identical log records are
+// suppressed. By default 10 identical messages per second are forwarded,
+// with bursts of up to 20.
+func NewRateLimiter(next sdklog.Processor, opts ...RateLimiterOption) *RateLimiter {
+	p := &RateLimiter{
+		next:    next,
+		rate:    10,
+		burst:   20,
+		maxKeys: defaultMaxRateLimitKeys,
+		now:     time.Now,
+		buckets: make(map[uint64]*list.Element),
+		lru:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.burst < 1 {
+		p.burst = 1
+	}
+	if p.maxKeys < 1 {
+		p.maxKeys = 1
+	}
+	return p
+}
+
+// Enabled forwards to the wrapped processor; the rate limit applies to
+// messages, which are not known yet
+func (p *RateLimiter) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	if fp, ok := p.next.(sdklog.FilterProcessor); ok {
+		return fp.Enabled(ctx, param)
+	}
+	return true
+}
+
+// OnEmit forwards the record unless its message exceeded the rate limit
+func (p *RateLimiter) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	key := rateLimitKey(record.Severity(), record.Body())
+	now := p.now()
+
+	p.mu.Lock()
+	var evicted *sdklog.Record
+	var b *rateBucket
+	if e, ok := p.buckets[key]; ok {
+		p.lru.MoveToFront(e)
+		b = e.Value.(*rateBucket)
+	} else {
+		if p.lru.Len() >= p.maxKeys {
+			evicted = p.evictLocked()
+		}
+		b = &rateBucket{key: key, tokens: p.burst, last: now}
+		p.buckets[key] = p.lru.PushFront(b)
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * p.rate
+	if b.tokens > p.burst {
+		b.tokens = p.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		b.suppressed++
+		b.sample = record.Clone()
+		p.mu.Unlock()
+		if evicted != nil {
+			return p.next.OnEmit(ctx, evicted)
+		}
+		return nil
+	}
+	b.tokens--
+
+	var summary *sdklog.Record
+	if b.suppressed > 0 {
+		summary = p.summaryLocked(b)
+	}
+	p.mu.Unlock()
+
+	if evicted != nil {
+		if err := p.next.OnEmit(ctx, evicted); err != nil {
+			return err
+		}
+	}
+	if summary != nil {
+		if err := p.next.OnEmit(ctx, summary); err != nil {
+			return err
+		}
+	}
+	return p.next.OnEmit(ctx, record)
+}
+
+// summaryLocked builds the summary record for a bucket and resets its counter
+func (p *RateLimiter) summaryLocked(b *rateBucket) *sdklog.Record {
+	summary := b.sample.Clone()
+	summary.SetBody(log.StringValue(fmt.Sprintf("%d similar messages suppressed: %s", b.suppressed, b.sample.Body().String())))
+	summary.AddAttributes(log.Int(SuppressedCountKey, b.suppressed))
+	b.suppressed = 0
+	b.sample = sdklog.Record{}
+	return &summary
+}
+
+// evictLocked drops the least recently seen bucket and returns its summary
+// record, if it has suppressed messages to report
+func (p *RateLimiter) evictLocked() *sdklog.Record {
+	e := p.lru.Back()
+	if e == nil {
+		return nil
+	}
+	b := p.lru.Remove(e).(*rateBucket)
+	delete(p.buckets, b.key)
+	if b.suppressed > 0 {
+		return p.summaryLocked(b)
+	}
+	return nil
+}
+
+// flushSummaries forwards the summary records of all buckets with suppressed messages
+func (p *RateLimiter) flushSummaries(ctx context.Context) error {
+	p.mu.Lock()
+	var summaries []*sdklog.Record
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		if b := e.Value.(*rateBucket); b.suppressed > 0 {
+			summaries = append(summaries, p.summaryLocked(b))
+		}
+	}
+	p.mu.Unlock()
+
+	for _, s := range summaries {
+		if err := p.next.OnEmit(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown reports pending suppressions and shuts down the wrapped processor
+func (p *RateLimiter) Shutdown(ctx context.Context) error {
+	if err := p.flushSummaries(ctx); err != nil {
+		return err
+	}
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush reports pending suppressions and flushes the wrapped processor
+func (p *RateLimiter) ForceFlush(ctx context.Context) error {
+	if err := p.flushSummaries(ctx); err != nil {
+		return err
+	}
+	return p.next.ForceFlush(ctx)
+}
+
+// rateLimitKey hashes the severity and body identifying a message
+func rateLimitKey(severity log.Severity, body log.Value) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s", severity, body.String())
+	return h.Sum64()
+}
@@ -0,0 +1,103 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestRateLimiter(t *testing.T) {
+	capture := &captureProcessor{}
+	limiter := NewRateLimiter(capture, WithRateLimit(1, 2))
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(limiter))
+	logger := provider.Logger("test")
+	emit := func(body string) {
+		var r log.Record
+		r.SetSeverity(log.SeverityError)
+		r.SetBody(log.StringValue(body))
+		logger.Emit(context.Background(), r)
+	}
+
+	for i := 0; i < 5; i++ {
+		emit("connection refused")
+	}
+	emit("other message")
+	if len(capture.records) != 3 {
+		t.Fatalf("Expected burst of 2 plus the other message, got %d records", len(capture.records))
+	}
+
+	// One token is refilled after a second; the summary precedes the record
+	now = now.Add(time.Second)
+	emit("connection refused")
+	if len(capture.records) != 5 {
+		t.Fatalf("Expected summary and record, got %d records", len(capture.records))
+	}
+	summary := capture.records[3]
+	if got := summary.Body().AsString(); got != "3 similar messages suppressed: connection refused" {
+		t.Errorf("Unexpected summary body %q", got)
+	}
+	count := int64(0)
+	summary.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == SuppressedCountKey {
+			count = kv.Value.AsInt64()
+		}
+		return true
+	})
+	if count != 3 {
+		t.Errorf("Expected suppressed count 3, got %d", count)
+	}
+
+	// Pending suppressions are reported on shutdown
+	emit("connection refused")
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if len(capture.records) != 6 {
+		t.Fatalf("Expected summary on shutdown, got %d records", len(capture.records))
+	}
+}
+
+func TestRateLimiterBoundsKeys(t *testing.T) {
+	capture := &captureProcessor{}
+	limiter := NewRateLimiter(capture, WithRateLimit(1, 1), WithMaxRateLimitKeys(3))
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(limiter))
+	logger := provider.Logger("test")
+	emit := func(body string) {
+		var r log.Record
+		r.SetSeverity(log.SeverityError)
+		r.SetBody(log.StringValue(body))
+		logger.Emit(context.Background(), r)
+	}
+
+	// The first message is suppressed once before unique messages evict it
+	emit("connection refused")
+	emit("connection refused")
+	for i := 0; i < 100; i++ {
+		emit(fmt.Sprintf("request %d failed", i))
+		limiter.mu.Lock()
+		n := len(limiter.buckets)
+		limiter.mu.Unlock()
+		if n > 3 {
+			t.Fatalf("Expected at most 3 buckets, got %d", n)
+		}
+	}
+
+	// The third unique message evicts the first bucket, which reports its
+	// suppression before the new record is forwarded
+	if len(capture.records) != 102 {
+		t.Fatalf("Expected 101 records and 1 summary, got %d records", len(capture.records))
+	}
+	if got := capture.records[3].Body().AsString(); got != "1 similar messages suppressed: connection refused" {
+		t.Errorf("Unexpected summary body %q", got)
+	}
+}
@@ -0,0 +1,429 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DefaultRedactionMask replaces redacted values
+const DefaultRedactionMask = "[REDACTED]"
+
+// DefaultRedactedKeys are attribute keys whose values are always masked.
+// A key also matches attributes ending in "."+key, so "authorization"
+// covers http.request.header.authorization.
+var DefaultRedactedKeys = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"password",
+	"passwd",
+	"secret",
+	"client_secret",
+	"api_key",
+	"apikey",
+	"x-api-key",
+	"access_token",
+	"refresh_token",
+}
+
+// redactionPattern is a value pattern; matches are masked only if valid
+// returns true (or is nil), which keeps false positives such as 16 digit
+// timestamps out of the card number pattern
+type redactionPattern struct {
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// defaultRedactionPatterns mask emails, bearer tokens and card numbers in any string value
+var defaultRedactionPatterns = []redactionPattern{
+	{re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{re: regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)},
+	{re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhn},
+}
+
+// Redactor masks sensitive attribute values on spans, log records and
+// metric data points before they are exported
+type Redactor struct {
+	keys     []string
+	patterns []redactionPattern
+	mask     string
+}
+
+// RedactorOption configures a Redactor
+type RedactorOption func(*redactorConfig)
+
+// redactorConfig holds the Redactor settings
+type redactorConfig struct {
+	keys     []string
+	patterns []string
+	mask     string
+}
+
+// WithRedactedKeys adds attribute keys whose values are masked entirely
+func WithRedactedKeys(keys ...string) RedactorOption {
+	return func(c *redactorConfig) {
+		c.keys = append(c.keys, keys...)
+	}
+}
+
+// WithRedactedPatterns adds regular expressions; matching parts of string values are masked
+func WithRedactedPatterns(patterns ...string) RedactorOption {
+	return func(c *redactorConfig) {
+		c.patterns = append(c.patterns, patterns...)
+	}
+}
+
+// WithRedactionMask sets the replacement for redacted values
+func WithRedactionMask(mask string) RedactorOption {
+	return func(c *redactorConfig) {
+		c.mask = mask
+	}
+}
+
+// NewRedactor creates a redactor using the default keys and patterns plus
+// the configured ones. It fails if a pattern is not a valid regular expression.
+func NewRedactor(opts ...RedactorOption) (*Redactor, error) {
+	cfg := &redactorConfig{mask: DefaultRedactionMask}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	r := &Redactor{
+		mask:     cfg.mask,
+		patterns: append([]redactionPattern(nil), defaultRedactionPatterns...),
+	}
+	for _, k := range append(append([]string(nil), DefaultRedactedKeys...), cfg.keys...) {
+		r.keys = append(r.keys, strings.ToLower(k))
+	}
+	for _, p := range cfg.patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, redactionPattern{re: re})
+	}
+	return r, nil
+}
+
+// sensitiveKey reports whether values of the key must be masked entirely
+func (r *Redactor) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if key == k || strings.HasSuffix(key, "."+k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks all pattern matches in s
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		if p.valid == nil {
+			s = p.re.ReplaceAllLiteralString(s, r.mask)
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.valid(match) {
+				return r.mask
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// Attribute returns the redacted attribute and whether it was changed
+func (r *Redactor) Attribute(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	if r.sensitiveKey(string(kv.Key)) {
+		return kv.Key.String(r.mask), true
+	}
+
+	switch kv.Value.Type() {
+	case attribute.STRING:
+		if s := r.String(kv.Value.AsString()); s != kv.Value.AsString() {
+			return kv.Key.String(s), true
+		}
+	case attribute.STRINGSLICE:
+		values := kv.Value.AsStringSlice()
+		changed := false
+		for i, v := range values {
+			if s := r.String(v); s != v {
+				values[i] = s
+				changed = true
+			}
+		}
+		if changed {
+			return kv.Key.StringSlice(values), true
+		}
+	}
+	return kv, false
+}
+
+// Attributes returns the redacted attributes. The input is returned as is
+// when nothing had to be masked.
+func (r *Redactor) Attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	out, _ := r.attributes(attrs)
+	return out
+}
+
+// attributes returns the redacted attributes and whether any was changed
+func (r *Redactor) attributes(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		redacted, changed := r.Attribute(kv)
+		if changed && out == nil {
+			out = make([]attribute.KeyValue, len(attrs))
+			copy(out, attrs)
+		}
+		if out != nil {
+			out[i] = redacted
+		}
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+// set returns the redacted attribute set
+func (r *Redactor) set(s attribute.Set) attribute.Set {
+	redacted, changed := r.attributes(s.ToSlice())
+	if !changed {
+		return s
+	}
+	return attribute.NewSet(redacted...)
+}
+
+// logValue returns the redacted log value and whether it was changed
+func (r *Redactor) logValue(key string, v log.Value) (log.Value, bool) {
+	if key != "" && r.sensitiveKey(key) {
+		return log.StringValue(r.mask), true
+	}
+
+	switch v.Kind() {
+	case log.KindString:
+		if s := r.String(v.AsString()); s != v.AsString() {
+			return log.StringValue(s), true
+		}
+	case log.KindSlice:
+		// Copy, the slice is shared with the original value
+		values := append([]log.Value(nil), v.AsSlice()...)
+		changed := false
+		for i, item := range values {
+			if redacted, ok := r.logValue("", item); ok {
+				values[i] = redacted
+				changed = true
+			}
+		}
+		if changed {
+			return log.SliceValue(values...), true
+		}
+	case log.KindMap:
+		kvs := append([]log.KeyValue(nil), v.AsMap()...)
+		changed := false
+		for i, kv := range kvs {
+			if redacted, ok := r.logValue(kv.Key, kv.Value); ok {
+				kvs[i].Value = redacted
+				changed = true
+			}
+		}
+		if changed {
+			return log.MapValue(kvs...), true
+		}
+	}
+	return v, false
+}
+
+// Enabled returns false: the redactor only modifies records for the
+// processors registered after it, which decide whether they are needed
+func (r *Redactor) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return false
+}
+
+// OnEmit redacts the body and attributes of the record. Register the
+// redactor before the exporting processor.
+func (r *Redactor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if body, changed := r.logValue("", record.Body()); changed {
+		record.SetBody(body)
+	}
+
+	attrs := make([]log.KeyValue, 0, record.AttributesLen())
+	changed := false
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		if v, ok := r.logValue(kv.Key, kv.Value); ok {
+			kv.Value = v
+			changed = true
+		}
+		attrs = append(attrs, kv)
+		return true
+	})
+	if changed {
+		record.SetAttributes(attrs...)
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (r *Redactor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (r *Redactor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// SpanExporter wraps next so that span and event attributes are redacted
+// before export. Spans are immutable once ended, so redaction happens at
+// export time rather than in a span processor.
+func (r *Redactor) SpanExporter(next sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &redactingSpanExporter{SpanExporter: next, redactor: r}
+}
+
+// redactingSpanExporter redacts spans before handing them to the wrapped exporter
+type redactingSpanExporter struct {
+	sdktrace.SpanExporter
+	redactor *Redactor
+}
+
+// ExportSpans exports the redacted spans
+func (e *redactingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		redacted[i] = e.redactor.span(s)
+	}
+	return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+// overriddenSpan overrides the attributes and events of an ended span, for
+// processors that modify spans at export time
+type overriddenSpan struct {
+	sdktrace.ReadOnlySpan
+	attrs  []attribute.KeyValue
+	events []sdktrace.Event
+}
+
+func (s *overriddenSpan) Attributes() []attribute.KeyValue { return s.attrs }
+func (s *overriddenSpan) Events() []sdktrace.Event         { return s.events }
+
+// span returns s itself when nothing had to be masked
+func (r *Redactor) span(s sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	attrs, changed := r.attributes(s.Attributes())
+
+	events := s.Events()
+	var redactedEvents []sdktrace.Event
+	for i, ev := range events {
+		evAttrs, evChanged := r.attributes(ev.Attributes)
+		if !evChanged {
+			continue
+		}
+		if redactedEvents == nil {
+			redactedEvents = make([]sdktrace.Event, len(events))
+			copy(redactedEvents, events)
+		}
+		redactedEvents[i].Attributes = evAttrs
+	}
+
+	if !changed && redactedEvents == nil {
+		return s
+	}
+	if redactedEvents == nil {
+		redactedEvents = events
+	}
+	return &overriddenSpan{ReadOnlySpan: s, attrs: attrs, events: redactedEvents}
+}
+
+// MetricExporter wraps next so that data point attributes are redacted before export
+func (r *Redactor) MetricExporter(next metric.Exporter) metric.Exporter {
+	return &redactingMetricExporter{Exporter: next, redactor: r}
+}
+
+// redactingMetricExporter redacts data points before handing them to the wrapped exporter
+type redactingMetricExporter struct {
+	metric.Exporter
+	redactor *Redactor
+}
+
+// Export exports the redacted metrics
+func (e *redactingMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			e.redactor.aggregation(m.Data)
+		}
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+// aggregation redacts the data point attributes in place
+func (r *Redactor) aggregation(data metricdata.Aggregation) {
+	switch a := data.(type) {
+	case metricdata.Gauge[int64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.Gauge[float64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.Sum[int64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.Sum[float64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.Histogram[int64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.Histogram[float64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.ExponentialHistogram[int64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.ExponentialHistogram[float64]:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	case metricdata.Summary:
+		for i := range a.DataPoints {
+			a.DataPoints[i].Attributes = r.set(a.DataPoints[i].Attributes)
+		}
+	}
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card numbers
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,129 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r, err := NewRedactor()
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"user jane.doe@example.com logged in": "user [REDACTED] logged in",
+		"Bearer eyJhbGciOi.abc-def":           "[REDACTED]",
+		"card 4111 1111 1111 1111 charged":    "card [REDACTED] charged",
+		"took 1697040000000123 ns":            "took 1697040000000123 ns",
+		"nothing to see":                      "nothing to see",
+	}
+	for in, expected := range tests {
+		if got := r.String(in); got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, in, got)
+		}
+	}
+}
+
+func TestRedactor_Attributes(t *testing.T) {
+	r, err := NewRedactor(WithRedactedKeys("tenant.secret_id"), WithRedactedPatterns(`ORD-\d+`), WithRedactionMask("***"))
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.StringSlice("http.request.header.authorization", []string{"Basic abc"}),
+		attribute.String("tenant.secret_id", "t1"),
+		attribute.String("order", "ORD-42"),
+		attribute.Int("http.response.status_code", 200),
+	}
+	redacted := r.Attributes(attrs)
+
+	expected := []string{"***", "***", "***"}
+	for i, want := range expected {
+		if got := redacted[i].Value.Emit(); got != want {
+			t.Errorf("Expected %s to be %q, got %q", redacted[i].Key, want, got)
+		}
+	}
+	if redacted[3] != attrs[3] {
+		t.Error("Expected non-sensitive attribute to be untouched")
+	}
+	if attrs[1].Value.AsString() != "t1" {
+		t.Error("Expected input attributes not to be modified")
+	}
+
+	if _, err := NewRedactor(WithRedactedPatterns("(")); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestRedactor_OnEmit(t *testing.T) {
+	r, _ := NewRedactor()
+	capture := &captureProcessor{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(r), sdklog.WithProcessor(capture))
+	defer provider.Shutdown(context.Background())
+
+	var rec log.Record
+	rec.SetBody(log.StringValue("mail to jane@example.com failed"))
+	rec.AddAttributes(log.String("password", "hunter2"), log.String("user", "jane"))
+	provider.Logger("test").Emit(context.Background(), rec)
+
+	record := capture.records[0]
+	if got := record.Body().AsString(); got != "mail to [REDACTED] failed" {
+		t.Errorf("Unexpected body %q", got)
+	}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == "password" && kv.Value.AsString() != DefaultRedactionMask {
+			t.Errorf("Expected password to be masked, got %q", kv.Value.AsString())
+		}
+		if kv.Key == "user" && kv.Value.AsString() != "jane" {
+			t.Errorf("Expected user to be untouched, got %q", kv.Value.AsString())
+		}
+		return true
+	})
+}
+
+func TestRedactor_SpanExporter(t *testing.T) {
+	r, _ := NewRedactor()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(r.SpanExporter(exporter)))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.SetAttributes(attribute.String("cookie", "session=1"))
+	span.AddEvent("login", trace.WithAttributes(attribute.String("user.email", "jane@example.com")))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].Attributes[0].Value.AsString(); got != DefaultRedactionMask {
+		t.Errorf("Expected cookie to be masked, got %q", got)
+	}
+	if got := spans[0].Events[0].Attributes[0].Value.AsString(); got != DefaultRedactionMask {
+		t.Errorf("Expected event email to be masked, got %q", got)
+	}
+}
+
+func TestRedactor_Metrics(t *testing.T) {
+	r, _ := NewRedactor()
+	sum := metricdata.Sum[int64]{
+		DataPoints: []metricdata.DataPoint[int64]{
+			{Attributes: attribute.NewSet(attribute.String("user", "jane@example.com")), Value: 1},
+		},
+	}
+	r.aggregation(sum)
+
+	v, _ := sum.DataPoints[0].Attributes.Value("user")
+	if v.AsString() != DefaultRedactionMask {
+		t.Errorf("Expected data point attribute to be masked, got %q", v.AsString())
+	}
+}
@@ -0,0 +1,97 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// SeverityFilter is a log processor that forwards records to the wrapped
+// processor only if their severity is at or above a minimum. The minimum can
+// be changed at runtime, e.g. to temporarily enable debug logs.
+type SeverityFilter struct {
+	next    sdklog.Processor
+	minimum atomic.Int64
+}
+
+// Compile-time check that SeverityFilter lets loggers skip building records
+var _ sdklog.FilterProcessor = (*SeverityFilter)(nil)
+
+// NewSeverityFilter wraps next so that records below minimum are dropped
+func NewSeverityFilter(next sdklog.Processor, minimum log.Severity) *SeverityFilter {
+	p := &SeverityFilter{next: next}
+	p.SetMinimum(minimum)
+	return p
+}
+
+// SetMinimum changes the minimum severity. It is safe to call concurrently with OnEmit.
+func (p *SeverityFilter) SetMinimum(minimum log.Severity) {
+	p.minimum.Store(int64(minimum))
+}
+
+// Minimum returns the current minimum severity
+func (p *SeverityFilter) Minimum() log.Severity {
+	return log.Severity(p.minimum.Load())
+}
+
+// allowed reports whether a record of the given severity passes the filter.
+// Records without a severity are always passed, since their level is unknown.
+func (p *SeverityFilter) allowed(severity log.Severity) bool {
+	return severity == log.SeverityUndefined || severity >= p.Minimum()
+}
+
+// Enabled reports whether records of the given severity would be processed
+func (p *SeverityFilter) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	if !p.allowed(param.Severity) {
+		return false
+	}
+	if fp, ok := p.next.(sdklog.FilterProcessor); ok {
+		return fp.Enabled(ctx, param)
+	}
+	return true
+}
+
+// OnEmit forwards the record if its severity passes the filter
+func (p *SeverityFilter) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if !p.allowed(record.Severity()) {
+		return nil
+	}
+	return p.next.OnEmit(ctx, record)
+}
+
+// Shutdown shuts down the wrapped processor
+func (p *SeverityFilter) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the wrapped processor
+func (p *SeverityFilter) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// ParseSeverity converts a level name (trace, debug, info, warn, error,
+// fatal; case-insensitive) into the lowest severity of that level
+func ParseSeverity(level string) (log.Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "all":
+		return log.SeverityUndefined, nil
+	case "trace":
+		return log.SeverityTrace, nil
+	case "debug":
+		return log.SeverityDebug, nil
+	case "info":
+		return log.SeverityInfo, nil
+	case "warn", "warning":
+		return log.SeverityWarn, nil
+	case "error":
+		return log.SeverityError, nil
+	case "fatal":
+		return log.SeverityFatal, nil
+	default:
+		return log.SeverityUndefined, fmt.Errorf("unknown log level: %s", level)
+	}
+}
@@ -0,0 +1,67 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestSeverityFilter(t *testing.T) {
+	capture := &captureProcessor{}
+	filter := NewSeverityFilter(capture, log.SeverityWarn)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(filter))
+	defer provider.Shutdown(context.Background())
+
+	logger := provider.Logger("test")
+	emit := func(severity log.Severity) {
+		var r log.Record
+		r.SetSeverity(severity)
+		logger.Emit(context.Background(), r)
+	}
+
+	emit(log.SeverityDebug)
+	emit(log.SeverityInfo)
+	emit(log.SeverityWarn)
+	emit(log.SeverityError)
+	if len(capture.records) != 2 {
+		t.Fatalf("Expected 2 records at or above warn, got %d", len(capture.records))
+	}
+
+	if logger.Enabled(context.Background(), log.EnabledParameters{Severity: log.SeverityDebug}) {
+		t.Error("Expected debug to be disabled")
+	}
+
+	filter.SetMinimum(log.SeverityDebug)
+	emit(log.SeverityDebug)
+	if len(capture.records) != 3 {
+		t.Errorf("Expected debug record after lowering the minimum, got %d records", len(capture.records))
+	}
+	if !logger.Enabled(context.Background(), log.EnabledParameters{Severity: log.SeverityDebug}) {
+		t.Error("Expected debug to be enabled")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := map[string]log.Severity{
+		"":        log.SeverityUndefined,
+		"debug":   log.SeverityDebug,
+		"INFO":    log.SeverityInfo,
+		"warning": log.SeverityWarn,
+		"error":   log.SeverityError,
+	}
+	for level, expected := range tests {
+		got, err := ParseSeverity(level)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", level, err)
+		}
+		if got != expected {
+			t.Errorf("Expected %v for %q, got %v", expected, level, got)
+		}
+	}
+
+	if _, err := ParseSeverity("verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
@@ -0,0 +1,115 @@
+package processors
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanEvents is a log processor that records log records of high severity
+// as events on the span active in the emit context, so traces show the
+// error context without switching to the logs
+type SpanEvents struct {
+	minimum     log.Severity
+	errorStatus bool
+}
+
+// SpanEventsOption configures a SpanEvents processor
+type SpanEventsOption func(*SpanEvents)
+
+// WithSpanEventSeverity sets the minimum severity recorded as span event (default Error)
+func WithSpanEventSeverity(minimum log.Severity) SpanEventsOption {
+	return func(p *SpanEvents) {
+		p.minimum = minimum
+	}
+}
+
+// WithSpanErrorStatus additionally sets the span status to Error with the log message
+func WithSpanErrorStatus() SpanEventsOption {
+	return func(p *SpanEvents) {
+		p.errorStatus = true
+	}
+}
+
+// NewSpanEvents creates a log processor recording error logs as span events
+func NewSpanEvents(opts ...SpanEventsOption) *SpanEvents {
+	p := &SpanEvents{minimum: log.SeverityError}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Enabled reports whether a record of the given severity would become an
+// event of the active span
+func (p *SpanEvents) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	if param.Severity != log.SeverityUndefined && param.Severity < p.minimum {
+		return false
+	}
+	return trace.SpanFromContext(ctx).IsRecording()
+}
+
+// OnEmit adds a "log" event to the active span if the record is severe enough
+func (p *SpanEvents) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if record.Severity() < p.minimum {
+		return nil
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+
+	message := record.Body().String()
+	severity := record.SeverityText()
+	if severity == "" {
+		severity = record.Severity().String()
+	}
+
+	attrs := make([]attribute.KeyValue, 0, record.AttributesLen()+2)
+	attrs = append(attrs,
+		attribute.String("log.severity", severity),
+		attribute.String("log.message", message),
+	)
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs = append(attrs, logAttribute(kv))
+		return true
+	})
+
+	span.AddEvent("log", trace.WithTimestamp(record.Timestamp()), trace.WithAttributes(attrs...))
+	if p.errorStatus {
+		span.SetStatus(codes.Error, message)
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (p *SpanEvents) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *SpanEvents) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// logAttribute converts a log attribute into a span attribute; maps,
+// slices and bytes are rendered as strings
+func logAttribute(kv log.KeyValue) attribute.KeyValue {
+	key := attribute.Key(kv.Key)
+	switch kv.Value.Kind() {
+	case log.KindBool:
+		return key.Bool(kv.Value.AsBool())
+	case log.KindInt64:
+		return key.Int64(kv.Value.AsInt64())
+	case log.KindFloat64:
+		return key.Float64(kv.Value.AsFloat64())
+	case log.KindString:
+		return key.String(kv.Value.AsString())
+	default:
+		return key.String(kv.Value.String())
+	}
+}
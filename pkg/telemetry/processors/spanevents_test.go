@@ -0,0 +1,60 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanEvents(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(NewSpanEvents(WithSpanErrorStatus())))
+	logger := lp.Logger("test")
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	emit := func(severity log.Severity, body string) {
+		var r log.Record
+		r.SetSeverity(severity)
+		r.SetBody(log.StringValue(body))
+		r.AddAttributes(log.String("db.system", "hana"))
+		logger.Emit(ctx, r)
+	}
+	emit(log.SeverityInfo, "query started")
+	emit(log.SeverityError, "query failed")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	events := spans[0].Events
+	if len(events) != 1 {
+		t.Fatalf("Expected only the error log as event, got %d events", len(events))
+	}
+	if events[0].Name != "log" {
+		t.Errorf("Expected event name log, got %s", events[0].Name)
+	}
+
+	values := map[string]string{}
+	for _, kv := range events[0].Attributes {
+		values[string(kv.Key)] = kv.Value.Emit()
+	}
+	if values["log.message"] != "query failed" {
+		t.Errorf("Expected log.message attribute, got %q", values["log.message"])
+	}
+	if values["log.severity"] != "ERROR" {
+		t.Errorf("Expected log.severity ERROR, got %q", values["log.severity"])
+	}
+	if values["db.system"] != "hana" {
+		t.Error("Expected record attributes to be copied")
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Error("Expected span status Error")
+	}
+}
@@ -0,0 +1,193 @@
+// Package processors provides span and log processors that enrich, filter or
+// derive telemetry before it reaches the configured exporters.
+package processors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope used for metrics derived by processors
+const ScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/processors"
+
+// SpanMetrics is a span processor deriving RED metrics (rate, errors,
+// duration) from ended spans, aggregated per span name, kind and route
+type SpanMetrics struct {
+	calls      metric.Int64Counter
+	errors     metric.Int64Counter
+	duration   metric.Float64Histogram
+	dimensions []attribute.Key
+	kinds      map[trace.SpanKind]bool
+	tenants    *tenant.Limiter
+}
+
+// spanMetricsConfig holds the SpanMetrics settings
+type spanMetricsConfig struct {
+	meterProvider metric.MeterProvider
+	dimensions    []attribute.Key
+	kinds         []trace.SpanKind
+	buckets       []float64
+	tenants       *tenant.Limiter
+}
+
+// SpanMetricsOption configures a SpanMetrics processor
+type SpanMetricsOption func(*spanMetricsConfig)
+
+// WithSpanMetricsMeterProvider sets the meter provider the metrics are recorded on
+func WithSpanMetricsMeterProvider(mp metric.MeterProvider) SpanMetricsOption {
+	return func(c *spanMetricsConfig) {
+		c.meterProvider = mp
+	}
+}
+
+// WithDimensions adds span attributes copied onto the metrics as extra dimensions.
+// Only use low-cardinality attributes here.
+func WithDimensions(keys ...string) SpanMetricsOption {
+	return func(c *spanMetricsConfig) {
+		for _, k := range keys {
+			c.dimensions = append(c.dimensions, attribute.Key(k))
+		}
+	}
+}
+
+// WithTenantDimension adds the sap.tenant_id span attribute as dimension,
+// recording tenants beyond the limiter's cap as tenant.Other
+func WithTenantDimension(limiter *tenant.Limiter) SpanMetricsOption {
+	return func(c *spanMetricsConfig) {
+		if limiter == nil {
+			limiter = tenant.NewLimiter(0)
+		}
+		c.tenants = limiter
+	}
+}
+
+// WithSpanKinds restricts the processor to spans of the given kinds
+func WithSpanKinds(kinds ...trace.SpanKind) SpanMetricsOption {
+	return func(c *spanMetricsConfig) {
+		c.kinds = append(c.kinds, kinds...)
+	}
+}
+
+// WithDurationBuckets sets explicit histogram bucket boundaries in milliseconds
+func WithDurationBuckets(buckets ...float64) SpanMetricsOption {
+	return func(c *spanMetricsConfig) {
+		c.buckets = buckets
+	}
+}
+
+// NewSpanMetrics creates a span processor that records
+// traces.span.metrics.calls, traces.span.metrics.errors and
+// traces.span.metrics.duration for every ended span
+func NewSpanMetrics(opts ...SpanMetricsOption) (*SpanMetrics, error) {
+	cfg := &spanMetricsConfig{
+		// http.route keeps server spans aggregatable per endpoint
+		dimensions: []attribute.Key{semconv.HTTPRouteKey},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+
+	calls, err := meter.Int64Counter("traces.span.metrics.calls",
+		metric.WithDescription("Number of spans ended"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calls counter: %w", err)
+	}
+
+	errors, err := meter.Int64Counter("traces.span.metrics.errors",
+		metric.WithDescription("Number of spans ended with error status"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create errors counter: %w", err)
+	}
+
+	histOpts := []metric.Float64HistogramOption{
+		metric.WithDescription("Duration of spans"),
+		metric.WithUnit("ms"),
+	}
+	if len(cfg.buckets) > 0 {
+		histOpts = append(histOpts, metric.WithExplicitBucketBoundaries(cfg.buckets...))
+	}
+	duration, err := meter.Float64Histogram("traces.span.metrics.duration", histOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duration histogram: %w", err)
+	}
+
+	p := &SpanMetrics{
+		calls:      calls,
+		errors:     errors,
+		duration:   duration,
+		dimensions: cfg.dimensions,
+		tenants:    cfg.tenants,
+	}
+	if len(cfg.kinds) > 0 {
+		p.kinds = make(map[trace.SpanKind]bool, len(cfg.kinds))
+		for _, k := range cfg.kinds {
+			p.kinds[k] = true
+		}
+	}
+	return p, nil
+}
+
+// OnStart is a no-op; metrics are derived when spans end
+func (p *SpanMetrics) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+// OnEnd records the RED metrics for the span. Spans recorded but not
+// sampled, e.g. for the flight recorder, are left out.
+func (p *SpanMetrics) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+	if p.kinds != nil && !p.kinds[s.SpanKind()] {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, 3+len(p.dimensions))
+	attrs = append(attrs,
+		attribute.String("span.name", s.Name()),
+		attribute.String("span.kind", s.SpanKind().String()),
+		attribute.String("status.code", s.Status().Code.String()),
+	)
+	if len(p.dimensions) > 0 || p.tenants != nil {
+		for _, kv := range s.Attributes() {
+			for _, key := range p.dimensions {
+				if kv.Key == key {
+					attrs = append(attrs, kv)
+				}
+			}
+			if p.tenants != nil && kv.Key == tenant.Key {
+				attrs = append(attrs, attribute.String(tenant.Key, p.tenants.Limit(kv.Value.AsString())))
+			}
+		}
+	}
+
+	ctx := context.Background()
+	set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+	p.calls.Add(ctx, 1, set)
+	if s.Status().Code == codes.Error {
+		p.errors.Add(ctx, 1, set)
+	}
+	p.duration.Record(ctx, float64(s.EndTime().Sub(s.StartTime()))/1e6, set)
+}
+
+// Shutdown does nothing; the metrics are owned by the meter provider
+func (p *SpanMetrics) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing; the metrics are owned by the meter provider
+func (p *SpanMetrics) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSpanMetrics_RecordsRED(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	sm, err := NewSpanMetrics(WithSpanMetricsMeterProvider(mp))
+	if err != nil {
+		t.Fatalf("NewSpanMetrics failed: %v", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sm))
+	tracer := tp.Tracer("test")
+
+	for i := 0; i < 3; i++ {
+		_, span := tracer.Start(context.Background(), "GET /orders")
+		if i == 0 {
+			span.SetStatus(codes.Error, "boom")
+		}
+		span.End()
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	totals := make(map[string]int64)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					totals[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					totals[m.Name] += int64(dp.Count)
+				}
+			}
+		}
+	}
+
+	if totals["traces.span.metrics.calls"] != 3 {
+		t.Errorf("Expected 3 calls, got %d", totals["traces.span.metrics.calls"])
+	}
+	if totals["traces.span.metrics.errors"] != 1 {
+		t.Errorf("Expected 1 error, got %d", totals["traces.span.metrics.errors"])
+	}
+	if totals["traces.span.metrics.duration"] != 3 {
+		t.Errorf("Expected 3 duration samples, got %d", totals["traces.span.metrics.duration"])
+	}
+}
+
+func TestSpanMetrics_TenantDimension(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	sm, err := NewSpanMetrics(WithSpanMetricsMeterProvider(mp), WithTenantDimension(tenant.NewLimiter(1)))
+	if err != nil {
+		t.Fatalf("NewSpanMetrics failed: %v", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tenant.NewProcessor()), sdktrace.WithSpanProcessor(sm))
+	tracer := tp.Tracer("test")
+
+	for _, id := range []string{"t1", "t2", "t3"} {
+		_, span := tracer.Start(tenant.ContextWithID(context.Background(), id), "GET /orders")
+		span.End()
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	calls := make(map[string]int64)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if data, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "traces.span.metrics.calls" {
+				for _, dp := range data.DataPoints {
+					v, _ := dp.Attributes.Value(tenant.Key)
+					calls[v.AsString()] += dp.Value
+				}
+			}
+		}
+	}
+
+	if calls["t1"] != 1 || calls[tenant.Other] != 2 {
+		t.Errorf("Expected t1 and two %s calls, got %v", tenant.Other, calls)
+	}
+}
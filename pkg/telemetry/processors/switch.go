@@ -0,0 +1,91 @@
+package processors
+
+import (
+	"context"
+	"sync/atomic"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Switch turns the export of a signal on and off at runtime, e.g. by
+// remote configuration. It is on when created.
+type Switch struct {
+	off atomic.Bool
+}
+
+// NewSwitch creates a switch that is on
+func NewSwitch() *Switch {
+	return &Switch{}
+}
+
+// SetEnabled turns the switch on or off. It is safe to call concurrently with exports.
+func (s *Switch) SetEnabled(enabled bool) {
+	s.off.Store(!enabled)
+}
+
+// IsEnabled reports whether the switch is on
+func (s *Switch) IsEnabled() bool {
+	return !s.off.Load()
+}
+
+// LogProcessor wraps next so that records are dropped while the switch is off
+func (s *Switch) LogProcessor(next sdklog.Processor) sdklog.Processor {
+	return &switchedLogProcessor{next: next, s: s}
+}
+
+// switchedLogProcessor forwards records while its switch is on
+type switchedLogProcessor struct {
+	next sdklog.Processor
+	s    *Switch
+}
+
+// Enabled lets loggers skip building records while the switch is off
+func (p *switchedLogProcessor) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	if !p.s.IsEnabled() {
+		return false
+	}
+	if fp, ok := p.next.(sdklog.FilterProcessor); ok {
+		return fp.Enabled(ctx, param)
+	}
+	return true
+}
+
+// OnEmit forwards the record while the switch is on
+func (p *switchedLogProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if !p.s.IsEnabled() {
+		return nil
+	}
+	return p.next.OnEmit(ctx, record)
+}
+
+// Shutdown shuts down the wrapped processor
+func (p *switchedLogProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush flushes the wrapped processor
+func (p *switchedLogProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// MetricExporter wraps next so that collected metrics are discarded while
+// the switch is off
+func (s *Switch) MetricExporter(next metric.Exporter) metric.Exporter {
+	return &switchedMetricExporter{Exporter: next, s: s}
+}
+
+// switchedMetricExporter exports metrics while its switch is on
+type switchedMetricExporter struct {
+	metric.Exporter
+	s *Switch
+}
+
+// Export exports the metrics while the switch is on
+func (e *switchedMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if !e.s.IsEnabled() {
+		return nil
+	}
+	return e.Exporter.Export(ctx, rm)
+}
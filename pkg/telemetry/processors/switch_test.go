@@ -0,0 +1,63 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// countingMetricExporter counts exports
+type countingMetricExporter struct {
+	metric.Exporter
+	exports int
+}
+
+func (e *countingMetricExporter) Export(context.Context, *metricdata.ResourceMetrics) error {
+	e.exports++
+	return nil
+}
+
+func TestSwitch_LogProcessor(t *testing.T) {
+	capture := &captureProcessor{}
+	s := NewSwitch()
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(s.LogProcessor(capture)))
+	defer provider.Shutdown(context.Background())
+	logger := provider.Logger("test")
+
+	var r log.Record
+	r.SetSeverity(log.SeverityInfo)
+	logger.Emit(context.Background(), r)
+
+	s.SetEnabled(false)
+	logger.Emit(context.Background(), r)
+	if logger.Enabled(context.Background(), log.EnabledParameters{Severity: log.SeverityInfo}) {
+		t.Error("Expected logger to be disabled while the switch is off")
+	}
+
+	s.SetEnabled(true)
+	logger.Emit(context.Background(), r)
+	if len(capture.records) != 2 {
+		t.Errorf("Expected 2 records emitted while on, got %d", len(capture.records))
+	}
+}
+
+func TestSwitch_MetricExporter(t *testing.T) {
+	next := &countingMetricExporter{}
+	s := NewSwitch()
+	exporter := s.MetricExporter(next)
+
+	s.SetEnabled(false)
+	if s.IsEnabled() {
+		t.Error("Expected switch to be off")
+	}
+	_ = exporter.Export(context.Background(), &metricdata.ResourceMetrics{})
+	s.SetEnabled(true)
+	_ = exporter.Export(context.Background(), &metricdata.ResourceMetrics{})
+	if next.exports != 1 {
+		t.Errorf("Expected 1 export while on, got %d", next.exports)
+	}
+}
@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceContext is a log processor that stamps the trace ID, span ID and
+// trace flags of the active span in the emit context onto every log record
+// that does not carry a trace context yet
+type TraceContext struct {
+	asAttributes bool
+}
+
+// TraceContextOption configures a TraceContext processor
+type TraceContextOption func(*TraceContext)
+
+// WithTraceContextAttributes additionally adds trace_id and span_id
+// attributes, for backends that only index attributes
+func WithTraceContextAttributes() TraceContextOption {
+	return func(p *TraceContext) {
+		p.asAttributes = true
+	}
+}
+
+// NewTraceContext creates a trace context injecting log processor.
+// Register it before the exporting processor.
+func NewTraceContext(opts ...TraceContextOption) *TraceContext {
+	p := &TraceContext{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Enabled returns false: the processor only stamps records for the
+// processors registered after it, which decide whether they are needed
+func (p *TraceContext) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return false
+}
+
+// OnEmit copies the span context from ctx onto the record
+func (p *TraceContext) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if !record.TraceID().IsValid() {
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() {
+			return nil
+		}
+		record.SetTraceID(sc.TraceID())
+		record.SetSpanID(sc.SpanID())
+		record.SetTraceFlags(sc.TraceFlags())
+	}
+
+	if p.asAttributes {
+		record.AddAttributes(
+			log.String("trace_id", record.TraceID().String()),
+			log.String("span_id", record.SpanID().String()),
+		)
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (p *TraceContext) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *TraceContext) ForceFlush(ctx context.Context) error {
+	return nil
+}
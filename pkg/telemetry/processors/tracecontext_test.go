@@ -0,0 +1,77 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContext_OnEmit(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	capture := &captureProcessor{}
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(NewTraceContext(WithTraceContextAttributes())),
+		sdklog.WithProcessor(capture),
+	)
+	defer provider.Shutdown(context.Background())
+
+	var r log.Record
+	r.SetBody(log.StringValue("hello"))
+	provider.Logger("test").Emit(ctx, r)
+
+	if len(capture.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(capture.records))
+	}
+	record := capture.records[0]
+
+	if record.TraceID() != traceID || record.SpanID() != spanID {
+		t.Error("Expected trace and span ID to be stamped onto the record")
+	}
+	if !record.TraceFlags().IsSampled() {
+		t.Error("Expected sampled trace flags")
+	}
+
+	found := false
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == "trace_id" && kv.Value.AsString() == traceID.String() {
+			found = true
+		}
+		return true
+	})
+	if !found {
+		t.Error("Expected trace_id attribute")
+	}
+}
+
+func TestTraceContext_NoSpan(t *testing.T) {
+	record := &sdklog.Record{}
+	if err := NewTraceContext().OnEmit(context.Background(), record); err != nil {
+		t.Fatalf("OnEmit failed: %v", err)
+	}
+	if record.TraceID().IsValid() {
+		t.Error("Expected no trace ID without an active span")
+	}
+}
+
+// captureProcessor keeps a copy of every emitted record
+type captureProcessor struct {
+	records []sdklog.Record
+}
+
+func (p *captureProcessor) OnEmit(_ context.Context, record *sdklog.Record) error {
+	p.records = append(p.records, record.Clone())
+	return nil
+}
+
+func (p *captureProcessor) Shutdown(context.Context) error   { return nil }
+func (p *captureProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,208 @@
+package processors
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TruncationSuffix marks a shortened attribute value
+const TruncationSuffix = "…"
+
+// Truncator shortens long string attribute values, such as db.statement or
+// request bodies, before export
+type Truncator struct {
+	max int
+}
+
+// NewTruncator creates a truncator keeping at most max characters per
+// string value; max <= 0 disables truncation
+func NewTruncator(max int) *Truncator {
+	return &Truncator{max: max}
+}
+
+// String returns s cut to the maximum length and whether it was cut
+func (t *Truncator) String(s string) (string, bool) {
+	if t.max <= 0 || len(s) <= t.max || utf8.RuneCountInString(s) <= t.max {
+		return s, false
+	}
+	// Cut at a rune boundary so multi-byte characters stay intact
+	n := 0
+	for i := range s {
+		if n == t.max {
+			return s[:i] + TruncationSuffix, true
+		}
+		n++
+	}
+	return s, false
+}
+
+// Attribute truncates string and string slice values
+func (t *Truncator) Attribute(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	switch kv.Value.Type() {
+	case attribute.STRING:
+		if s, ok := t.String(kv.Value.AsString()); ok {
+			return attribute.String(string(kv.Key), s), true
+		}
+	case attribute.STRINGSLICE:
+		values := kv.Value.AsStringSlice()
+		changed := false
+		for i, v := range values {
+			if s, ok := t.String(v); ok {
+				values[i] = s
+				changed = true
+			}
+		}
+		if changed {
+			return attribute.StringSlice(string(kv.Key), values), true
+		}
+	}
+	return kv, false
+}
+
+// Attributes returns the truncated attributes and whether any was changed
+func (t *Truncator) Attributes(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		truncated, changed := t.Attribute(kv)
+		if !changed {
+			if out != nil {
+				out[i] = kv
+			}
+			continue
+		}
+		// Copy lazily on the first truncated attribute
+		if out == nil {
+			out = make([]attribute.KeyValue, len(attrs))
+			copy(out, attrs[:i])
+		}
+		out[i] = truncated
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+// logValue truncates strings, also within maps and slices
+func (t *Truncator) logValue(v log.Value) (log.Value, bool) {
+	switch v.Kind() {
+	case log.KindString:
+		if s, ok := t.String(v.AsString()); ok {
+			return log.StringValue(s), true
+		}
+	case log.KindSlice:
+		items := v.AsSlice()
+		out := make([]log.Value, len(items))
+		changed := false
+		for i, item := range items {
+			var ok bool
+			out[i], ok = t.logValue(item)
+			changed = changed || ok
+		}
+		if changed {
+			return log.SliceValue(out...), true
+		}
+	case log.KindMap:
+		kvs := v.AsMap()
+		out := make([]log.KeyValue, len(kvs))
+		changed := false
+		for i, kv := range kvs {
+			var ok bool
+			out[i] = kv
+			out[i].Value, ok = t.logValue(kv.Value)
+			changed = changed || ok
+		}
+		if changed {
+			return log.MapValue(out...), true
+		}
+	}
+	return v, false
+}
+
+// Enabled returns false: the truncator only modifies records for the
+// processors registered after it, which decide whether they are needed
+func (t *Truncator) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return false
+}
+
+// OnEmit truncates the attribute values of the record; the body is kept.
+// Register the truncator before the exporting processor.
+func (t *Truncator) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	attrs := make([]log.KeyValue, 0, record.AttributesLen())
+	changed := false
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		if v, ok := t.logValue(kv.Value); ok {
+			kv.Value = v
+			changed = true
+		}
+		attrs = append(attrs, kv)
+		return true
+	})
+	if changed {
+		record.SetAttributes(attrs...)
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (t *Truncator) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (t *Truncator) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// SpanExporter wraps next so that span and event attributes are truncated
+// before export
+func (t *Truncator) SpanExporter(next sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &truncatingSpanExporter{SpanExporter: next, truncator: t}
+}
+
+// truncatingSpanExporter truncates spans before handing them to the wrapped exporter
+type truncatingSpanExporter struct {
+	sdktrace.SpanExporter
+	truncator *Truncator
+}
+
+// ExportSpans exports the truncated spans
+func (e *truncatingSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	truncated := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		truncated[i] = e.truncator.span(s)
+	}
+	return e.SpanExporter.ExportSpans(ctx, truncated)
+}
+
+// span returns s itself when no value had to be truncated
+func (t *Truncator) span(s sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	attrs, changed := t.Attributes(s.Attributes())
+
+	events := s.Events()
+	var truncatedEvents []sdktrace.Event
+	for i, ev := range events {
+		evAttrs, evChanged := t.Attributes(ev.Attributes)
+		if !evChanged {
+			continue
+		}
+		if truncatedEvents == nil {
+			truncatedEvents = make([]sdktrace.Event, len(events))
+			copy(truncatedEvents, events)
+		}
+		truncatedEvents[i].Attributes = evAttrs
+	}
+
+	if !changed && truncatedEvents == nil {
+		return s
+	}
+	if truncatedEvents == nil {
+		truncatedEvents = events
+	}
+	return &overriddenSpan{ReadOnlySpan: s, attrs: attrs, events: truncatedEvents}
+}
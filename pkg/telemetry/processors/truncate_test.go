@@ -0,0 +1,115 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTruncator_String(t *testing.T) {
+	tr := NewTruncator(5)
+
+	tests := map[string]string{
+		"short":          "short",
+		"SELECT * FROM":  "SELEC" + TruncationSuffix,
+		"grüße aus köln": "grüße" + TruncationSuffix,
+	}
+	for in, expected := range tests {
+		if got, _ := tr.String(in); got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, in, got)
+		}
+	}
+
+	if got, changed := NewTruncator(0).String("unlimited"); changed || got != "unlimited" {
+		t.Errorf("Expected no truncation without a maximum, got %q", got)
+	}
+}
+
+func TestTruncator_Attributes(t *testing.T) {
+	tr := NewTruncator(4)
+
+	attrs := []attribute.KeyValue{
+		attribute.Int("http.response.status_code", 200),
+		attribute.String("db.statement", "SELECT 1"),
+		attribute.StringSlice("tags", []string{"a", "abcdefg"}),
+	}
+	truncated, changed := tr.Attributes(attrs)
+	if !changed {
+		t.Fatal("Expected attributes to be changed")
+	}
+	if truncated[0] != attrs[0] {
+		t.Error("Expected non-string attribute to be untouched")
+	}
+	if got := truncated[1].Value.AsString(); got != "SELE"+TruncationSuffix {
+		t.Errorf("Unexpected db.statement %q", got)
+	}
+	if got := truncated[2].Value.AsStringSlice()[1]; got != "abcd"+TruncationSuffix {
+		t.Errorf("Unexpected tag %q", got)
+	}
+	if attrs[1].Value.AsString() != "SELECT 1" {
+		t.Error("Expected input attributes not to be modified")
+	}
+
+	if _, changed := tr.Attributes(attrs[:1]); changed {
+		t.Error("Expected short attributes to be unchanged")
+	}
+}
+
+func TestTruncator_OnEmit(t *testing.T) {
+	capture := &captureProcessor{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(NewTruncator(3)), sdklog.WithProcessor(capture))
+	defer provider.Shutdown(context.Background())
+
+	var rec log.Record
+	rec.SetBody(log.StringValue("body is kept"))
+	rec.AddAttributes(
+		log.String("request.body", "abcdef"),
+		log.Map("nested", log.String("value", "uvwxyz")),
+	)
+	provider.Logger("test").Emit(context.Background(), rec)
+
+	record := capture.records[0]
+	if got := record.Body().AsString(); got != "body is kept" {
+		t.Errorf("Expected body to be untouched, got %q", got)
+	}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		switch kv.Key {
+		case "request.body":
+			if got := kv.Value.AsString(); got != "abc"+TruncationSuffix {
+				t.Errorf("Unexpected request.body %q", got)
+			}
+		case "nested":
+			if got := kv.Value.AsMap()[0].Value.AsString(); got != "uvw"+TruncationSuffix {
+				t.Errorf("Unexpected nested value %q", got)
+			}
+		}
+		return true
+	})
+}
+
+func TestTruncator_SpanExporter(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewTruncator(6).SpanExporter(exporter)))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "query")
+	span.SetAttributes(attribute.String("db.statement", "SELECT * FROM orders"))
+	span.AddEvent("retry", trace.WithAttributes(attribute.String("reason", "deadlock detected")))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].Attributes[0].Value.AsString(); got != "SELECT"+TruncationSuffix {
+		t.Errorf("Unexpected db.statement %q", got)
+	}
+	if got := spans[0].Events[0].Attributes[0].Value.AsString(); got != "deadlo"+TruncationSuffix {
+		t.Errorf("Unexpected event reason %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"fmt"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/profiling"
+)
+
+// initProfiling starts capturing profiles and labels the samples of root
+// spans if trace_labels is set
+func (t *Telemetry) initProfiling() error {
+	cfg := t.config.Profiling
+	exporter, err := profilingExporter(cfg.Exporter, t.config.ServiceName)
+	if err != nil {
+		return err
+	}
+
+	opts := []profiling.Option{
+		profiling.WithInterval(cfg.GetInterval()),
+		profiling.WithCPUDuration(cfg.GetCPUDuration()),
+		profiling.WithErrorHandler(func(err error) {
+			t.logger.Printf("profiling: %v", err)
+		}),
+	}
+	if len(cfg.Types) > 0 {
+		opts = append(opts, profiling.WithTypes(cfg.Types...))
+	}
+	t.profiler, err = profiling.Start(exporter, opts...)
+	if err != nil {
+		return err
+	}
+
+	if cfg.TraceLabels && t.tracerProvider != nil {
+		t.globalTracer = profiling.TracerProvider(t.tracerProvider)
+	}
+	return nil
+}
+
+// profilingExporter creates the exporter of profiling.exporter; Pyroscope
+// applications are named after the service
+func profilingExporter(exp *config.ExporterConfig, serviceName string) (profiling.Exporter, error) {
+	switch exp.Module {
+	case "pyroscope":
+		url, ok := exp.Config["url"].(string)
+		if !ok || url == "" {
+			return nil, fmt.Errorf("failed to configure pyroscope profile exporter: missing url")
+		}
+		var opts []profiling.PyroscopeOption
+		if v, ok := exp.Config["tags"]; ok {
+			tags, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("failed to configure pyroscope profile exporter: invalid tags: expected a map, got %T", v)
+			}
+			labels := make(map[string]string, len(tags))
+			for k, v := range tags {
+				labels[k] = fmt.Sprint(v)
+			}
+			opts = append(opts, profiling.WithTags(labels))
+		}
+		if user, ok := exp.Config["basic_auth_user"].(string); ok {
+			var password string
+			if secret, ok := exp.Secret("basic_auth_password"); ok {
+				value, err := secret.Value()
+				if err != nil {
+					return nil, fmt.Errorf("failed to configure pyroscope profile exporter: %w", err)
+				}
+				password = value
+			}
+			opts = append(opts, profiling.WithBasicAuth(user, password))
+		}
+		return profiling.NewPyroscopeExporter(url, serviceName, opts...)
+	case "file":
+		dir, ok := exp.Config["dir"].(string)
+		if !ok || dir == "" {
+			return nil, fmt.Errorf("failed to configure file profile exporter: missing dir")
+		}
+		return profiling.NewDirExporter(dir)
+	default:
+		return nil, fmt.Errorf("unsupported profile exporter: %s", exp.Module)
+	}
+}
@@ -0,0 +1,162 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PyroscopeExporter ships profiles to the ingest API of Pyroscope or
+// Grafana Cloud Profiles
+type PyroscopeExporter struct {
+	endpoint string
+	app      string
+	tags     map[string]string
+	client   *http.Client
+
+	username string
+	password string
+}
+
+// PyroscopeOption configures the Pyroscope exporter
+type PyroscopeOption func(*PyroscopeExporter)
+
+// WithTags adds static labels to all profiles, e.g. the environment
+func WithTags(tags map[string]string) PyroscopeOption {
+	return func(e *PyroscopeExporter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// WithBasicAuth authenticates the requests, e.g. for Grafana Cloud
+func WithBasicAuth(username, password string) PyroscopeOption {
+	return func(e *PyroscopeExporter) {
+		e.username = username
+		e.password = password
+	}
+}
+
+// WithHTTPClient sets the client shipping the profiles
+func WithHTTPClient(client *http.Client) PyroscopeOption {
+	return func(e *PyroscopeExporter) {
+		e.client = client
+	}
+}
+
+// NewPyroscopeExporter creates an exporter shipping the profiles of app to
+// the server at endpoint, e.g. "http://localhost:4040"
+func NewPyroscopeExporter(endpoint, app string, opts ...PyroscopeOption) (*PyroscopeExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pyroscope URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("pyroscope URL %q must use http or https", endpoint)
+	}
+	e := &PyroscopeExporter{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		app:      app,
+		tags:     make(map[string]string),
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// pyroscopeNames maps the profile types to the names of the Go profiles
+// in Pyroscope
+var pyroscopeNames = map[string]string{
+	CPU:       "process_cpu",
+	Heap:      "memory",
+	Allocs:    "memory",
+	Goroutine: "goroutines",
+	Mutex:     "mutex",
+	Block:     "block",
+}
+
+// Export posts the profile to /ingest
+func (e *PyroscopeExporter) Export(ctx context.Context, p *Profile) error {
+	query := url.Values{}
+	query.Set("name", e.name(p.Type))
+	query.Set("from", strconv.FormatInt(p.Start.Unix(), 10))
+	query.Set("until", strconv.FormatInt(p.End.Unix(), 10))
+	query.Set("format", "pprof")
+	query.Set("spyName", "gospy")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+"/ingest?"+query.Encode(), bytes.NewReader(p.Data))
+	if err != nil {
+		return fmt.Errorf("failed to create ingest request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if e.username != "" || e.password != "" {
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send profile: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send profile: pyroscope returned %s", resp.Status)
+	}
+	return nil
+}
+
+// name renders the application name with the profile name and tags,
+// e.g. "bookshop.process_cpu{env=prod}"
+func (e *PyroscopeExporter) name(typ string) string {
+	name := e.app
+	if n, ok := pyroscopeNames[typ]; ok {
+		name += "." + n
+	}
+	if len(e.tags) == 0 {
+		return name
+	}
+	keys := make([]string, 0, len(e.tags))
+	for k := range e.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]string, len(keys))
+	for i, k := range keys {
+		tags[i] = k + "=" + e.tags[k]
+	}
+	return name + "{" + strings.Join(tags, ",") + "}"
+}
+
+// DirExporter writes profiles to files in a directory, e.g. for local
+// analysis with go tool pprof
+type DirExporter struct {
+	dir string
+}
+
+// NewDirExporter creates an exporter writing to dir, creating it if needed
+func NewDirExporter(dir string) (*DirExporter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return &DirExporter{dir: dir}, nil
+}
+
+// Export writes the profile to <type>-<unix nanos>.pb.gz
+func (e *DirExporter) Export(ctx context.Context, p *Profile) error {
+	file := filepath.Join(e.dir, fmt.Sprintf("%s-%d.pb.gz", p.Type, p.Start.UnixNano()))
+	if err := os.WriteFile(file, p.Data, 0o644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pprof labels set by the tracer provider of TracerProvider
+const (
+	TraceIDLabel = "trace_id"
+	SpanIDLabel  = "span_id"
+)
+
+// TracerProvider wraps tp so sampled local root spans, e.g. server spans,
+// label the profile samples of their goroutine and the goroutines started
+// with their context with their trace and span IDs until they end
+func TracerProvider(tp trace.TracerProvider) trace.TracerProvider {
+	return &tracerProvider{TracerProvider: tp}
+}
+
+// tracerProvider creates labeling tracers
+type tracerProvider struct {
+	trace.TracerProvider
+}
+
+// Tracer returns a labeling tracer
+func (p *tracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return &tracer{Tracer: p.TracerProvider.Tracer(name, opts...)}
+}
+
+// tracer labels the goroutines of local root spans
+type tracer struct {
+	trace.Tracer
+}
+
+// Start starts the span and labels the goroutine if it is a sampled local
+// root span
+func (t *tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanContextFromContext(ctx)
+	spanCtx, span := t.Tracer.Start(ctx, name, opts...)
+	sc := span.SpanContext()
+	if (parent.IsValid() && !parent.IsRemote()) || !sc.IsSampled() {
+		return spanCtx, span
+	}
+
+	labeled := &labeledSpan{Span: span, parent: ctx}
+	spanCtx = pprof.WithLabels(trace.ContextWithSpan(spanCtx, labeled),
+		pprof.Labels(TraceIDLabel, sc.TraceID().String(), SpanIDLabel, sc.SpanID().String()))
+	pprof.SetGoroutineLabels(spanCtx)
+	return spanCtx, labeled
+}
+
+// labeledSpan restores the labels of the parent context when it ends
+type labeledSpan struct {
+	trace.Span
+	parent context.Context
+}
+
+// End ends the span and restores the goroutine labels
+func (s *labeledSpan) End(opts ...trace.SpanEndOption) {
+	s.Span.End(opts...)
+	pprof.SetGoroutineLabels(s.parent)
+}
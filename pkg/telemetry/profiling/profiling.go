@@ -0,0 +1,214 @@
+// Package profiling captures pprof profiles continuously and ships them to
+// a profiling backend.
+//
+// A Profiler captures a CPU profile of a configurable duration and
+// snapshots of the other profile types once per interval and hands each
+// profile to an Exporter. Wrapping the tracer provider with TracerProvider
+// labels the samples of local root spans with their trace and span IDs, so
+// a slow trace can be looked up in the profiles.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// Profile types
+const (
+	CPU       = "cpu"
+	Heap      = "heap"
+	Allocs    = "allocs"
+	Goroutine = "goroutine"
+	Mutex     = "mutex"
+	Block     = "block"
+)
+
+// Defaults of the profiler
+const (
+	DefaultInterval    = time.Minute
+	DefaultCPUDuration = 10 * time.Second
+)
+
+// Profile is a captured pprof profile
+type Profile struct {
+	// Type is one of the profile types, e.g. CPU
+	Type string
+	// Start and End delimit the time the profile covers; snapshots of
+	// non-CPU profiles have the same start and end
+	Start time.Time
+	End   time.Time
+	// Data is the gzipped pprof protobuf
+	Data []byte
+}
+
+// Exporter ships captured profiles to a backend
+type Exporter interface {
+	Export(ctx context.Context, p *Profile) error
+}
+
+// Profiler captures profiles on its schedule until Shutdown
+type Profiler struct {
+	exporter    Exporter
+	types       []string
+	interval    time.Duration
+	cpuDuration time.Duration
+	onError     func(error)
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Option configures the profiler
+type Option func(*Profiler)
+
+// WithTypes sets the profile types captured; defaults to CPU and Heap
+func WithTypes(types ...string) Option {
+	return func(p *Profiler) {
+		p.types = append([]string{}, types...)
+	}
+}
+
+// WithInterval sets how often profiles are captured, DefaultInterval if
+// unset
+func WithInterval(d time.Duration) Option {
+	return func(p *Profiler) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// WithCPUDuration sets how long each CPU profile runs, DefaultCPUDuration
+// if unset; it is capped to the interval
+func WithCPUDuration(d time.Duration) Option {
+	return func(p *Profiler) {
+		if d > 0 {
+			p.cpuDuration = d
+		}
+	}
+}
+
+// WithErrorHandler sets the function receiving capture and export errors,
+// which are dropped otherwise
+func WithErrorHandler(handler func(error)) Option {
+	return func(p *Profiler) {
+		p.onError = handler
+	}
+}
+
+// Start validates the options and starts capturing profiles for exporter
+func Start(exporter Exporter, opts ...Option) (*Profiler, error) {
+	p := &Profiler{
+		exporter:    exporter,
+		types:       []string{CPU, Heap},
+		interval:    DefaultInterval,
+		cpuDuration: DefaultCPUDuration,
+		onError:     func(error) {},
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for _, typ := range p.types {
+		if typ != CPU && pprof.Lookup(typ) == nil {
+			return nil, fmt.Errorf("unknown profile type %q", typ)
+		}
+	}
+	p.cpuDuration = min(p.cpuDuration, p.interval)
+
+	go p.run()
+	return p, nil
+}
+
+// run captures profiles every interval until Shutdown
+func (p *Profiler) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.capture()
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// capture captures and exports one round of profiles
+func (p *Profiler) capture() {
+	ctx := context.Background()
+	for _, typ := range p.types {
+		profile, err := p.profile(typ)
+		if err != nil {
+			p.onError(fmt.Errorf("failed to capture %s profile: %w", typ, err))
+			continue
+		}
+		if profile == nil {
+			return
+		}
+		if err := p.exporter.Export(ctx, profile); err != nil {
+			p.onError(fmt.Errorf("failed to export %s profile: %w", typ, err))
+		}
+	}
+}
+
+// profile captures a profile of typ; nil if the profiler was stopped while
+// the CPU profile ran
+func (p *Profiler) profile(typ string) (*Profile, error) {
+	var buf bytes.Buffer
+	start := time.Now()
+	if typ != CPU {
+		if err := pprof.Lookup(typ).WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+		return &Profile{Type: typ, Start: start, End: start, Data: buf.Bytes()}, nil
+	}
+
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(p.cpuDuration)
+	defer timer.Stop()
+	stopped := false
+	select {
+	case <-timer.C:
+	case <-p.stop:
+		stopped = true
+	}
+	pprof.StopCPUProfile()
+	profile := &Profile{Type: CPU, Start: start, End: time.Now(), Data: buf.Bytes()}
+	if stopped {
+		// Ship the partial profile, nothing else is captured anymore
+		if err := p.exporter.Export(context.Background(), profile); err != nil {
+			p.onError(fmt.Errorf("failed to export %s profile: %w", CPU, err))
+		}
+		return nil, nil
+	}
+	return profile, nil
+}
+
+// Shutdown stops capturing, a running CPU profile is stopped and exported
+func (p *Profiler) Shutdown(ctx context.Context) error {
+	stopped := false
+	p.once.Do(func() {
+		close(p.stop)
+		stopped = true
+	})
+	if !stopped {
+		return errors.New("profiler already shut down")
+	}
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,181 @@
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingExporter records the exported profiles
+type recordingExporter struct {
+	mu       sync.Mutex
+	profiles []*Profile
+	exported chan struct{}
+}
+
+func newRecordingExporter() *recordingExporter {
+	return &recordingExporter{exported: make(chan struct{}, 100)}
+}
+
+func (e *recordingExporter) Export(ctx context.Context, p *Profile) error {
+	e.mu.Lock()
+	e.profiles = append(e.profiles, p)
+	e.mu.Unlock()
+	e.exported <- struct{}{}
+	return nil
+}
+
+// wait waits for n exports
+func (e *recordingExporter) wait(t *testing.T, n int) []*Profile {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-e.exported:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected %d profiles, got %d", n, i)
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Profile(nil), e.profiles...)
+}
+
+// isGzip reports whether data is a gzipped pprof protobuf
+func isGzip(data []byte) bool {
+	return len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+func TestProfiler(t *testing.T) {
+	exporter := newRecordingExporter()
+	p, err := Start(exporter, WithTypes(CPU, Heap, Goroutine), WithCPUDuration(20*time.Millisecond), WithInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to start profiler: %v", err)
+	}
+
+	profiles := exporter.wait(t, 3)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	for i, typ := range []string{CPU, Heap, Goroutine} {
+		if profiles[i].Type != typ || !isGzip(profiles[i].Data) {
+			t.Errorf("Expected a gzipped %s profile, got %s", typ, profiles[i].Type)
+		}
+	}
+	if d := profiles[0].End.Sub(profiles[0].Start); d < 20*time.Millisecond {
+		t.Errorf("Expected the CPU profile to cover 20ms, got %v", d)
+	}
+	if err := p.Shutdown(context.Background()); err == nil {
+		t.Error("Expected an error shutting down twice")
+	}
+}
+
+func TestProfiler_ShutdownDuringCPUProfile(t *testing.T) {
+	exporter := newRecordingExporter()
+	p, err := Start(exporter, WithTypes(CPU, Heap), WithCPUDuration(time.Hour), WithInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to start profiler: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	if profiles := exporter.wait(t, 1); len(profiles) != 1 || profiles[0].Type != CPU {
+		t.Errorf("Expected only the partial CPU profile, got %d profiles", len(profiles))
+	}
+}
+
+func TestStart_UnknownType(t *testing.T) {
+	if _, err := Start(newRecordingExporter(), WithTypes("threads")); err == nil {
+		t.Error("Expected an error for an unknown profile type")
+	}
+}
+
+func TestPyroscopeExporter(t *testing.T) {
+	var query map[string][]string
+	var user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		user, password, _ = r.BasicAuth()
+	}))
+	defer server.Close()
+
+	exporter, err := NewPyroscopeExporter(server.URL, "bookshop",
+		WithTags(map[string]string{"env": "prod", "region": "eu10"}), WithBasicAuth("user", "secret"))
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	profile := &Profile{Type: CPU, Start: start, End: start.Add(10 * time.Second), Data: []byte{0x1f, 0x8b}}
+	if err := exporter.Export(context.Background(), profile); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	if got := query["name"][0]; got != "bookshop.process_cpu{env=prod,region=eu10}" {
+		t.Errorf("Unexpected name %q", got)
+	}
+	if query["from"][0] != "1700000000" || query["until"][0] != "1700000010" || query["format"][0] != "pprof" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if user != "user" || password != "secret" {
+		t.Errorf("Expected basic auth, got %q %q", user, password)
+	}
+
+	if _, err := NewPyroscopeExporter("localhost:4040", "bookshop"); err == nil {
+		t.Error("Expected an error for a URL without scheme")
+	}
+}
+
+func TestDirExporter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	exporter, err := NewDirExporter(dir)
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+	start := time.Unix(0, 42)
+	if err := exporter.Export(context.Background(), &Profile{Type: Heap, Start: start, End: start, Data: []byte("data")}); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "heap-42.pb.gz")); err != nil || string(data) != "data" {
+		t.Errorf("Expected the profile file, got %q %v", data, err)
+	}
+}
+
+func TestTracerProvider(t *testing.T) {
+	tracer := TracerProvider(sdktrace.NewTracerProvider()).Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "GET /books")
+	sc := root.SpanContext()
+	if got, _ := pprof.Label(ctx, TraceIDLabel); got != sc.TraceID().String() {
+		t.Errorf("Expected the trace ID label, got %q", got)
+	}
+	if trace.SpanFromContext(ctx) != root {
+		t.Error("Expected the labeled span in the context")
+	}
+
+	// Children keep the labels of their local root
+	childCtx, child := tracer.Start(ctx, "db.query")
+	if got, _ := pprof.Label(childCtx, SpanIDLabel); got != sc.SpanID().String() {
+		t.Errorf("Expected the span ID of the root, got %q", got)
+	}
+	child.End()
+	root.End()
+
+	unsampled := TracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))).Tracer("test")
+	ctx, span := unsampled.Start(context.Background(), "GET /health")
+	if _, ok := pprof.Label(ctx, TraceIDLabel); ok {
+		t.Error("Expected no labels for unsampled spans")
+	}
+	span.End()
+}
@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/profiling"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInitProfiling(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	dir := t.TempDir()
+	cfg := config.NewDefaultConfig()
+	cfg.Logging.Enabled = false
+	cfg.Metrics.Enabled = false
+	cfg.Profiling = &config.ProfilingConfig{
+		Enabled:     true,
+		Types:       []string{profiling.Goroutine},
+		TraceLabels: true,
+		Exporter:    &config.ExporterConfig{Module: "file", Config: map[string]interface{}{"dir": dir}},
+	}
+	tel, err := New(WithConfig(cfg), WithSpanExporter(tracetest.NewInMemoryExporter()), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+
+	ctx, _, end := Start(context.Background(), "GET /books")
+	if _, ok := pprof.Label(ctx, profiling.TraceIDLabel); !ok {
+		t.Error("Expected root spans to label the profiles")
+	}
+	end(nil)
+
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "goroutine-*.pb.gz")); len(files) == 0 {
+		entries, _ := os.ReadDir(dir)
+		t.Errorf("Expected a goroutine profile, got %v", entries)
+	}
+}
+
+func TestProfilingExporter(t *testing.T) {
+	tests := []*config.ExporterConfig{
+		{Module: "pyroscope"},
+		{Module: "pyroscope", Config: map[string]interface{}{"url": "http://localhost:4040", "tags": "env=prod"}},
+		{Module: "file"},
+		{Module: "otlp"},
+	}
+	for _, exp := range tests {
+		if _, err := profilingExporter(exp, "bookshop"); err == nil {
+			t.Errorf("Expected an error for %v", exp)
+		}
+	}
+
+	exp := &config.ExporterConfig{Module: "pyroscope", Config: map[string]interface{}{
+		"url":  "http://localhost:4040",
+		"tags": map[string]interface{}{"env": "prod"},
+	}}
+	if _, err := profilingExporter(exp, "bookshop"); err != nil {
+		t.Errorf("Expected the pyroscope exporter, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/correlation"
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/contrib/propagators/ot"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// defaultPropagators are used when neither OTEL_PROPAGATORS nor the
+// configuration name any propagator
+var defaultPropagators = []string{"tracecontext", "baggage"}
+
+// newPropagator creates a single propagator by its OTEL_PROPAGATORS name
+func newPropagator(name string) (propagation.TextMapPropagator, error) {
+	switch name {
+	case "tracecontext":
+		return propagation.TraceContext{}, nil
+	case "baggage":
+		return propagation.Baggage{}, nil
+	case "b3":
+		return b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)), nil
+	case "b3multi":
+		return b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)), nil
+	case "jaeger":
+		return jaeger.Jaeger{}, nil
+	case "xray":
+		return xray.Propagator{}, nil
+	case "ottrace":
+		return ot.OT{}, nil
+	case "correlation":
+		return correlation.Propagator{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported propagator: %s", name)
+	}
+}
+
+// createPropagator creates the composite propagator from OTEL_PROPAGATORS,
+// falling back to tracing.propagators and then to tracecontext and baggage.
+// The correlation propagator is added when correlation_id is enabled.
+func (t *Telemetry) createPropagator() (propagation.TextMapPropagator, error) {
+	names := defaultPropagators
+	if env := os.Getenv("OTEL_PROPAGATORS"); env != "" {
+		names = strings.Split(env, ",")
+	} else if t.config.Tracing != nil && len(t.config.Tracing.Propagators) > 0 {
+		names = t.config.Tracing.Propagators
+	}
+
+	// The correlation propagator goes last so the baggage is extracted first
+	if t.config.CorrelationID && !slices.Contains(names, "correlation") {
+		names = append(slices.Clip(names), "correlation")
+	}
+
+	var propagators []propagation.TextMapPropagator
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		// "none" disables propagation altogether
+		if name == "none" {
+			return propagation.NewCompositeTextMapPropagator(), nil
+		}
+		p, err := newPropagator(name)
+		if err != nil {
+			return nil, err
+		}
+		propagators = append(propagators, p)
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
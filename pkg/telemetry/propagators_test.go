@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+)
+
+func TestCreatePropagator(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	fields := func() string {
+		p, err := tel.createPropagator()
+		if err != nil {
+			t.Fatalf("createPropagator failed: %v", err)
+		}
+		f := p.Fields()
+		sort.Strings(f)
+		return strings.Join(f, ",")
+	}
+
+	if got := fields(); got != "baggage,traceparent,tracestate" {
+		t.Errorf("Unexpected default fields %s", got)
+	}
+
+	cfg.Tracing.Propagators = []string{"b3multi"}
+	if got := fields(); got != "x-b3-flags,x-b3-sampled,x-b3-spanid,x-b3-traceid" {
+		t.Errorf("Unexpected b3multi fields %s", got)
+	}
+
+	cfg.CorrelationID = true
+	if got := fields(); got != "X-Correlation-ID,X-Vcap-Request-Id,x-b3-flags,x-b3-sampled,x-b3-spanid,x-b3-traceid" {
+		t.Errorf("Expected correlation propagator to be appended, got %s", got)
+	}
+	cfg.CorrelationID = false
+
+	t.Setenv("OTEL_PROPAGATORS", "jaeger, b3")
+	if got := fields(); got != "b3,uber-trace-id" {
+		t.Errorf("Expected OTEL_PROPAGATORS to take precedence, got %s", got)
+	}
+
+	t.Setenv("OTEL_PROPAGATORS", "none")
+	if got := fields(); got != "" {
+		t.Errorf("Expected no fields for none, got %s", got)
+	}
+
+	t.Setenv("OTEL_PROPAGATORS", "unknown")
+	if _, err := tel.createPropagator(); err == nil {
+		t.Error("Expected error for unknown propagator")
+	}
+}
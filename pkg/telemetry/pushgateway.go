@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"fmt"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/pushgateway"
+)
+
+// pushgatewayExporter creates the Pushgateway exporter of
+// metrics.exporter.config; the job defaults to the service name
+func pushgatewayExporter(cfg map[string]interface{}, serviceName string) (*pushgateway.Exporter, error) {
+	url, ok := cfg["url"].(string)
+	if !ok || url == "" {
+		return nil, fmt.Errorf("missing url")
+	}
+
+	opts := []pushgateway.Option{pushgateway.WithJob(serviceName)}
+	if v, ok := cfg["job"]; ok {
+		opts = append(opts, pushgateway.WithJob(fmt.Sprint(v)))
+	}
+	if v, ok := cfg["grouping"]; ok {
+		labels, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid grouping: expected a map, got %T", v)
+		}
+		grouping := make(map[string]string, len(labels))
+		for k, v := range labels {
+			grouping[k] = fmt.Sprint(v)
+		}
+		opts = append(opts, pushgateway.WithGrouping(grouping))
+	}
+	return pushgateway.New(url, opts...)
+}
@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"errors"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/remote"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// remoteBaseline holds the configured settings, restored when the remote
+// document no longer overrides them
+type remoteBaseline struct {
+	sampler       trace.Sampler
+	samplerConfig *config.SamplerConfig
+	logLevel      string
+}
+
+// initRemote starts polling the remote configuration if a URL is configured
+func (t *Telemetry) initRemote() error {
+	cfg := t.config.Remote
+	if cfg == nil || cfg.URL == "" {
+		return nil
+	}
+
+	opts := []remote.PollerOption{
+		remote.WithLogger(t.logger),
+		remote.WithPollInterval(cfg.GetPollInterval()),
+	}
+	if cfg.PublicKey != "" {
+		key, err := remote.ParsePublicKey(cfg.PublicKey)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, remote.WithPublicKey(key))
+	}
+
+	if t.sampler != nil {
+		t.remoteBaseline.sampler = t.sampler.Sampler()
+		if sc := t.config.Tracing.Sampler; sc != nil {
+			copied := *sc
+			t.remoteBaseline.samplerConfig = &copied
+		}
+	}
+	if t.logLevel != nil {
+		t.remoteBaseline.logLevel = t.config.Logging.Level
+	}
+
+	t.remote = remote.NewPoller(cfg.URL, t.applyRemoteSettings, opts...)
+	t.remote.Start()
+	return nil
+}
+
+// applyRemoteSettings applies a remote document. Settings it leaves out are
+// restored to their configured values. Signals disabled at startup can't be
+// turned on remotely, so their settings are ignored.
+func (t *Telemetry) applyRemoteSettings(s *remote.Settings) error {
+	t.settingsMu.Lock()
+	defer t.settingsMu.Unlock()
+
+	var errs []error
+
+	if t.sampler != nil {
+		tracing := s.Tracing
+		if tracing == nil {
+			tracing = &remote.TracingSettings{}
+		}
+		if tracing.SamplingRatio != nil {
+			errs = append(errs, t.setSamplingRatio(*tracing.SamplingRatio))
+		} else {
+			t.sampler.Set(t.remoteBaseline.sampler)
+			if t.remoteBaseline.samplerConfig != nil {
+				copied := *t.remoteBaseline.samplerConfig
+				t.config.Tracing.Sampler = &copied
+			}
+		}
+		t.sampler.SetEnabled(tracing.Enabled == nil || *tracing.Enabled)
+	}
+
+	if t.metricsSwitch != nil {
+		t.metricsSwitch.SetEnabled(s.Metrics == nil || s.Metrics.Enabled == nil || *s.Metrics.Enabled)
+	}
+
+	if t.loggingSwitch != nil {
+		logging := s.Logging
+		if logging == nil {
+			logging = &remote.LoggingSettings{}
+		}
+		level := t.remoteBaseline.logLevel
+		if logging.Level != nil {
+			level = *logging.Level
+		}
+		errs = append(errs, t.setLogLevel(level))
+		t.loggingSwitch.SetEnabled(logging.Enabled == nil || *logging.Enabled)
+	}
+
+	return errors.Join(errs...)
+}
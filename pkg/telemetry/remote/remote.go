@@ -0,0 +1,281 @@
+// Package remote polls a central configuration endpoint for settings
+// overriding sampling ratios, log levels and enabled signals at runtime.
+//
+// The endpoint serves a JSON document such as
+//
+//	{"tracing": {"sampling_ratio": 0.05}, "logging": {"level": "debug"}}
+//
+// Unchanged documents are skipped using ETags. If a public key is
+// configured, the document must carry an ed25519 signature of its body in
+// the SignatureHeader, base64 encoded.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// SignatureHeader carries the base64 encoded ed25519 signature of the body
+const SignatureHeader = "X-Telemetry-Signature"
+
+// DefaultPollInterval is the interval between two requests
+const DefaultPollInterval = 30 * time.Second
+
+// maxDocumentSize bounds the settings document read from the endpoint
+const maxDocumentSize = 1 << 20
+
+// Settings override the local configuration. Settings left out keep their
+// configured values.
+type Settings struct {
+	Tracing *TracingSettings `json:"tracing,omitempty"`
+	Metrics *SignalSettings  `json:"metrics,omitempty"`
+	Logging *LoggingSettings `json:"logging,omitempty"`
+}
+
+// TracingSettings override the tracing configuration
+type TracingSettings struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	// SamplingRatio is the fraction of sampled root spans, 0 to 1
+	SamplingRatio *float64 `json:"sampling_ratio,omitempty"`
+}
+
+// SignalSettings turn a signal on or off
+type SignalSettings struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// LoggingSettings override the logging configuration
+type LoggingSettings struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	// Level is the minimum exported severity, e.g. debug
+	Level *string `json:"level,omitempty"`
+}
+
+// ApplyFunc applies the settings of a changed document
+type ApplyFunc func(settings *Settings) error
+
+// Poller fetches the settings document periodically and applies it
+// whenever it changes
+type Poller struct {
+	url       string
+	apply     ApplyFunc
+	interval  time.Duration
+	client    *http.Client
+	publicKey ed25519.PublicKey
+	logger    *log.Logger
+
+	mu      sync.Mutex
+	etag    string
+	started bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// PollerOption configures the poller
+type PollerOption func(*Poller)
+
+// WithPollInterval sets the interval between two requests
+func WithPollInterval(d time.Duration) PollerOption {
+	return func(p *Poller) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// WithHTTPClient sets the client used to fetch the document, e.g. one
+// adding authentication
+func WithHTTPClient(client *http.Client) PollerOption {
+	return func(p *Poller) {
+		p.client = client
+	}
+}
+
+// WithPublicKey requires documents to be signed with the matching private key
+func WithPublicKey(key ed25519.PublicKey) PollerOption {
+	return func(p *Poller) {
+		p.publicKey = key
+	}
+}
+
+// WithLogger sets the logger reporting failed polls
+func WithLogger(logger *log.Logger) PollerOption {
+	return func(p *Poller) {
+		p.logger = logger
+	}
+}
+
+// NewPoller creates a poller fetching the settings document from url
+func NewPoller(url string, apply ApplyFunc, opts ...PollerOption) *Poller {
+	p := &Poller{
+		url:      url,
+		apply:    apply,
+		interval: DefaultPollInterval,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   log.New(os.Stdout, "[telemetry] ", log.LstdFlags),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// ParsePublicKey decodes a base64 encoded ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key has %d bytes, expected %d", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Start polls right away and then periodically until Shutdown. Failed
+// polls are logged and keep the settings applied last.
+func (p *Poller) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+			if _, err := p.Poll(ctx); err != nil {
+				p.logger.Printf("remote configuration: %v", err)
+			}
+			cancel()
+
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops polling and waits for a running poll to finish
+func (p *Poller) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	p.mu.Lock()
+	started := p.started
+	p.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Poll fetches the document once and applies it if it changed. It reports
+// whether the settings were applied.
+func (p *Poller) Poll(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	p.mu.Lock()
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	p.mu.Unlock()
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", p.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("failed to fetch %s: %s", p.url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", p.url, err)
+	}
+	if err := p.verify(body, resp.Header.Get(SignatureHeader)); err != nil {
+		return false, err
+	}
+
+	settings, err := parseSettings(body)
+	if err != nil {
+		return false, err
+	}
+	if err := p.apply(settings); err != nil {
+		return false, fmt.Errorf("failed to apply remote settings: %w", err)
+	}
+
+	// Only documents applied successfully are skipped next time
+	p.mu.Lock()
+	p.etag = resp.Header.Get("ETag")
+	p.mu.Unlock()
+	return true, nil
+}
+
+// verify checks the signature of body if a public key is configured
+func (p *Poller) verify(body []byte, signature string) error {
+	if p.publicKey == nil {
+		return nil
+	}
+	if signature == "" {
+		return fmt.Errorf("document of %s is not signed", p.url)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature of %s: %w", p.url, err)
+	}
+	if !ed25519.Verify(p.publicKey, body, sig) {
+		return fmt.Errorf("invalid signature of %s", p.url)
+	}
+	return nil
+}
+
+// parseSettings decodes a document, rejecting unknown settings
+func parseSettings(body []byte) (*Settings, error) {
+	settings := &Settings{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(settings); err != nil {
+		return nil, fmt.Errorf("failed to parse remote settings: %w", err)
+	}
+	if t := settings.Tracing; t != nil && t.SamplingRatio != nil {
+		if r := *t.SamplingRatio; r < 0 || r > 1 {
+			return nil, fmt.Errorf("sampling ratio %v must be between 0 and 1", r)
+		}
+	}
+	return settings, nil
+}
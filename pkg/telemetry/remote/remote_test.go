@@ -0,0 +1,167 @@
+package remote
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// settingsServer serves a document with an ETag
+type settingsServer struct {
+	mu        sync.Mutex
+	body      string
+	etag      string
+	signature string
+	requests  int
+}
+
+func (s *settingsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	if s.signature != "" {
+		w.Header().Set(SignatureHeader, s.signature)
+	}
+	w.Write([]byte(s.body))
+}
+
+func (s *settingsServer) set(body, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func TestPoller_Poll(t *testing.T) {
+	server := &settingsServer{}
+	server.set(`{"tracing": {"sampling_ratio": 0.25}, "logging": {"level": "debug"}}`, `"v1"`)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	var applied []*Settings
+	p := NewPoller(ts.URL, func(s *Settings) error {
+		applied = append(applied, s)
+		return nil
+	})
+
+	changed, err := p.Poll(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Expected settings to be applied, got %v, %v", changed, err)
+	}
+	if *applied[0].Tracing.SamplingRatio != 0.25 || *applied[0].Logging.Level != "debug" {
+		t.Errorf("Expected ratio 0.25 and level debug, got %+v", applied[0])
+	}
+
+	// The same ETag is not applied again
+	changed, err = p.Poll(context.Background())
+	if err != nil || changed {
+		t.Errorf("Expected unchanged document to be skipped, got %v, %v", changed, err)
+	}
+
+	server.set(`{"tracing": {"enabled": false}}`, `"v2"`)
+	if changed, _ := p.Poll(context.Background()); !changed {
+		t.Fatal("Expected the changed document to be applied")
+	}
+	if applied[1].Tracing.SamplingRatio != nil || *applied[1].Tracing.Enabled {
+		t.Errorf("Expected tracing to be turned off without ratio, got %+v", applied[1].Tracing)
+	}
+}
+
+func TestPoller_InvalidDocuments(t *testing.T) {
+	server := &settingsServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	applied := 0
+	p := NewPoller(ts.URL, func(*Settings) error {
+		applied++
+		return nil
+	})
+
+	for _, body := range []string{
+		`{"tracing": {"sampling_rate": 0.5}}`,
+		`{"tracing": {"sampling_ratio": 2}}`,
+		`not json`,
+	} {
+		server.set(body, `"`+body+`"`)
+		if _, err := p.Poll(context.Background()); err == nil {
+			t.Errorf("Expected an error for %s", body)
+		}
+	}
+	if applied != 0 {
+		t.Errorf("Expected no invalid document to be applied, got %d", applied)
+	}
+}
+
+func TestPoller_Signature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(public)
+	key, err := ParsePublicKey(encoded)
+	if err != nil {
+		t.Fatalf("ParsePublicKey failed: %v", err)
+	}
+
+	body := `{"metrics": {"enabled": false}}`
+	server := &settingsServer{}
+	server.set(body, `"v1"`)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	p := NewPoller(ts.URL, func(*Settings) error { return nil }, WithPublicKey(key))
+	if _, err := p.Poll(context.Background()); err == nil {
+		t.Error("Expected unsigned document to be rejected")
+	}
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	server.signature = base64.StdEncoding.EncodeToString(ed25519.Sign(other, []byte(body)))
+	if _, err := p.Poll(context.Background()); err == nil {
+		t.Error("Expected document signed with another key to be rejected")
+	}
+
+	server.signature = base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(body)))
+	if changed, err := p.Poll(context.Background()); err != nil || !changed {
+		t.Errorf("Expected signed document to be applied, got %v, %v", changed, err)
+	}
+
+	if _, err := ParsePublicKey("c2hvcnQ="); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
+
+func TestPoller_Start(t *testing.T) {
+	server := &settingsServer{}
+	server.set(`{}`, `"v1"`)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	applied := make(chan struct{}, 1)
+	p := NewPoller(ts.URL, func(*Settings) error {
+		applied <- struct{}{}
+		return nil
+	}, WithPollInterval(time.Hour))
+	p.Start()
+
+	select {
+	case <-applied:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the first poll right after Start")
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if err := NewPoller(ts.URL, nil).Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected Shutdown of a poller never started to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/processors"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/remote"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestApplyRemoteSettings(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tracing.Sampler.IgnoreIncomingPaths = nil
+	cfg.Logging.Level = "info"
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	sampler, err := tel.createSampler()
+	if err != nil {
+		t.Fatalf("createSampler failed: %v", err)
+	}
+	tel.sampler = sampling.NewDynamic(sampler)
+	tel.metricsSwitch = processors.NewSwitch()
+	tel.loggingSwitch = processors.NewSwitch()
+	tel.logLevel = processors.NewSeverityFilter(sdklog.NewSimpleProcessor(nil), otellog.SeverityInfo)
+	tel.remoteBaseline = remoteBaseline{sampler: sampler, samplerConfig: &config.SamplerConfig{Kind: "ParentBasedSampler", Root: "AlwaysOnSampler"}, logLevel: "info"}
+
+	ratio, level, off := 0.1, "debug", false
+	err = tel.applyRemoteSettings(&remote.Settings{
+		Tracing: &remote.TracingSettings{SamplingRatio: &ratio},
+		Metrics: &remote.SignalSettings{Enabled: &off},
+		Logging: &remote.LoggingSettings{Level: &level},
+	})
+	if err != nil {
+		t.Fatalf("applyRemoteSettings failed: %v", err)
+	}
+	if got := tel.sampler.Description(); !strings.Contains(got, "TraceIDRatioBased{0.1}") {
+		t.Errorf("Expected ratio based sampler, got %s", got)
+	}
+	if cfg.Tracing.Sampler.Ratio != 0.1 || cfg.Logging.Level != "debug" {
+		t.Errorf("Expected config to reflect the remote settings, got ratio %v and level %s", cfg.Tracing.Sampler.Ratio, cfg.Logging.Level)
+	}
+	if tel.logLevel.Minimum() != otellog.SeverityDebug || tel.metricsSwitch.IsEnabled() {
+		t.Error("Expected debug logs and metrics turned off")
+	}
+
+	// Settings left out are restored
+	if err := tel.applyRemoteSettings(&remote.Settings{Tracing: &remote.TracingSettings{Enabled: &off}}); err != nil {
+		t.Fatalf("applyRemoteSettings failed: %v", err)
+	}
+	if got := tel.sampler.Sampler().Description(); !strings.HasPrefix(got, "ParentBased{root:AlwaysOnSampler") {
+		t.Errorf("Expected configured sampler to be restored, got %s", got)
+	}
+	if tel.sampler.IsEnabled() || !tel.metricsSwitch.IsEnabled() || tel.logLevel.Minimum() != otellog.SeverityInfo {
+		t.Error("Expected tracing off, metrics on and the configured log level")
+	}
+	if cfg.Tracing.Sampler.Root != "AlwaysOnSampler" {
+		t.Errorf("Expected configured root sampler, got %s", cfg.Tracing.Sampler.Root)
+	}
+
+	bad := "loud"
+	if err := tel.applyRemoteSettings(&remote.Settings{Logging: &remote.LoggingSettings{Level: &bad}}); err == nil {
+		t.Error("Expected an error for an unknown log level")
+	}
+}
+
+func TestApplyRemoteSettings_DisabledSignals(t *testing.T) {
+	tel := &Telemetry{config: config.NewDefaultConfig(), logger: log.New(io.Discard, "", 0)}
+	on, ratio := true, 0.5
+	err := tel.applyRemoteSettings(&remote.Settings{
+		Tracing: &remote.TracingSettings{Enabled: &on, SamplingRatio: &ratio},
+		Metrics: &remote.SignalSettings{Enabled: &on},
+	})
+	if err != nil {
+		t.Errorf("Expected settings of signals not running to be ignored, got %v", err)
+	}
+	if err := tel.SetTracingEnabled(true); err == nil {
+		t.Error("Expected an error turning on tracing not enabled at startup")
+	}
+}
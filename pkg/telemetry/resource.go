@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/iklimetscisco/cap-go-telemetry/internal/version"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/detectors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// initResource initializes the OpenTelemetry resource. Later sources take
+// precedence: SDK defaults, service and build info, detected cloud
+// attributes, the static attributes from the configuration and finally
+// OTEL_RESOURCE_ATTRIBUTES, as the OpenTelemetry specification requires.
+func (t *Telemetry) initResource() error {
+	serviceName := t.config.ServiceName
+	if serviceName == "" {
+		serviceName = "CAP Application"
+	}
+
+	info := version.Get()
+
+	// Try to get service version from environment or the build info
+	serviceVersion := os.Getenv("OTEL_SERVICE_VERSION")
+	if serviceVersion == "" {
+		serviceVersion = info.Version
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+	}
+	// GitCommit and BuildDate are only known when set via -ldflags
+	if info.GitCommit != "" && info.GitCommit != "unknown" {
+		attrs = append(attrs, attribute.String("vcs.revision", info.GitCommit))
+	}
+	if info.BuildDate != "" && info.BuildDate != "unknown" {
+		attrs = append(attrs, attribute.String("build.date", info.BuildDate))
+	}
+
+	r, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, attrs...),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	rc := t.config.Resource
+	if rc == nil {
+		rc = &config.ResourceConfig{}
+	}
+
+	// Run the opt-in cloud detectors. Dynatrace entity enrichment is always
+	// read for the Dynatrace kind, so exported telemetry links to its entities.
+	names := rc.Detectors
+	if t.config.Kind == "telemetry-to-dynatrace" && !slices.Contains(names, "dynatrace") {
+		names = append(slices.Clip(names), "dynatrace")
+	}
+	if len(names) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), rc.GetDetectorTimeout())
+		defer cancel()
+
+		detected, err := detectors.Detect(ctx, names...)
+		if err != nil {
+			return fmt.Errorf("failed to detect resource: %w", err)
+		}
+		if r, err = resource.Merge(r, detected); err != nil {
+			return fmt.Errorf("failed to merge detected resource: %w", err)
+		}
+	}
+
+	// Static attributes declared in the configuration
+	if len(rc.Attributes) > 0 {
+		static := make([]attribute.KeyValue, 0, len(rc.Attributes))
+		for k, v := range rc.Attributes {
+			static = append(static, attribute.String(k, v))
+		}
+		if r, err = resource.Merge(r, resource.NewSchemaless(static...)); err != nil {
+			return fmt.Errorf("failed to merge configured resource attributes: %w", err)
+		}
+	}
+
+	// OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME override the configuration
+	fromEnv, err := resource.New(context.Background(), resource.WithFromEnv())
+	if err != nil {
+		// Malformed entries are skipped, the valid ones are still returned
+		t.logger.Printf("invalid OTEL_RESOURCE_ATTRIBUTES: %v", err)
+	}
+	if fromEnv != nil {
+		if r, err = resource.Merge(r, fromEnv); err != nil {
+			return fmt.Errorf("failed to merge environment resource attributes: %w", err)
+		}
+	}
+
+	t.resource = r
+	return nil
+}
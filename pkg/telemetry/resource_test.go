@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/internal/version"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestInitResource(t *testing.T) {
+	commit := version.GitCommit
+	version.GitCommit = "abc1234"
+	defer func() { version.GitCommit = commit }()
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "orders"
+	cfg.Resource = &config.ResourceConfig{
+		Attributes: map[string]string{
+			"deployment.environment": "dev",
+		},
+	}
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+	if err := tel.initResource(); err != nil {
+		t.Fatalf("initResource failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"service.name":           "orders",
+		"service.version":        version.Version,
+		"vcs.revision":           "abc1234",
+		"deployment.environment": "dev",
+	}
+	for key, want := range expected {
+		got, _ := tel.resource.Set().Value(attribute.Key(key))
+		if got.Emit() != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got.Emit())
+		}
+	}
+	if _, ok := tel.resource.Set().Value("build.date"); ok {
+		t.Error("Expected unknown build date to be omitted")
+	}
+}
+
+func TestInitResource_Env(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=prod,team=checkout%20squad")
+
+	cfg := config.NewDefaultConfig()
+	cfg.Resource = &config.ResourceConfig{
+		Attributes: map[string]string{
+			"deployment.environment": "dev",
+			"region":                 "eu10",
+		},
+	}
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+	if err := tel.initResource(); err != nil {
+		t.Fatalf("initResource failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"deployment.environment": "prod",
+		"team":                   "checkout squad",
+		"region":                 "eu10",
+	}
+	for key, want := range expected {
+		got, _ := tel.resource.Set().Value(attribute.Key(key))
+		if got.Emit() != want {
+			t.Errorf("Expected %s=%q, got %q", key, want, got.Emit())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package sampling
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Dynamic delegates to a sampler that can be replaced or turned off at
+// runtime, e.g. by remote configuration
+type Dynamic struct {
+	delegate      atomic.Pointer[samplerHolder]
+	disabled      atomic.Bool
+	recordDropped atomic.Bool
+}
+
+// samplerHolder lets samplers of different types share an atomic pointer
+type samplerHolder struct {
+	sampler sdktrace.Sampler
+}
+
+// NewDynamic creates a dynamic sampler delegating to sampler
+func NewDynamic(sampler sdktrace.Sampler) *Dynamic {
+	s := &Dynamic{}
+	s.Set(sampler)
+	return s
+}
+
+// Set replaces the sampler. It is safe to call concurrently with ShouldSample.
+func (s *Dynamic) Set(sampler sdktrace.Sampler) {
+	s.delegate.Store(&samplerHolder{sampler: sampler})
+}
+
+// Sampler returns the current sampler
+func (s *Dynamic) Sampler() sdktrace.Sampler {
+	return s.delegate.Load().sampler
+}
+
+// SetEnabled turns sampling on or off. While off, all spans are dropped.
+func (s *Dynamic) SetEnabled(enabled bool) {
+	s.disabled.Store(!enabled)
+}
+
+// IsEnabled reports whether sampling is on
+func (s *Dynamic) IsEnabled() bool {
+	return !s.disabled.Load()
+}
+
+// SetRecordDropped makes spans dropped by the sampler recorded but not
+// sampled, so span processors such as the flight recorder see them while
+// they are not exported. Spans are still dropped while sampling is off.
+func (s *Dynamic) SetRecordDropped(enabled bool) {
+	s.recordDropped.Store(enabled)
+}
+
+// ShouldSample drops all spans while turned off and delegates otherwise
+func (s *Dynamic) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if s.disabled.Load() {
+		return sdktrace.NeverSample().ShouldSample(p)
+	}
+	result := s.Sampler().ShouldSample(p)
+	if result.Decision == sdktrace.Drop && s.recordDropped.Load() {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+// Description describes the current sampler
+func (s *Dynamic) Description() string {
+	if s.disabled.Load() {
+		return "Dynamic{off}"
+	}
+	return fmt.Sprintf("Dynamic{%s}", s.Sampler().Description())
+}
@@ -0,0 +1,57 @@
+package sampling
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDynamic(t *testing.T) {
+	params := sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: trace.TraceID{1}, Name: "op"}
+	sampler := NewDynamic(sdktrace.AlwaysSample())
+
+	if sampler.ShouldSample(params).Decision != sdktrace.RecordAndSample {
+		t.Error("Expected the initial sampler to decide")
+	}
+
+	sampler.Set(sdktrace.NeverSample())
+	if sampler.ShouldSample(params).Decision != sdktrace.Drop {
+		t.Error("Expected the replaced sampler to decide")
+	}
+	if sampler.Description() != "Dynamic{AlwaysOffSampler}" {
+		t.Errorf("Expected description of the current sampler, got %s", sampler.Description())
+	}
+
+	sampler.Set(sdktrace.AlwaysSample())
+	sampler.SetEnabled(false)
+	if sampler.IsEnabled() || sampler.ShouldSample(params).Decision != sdktrace.Drop {
+		t.Error("Expected spans to be dropped while turned off")
+	}
+
+	sampler.SetEnabled(true)
+	if sampler.ShouldSample(params).Decision != sdktrace.RecordAndSample {
+		t.Error("Expected spans to be sampled after turning sampling on")
+	}
+}
+
+func TestDynamicRecordDropped(t *testing.T) {
+	params := sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: trace.TraceID{1}, Name: "op"}
+	sampler := NewDynamic(sdktrace.NeverSample())
+	sampler.SetRecordDropped(true)
+
+	if sampler.ShouldSample(params).Decision != sdktrace.RecordOnly {
+		t.Error("Expected dropped spans to be recorded")
+	}
+
+	sampler.Set(sdktrace.AlwaysSample())
+	if sampler.ShouldSample(params).Decision != sdktrace.RecordAndSample {
+		t.Error("Expected sampled spans to stay sampled")
+	}
+
+	sampler.SetEnabled(false)
+	if sampler.ShouldSample(params).Decision != sdktrace.Drop {
+		t.Error("Expected spans to be dropped while turned off")
+	}
+}
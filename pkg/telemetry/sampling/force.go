@@ -0,0 +1,102 @@
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// forceSamplingKey is the context key marking a request for forced sampling
+type forceSamplingKey struct{}
+
+// ContextWithForceSampling marks ctx so that spans started from it are
+// sampled regardless of the configured sampler
+func ContextWithForceSampling(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceSamplingKey{}, true)
+}
+
+// ForceSamplingFromContext reports whether ctx was marked for forced sampling
+func ForceSamplingFromContext(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceSamplingKey{}).(bool)
+	return forced
+}
+
+// IsDebugValue reports whether a debug header or baggage value requests
+// sampling; anything but empty, "0", "false" and "off" does
+func IsDebugValue(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "0", "false", "off":
+		return false
+	default:
+		return true
+	}
+}
+
+// forceSampler samples marked requests and delegates everything else
+type forceSampler struct {
+	delegate   sdktrace.Sampler
+	baggageKey string
+}
+
+// ForceSamplerOption configures the force sampler
+type ForceSamplerOption func(*forceSampler)
+
+// WithBaggageKey also forces sampling when the parent context carries this
+// baggage entry with a debug value, so the decision follows the request
+// across services
+func WithBaggageKey(key string) ForceSamplerOption {
+	return func(s *forceSampler) {
+		s.baggageKey = key
+	}
+}
+
+// NewForceSampler wraps delegate so that requests marked with
+// ContextWithForceSampling (see DebugHeaderMiddleware) are always sampled.
+// It must be the outermost sampler to override parent-based decisions.
+func NewForceSampler(delegate sdktrace.Sampler, opts ...ForceSamplerOption) sdktrace.Sampler {
+	s := &forceSampler{delegate: delegate}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ShouldSample samples forced requests and delegates the rest
+func (s *forceSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if s.forced(p.ParentContext) {
+		return sdktrace.AlwaysSample().ShouldSample(p)
+	}
+	return s.delegate.ShouldSample(p)
+}
+
+// forced checks the context mark and the baggage entry
+func (s *forceSampler) forced(ctx context.Context) bool {
+	if ForceSamplingFromContext(ctx) {
+		return true
+	}
+	if s.baggageKey != "" {
+		return IsDebugValue(baggage.FromContext(ctx).Member(s.baggageKey).Value())
+	}
+	return false
+}
+
+// Description describes the wrapped sampler
+func (s *forceSampler) Description() string {
+	return fmt.Sprintf("ForceSampler{%s}", s.delegate.Description())
+}
+
+// DebugHeaderMiddleware marks requests carrying the given header with a
+// debug value (e.g. "X-Debug-Trace: 1") for forced sampling. Install it in
+// front of the tracing middleware.
+func DebugHeaderMiddleware(header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsDebugValue(r.Header.Get(header)) {
+			r = r.WithContext(ContextWithForceSampling(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package sampling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestForceSampler(t *testing.T) {
+	sampler := NewForceSampler(sdktrace.NeverSample(), WithBaggageKey("debug-trace"))
+	params := func(ctx context.Context) sdktrace.SamplingParameters {
+		return sdktrace.SamplingParameters{ParentContext: ctx, TraceID: trace.TraceID{1}, Name: "op"}
+	}
+
+	if sampler.ShouldSample(params(context.Background())).Decision != sdktrace.Drop {
+		t.Error("Expected unmarked requests to be delegated")
+	}
+
+	if sampler.ShouldSample(params(ContextWithForceSampling(context.Background()))).Decision != sdktrace.RecordAndSample {
+		t.Error("Expected marked request to be sampled")
+	}
+
+	member, _ := baggage.NewMember("debug-trace", "1")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	if sampler.ShouldSample(params(ctx)).Decision != sdktrace.RecordAndSample {
+		t.Error("Expected request with debug baggage to be sampled")
+	}
+}
+
+func TestDebugHeaderMiddleware(t *testing.T) {
+	var forced bool
+	handler := DebugHeaderMiddleware("X-Debug-Trace", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forced = ForceSamplingFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Debug-Trace", "1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !forced {
+		t.Error("Expected request with debug header to be marked")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Debug-Trace", "false")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if forced {
+		t.Error("Expected request with false debug header not to be marked")
+	}
+}
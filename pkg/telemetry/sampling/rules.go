@@ -0,0 +1,154 @@
+// Package sampling provides trace samplers that complement the ones of the
+// OpenTelemetry SDK, such as per-route sampling rules.
+package sampling
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Rule samples the spans it matches at a fixed ratio. All non-empty
+// criteria must match.
+type Rule struct {
+	// Name identifies the rule in the sampler description
+	Name string
+	// Route is matched against http.route, falling back to url.path.
+	// It supports path.Match globs; a trailing "/**" matches any suffix.
+	Route string
+	// SpanKind restricts the rule to spans of that kind; unspecified matches all
+	SpanKind trace.SpanKind
+	// Attributes must all be present on the span with the given values
+	Attributes map[string]string
+	// Ratio is the fraction of matching traces sampled; 0 drops them
+	Ratio float64
+}
+
+// rule is a Rule with its ratio sampler
+type rule struct {
+	Rule
+	sampler sdktrace.Sampler
+}
+
+// ruleBased samples by the first matching rule, then falls back
+type ruleBased struct {
+	rules    []rule
+	fallback sdktrace.Sampler
+}
+
+// NewRuleBased returns a sampler that applies the first rule matching a
+// span and delegates to fallback when none matches. Rules are evaluated on
+// the attributes known at span start, so routers must set http.route or
+// url.path when starting the span.
+func NewRuleBased(rules []Rule, fallback sdktrace.Sampler) sdktrace.Sampler {
+	s := &ruleBased{fallback: fallback}
+	for _, r := range rules {
+		s.rules = append(s.rules, rule{Rule: r, sampler: ratioSampler(r.Ratio)})
+	}
+	return s
+}
+
+// ratioSampler avoids TraceIDRatioBased for the common 0 and 1 ratios
+func ratioSampler(ratio float64) sdktrace.Sampler {
+	switch {
+	case ratio <= 0:
+		return sdktrace.NeverSample()
+	case ratio >= 1:
+		return sdktrace.AlwaysSample()
+	default:
+		return sdktrace.TraceIDRatioBased(ratio)
+	}
+}
+
+// ShouldSample applies the first matching rule or the fallback
+func (s *ruleBased) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, r := range s.rules {
+		if r.matches(p) {
+			return r.sampler.ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+// Description describes the rules and the fallback
+func (s *ruleBased) Description() string {
+	names := make([]string, len(s.rules))
+	for i, r := range s.rules {
+		name := r.Name
+		if name == "" {
+			name = r.Route
+		}
+		names[i] = fmt.Sprintf("%s=%g", name, r.Ratio)
+	}
+	return fmt.Sprintf("RuleBased{rules=[%s],fallback=%s}", strings.Join(names, ","), s.fallback.Description())
+}
+
+// matches reports whether all criteria of the rule hold for the span
+func (r *rule) matches(p sdktrace.SamplingParameters) bool {
+	if r.SpanKind != trace.SpanKindUnspecified && r.SpanKind != p.Kind {
+		return false
+	}
+
+	if r.Route != "" {
+		route, ok := lookup(p.Attributes, semconv.HTTPRouteKey)
+		if !ok {
+			route, ok = lookup(p.Attributes, semconv.URLPathKey)
+		}
+		if !ok || !matchRoute(r.Route, route) {
+			return false
+		}
+	}
+
+	for key, want := range r.Attributes {
+		got, ok := lookup(p.Attributes, attribute.Key(key))
+		if !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the string form of an attribute value
+func lookup(attrs []attribute.KeyValue, key attribute.Key) (string, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value.Emit(), true
+		}
+	}
+	return "", false
+}
+
+// matchRoute matches a route against a glob pattern
+func matchRoute(pattern, route string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return route == prefix || strings.HasPrefix(route, prefix+"/")
+	}
+	matched, err := path.Match(pattern, route)
+	return err == nil && matched
+}
+
+// ParseSpanKind converts a span kind name (server, client, producer,
+// consumer, internal) into a trace.SpanKind; empty means unspecified
+func ParseSpanKind(kind string) (trace.SpanKind, error) {
+	switch strings.ToLower(kind) {
+	case "":
+		return trace.SpanKindUnspecified, nil
+	case "internal":
+		return trace.SpanKindInternal, nil
+	case "server":
+		return trace.SpanKindServer, nil
+	case "client":
+		return trace.SpanKindClient, nil
+	case "producer":
+		return trace.SpanKindProducer, nil
+	case "consumer":
+		return trace.SpanKindConsumer, nil
+	default:
+		return trace.SpanKindUnspecified, fmt.Errorf("unknown span kind: %s", kind)
+	}
+}
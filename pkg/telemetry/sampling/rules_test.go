@@ -0,0 +1,61 @@
+package sampling
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRuleBased(t *testing.T) {
+	sampler := NewRuleBased([]Rule{
+		{Name: "orders", Route: "/api/orders/**", Ratio: 1},
+		{Name: "static", Route: "/static/*", Ratio: 0},
+		{Name: "batch", Attributes: map[string]string{"job.type": "batch"}, SpanKind: trace.SpanKindInternal, Ratio: 0},
+	}, sdktrace.NeverSample())
+
+	tests := []struct {
+		name     string
+		kind     trace.SpanKind
+		attrs    []attribute.KeyValue
+		expected sdktrace.SamplingDecision
+	}{
+		{"route match", trace.SpanKindServer, []attribute.KeyValue{semconv.HTTPRoute("/api/orders/:id")}, sdktrace.RecordAndSample},
+		{"prefix itself", trace.SpanKindServer, []attribute.KeyValue{semconv.URLPath("/api/orders")}, sdktrace.RecordAndSample},
+		{"static asset", trace.SpanKindServer, []attribute.KeyValue{semconv.URLPath("/static/app.js")}, sdktrace.Drop},
+		{"attribute match", trace.SpanKindInternal, []attribute.KeyValue{attribute.String("job.type", "batch")}, sdktrace.Drop},
+		{"fallback", trace.SpanKindServer, []attribute.KeyValue{semconv.URLPath("/api/ordersx")}, sdktrace.Drop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				TraceID:    trace.TraceID{1},
+				Name:       "op",
+				Kind:       tt.kind,
+				Attributes: tt.attrs,
+			})
+			if result.Decision != tt.expected {
+				t.Errorf("Expected decision %v, got %v", tt.expected, result.Decision)
+			}
+		})
+	}
+}
+
+func TestRuleBased_Fallback(t *testing.T) {
+	sampler := NewRuleBased([]Rule{{Route: "/health", Ratio: 0}}, sdktrace.AlwaysSample())
+
+	result := sampler.ShouldSample(sdktrace.SamplingParameters{
+		TraceID:    trace.TraceID{1},
+		Attributes: []attribute.KeyValue{semconv.URLPath("/api")},
+	})
+	if result.Decision != sdktrace.RecordAndSample {
+		t.Error("Expected fallback sampler to sample unmatched spans")
+	}
+
+	if got := sampler.Description(); got != "RuleBased{rules=[/health=0],fallback=AlwaysOnSampler}" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+// Package cap provides attributes describing CAP (SAP Cloud Application
+// Programming Model) requests, and a middleware deriving them from OData
+// URLs, so CAP services get consistent, queryable span metadata.
+package cap
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceKey is the CDS service handling the request, e.g. "CatalogService"
+	// or its path "catalog"
+	ServiceKey = attribute.Key("cap.service")
+	// EntityKey is the CDS entity (OData entity set) the request targets
+	EntityKey = attribute.Key("cap.entity")
+	// EventKey is the CAP event: READ, CREATE, UPDATE, DELETE or the action
+	// or function name
+	EventKey = attribute.Key("cap.event")
+	// ODataOperationKey is the OData operation: read, create, update,
+	// delete, action, function, batch or metadata
+	ODataOperationKey = attribute.Key("odata.operation")
+)
+
+// Service returns the cap.service attribute
+func Service(name string) attribute.KeyValue {
+	return ServiceKey.String(name)
+}
+
+// Entity returns the cap.entity attribute
+func Entity(name string) attribute.KeyValue {
+	return EntityKey.String(name)
+}
+
+// Event returns the cap.event attribute
+func Event(name string) attribute.KeyValue {
+	return EventKey.String(name)
+}
+
+// ODataOperation returns the odata.operation attribute
+func ODataOperation(op string) attribute.KeyValue {
+	return ODataOperationKey.String(op)
+}
+
+// DefaultPrefixes are the path prefixes CAP serves OData services under
+var DefaultPrefixes = []string{"/odata/v4", "/odata/v2"}
+
+// Request describes a CAP request parsed from its OData URL
+type Request struct {
+	Service   string
+	Entity    string
+	Event     string
+	Operation string
+}
+
+// Attributes returns the non-empty attributes of the request
+func (r Request) Attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 4)
+	if r.Service != "" {
+		attrs = append(attrs, Service(r.Service))
+	}
+	if r.Entity != "" {
+		attrs = append(attrs, Entity(r.Entity))
+	}
+	if r.Event != "" {
+		attrs = append(attrs, Event(r.Event))
+	}
+	if r.Operation != "" {
+		attrs = append(attrs, ODataOperation(r.Operation))
+	}
+	return attrs
+}
+
+// ParseRequest parses an OData request path such as
+// /odata/v4/catalog/Books(201)/author. The service is the first segment
+// after one of the prefixes; pass "" for services served at the root.
+// Following CDS naming conventions, capitalized segments are entities and
+// lowercase ones are actions (POST) or functions (GET). It reports false
+// for paths that are not OData requests, i.e. match none of the prefixes.
+func ParseRequest(method, path string, prefixes ...string) (Request, bool) {
+	if len(prefixes) == 0 {
+		prefixes = DefaultPrefixes
+	}
+	matched := false
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(path, prefix+"/"); ok {
+			path, matched = rest, true
+			break
+		}
+	}
+	if !matched {
+		return Request{}, false
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) == 0 || segments[0] == "" {
+		return Request{}, false
+	}
+
+	req := Request{Service: segments[0]}
+	if len(segments) == 1 {
+		// The service document
+		req.Operation = "read"
+		return req, true
+	}
+
+	target := segments[1]
+	switch target {
+	case "$metadata":
+		req.Operation = "metadata"
+		return req, true
+	case "$batch":
+		req.Operation = "batch"
+		return req, true
+	}
+
+	name, _, _ := strings.Cut(target, "(")
+	if name == "" {
+		return Request{}, false
+	}
+
+	if isEntity(name) {
+		req.Entity = name
+		// Bound actions and functions follow the entity, e.g. Books(1)/CatalogService.review
+		if len(segments) > 2 {
+			last := segments[len(segments)-1]
+			if i := strings.LastIndex(last, "."); i >= 0 && !strings.HasPrefix(last, "$") {
+				op, _, _ := strings.Cut(last[i+1:], "(")
+				req.Event = op
+				req.Operation = operationFor(method)
+				return req, true
+			}
+		}
+		req.Event, req.Operation = crudEvent(method)
+		return req, true
+	}
+
+	// Unbound action or function
+	req.Event = name
+	req.Operation = operationFor(method)
+	return req, true
+}
+
+// isEntity reports whether a segment names an entity set
+func isEntity(name string) bool {
+	return name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// crudEvent maps the HTTP method of an entity request to the CAP event
+func crudEvent(method string) (event, operation string) {
+	switch method {
+	case http.MethodPost:
+		return "CREATE", "create"
+	case http.MethodPut, http.MethodPatch:
+		return "UPDATE", "update"
+	case http.MethodDelete:
+		return "DELETE", "delete"
+	default:
+		return "READ", "read"
+	}
+}
+
+// operationFor distinguishes actions (POST) from functions (GET)
+func operationFor(method string) string {
+	if method == http.MethodPost {
+		return "action"
+	}
+	return "function"
+}
+
+// config holds the middleware settings
+type config struct {
+	prefixes []string
+}
+
+// Option configures the middleware
+type Option func(*config)
+
+// WithPrefixes sets the path prefixes OData services are served under,
+// replacing DefaultPrefixes; "" matches services served at the root
+func WithPrefixes(prefixes ...string) Option {
+	return func(c *config) {
+		c.prefixes = prefixes
+	}
+}
+
+// Middleware adds the CAP attributes parsed from the request URL to the
+// active span. Install it inside the tracing middleware, so the server
+// span is already in the request context.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	cfg := &config{prefixes: DefaultPrefixes}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if req, ok := ParseRequest(r.Method, r.URL.Path, cfg.prefixes...); ok {
+				trace.SpanFromContext(r.Context()).SetAttributes(req.Attributes()...)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
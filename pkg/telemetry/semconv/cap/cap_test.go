@@ -0,0 +1,76 @@
+package cap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected Request
+	}{
+		{"GET", "/odata/v4/catalog/Books", Request{Service: "catalog", Entity: "Books", Event: "READ", Operation: "read"}},
+		{"PATCH", "/odata/v4/catalog/Books(201)", Request{Service: "catalog", Entity: "Books", Event: "UPDATE", Operation: "update"}},
+		{"GET", "/odata/v4/catalog/Books(201)/author", Request{Service: "catalog", Entity: "Books", Event: "READ", Operation: "read"}},
+		{"POST", "/odata/v4/catalog/Books(201)/CatalogService.review", Request{Service: "catalog", Entity: "Books", Event: "review", Operation: "action"}},
+		{"POST", "/odata/v4/catalog/submitOrder", Request{Service: "catalog", Event: "submitOrder", Operation: "action"}},
+		{"GET", "/odata/v4/catalog/stock(book=1)", Request{Service: "catalog", Event: "stock", Operation: "function"}},
+		{"GET", "/odata/v4/catalog/$metadata", Request{Service: "catalog", Operation: "metadata"}},
+		{"POST", "/odata/v4/catalog/$batch", Request{Service: "catalog", Operation: "batch"}},
+		{"DELETE", "/odata/v2/admin/Authors(1)", Request{Service: "admin", Entity: "Authors", Event: "DELETE", Operation: "delete"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			got, ok := ParseRequest(tt.method, tt.path)
+			if !ok {
+				t.Fatal("Expected path to be parsed")
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	for _, path := range []string{"/", "/health", "/static/app.js", "/odata/v4"} {
+		if got, ok := ParseRequest("GET", path); ok {
+			t.Errorf("Expected %s not to be parsed, got %+v", path, got)
+		}
+	}
+
+	// Services served at the root need the empty prefix
+	got, ok := ParseRequest("DELETE", "/admin/Authors(1)", "")
+	if !ok || got.Service != "admin" || got.Entity != "Authors" {
+		t.Errorf("Expected root service to be parsed with the empty prefix, got %+v", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+
+	handler := Middleware(WithPrefixes("/api"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	ctx, span := tracer.Start(context.Background(), "POST")
+	req := httptest.NewRequest(http.MethodPost, "/api/orders/Orders", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	span.End()
+
+	values := map[string]string{}
+	for _, kv := range exporter.GetSpans()[0].Attributes {
+		values[string(kv.Key)] = kv.Value.AsString()
+	}
+	if values["cap.service"] != "orders" || values["cap.entity"] != "Orders" {
+		t.Errorf("Expected service and entity attributes, got %v", values)
+	}
+	if values["cap.event"] != "CREATE" || values["odata.operation"] != "create" {
+		t.Errorf("Expected CREATE event, got %v", values)
+	}
+}
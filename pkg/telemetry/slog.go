@@ -0,0 +1,212 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// SlogScopeName is the default instrumentation scope of the slog bridge
+const SlogScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/slog"
+
+// SlogHandler is a slog.Handler that converts records into OpenTelemetry log
+// records and emits them through a logger provider. Trace and span IDs are
+// taken from the context passed to the slog call (e.g. slog.InfoContext).
+type SlogHandler struct {
+	logger log.Logger
+	level  slog.Leveler
+	clock  clock.Clock
+	attrs  []log.KeyValue
+	prefix string
+}
+
+// slogHandlerConfig holds the SlogHandler settings
+type slogHandlerConfig struct {
+	loggerProvider log.LoggerProvider
+	level          slog.Leveler
+	scope          string
+	clock          clock.Clock
+}
+
+// SlogHandlerOption configures a SlogHandler
+type SlogHandlerOption func(*slogHandlerConfig)
+
+// WithSlogLoggerProvider sets the logger provider records are emitted through.
+// Defaults to the global logger provider installed by New.
+func WithSlogLoggerProvider(lp log.LoggerProvider) SlogHandlerOption {
+	return func(c *slogHandlerConfig) {
+		c.loggerProvider = lp
+	}
+}
+
+// WithSlogLevel sets the minimum slog level handled; defaults to slog.LevelInfo
+func WithSlogLevel(level slog.Leveler) SlogHandlerOption {
+	return func(c *slogHandlerConfig) {
+		c.level = level
+	}
+}
+
+// WithSlogScope sets the instrumentation scope name of the emitted records
+func WithSlogScope(name string) SlogHandlerOption {
+	return func(c *slogHandlerConfig) {
+		c.scope = name
+	}
+}
+
+// WithSlogClock sets the clock of the observed timestamps of records, the
+// system clock by default
+func WithSlogClock(c clock.Clock) SlogHandlerOption {
+	return func(cfg *slogHandlerConfig) {
+		cfg.clock = c
+	}
+}
+
+// NewSlogHandler creates a slog.Handler bridging into the OpenTelemetry log pipeline
+func NewSlogHandler(opts ...SlogHandlerOption) *SlogHandler {
+	cfg := &slogHandlerConfig{
+		level: slog.LevelInfo,
+		scope: SlogScopeName,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.loggerProvider == nil {
+		cfg.loggerProvider = global.GetLoggerProvider()
+	}
+
+	return &SlogHandler{
+		logger: cfg.loggerProvider.Logger(cfg.scope),
+		level:  cfg.level,
+		clock:  clock.OrSystem(cfg.clock),
+	}
+}
+
+// Enabled reports whether records of the given level are handled
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level < h.level.Level() {
+		return false
+	}
+	return h.logger.Enabled(ctx, log.EnabledParameters{Severity: slogSeverity(level)})
+}
+
+// Handle converts the slog record and emits it
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var record log.Record
+	record.SetTimestamp(r.Time)
+	record.SetObservedTimestamp(h.clock.Now())
+	record.SetSeverity(slogSeverity(r.Level))
+	record.SetSeverityText(r.Level.String())
+	record.SetBody(log.StringValue(r.Message))
+
+	kvs := make([]log.KeyValue, len(h.attrs), len(h.attrs)+r.NumAttrs())
+	copy(kvs, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		kvs = appendSlogAttr(kvs, h.prefix, a)
+		return true
+	})
+	record.AddAttributes(kvs...)
+
+	h.logger.Emit(ctx, record)
+	return nil
+}
+
+// WithAttrs returns a handler that adds the given attributes to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := *h
+	clone.attrs = make([]log.KeyValue, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(clone.attrs, h.attrs)
+	for _, a := range attrs {
+		clone.attrs = appendSlogAttr(clone.attrs, h.prefix, a)
+	}
+	return &clone
+}
+
+// WithGroup returns a handler that qualifies subsequent attribute keys with
+// the group name, using dotted keys ("group.key")
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// slogSeverity maps a slog level onto the OpenTelemetry severity range:
+// Debug→DEBUG, Info→INFO, Warn→WARN, Error→ERROR, with offsets preserved
+func slogSeverity(level slog.Level) log.Severity {
+	severity := log.Severity(int(level) + int(log.SeverityInfo))
+	if severity < log.SeverityTrace1 {
+		return log.SeverityTrace1
+	}
+	if severity > log.SeverityFatal4 {
+		return log.SeverityFatal4
+	}
+	return severity
+}
+
+// appendSlogAttr converts a slog attribute and appends it to kvs. Groups are
+// flattened into dotted keys, matching WithGroup; empty attributes are
+// skipped and groups with an empty key are inlined, as slog specifies.
+func appendSlogAttr(kvs []log.KeyValue, prefix string, a slog.Attr) []log.KeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, member := range a.Value.Group() {
+			kvs = appendSlogAttr(kvs, groupPrefix, member)
+		}
+		return kvs
+	}
+
+	return append(kvs, log.KeyValue{Key: prefix + a.Key, Value: slogValue(a.Value)})
+}
+
+// slogValue converts a resolved, non-group slog value into a log value
+func slogValue(v slog.Value) log.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return log.StringValue(v.String())
+	case slog.KindInt64:
+		return log.Int64Value(v.Int64())
+	case slog.KindUint64:
+		u := v.Uint64()
+		if u > uint64(1<<63-1) {
+			return log.StringValue(fmt.Sprint(u))
+		}
+		return log.Int64Value(int64(u))
+	case slog.KindFloat64:
+		return log.Float64Value(v.Float64())
+	case slog.KindBool:
+		return log.BoolValue(v.Bool())
+	case slog.KindDuration:
+		return log.Int64Value(v.Duration().Nanoseconds())
+	case slog.KindTime:
+		return log.StringValue(v.Time().Format(time.RFC3339Nano))
+	default:
+		switch x := v.Any().(type) {
+		case []byte:
+			return log.BytesValue(x)
+		case error:
+			return log.StringValue(x.Error())
+		default:
+			return log.StringValue(fmt.Sprintf("%+v", x))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	stdlog "log"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// recordingLogExporter keeps exported log records in memory
+type recordingLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingLogExporter) Shutdown(ctx context.Context) error   { return nil }
+func (e *recordingLogExporter) ForceFlush(ctx context.Context) error { return nil }
+
+func TestSlogHandler(t *testing.T) {
+	exporter := &recordingLogExporter{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	tp := sdktrace.NewTracerProvider()
+
+	logger := slog.New(NewSlogHandler(WithSlogLoggerProvider(lp))).
+		With("component", "orders").
+		WithGroup("req")
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	logger.DebugContext(ctx, "dropped")
+	logger.WarnContext(ctx, "slow request", "method", "GET", slog.Int("status", 200))
+	span.End()
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(exporter.records))
+	}
+	record := exporter.records[0]
+
+	if record.Body().AsString() != "slow request" {
+		t.Errorf("Unexpected body %q", record.Body().AsString())
+	}
+	if record.Severity() != log.SeverityWarn {
+		t.Errorf("Expected severity WARN, got %v", record.Severity())
+	}
+	if record.TraceID() != span.SpanContext().TraceID() {
+		t.Error("Expected record to carry the active trace ID")
+	}
+
+	attrs := make(map[string]string)
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+	for key, want := range map[string]string{
+		"component":  "orders",
+		"req.method": "GET",
+		"req.status": "200",
+	} {
+		if attrs[key] != want {
+			t.Errorf("Expected attribute %s=%s, got %q", key, want, attrs[key])
+		}
+	}
+}
+
+func TestSlogHandlerClock(t *testing.T) {
+	exporter := &recordingLogExporter{}
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	logger := slog.New(NewSlogHandler(WithSlogLoggerProvider(lp), WithSlogClock(clock.NewFixed(now))))
+	logger.Info("order placed")
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(exporter.records))
+	}
+	if got := exporter.records[0].ObservedTimestamp(); !got.Equal(now) {
+		t.Errorf("Expected the observed timestamp from the clock, got %v", got)
+	}
+}
+
+func TestSlogSeverity(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected log.Severity
+	}{
+		{slog.LevelDebug, log.SeverityDebug},
+		{slog.LevelInfo, log.SeverityInfo},
+		{slog.LevelWarn, log.SeverityWarn},
+		{slog.LevelError, log.SeverityError},
+		{slog.Level(-100), log.SeverityTrace1},
+		{slog.Level(100), log.SeverityFatal4},
+	}
+
+	for _, tt := range tests {
+		if got := slogSeverity(tt.level); got != tt.expected {
+			t.Errorf("slogSeverity(%v) = %v, want %v", tt.level, got, tt.expected)
+		}
+	}
+}
+
+func TestSlogHandlerHonorsLoggingLevel(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tracing.Enabled = false
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.Level = "warn"
+	cfg.Logging.MaxAttributeLength = 100
+	cfg.Logging.SpanEvents = true
+	cfg.Logging.RateLimit = &config.LogRateLimitConfig{Enabled: true}
+	cfg.Logging.Deduplication = &config.LogDeduplicationConfig{Enabled: true}
+	cfg.Redaction = &config.RedactionConfig{Enabled: true}
+	cfg.CorrelationID = true
+	cfg.Tenant = &config.TenantConfig{Enabled: true}
+
+	tel, err := New(WithConfig(cfg), WithLogExporter(&recordingLogExporter{}), WithLogger(stdlog.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	// All processors take part in Enabled, so records below the level are
+	// not even built
+	handler := NewSlogHandler(WithSlogLoggerProvider(tel.LoggerProvider()))
+	if handler.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected info logs to be disabled below the warn level")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Expected warn logs to be enabled")
+	}
+
+	tel.logLevel.SetMinimum(log.SeverityDebug)
+	if !handler.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected info logs to be enabled after lowering the level")
+	}
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SpanScopeName is the instrumentation scope of spans started by Start and
+// Trace
+const SpanScopeName = "github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+
+// EndFunc ends a span, recording err as with RecordError if it is not nil
+type EndFunc func(err error)
+
+// Start starts a span with the global tracer provider and returns the
+// function ending it:
+//
+//	ctx, span, end := telemetry.Start(ctx, "reserve stock")
+//	defer func() { end(err) }()
+func Start(ctx context.Context, name string, opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span, EndFunc) {
+	ctx, span := otel.Tracer(SpanScopeName).Start(ctx, name, opts...)
+	return ctx, span, func(err error) {
+		if err != nil {
+			recordError(ctx, span, err, &recordErrorConfig{})
+		}
+		span.End()
+	}
+}
+
+// PanicError is the error Trace returns for a panic of its function
+type PanicError struct {
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+}
+
+// Error describes the panic value
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Trace runs fn in a span and returns its error, recorded on the span as
+// for Start. A panic of fn is recovered into an exception event with its
+// stack trace and returned as *PanicError.
+func Trace(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...oteltrace.SpanStartOption) (err error) {
+	ctx, _, end := Start(ctx, name, opts...)
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+		end(err)
+	}()
+	return fn(ctx)
+}
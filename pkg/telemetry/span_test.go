@@ -0,0 +1,92 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// recordGlobalSpans installs a recording tracer provider for the test
+func recordGlobalSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestStart(t *testing.T) {
+	recorder := recordGlobalSpans(t)
+
+	_, _, end := Start(context.Background(), "reserve stock")
+	end(nil)
+	_, _, end = Start(context.Background(), "charge card")
+	end(errors.New("card declined"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Unset || len(spans[0].Events()) != 0 {
+		t.Errorf("Expected no error on the first span, got %v", spans[0].Status())
+	}
+	failed := spans[1]
+	if failed.Status().Code != codes.Error || failed.Status().Description != "card declined" {
+		t.Errorf("Expected the error status, got %v", failed.Status())
+	}
+	if len(failed.Events()) != 1 || failed.Events()[0].Name != semconv.ExceptionEventName {
+		t.Errorf("Expected an exception event, got %v", failed.Events())
+	}
+	if failed.InstrumentationScope().Name != SpanScopeName {
+		t.Errorf("Unexpected scope %q", failed.InstrumentationScope().Name)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	recorder := recordGlobalSpans(t)
+	errNotFound := errors.New("not found")
+
+	if err := Trace(context.Background(), "lookup", func(ctx context.Context) error {
+		return errNotFound
+	}); !errors.Is(err, errNotFound) {
+		t.Errorf("Expected the error of fn, got %v", err)
+	}
+
+	err := Trace(context.Background(), "import", func(ctx context.Context) error {
+		var m map[string]int
+		m["boom"] = 1
+		return nil
+	})
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected a panic error, got %v", err)
+	}
+	if !strings.Contains(perr.Error(), "assignment to entry in nil map") {
+		t.Errorf("Unexpected panic error %q", perr.Error())
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	panicked := spans[1]
+	if panicked.Status().Code != codes.Error || len(panicked.Events()) != 1 {
+		t.Fatalf("Expected an error status and exception event, got %v %v", panicked.Status(), panicked.Events())
+	}
+	var stack string
+	for _, kv := range panicked.Events()[0].Attributes {
+		if kv.Key == semconv.ExceptionStacktraceKey {
+			stack = kv.Value.AsString()
+		}
+	}
+	if !strings.Contains(stack, "TestTrace") {
+		t.Errorf("Expected the stack trace of the panic, got %q", stack)
+	}
+}
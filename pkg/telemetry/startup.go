@@ -0,0 +1,243 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/heartbeat"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// StartupEventName is the event name of the startup report log record
+const StartupEventName = "telemetry.startup"
+
+// StartupReport describes how the telemetry was set up: where the
+// configuration came from and which components were activated or skipped,
+// and why
+type StartupReport struct {
+	Kind string `json:"kind"`
+	// ConfigFile is the configuration file read, empty without file or
+	// with a configuration set in code
+	ConfigFile string `json:"config_file,omitempty"`
+	// Profile is the profile selected by TELEMETRY_PROFILE
+	Profile string `json:"profile,omitempty"`
+	// ConfigSource is "loader" or "code"
+	ConfigSource string `json:"config_source"`
+
+	Started    time.Time     `json:"started"`
+	ConfigLoad time.Duration `json:"config_load_ns"`
+	Duration   time.Duration `json:"duration_ns"`
+
+	Components []StartupComponent `json:"components"`
+}
+
+// StartupComponent is a component of the startup report
+type StartupComponent struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	// Reason explains the settings of an active component or why it was
+	// skipped
+	Reason string `json:"reason"`
+}
+
+// StartupReport returns the report of the setup of the telemetry
+func (t *Telemetry) StartupReport() StartupReport {
+	report := t.startup
+	report.Components = append([]StartupComponent(nil), t.startup.Components...)
+	return report
+}
+
+// addStartupComponent adds a component to the startup report
+func (t *Telemetry) addStartupComponent(name string, active bool, reason string, args ...interface{}) {
+	t.startup.Components = append(t.startup.Components, StartupComponent{
+		Name:   name,
+		Active: active,
+		Reason: fmt.Sprintf(reason, args...),
+	})
+}
+
+// reportStartup completes the startup report and emits it to the
+// telemetry logger, as log record and as startup span if enabled
+func (t *Telemetry) reportStartup() {
+	t.addSignalComponents()
+	t.startup.Duration = time.Since(t.startup.Started)
+
+	report := t.startup
+	source := report.ConfigSource
+	if report.ConfigFile != "" {
+		source = report.ConfigFile
+	}
+	if report.Profile != "" {
+		source += " (profile " + report.Profile + ")"
+	}
+	t.logger.Printf("telemetry initialized with kind %s from %s in %s", report.Kind, source, report.Duration.Round(time.Microsecond))
+	for _, c := range report.Components {
+		status := "skipped"
+		if c.Active {
+			status = "active"
+		}
+		t.logger.Printf("  %s: %s, %s", c.Name, status, c.Reason)
+	}
+
+	if t.loggerProvider != nil && t.config.Logging.StartupReport {
+		t.emitStartupRecord()
+	}
+	if t.tracerProvider != nil && t.config.Tracing.StartupSpan {
+		t.recordStartupSpan()
+	}
+}
+
+// addSignalComponents adds the signals and optional components, in the
+// order they are initialized
+func (t *Telemetry) addSignalComponents() {
+	cfg := t.config
+	signals := make([]StartupComponent, 0, 16)
+	add := func(name string, active bool, reason string, args ...interface{}) {
+		signals = append(signals, StartupComponent{Name: name, Active: active, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	if cfg.IsMetricsEnabled() {
+		add("metrics", true, "exporter %s, interval %s", t.exporterSource("metrics", t.metricExporter != nil, cfg.Metrics.Exporter),
+			cfg.Metrics.Config.GetExportInterval())
+		add("runtime_metrics", t.runtimeMetrics != nil, enabledReason(cfg.Metrics.RuntimeMetrics, "metrics.runtime_metrics"))
+		add("host_metrics", t.hostMetrics != nil, enabledReason(cfg.Metrics.HostMetrics, "metrics.host_metrics"))
+		gd := cfg.Metrics.GoroutineDiagnostics
+		add("goroutine_diagnostics", t.goroutineMetrics != nil, enabledReason(gd != nil && gd.Enabled, "metrics.goroutine_diagnostics.enabled"))
+	} else {
+		add("metrics", false, "metrics.enabled is false")
+	}
+
+	if cfg.IsTracingEnabled() {
+		sampler := "default"
+		if sc := cfg.Tracing.Sampler; sc != nil && sc.Kind != "" {
+			sampler = sc.Kind
+		}
+		if name := os.Getenv("OTEL_TRACES_SAMPLER"); name != "" {
+			sampler = name + " from OTEL_TRACES_SAMPLER"
+		}
+		add("tracing", true, "exporter %s, sampler %s", t.exporterSource("tracing", t.spanExporter != nil, cfg.Tracing.Exporter), sampler)
+	} else {
+		add("tracing", false, "tracing.enabled is false")
+	}
+
+	if cfg.IsLoggingEnabled() {
+		add("logging", true, "exporter %s, level %s", t.exporterSource("logging", t.logExporter != nil, cfg.Logging.Exporter), cfg.Logging.Level)
+		add("runtime_events", t.gcWatcher != nil, enabledReason(cfg.IsRuntimeEventsEnabled(), "logging.runtime_events.enabled"))
+	} else {
+		add("logging", false, "logging.enabled is false")
+	}
+	add("audit", t.auditLogger != nil, enabledReason(cfg.IsAuditEnabled(), "logging.audit.enabled"))
+	add("profiling", t.profiler != nil, enabledReason(cfg.IsProfilingEnabled(), "profiling.enabled"))
+	add("dashboard", t.dashboard != nil, enabledReason(cfg.IsDashboardEnabled(), "dashboard.enabled"))
+	add("flight_recorder", t.flightRecorder != nil, enabledReason(cfg.IsFlightRecorderEnabled(), "flight_recorder.enabled"))
+	if t.heartbeat != nil {
+		interval := cfg.Heartbeat.GetInterval()
+		if interval == 0 {
+			interval = heartbeat.DefaultInterval
+		}
+		add("heartbeat", true, "%d configured URLs, interval %s", len(cfg.Heartbeat.URLs), interval)
+	} else {
+		add("heartbeat", false, "heartbeat.enabled is false")
+	}
+	if t.remote != nil {
+		add("remote", true, "polling every %s", cfg.Remote.GetPollInterval())
+	} else {
+		add("remote", false, "remote.url is not set")
+	}
+
+	// Instrumentations were added while they were initialized
+	t.startup.Components = append(signals, t.startup.Components...)
+}
+
+// exporterSource describes the exporter of a signal
+func (t *Telemetry) exporterSource(signal string, inCode bool, exporter *config.ExporterConfig) string {
+	for _, d := range t.degraded {
+		if d.Signal == signal {
+			return d.Fallback + " after " + d.Error
+		}
+	}
+	if inCode {
+		return "set in code"
+	}
+	if exporter == nil {
+		return "none"
+	}
+	return exporter.Module
+}
+
+// enabledReason names the setting turning a component on or off
+func enabledReason(enabled bool, setting string) string {
+	if enabled {
+		return setting + " is true"
+	}
+	return setting + " is false"
+}
+
+// emitStartupRecord emits the startup report as a log record
+func (t *Telemetry) emitStartupRecord() {
+	report := t.startup
+	components := make([]otellog.Value, len(report.Components))
+	for i, c := range report.Components {
+		components[i] = otellog.MapValue(
+			otellog.String("name", c.Name),
+			otellog.Bool("active", c.Active),
+			otellog.String("reason", c.Reason),
+		)
+	}
+
+	var r otellog.Record
+	r.SetEventName(StartupEventName)
+	r.SetTimestamp(report.Started.Add(report.Duration))
+	r.SetSeverity(otellog.SeverityInfo)
+	r.SetBody(otellog.StringValue("telemetry initialized"))
+	r.AddAttributes(
+		otellog.String("telemetry.kind", report.Kind),
+		otellog.String("telemetry.config.source", report.ConfigSource),
+		otellog.Int64("telemetry.startup.duration_ns", report.Duration.Nanoseconds()),
+		otellog.Slice("telemetry.components", components...),
+	)
+	if report.ConfigFile != "" {
+		r.AddAttributes(otellog.String("telemetry.config.file", report.ConfigFile))
+	}
+	if report.Profile != "" {
+		r.AddAttributes(otellog.String("telemetry.config.profile", report.Profile))
+	}
+	t.loggerProvider.Logger(SpanScopeName).Emit(context.Background(), r)
+}
+
+// recordStartupSpan records the setup as a span with a child span of the
+// configuration loading and an event per component
+func (t *Telemetry) recordStartupSpan() {
+	report := t.startup
+	tracer := t.tracerProvider.Tracer(SpanScopeName)
+	attrs := []attribute.KeyValue{
+		attribute.String("telemetry.kind", report.Kind),
+		attribute.String("telemetry.config.source", report.ConfigSource),
+	}
+	if report.ConfigFile != "" {
+		attrs = append(attrs, attribute.String("telemetry.config.file", report.ConfigFile))
+	}
+	if report.Profile != "" {
+		attrs = append(attrs, attribute.String("telemetry.config.profile", report.Profile))
+	}
+
+	ctx, span := tracer.Start(context.Background(), "telemetry.startup",
+		oteltrace.WithNewRoot(),
+		oteltrace.WithTimestamp(report.Started),
+		oteltrace.WithAttributes(attrs...))
+	_, load := tracer.Start(ctx, "telemetry.config.load", oteltrace.WithTimestamp(report.Started))
+	load.End(oteltrace.WithTimestamp(report.Started.Add(report.ConfigLoad)))
+
+	for _, c := range report.Components {
+		span.AddEvent(c.Name, oteltrace.WithAttributes(
+			attribute.Bool("telemetry.component.active", c.Active),
+			attribute.String("telemetry.component.reason", c.Reason),
+		))
+	}
+	span.End(oteltrace.WithTimestamp(report.Started.Add(report.Duration)))
+}
@@ -0,0 +1,110 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartupReport(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Tracing.StartupSpan = true
+	spans := keptSpans{tracetest.NewInMemoryExporter()}
+	logs := &recordingLogExporter{}
+	var out bytes.Buffer
+	tel, err := New(WithConfig(cfg), WithSpanExporter(spans), WithLogExporter(logs), WithLogger(log.New(&out, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	report := tel.StartupReport()
+	if report.ConfigSource != "code" {
+		t.Errorf("Expected config source code, got %q", report.ConfigSource)
+	}
+	if report.Duration <= 0 {
+		t.Errorf("Expected a startup duration, got %s", report.Duration)
+	}
+	components := make(map[string]StartupComponent)
+	for _, c := range report.Components {
+		components[c.Name] = c
+	}
+	if c := components["metrics"]; c.Active || c.Reason != "metrics.enabled is false" {
+		t.Errorf("Expected metrics skipped because disabled, got %+v", c)
+	}
+	if c := components["tracing"]; !c.Active || !strings.Contains(c.Reason, "exporter set in code") {
+		t.Errorf("Expected tracing active with exporter set in code, got %+v", c)
+	}
+	if c := components["remote"]; c.Active {
+		t.Errorf("Expected remote skipped, got %+v", c)
+	}
+	if !strings.Contains(out.String(), "telemetry initialized with kind") || !strings.Contains(out.String(), "  metrics: skipped, metrics.enabled is false") {
+		t.Errorf("Expected the report in the log output, got %q", out.String())
+	}
+
+	if err := tel.LoggerProvider().ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	logs.mu.Lock()
+	var found bool
+	for _, r := range logs.records {
+		found = found || r.EventName() == StartupEventName
+	}
+	logs.mu.Unlock()
+	if !found {
+		t.Error("Expected a startup log record")
+	}
+
+	if err := tel.TracerProvider().ForceFlush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	names := make(map[string]int)
+	for _, s := range spans.GetSpans() {
+		names[s.Name] = len(s.Events)
+	}
+	if _, ok := names["telemetry.config.load"]; !ok {
+		t.Errorf("Expected a config load span, got %v", names)
+	}
+	if names["telemetry.startup"] != len(report.Components) {
+		t.Errorf("Expected a startup span with %d events, got %v", len(report.Components), names)
+	}
+}
+
+func TestStartupReportDisabled(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "bookshop"
+	cfg.Metrics.Enabled = false
+	cfg.Logging.Enabled = true
+	cfg.Logging.StartupReport = false
+	spans := keptSpans{tracetest.NewInMemoryExporter()}
+	logs := &recordingLogExporter{}
+	tel, err := New(WithConfig(cfg), WithSpanExporter(spans), WithLogExporter(logs), WithLogger(log.New(&bytes.Buffer{}, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	tel.LoggerProvider().ForceFlush(context.Background())
+	tel.TracerProvider().ForceFlush(context.Background())
+	if len(logs.records) != 0 {
+		t.Errorf("Expected no startup log record, got %d records", len(logs.records))
+	}
+	if len(spans.GetSpans()) != 0 {
+		t.Errorf("Expected no startup span, got %d spans", len(spans.GetSpans()))
+	}
+}
@@ -5,37 +5,89 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/audit"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
 	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/correlation"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/dashboard"
 	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/console"
-	"go.opentelemetry.io/otel"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/flightrecorder"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/gcevents"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/heartbeat"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/cardinality"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/goroutines"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/host"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/metrics/runtime"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/processors"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/profiling"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/remote"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/sampling"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 // Telemetry represents the main telemetry instance
 type Telemetry struct {
-	config         *config.Config
-	tracerProvider *trace.TracerProvider
-	meterProvider  *metric.MeterProvider
-	resource       *resource.Resource
-	logger         *log.Logger
+	config           *config.Config
+	tracerProvider   *trace.TracerProvider
+	globalTracer     oteltrace.TracerProvider
+	propagator       propagation.TextMapPropagator
+	meterProvider    *metric.MeterProvider
+	loggerProvider   *sdklog.LoggerProvider
+	auditProvider    *sdklog.LoggerProvider
+	auditLogger      *audit.Logger
+	logLevel         *processors.SeverityFilter
+	sampler          *sampling.Dynamic
+	metricsSwitch    *processors.Switch
+	loggingSwitch    *processors.Switch
+	remote           *remote.Poller
+	remoteBaseline   remoteBaseline
+	redactor         *processors.Redactor
+	tenantLimiter    *tenant.Limiter
+	correlator       *console.Correlator
+	resource         *resource.Resource
+	clock            clock.Clock
+	retimer          *processors.Retimer
+	logger           *log.Logger
+	runtimeMetrics   *runtime.Metrics
+	hostMetrics      *host.Metrics
+	goroutineMetrics *goroutines.Metrics
+	profiler         *profiling.Profiler
+	dashboard        *dashboard.Recorder
+	flightRecorder   *flightrecorder.Recorder
+	gcWatcher        *gcevents.Watcher
+	heartbeat        *heartbeat.Monitor
+
+	// settingsMu guards the settings in config changed at runtime: the log
+	// level and the sampler
+	settingsMu sync.Mutex
+
+	instrumentations []Instrumentation
+	startup          StartupReport
+	degraded         []DegradedSignal
+	spanStartHooks   []processors.SpanStartHook
+	spanEndHooks     []processors.SpanEndHook
+	metricProducers  []metric.Producer
+
+	// Exporters set in code replace the configured ones
+	spanExporter   trace.SpanExporter
+	metricExporter metric.Exporter
+	logExporter    sdklog.Exporter
 }
 
 // New creates a new telemetry instance
 func New(opts ...Option) (*Telemetry, error) {
-	// Load configuration
-	loader := config.NewLoader()
-	cfg, err := loader.Load()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load configuration: %w", err)
-	}
-
 	t := &Telemetry{
-		config: cfg,
 		logger: log.New(os.Stdout, "[telemetry] ", log.LstdFlags),
 	}
 
@@ -44,33 +96,138 @@ func New(opts ...Option) (*Telemetry, error) {
 		opt(t)
 	}
 
+	// Load configuration, unless set by WithConfig
+	t.startup.Started = time.Now()
+	t.startup.ConfigSource = "code"
+	if t.config == nil {
+		loader := config.NewLoader()
+		cfg, err := loader.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		t.config = cfg
+		t.startup.ConfigSource = "loader"
+		t.startup.ConfigFile = loader.GetConfigFile()
+		t.startup.Profile = os.Getenv(config.ProfileEnv)
+	}
+	cfg := t.config
+	t.startup.Kind = cfg.Kind
+	t.startup.ConfigLoad = time.Since(t.startup.Started)
+
 	// Check if telemetry is disabled
 	if !cfg.IsEnabled() {
 		t.logger.Println("telemetry is disabled")
 		return t, nil
 	}
 
+	if err := t.start(); err != nil {
+		// Stop what was started before the failure
+		_ = t.shutdown(context.Background())
+		return nil, err
+	}
+
+	t.reportStartup()
+	return t, nil
+}
+
+// start initializes the enabled signals and components. On error, some of
+// them may already be running and must be shut down.
+func (t *Telemetry) start() error {
+	cfg := t.config
+
+	// Take generated timestamps from the configured clock
+	t.initClock()
+
 	// Initialize resource
 	if err := t.initResource(); err != nil {
-		return nil, fmt.Errorf("failed to initialize resource: %w", err)
+		return fmt.Errorf("failed to initialize resource: %w", err)
+	}
+
+	// Initialize redaction, applied by all exporters
+	if err := t.initRedaction(); err != nil {
+		return fmt.Errorf("failed to initialize redaction: %w", err)
+	}
+
+	// Cap the tenants recorded in metrics
+	if cfg.IsTenantEnabled() {
+		t.tenantLimiter = tenant.NewLimiter(cfg.Tenant.GetMaxTenants())
+	}
+
+	// Record recent telemetry for the development dashboard if enabled
+	if cfg.IsDashboardEnabled() {
+		t.initDashboard()
+	}
+
+	// Keep recent spans and logs for dumps if enabled
+	if cfg.IsFlightRecorderEnabled() {
+		t.initFlightRecorder()
+	}
+
+	// Initialize metrics if enabled (before tracing, so span-derived
+	// metrics can be recorded on the meter provider)
+	if cfg.IsMetricsEnabled() {
+		if err := t.initMetrics(); err != nil {
+			return fmt.Errorf("failed to initialize metrics: %w", err)
+		}
 	}
 
 	// Initialize tracing if enabled
 	if cfg.IsTracingEnabled() {
 		if err := t.initTracing(); err != nil {
-			return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+			return fmt.Errorf("failed to initialize tracing: %w", err)
 		}
 	}
 
-	// Initialize metrics if enabled
-	if cfg.IsMetricsEnabled() {
-		if err := t.initMetrics(); err != nil {
-			return nil, fmt.Errorf("failed to initialize metrics: %w", err)
+	// Initialize logging if enabled
+	if cfg.IsLoggingEnabled() {
+		if err := t.initLogging(); err != nil {
+			return fmt.Errorf("failed to initialize logging: %w", err)
 		}
 	}
 
-	t.logger.Printf("telemetry initialized with kind: %s", cfg.Kind)
-	return t, nil
+	// Log GC pauses and memory pressure if enabled
+	if cfg.IsRuntimeEventsEnabled() {
+		t.initGCEvents()
+	}
+
+	// Initialize audit logging if enabled
+	if cfg.IsAuditEnabled() {
+		if err := t.initAudit(); err != nil {
+			return fmt.Errorf("failed to initialize audit logging: %w", err)
+		}
+	}
+
+	// Initialize continuous profiling if enabled
+	if cfg.IsProfilingEnabled() {
+		if err := t.initProfiling(); err != nil {
+			return fmt.Errorf("failed to initialize profiling: %w", err)
+		}
+	}
+
+	// Run the heartbeat probes if enabled
+	if cfg.IsHeartbeatEnabled() {
+		if err := t.initHeartbeat(); err != nil {
+			return fmt.Errorf("failed to initialize heartbeat: %w", err)
+		}
+	}
+
+	// Install the global providers once all signals are built, so that
+	// instrumentations find them; a later failure restores the previous ones
+	restore := t.installGlobals()
+
+	// Instantiate registered instrumentations declared in the config
+	if err := t.initInstrumentations(); err != nil {
+		restore()
+		return fmt.Errorf("failed to initialize instrumentations: %w", err)
+	}
+
+	// Poll the remote configuration once the signals are running
+	if err := t.initRemote(); err != nil {
+		restore()
+		return fmt.Errorf("failed to initialize remote configuration: %w", err)
+	}
+
+	return nil
 }
 
 // Option configures the telemetry instance
@@ -90,119 +247,667 @@ func WithLogger(logger *log.Logger) Option {
 	}
 }
 
-// initResource initializes the OpenTelemetry resource
-func (t *Telemetry) initResource() error {
-	serviceName := t.config.ServiceName
-	if serviceName == "" {
-		serviceName = "CAP Application"
+// WithSpanExporter exports spans to exporter instead of the configured
+// exporter, e.g. to record them in tests
+func WithSpanExporter(exporter trace.SpanExporter) Option {
+	return func(t *Telemetry) {
+		t.spanExporter = exporter
+	}
+}
+
+// WithMetricExporter exports metrics to exporter instead of the configured
+// exporter; its temporality and aggregation apply
+func WithMetricExporter(exporter metric.Exporter) Option {
+	return func(t *Telemetry) {
+		t.metricExporter = exporter
 	}
+}
 
-	// Try to get service version from environment or default
-	serviceVersion := os.Getenv("OTEL_SERVICE_VERSION")
-	if serviceVersion == "" {
-		serviceVersion = "1.0.0"
+// WithMetricProducer exports the metrics of producer along with those of
+// the meter provider, e.g. expvar.NewProducer() or the producer of the
+// OpenCensus bridge
+func WithMetricProducer(producer metric.Producer) Option {
+	return func(t *Telemetry) {
+		t.metricProducers = append(t.metricProducers, producer)
 	}
+}
 
-	r, err := resource.Merge(
-		resource.Default(),
-		resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName(serviceName),
-			semconv.ServiceVersion(serviceVersion),
-		),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create resource: %w", err)
+// WithLogExporter exports log records to exporter instead of the
+// configured exporter
+func WithLogExporter(exporter sdklog.Exporter) Option {
+	return func(t *Telemetry) {
+		t.logExporter = exporter
 	}
+}
 
-	t.resource = r
+// WithClock sets the clock of timestamps generated by the library, e.g.
+// the time console exporters print for records without one. With hrtime
+// enabled a *clock.Monotonic is also used to convert span and log times.
+func WithClock(c clock.Clock) Option {
+	return func(t *Telemetry) {
+		t.clock = c
+	}
+}
+
+// WithSpanStartHook registers a callback invoked for every started span,
+// e.g. to attach tenant, region or deployment attributes uniformly
+func WithSpanStartHook(hook func(ctx context.Context, span oteltrace.Span)) Option {
+	return func(t *Telemetry) {
+		t.spanStartHooks = append(t.spanStartHooks, hook)
+	}
+}
+
+// WithSpanEndHook registers a callback invoked for every ended sampled span
+func WithSpanEndHook(hook func(span trace.ReadOnlySpan)) Option {
+	return func(t *Telemetry) {
+		t.spanEndHooks = append(t.spanEndHooks, hook)
+	}
+}
+
+// initRedaction creates the redactor shared by all signals if enabled
+func (t *Telemetry) initRedaction() error {
+	cfg := t.config.Redaction
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	opts := []processors.RedactorOption{
+		processors.WithRedactedKeys(cfg.Keys...),
+		processors.WithRedactedPatterns(cfg.Patterns...),
+	}
+	if cfg.Mask != "" {
+		opts = append(opts, processors.WithRedactionMask(cfg.Mask))
+	}
+
+	r, err := processors.NewRedactor(opts...)
+	if err != nil {
+		return err
+	}
+	t.redactor = r
 	return nil
 }
 
+// initClock converts span and log times to a monotonic clock if hrtime is
+// enabled, which also becomes the clock of generated timestamps unless one
+// was set with WithClock
+func (t *Telemetry) initClock() {
+	if t.config.Tracing == nil || !t.config.Tracing.HRTime {
+		return
+	}
+	monotonic, ok := t.clock.(*clock.Monotonic)
+	if !ok {
+		monotonic = clock.NewMonotonic()
+	}
+	if t.clock == nil {
+		t.clock = monotonic
+	}
+	t.retimer = processors.NewRetimer(monotonic)
+}
+
 // initTracing initializes the tracing provider
 func (t *Telemetry) initTracing() error {
-	var exporter trace.SpanExporter
+	exporter, err := t.createSpanExporter()
+	if err != nil {
+		if !t.failOpen("tracing", err) {
+			return err
+		}
+		exporter = console.NewSpanExporter(console.WithClock(t.Clock()))
+	}
+	// Cut long values such as db.statement to keep payloads small
+	if n := t.config.Tracing.MaxAttributeLength; n > 0 {
+		exporter = processors.NewTruncator(n).SpanExporter(exporter)
+	}
 
-	// Create exporter based on configuration
-	exporterConfig := t.config.Tracing.Exporter
-	switch exporterConfig.Module {
-	case "console":
-		exporter = console.NewSpanExporter()
-	default:
-		return fmt.Errorf("unsupported trace exporter: %s", exporterConfig.Module)
+	// Strip attributes not meant for the backend
+	if af := t.config.Tracing.AttributeFilter; af != nil && (len(af.Allow) > 0 || len(af.Deny) > 0) {
+		filter := processors.NewAttributeFilter(
+			processors.WithAllowedAttributes(af.Allow...),
+			processors.WithDeniedAttributes(af.Deny...),
+		)
+		exporter = filter.SpanExporter(exporter)
+	}
+
+	// Redact before truncating, a cut secret no longer matches its pattern
+	if t.redactor != nil {
+		exporter = t.redactor.SpanExporter(exporter)
+	}
+
+	// Convert span times to the monotonic clock with hrtime
+	if t.retimer != nil {
+		exporter = t.retimer.SpanExporter(exporter)
 	}
 
-	// Create sampler
-	sampler := t.createSampler()
+	// Create sampler, replaceable at runtime
+	sampler, err := t.createSampler()
+	if err != nil {
+		return fmt.Errorf("failed to create sampler: %w", err)
+	}
+	t.sampler = sampling.NewDynamic(sampler)
+	if t.flightRecorder != nil {
+		// Record unsampled spans for the flight recorder, they still aren't exported
+		t.sampler.SetRecordDropped(true)
+	}
 
 	// Create tracer provider
+	var spanProcessor trace.SpanProcessor
+	switch t.config.Tracing.Processor {
+	case "", "batch":
+		spanProcessor = trace.NewBatchSpanProcessor(exporter, spanBatcherOptions(t.config.Tracing.Batcher)...)
+	case "simple":
+		spanProcessor = trace.NewSimpleSpanProcessor(exporter)
+	default:
+		return fmt.Errorf("unsupported span processor: %s", t.config.Tracing.Processor)
+	}
+
+	// Drop noise spans before they reach the exporter
+	if len(t.config.Tracing.DropSpans) > 0 {
+		rules, err := createDropRules(t.config.Tracing.DropSpans)
+		if err != nil {
+			return err
+		}
+		spanProcessor = processors.NewSpanDropper(spanProcessor, rules...)
+	}
 	opts := []trace.TracerProviderOption{
-		trace.WithBatcher(exporter),
+		trace.WithSpanProcessor(spanProcessor),
 		trace.WithResource(t.resource),
-		trace.WithSampler(sampler),
+		trace.WithSampler(t.sampler),
 	}
 
-	t.tracerProvider = trace.NewTracerProvider(opts...)
+	// Derive RED metrics from spans if enabled
+	if t.config.Tracing.SpanMetrics && t.meterProvider != nil {
+		smOpts := []processors.SpanMetricsOption{processors.WithSpanMetricsMeterProvider(t.meterProvider)}
+		if t.config.IsTenantEnabled() {
+			smOpts = append(smOpts, processors.WithTenantDimension(t.tenantLimiter))
+		}
+		spanMetrics, err := processors.NewSpanMetrics(smOpts...)
+		if err != nil {
+			return fmt.Errorf("failed to create span metrics processor: %w", err)
+		}
+		opts = append(opts, trace.WithSpanProcessor(spanMetrics))
+	}
+
+	// Invoke the application's span hooks
+	if len(t.spanStartHooks) > 0 || len(t.spanEndHooks) > 0 {
+		opts = append(opts, trace.WithSpanProcessor(processors.NewHooks(t.spanStartHooks, t.spanEndHooks)))
+	}
 
-	// Set global tracer provider
-	otel.SetTracerProvider(t.tracerProvider)
+	// Copy allow-listed baggage members onto spans
+	if keys := t.config.Tracing.BaggageAttributes; len(keys) > 0 {
+		opts = append(opts, trace.WithSpanProcessor(processors.NewBaggageAttributes(keys...)))
+	}
 
-	// Set global text map propagator
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	// Stamp the correlation ID onto spans if enabled
+	if t.config.CorrelationID {
+		opts = append(opts, trace.WithSpanProcessor(correlation.NewProcessor()))
+	}
+
+	// Stamp the tenant onto spans if enabled
+	if t.config.IsTenantEnabled() {
+		opts = append(opts, trace.WithSpanProcessor(tenant.NewProcessor()))
+	}
+
+	// Record spans for the development dashboard
+	if t.dashboard != nil {
+		opts = append(opts, trace.WithSpanProcessor(t.dashboard))
+	}
+
+	// Keep recent spans, including unsampled and dropped ones, for dumps
+	if t.flightRecorder != nil {
+		opts = append(opts, trace.WithSpanProcessor(t.flightRecorder))
+	}
+
+	// Create the propagator first, a failure then leaves nothing to shut down
+	propagator, err := t.createPropagator()
+	if err != nil {
+		return fmt.Errorf("failed to create propagator: %w", err)
+	}
+	t.propagator = propagator
+
+	t.tracerProvider = trace.NewTracerProvider(opts...)
+	t.globalTracer = t.tracerProvider
 
 	return nil
 }
 
+// createSpanExporter creates the span exporter based on configuration
+func (t *Telemetry) createSpanExporter() (trace.SpanExporter, error) {
+	exporterConfig := t.config.Tracing.Exporter
+	switch {
+	case t.spanExporter != nil:
+		return t.spanExporter, nil
+	case exporterConfig.Module == "console":
+		opts, err := consoleSpanOptions(exporterConfig.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure console span exporter: %w", err)
+		}
+		opts = append(opts, console.WithClock(t.Clock()))
+		correlated, window, err := consoleCorrelation(exporterConfig.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure console span exporter: %w", err)
+		}
+		if correlated {
+			// Console logs are collected by the same correlator, see initLogging
+			t.correlator = console.NewCorrelator(window, opts...)
+			return t.correlator.SpanExporter(), nil
+		}
+		return console.NewSpanExporter(opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s", exporterConfig.Module)
+	}
+}
+
 // initMetrics initializes the metrics provider
 func (t *Telemetry) initMetrics() error {
 	var exporter metric.Exporter
 
 	// Create exporter based on configuration
-	exporterConfig := t.config.Metrics.Exporter
-	switch exporterConfig.Module {
-	case "console":
-		exporter = console.NewMetricExporter()
-	default:
-		return fmt.Errorf("unsupported metric exporter: %s", exporterConfig.Module)
+	temporality, err := temporalitySelector(t.config.Metrics.Config.Temporality)
+	if err != nil {
+		return err
+	}
+	aggregation, err := aggregationSelector(t.config.Metrics.Config.HistogramAggregation)
+	if err != nil {
+		return err
+	}
+
+	exporter, err = t.createMetricExporter(temporality, aggregation)
+	if err != nil {
+		if !t.failOpen("metrics", err) {
+			return err
+		}
+		exporter = console.NewMetricExporter(
+			console.WithTemporalitySelector(temporality),
+			console.WithAggregationSelector(aggregation),
+		)
+	}
+	if t.redactor != nil {
+		exporter = t.redactor.MetricExporter(exporter)
+	}
+
+	// Report instruments exceeding the cardinality limit
+	limit := t.config.Metrics.CardinalityLimit
+	var overflows *cardinality.Exporter
+	if limit > 0 {
+		overflows = cardinality.NewExporter(exporter, t.logger)
+		exporter = overflows
 	}
 
+	// Let metrics be turned off at runtime
+	t.metricsSwitch = processors.NewSwitch()
+	exporter = t.metricsSwitch.MetricExporter(exporter)
+
 	// Create meter provider
-	exportInterval := t.config.Metrics.Config.GetExportInterval()
 	opts := []metric.Option{
 		metric.WithResource(t.resource),
-		metric.WithReader(metric.NewPeriodicReader(exporter,
-			metric.WithInterval(exportInterval))),
+		metric.WithReader(metricReader(exporter, t.config.Metrics.Config, t.metricProducers...)),
+	}
+
+	// Apply the configured views
+	views, err := createViews(t.config.Metrics.Views)
+	if err != nil {
+		return err
+	}
+	if len(views) > 0 {
+		opts = append(opts, metric.WithView(views...))
+	}
+	if limit > 0 {
+		opts = append(opts, metric.WithCardinalityLimit(limit))
+	}
+
+	// Read current values for the development dashboard
+	if t.dashboard != nil {
+		opts = append(opts, metric.WithReader(t.dashboard.Reader()))
 	}
 
 	t.meterProvider = metric.NewMeterProvider(opts...)
+	if overflows != nil {
+		if _, err := overflows.RegisterMetrics(t.meterProvider); err != nil {
+			return fmt.Errorf("failed to register cardinality metrics: %w", err)
+		}
+	}
 
-	// Set global meter provider
-	otel.SetMeterProvider(t.meterProvider)
+	// Start Go runtime metrics if enabled
+	if t.config.Metrics.RuntimeMetrics {
+		rm, err := runtime.Start(runtime.WithMeterProvider(t.meterProvider))
+		if err != nil {
+			return fmt.Errorf("failed to start runtime metrics: %w", err)
+		}
+		t.runtimeMetrics = rm
+	}
+
+	// Start goroutine diagnostics if enabled
+	if gd := t.config.Metrics.GoroutineDiagnostics; gd != nil && gd.Enabled {
+		gm, err := goroutines.Start(
+			goroutines.WithMeterProvider(t.meterProvider),
+			goroutines.WithBlockedThreshold(gd.GetBlockedThreshold()),
+			goroutines.WithLabels(gd.Labels...),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to start goroutine diagnostics: %w", err)
+		}
+		t.goroutineMetrics = gm
+	}
+
+	// Start process and host metrics if enabled
+	if t.config.Metrics.HostMetrics {
+		hm, err := host.Start(host.WithMeterProvider(t.meterProvider))
+		if err != nil {
+			return fmt.Errorf("failed to start host metrics: %w", err)
+		}
+		t.hostMetrics = hm
+	}
 
 	return nil
 }
 
-// createSampler creates a sampler based on configuration
-func (t *Telemetry) createSampler() trace.Sampler {
+// createMetricExporter creates the metric exporter based on configuration
+func (t *Telemetry) createMetricExporter(temporality metric.TemporalitySelector, aggregation metric.AggregationSelector) (metric.Exporter, error) {
+	exporterConfig := t.config.Metrics.Exporter
+	switch {
+	case t.metricExporter != nil:
+		return t.metricExporter, nil
+	case exporterConfig.Module == "console":
+		opts, err := consoleMetricOptions(exporterConfig.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure console metric exporter: %w", err)
+		}
+		opts = append(opts,
+			console.WithTemporalitySelector(temporality),
+			console.WithAggregationSelector(aggregation),
+		)
+		return console.NewMetricExporter(opts...), nil
+	case exporterConfig.Module == "pushgateway":
+		pg, err := pushgatewayExporter(exporterConfig.Config, t.config.ServiceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure pushgateway metric exporter: %w", err)
+		}
+		return pg, nil
+	default:
+		return nil, fmt.Errorf("unsupported metric exporter: %s", exporterConfig.Module)
+	}
+}
+
+// initLogging initializes the logger provider
+func (t *Telemetry) initLogging() error {
+	exporter, err := t.createLogExporter()
+	if err != nil {
+		if !t.failOpen("logging", err) {
+			return err
+		}
+		exporter = console.NewLogExporter(console.WithLogClock(t.Clock()))
+	}
+
+	// Drop records below the configured level before they are batched
+	minimum, err := processors.ParseSeverity(t.config.Logging.Level)
+	if err != nil {
+		return fmt.Errorf("invalid logging level: %w", err)
+	}
+	var next sdklog.Processor = sdklog.NewBatchProcessor(exporter, logBatcherOptions(t.config.Logging.Batcher)...)
+
+	// Suppress floods of identical messages if enabled
+	if rl := t.config.Logging.RateLimit; rl != nil && rl.Enabled {
+		var rlOpts []processors.RateLimiterOption
+		if rl.PerSecond > 0 {
+			rlOpts = append(rlOpts, processors.WithRateLimit(rl.PerSecond, rl.Burst))
+		}
+		next = processors.NewRateLimiter(next, rlOpts...)
+	}
+
+	// Collapse identical consecutive records if enabled, before they
+	// count against the rate limit
+	if dd := t.config.Logging.Deduplication; dd != nil && dd.Enabled {
+		next = processors.NewDeduplicator(next, processors.WithDedupWindow(dd.GetWindow()))
+	}
+
+	// Let logging be turned off at runtime
+	t.loggingSwitch = processors.NewSwitch()
+	next = t.loggingSwitch.LogProcessor(next)
+
+	t.logLevel = processors.NewSeverityFilter(next, minimum)
+
+	// Create logger provider
+	// Stamp the active trace context onto records before they are batched
+	opts := []sdklog.LoggerProviderOption{
+		sdklog.WithResource(t.resource),
+		sdklog.WithProcessor(processors.NewTraceContext()),
+	}
+	if t.retimer != nil {
+		opts = append(opts, sdklog.WithProcessor(t.retimer))
+	}
+	if t.config.CorrelationID {
+		opts = append(opts, sdklog.WithProcessor(correlation.NewProcessor()))
+	}
+	if t.config.IsTenantEnabled() {
+		opts = append(opts, sdklog.WithProcessor(tenant.NewProcessor()))
+	}
+	if t.redactor != nil {
+		opts = append(opts, sdklog.WithProcessor(t.redactor))
+	}
+	if n := t.config.Logging.MaxAttributeLength; n > 0 {
+		opts = append(opts, sdklog.WithProcessor(processors.NewTruncator(n)))
+	}
+	if t.config.Logging.SpanEvents {
+		var seOpts []processors.SpanEventsOption
+		if t.config.Logging.SpanErrorStatus {
+			seOpts = append(seOpts, processors.WithSpanErrorStatus())
+		}
+		opts = append(opts, sdklog.WithProcessor(processors.NewSpanEvents(seOpts...)))
+	}
+	// Record logs of all levels for the development dashboard
+	if t.dashboard != nil {
+		opts = append(opts, sdklog.WithProcessor(t.dashboard))
+	}
+	// Keep recent logs of all levels for dumps
+	if t.flightRecorder != nil {
+		opts = append(opts, sdklog.WithProcessor(t.flightRecorder))
+	}
+	opts = append(opts, sdklog.WithProcessor(t.logLevel))
+
+	t.loggerProvider = sdklog.NewLoggerProvider(opts...)
+
+	return nil
+}
+
+// createLogExporter creates the log exporter based on configuration
+func (t *Telemetry) createLogExporter() (sdklog.Exporter, error) {
+	exporterConfig := t.config.Logging.Exporter
+	switch {
+	case t.logExporter != nil:
+		return t.logExporter, nil
+	case exporterConfig.Module == "console":
+		opts, err := consoleLogOptions(exporterConfig.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure console log exporter: %w", err)
+		}
+		opts = append(opts, console.WithLogClock(t.Clock()))
+		if t.correlator != nil {
+			return t.correlator.LogExporter(opts...), nil
+		}
+		return console.NewLogExporter(opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported log exporter: %s", exporterConfig.Module)
+	}
+}
+
+// initAudit initializes the dedicated audit logger provider
+func (t *Telemetry) initAudit() error {
+	var exporter sdklog.Exporter
+
+	module := "console"
+	if exp := t.config.Logging.Audit.Exporter; exp != nil && exp.Module != "" {
+		module = exp.Module
+	}
+	switch module {
+	case "console":
+		exporter = console.NewLogExporter()
+	default:
+		err := fmt.Errorf("unsupported audit log exporter: %s", module)
+		if !t.failOpen("audit", err) {
+			return err
+		}
+		exporter = console.NewLogExporter()
+	}
+
+	// Audit events must not be lost in a batch when the process dies
+	t.auditProvider = sdklog.NewLoggerProvider(
+		sdklog.WithResource(t.resource),
+		sdklog.WithProcessor(processors.NewTraceContext()),
+		sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)),
+	)
+	t.auditLogger = audit.NewLogger(t.auditProvider)
+	return nil
+}
+
+// AuditLogger returns the audit logger; events are dropped unless
+// logging.audit.enabled is set
+func (t *Telemetry) AuditLogger() *audit.Logger {
+	if t.auditLogger == nil {
+		return audit.NewNoopLogger()
+	}
+	return t.auditLogger
+}
+
+// SetLogLevel changes the minimum exported log severity at runtime,
+// e.g. to temporarily turn on debug logs without redeploying
+func (t *Telemetry) SetLogLevel(level string) error {
+	t.settingsMu.Lock()
+	defer t.settingsMu.Unlock()
+	return t.setLogLevel(level)
+}
+
+// setLogLevel changes the log level; settingsMu must be held
+func (t *Telemetry) setLogLevel(level string) error {
+	if t.logLevel == nil {
+		return fmt.Errorf("logging is not enabled")
+	}
+
+	minimum, err := processors.ParseSeverity(level)
+	if err != nil {
+		return err
+	}
+	t.logLevel.SetMinimum(minimum)
+	t.config.Logging.Level = level
+	return nil
+}
+
+// SetSamplingRatio samples the given fraction of root spans at runtime,
+// keeping the decision of the parent for child spans. Sampling rules,
+// ignored paths and forced sampling still apply.
+func (t *Telemetry) SetSamplingRatio(ratio float64) error {
+	t.settingsMu.Lock()
+	defer t.settingsMu.Unlock()
+	return t.setSamplingRatio(ratio)
+}
+
+// setSamplingRatio changes the sampling ratio; settingsMu must be held
+func (t *Telemetry) setSamplingRatio(ratio float64) error {
+	if t.sampler == nil {
+		return fmt.Errorf("tracing is not enabled")
+	}
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("sampling ratio %v must be between 0 and 1", ratio)
+	}
+
+	if t.config.Tracing.Sampler == nil {
+		t.config.Tracing.Sampler = &config.SamplerConfig{}
+	}
+	root, err := t.withSamplingRules(trace.TraceIDRatioBased(ratio))
+	if err != nil {
+		return err
+	}
+	t.sampler.Set(t.withForceSampling(trace.ParentBased(root)))
+
+	t.config.Tracing.Sampler.Kind = "ParentBasedSampler"
+	t.config.Tracing.Sampler.Root = "TraceIdRatioBasedSampler"
+	t.config.Tracing.Sampler.Ratio = ratio
+	return nil
+}
+
+// SetTracingEnabled turns tracing on or off at runtime; while off, no spans
+// are recorded. Tracing must have been enabled at startup.
+func (t *Telemetry) SetTracingEnabled(enabled bool) error {
+	if t.sampler == nil {
+		return fmt.Errorf("tracing is not enabled")
+	}
+	t.sampler.SetEnabled(enabled)
+	return nil
+}
+
+// SetMetricsEnabled turns the export of metrics on or off at runtime.
+// Metrics must have been enabled at startup.
+func (t *Telemetry) SetMetricsEnabled(enabled bool) error {
+	if t.metricsSwitch == nil {
+		return fmt.Errorf("metrics are not enabled")
+	}
+	t.metricsSwitch.SetEnabled(enabled)
+	return nil
+}
+
+// SetLoggingEnabled turns the export of logs on or off at runtime. Logging
+// must have been enabled at startup.
+func (t *Telemetry) SetLoggingEnabled(enabled bool) error {
+	if t.loggingSwitch == nil {
+		return fmt.Errorf("logging is not enabled")
+	}
+	t.loggingSwitch.SetEnabled(enabled)
+	return nil
+}
+
+// createSampler creates a sampler based on configuration.
+// OTEL_TRACES_SAMPLER takes precedence over the configuration file.
+func (t *Telemetry) createSampler() (trace.Sampler, error) {
+	if name := os.Getenv("OTEL_TRACES_SAMPLER"); name != "" {
+		sampler, ok, err := t.samplerFromEnv(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return t.withForceSampling(sampler), nil
+		}
+		t.logger.Printf("unsupported OTEL_TRACES_SAMPLER %s, using configured sampler", name)
+	}
+
+	if t.config.Tracing.Sampler == nil {
+		return trace.AlwaysSample(), nil
+	}
+	sampler, err := t.createConfiguredSampler()
+	if err != nil {
+		return nil, err
+	}
+	return t.withForceSampling(sampler), nil
+}
+
+// withForceSampling wraps the sampler for the configured debug header or
+// baggage key. Forced sampling must wrap everything to override
+// parent-based decisions.
+func (t *Telemetry) withForceSampling(sampler trace.Sampler) trace.Sampler {
 	samplerConfig := t.config.Tracing.Sampler
-	if samplerConfig == nil {
-		return trace.AlwaysSample()
+	if samplerConfig == nil || samplerConfig.DebugHeader == "" && samplerConfig.DebugBaggageKey == "" {
+		return sampler
 	}
 
+	var opts []sampling.ForceSamplerOption
+	if samplerConfig.DebugBaggageKey != "" {
+		opts = append(opts, sampling.WithBaggageKey(samplerConfig.DebugBaggageKey))
+	}
+	return sampling.NewForceSampler(sampler, opts...)
+}
+
+// createConfiguredSampler creates the sampler of the configured kind
+func (t *Telemetry) createConfiguredSampler() (trace.Sampler, error) {
+	samplerConfig := t.config.Tracing.Sampler
+
 	switch samplerConfig.Kind {
 	case "AlwaysOnSampler":
-		return trace.AlwaysSample()
+		return t.withSamplingRules(trace.AlwaysSample())
 	case "AlwaysOffSampler":
-		return trace.NeverSample()
+		return t.withSamplingRules(trace.NeverSample())
 	case "TraceIdRatioBasedSampler":
 		ratio := samplerConfig.Ratio
 		if ratio <= 0 {
 			ratio = 1.0
 		}
-		return trace.TraceIDRatioBased(ratio)
+		return t.withSamplingRules(trace.TraceIDRatioBased(ratio))
 	case "ParentBasedSampler":
 		var root trace.Sampler
 		switch samplerConfig.Root {
@@ -210,39 +915,226 @@ func (t *Telemetry) createSampler() trace.Sampler {
 			root = trace.AlwaysSample()
 		case "AlwaysOffSampler":
 			root = trace.NeverSample()
+		case "TraceIdRatioBasedSampler":
+			ratio := samplerConfig.Ratio
+			if ratio <= 0 {
+				ratio = 1.0
+			}
+			root = trace.TraceIDRatioBased(ratio)
 		default:
 			root = trace.AlwaysSample()
 		}
-		return trace.ParentBased(root)
+		// Rules only decide for root spans, children follow their parent
+		root, err := t.withSamplingRules(root)
+		if err != nil {
+			return nil, err
+		}
+		return trace.ParentBased(root), nil
 	default:
-		return trace.AlwaysSample()
+		return t.withSamplingRules(trace.AlwaysSample())
+	}
+}
+
+// samplerFromEnv creates the sampler named by OTEL_TRACES_SAMPLER, with the
+// ratio from OTEL_TRACES_SAMPLER_ARG. It reports false for unsupported names.
+func (t *Telemetry) samplerFromEnv(name string) (trace.Sampler, bool, error) {
+	ratio := 1.0
+	if arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil || v < 0 || v > 1 {
+			t.logger.Printf("invalid OTEL_TRACES_SAMPLER_ARG %s, using 1.0", arg)
+		} else {
+			ratio = v
+		}
+	}
+
+	var root trace.Sampler
+	parentBased := false
+	switch strings.ToLower(name) {
+	case "always_on":
+		root = trace.AlwaysSample()
+	case "always_off":
+		root = trace.NeverSample()
+	case "traceidratio":
+		root = trace.TraceIDRatioBased(ratio)
+	case "parentbased_always_on":
+		root, parentBased = trace.AlwaysSample(), true
+	case "parentbased_always_off":
+		root, parentBased = trace.NeverSample(), true
+	case "parentbased_traceidratio":
+		root, parentBased = trace.TraceIDRatioBased(ratio), true
+	default:
+		return nil, false, nil
+	}
+
+	root, err := t.withSamplingRules(root)
+	if err != nil {
+		return nil, true, err
+	}
+	if parentBased {
+		return trace.ParentBased(root), true, nil
 	}
+	return root, true, nil
+}
+
+// withSamplingRules wraps the sampler with the configured sampling rules.
+// Ignored incoming paths become rules dropping server spans for those paths.
+func (t *Telemetry) withSamplingRules(fallback trace.Sampler) (trace.Sampler, error) {
+	samplerConfig := t.config.Tracing.Sampler
+	if samplerConfig == nil {
+		return fallback, nil
+	}
+
+	var rules []sampling.Rule
+	for _, p := range samplerConfig.IgnoreIncomingPaths {
+		rules = append(rules, sampling.Rule{Name: p, Route: p, SpanKind: oteltrace.SpanKindServer, Ratio: 0})
+	}
+	for i, rc := range samplerConfig.Rules {
+		if rc == nil {
+			continue
+		}
+		kind, err := sampling.ParseSpanKind(rc.SpanKind)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling rule %d: %w", i, err)
+		}
+		rules = append(rules, sampling.Rule{
+			Name:       rc.Name,
+			Route:      rc.Route,
+			SpanKind:   kind,
+			Attributes: rc.Attributes,
+			Ratio:      rc.Ratio,
+		})
+	}
+
+	if len(rules) == 0 {
+		return fallback, nil
+	}
+	return sampling.NewRuleBased(rules, fallback), nil
+}
+
+// createDropRules translates tracing.drop_spans into drop rules
+func createDropRules(cfgs []*config.DropSpanConfig) ([]processors.DropRule, error) {
+	var rules []processors.DropRule
+	for i, dc := range cfgs {
+		if dc == nil {
+			continue
+		}
+		if dc.Name == "" && dc.SpanKind == "" && len(dc.Attributes) == 0 {
+			return nil, fmt.Errorf("invalid drop_spans rule %d: no criteria", i)
+		}
+		kind, err := sampling.ParseSpanKind(dc.SpanKind)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drop_spans rule %d: %w", i, err)
+		}
+		rules = append(rules, processors.DropRule{
+			Name:       dc.Name,
+			SpanKind:   kind,
+			Attributes: dc.Attributes,
+		})
+	}
+	return rules, nil
 }
 
 // Shutdown gracefully shuts down the telemetry providers
 func (t *Telemetry) Shutdown(ctx context.Context) error {
+	if err := t.shutdown(ctx); err != nil {
+		return err
+	}
+
+	t.logger.Println("telemetry shutdown completed")
+	return nil
+}
+
+// shutdown stops the components that were started, skipping the others
+func (t *Telemetry) shutdown(ctx context.Context) error {
 	var errors []error
 
+	// Stop remote changes before the providers shut down
+	if t.remote != nil {
+		if err := t.remote.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown remote configuration: %w", err))
+		}
+	}
+
+	if t.profiler != nil {
+		if err := t.profiler.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown profiler: %w", err))
+		}
+	}
+
+	if t.heartbeat != nil {
+		if err := t.heartbeat.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown heartbeat: %w", err))
+		}
+	}
+
+	// Shut down instrumentations first so they can flush through the providers
+	for _, inst := range t.instrumentations {
+		if err := inst.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown instrumentation: %w", err))
+		}
+	}
+
 	if t.tracerProvider != nil {
 		if err := t.tracerProvider.Shutdown(ctx); err != nil {
 			errors = append(errors, fmt.Errorf("failed to shutdown tracer provider: %w", err))
 		}
 	}
 
+	if t.runtimeMetrics != nil {
+		if err := t.runtimeMetrics.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown runtime metrics: %w", err))
+		}
+	}
+
+	if t.goroutineMetrics != nil {
+		if err := t.goroutineMetrics.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown goroutine diagnostics: %w", err))
+		}
+	}
+
+	if t.hostMetrics != nil {
+		if err := t.hostMetrics.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown host metrics: %w", err))
+		}
+	}
+
 	if t.meterProvider != nil {
 		if err := t.meterProvider.Shutdown(ctx); err != nil {
 			errors = append(errors, fmt.Errorf("failed to shutdown meter provider: %w", err))
 		}
 	}
 
+	if t.gcWatcher != nil {
+		if err := t.gcWatcher.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown GC events: %w", err))
+		}
+	}
+
+	if t.loggerProvider != nil {
+		if err := t.loggerProvider.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown logger provider: %w", err))
+		}
+	}
+
+	if t.auditProvider != nil {
+		if err := t.auditProvider.Shutdown(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("failed to shutdown audit logger provider: %w", err))
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errors)
 	}
-
-	t.logger.Println("telemetry shutdown completed")
 	return nil
 }
 
+// Clock returns the clock of generated timestamps, e.g. for
+// WithSlogClock
+func (t *Telemetry) Clock() clock.Clock {
+	return clock.OrSystem(t.clock)
+}
+
 // TracerProvider returns the tracer provider
 func (t *Telemetry) TracerProvider() *trace.TracerProvider {
 	return t.tracerProvider
@@ -253,7 +1145,21 @@ func (t *Telemetry) MeterProvider() *metric.MeterProvider {
 	return t.meterProvider
 }
 
-// Config returns the configuration
+// LoggerProvider returns the logger provider
+func (t *Telemetry) LoggerProvider() *sdklog.LoggerProvider {
+	return t.loggerProvider
+}
+
+// Config returns a copy of the configuration, including the settings
+// changed at runtime. Changing it has no effect on the instance.
 func (t *Telemetry) Config() *config.Config {
-	return t.config
+	t.settingsMu.Lock()
+	defer t.settingsMu.Unlock()
+
+	cfg, err := t.config.Clone()
+	if err != nil {
+		t.logger.Printf("failed to copy configuration: %v", err)
+		return nil
+	}
+	return cfg
 }
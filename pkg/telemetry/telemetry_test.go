@@ -0,0 +1,201 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/clock"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/processors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCreateSampler_Env(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tracing.Sampler.IgnoreIncomingPaths = nil
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	sampler, err := tel.createSampler()
+	if err != nil {
+		t.Fatalf("createSampler failed: %v", err)
+	}
+	if got := sampler.Description(); got != "TraceIDRatioBased{0.25}" {
+		t.Errorf("Expected env sampler to take precedence, got %s", got)
+	}
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_always_off")
+	sampler, _ = tel.createSampler()
+	if got := sampler.Description(); !strings.HasPrefix(got, "ParentBased{root:AlwaysOffSampler") {
+		t.Errorf("Expected parent based always off sampler, got %s", got)
+	}
+
+	// Unsupported names fall back to the configuration file
+	t.Setenv("OTEL_TRACES_SAMPLER", "jaeger_remote")
+	sampler, _ = tel.createSampler()
+	if got := sampler.Description(); !strings.HasPrefix(got, "ParentBased{root:AlwaysOnSampler") {
+		t.Errorf("Expected configured sampler, got %s", got)
+	}
+}
+
+func TestCreateSampler_EnvWithoutSamplerConfig(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tracing.Sampler = nil
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_off")
+	sampler, err := tel.createSampler()
+	if err != nil {
+		t.Fatalf("createSampler failed: %v", err)
+	}
+	if got := sampler.Description(); got != "AlwaysOffSampler" {
+		t.Errorf("Expected env sampler without a sampler config, got %s", got)
+	}
+
+	t.Setenv("OTEL_TRACES_SAMPLER", "")
+	sampler, _ = tel.createSampler()
+	if got := sampler.Description(); got != "AlwaysOnSampler" {
+		t.Errorf("Expected always on sampler without env and sampler config, got %s", got)
+	}
+}
+
+func TestInitClock(t *testing.T) {
+	tel := &Telemetry{config: config.NewDefaultConfig()}
+	tel.initClock()
+	if tel.retimer != nil || tel.Clock() != clock.System {
+		t.Error("Expected the system clock without hrtime")
+	}
+
+	cfg, err := config.NewBuilder().Tracing(config.WithHRTime(true)).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	tel = &Telemetry{config: cfg}
+	tel.initClock()
+	if _, ok := tel.Clock().(*clock.Monotonic); !ok || tel.retimer == nil {
+		t.Errorf("Expected a monotonic clock with hrtime, got %T", tel.Clock())
+	}
+
+	// A clock set in code stays the clock of generated timestamps
+	fixed := clock.NewFixed(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	tel = &Telemetry{config: cfg, clock: fixed}
+	tel.initClock()
+	if tel.Clock() != clock.Clock(fixed) || tel.retimer == nil {
+		t.Error("Expected the fixed clock and span times converted to a monotonic clock")
+	}
+}
+
+func TestRedactionBeforeTruncation(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cfg := config.NewDefaultConfig()
+	cfg.Metrics.Enabled = false
+	cfg.Tracing.MaxAttributeLength = 20
+	cfg.Redaction = &config.RedactionConfig{Enabled: true}
+	exporter := tracetest.NewInMemoryExporter()
+	tel, err := New(WithConfig(cfg), WithSpanExporter(keptSpans{exporter}), WithLogger(log.New(io.Discard, "", 0)))
+	if err != nil {
+		t.Fatalf("failed to create telemetry: %v", err)
+	}
+
+	// The limit cuts the email, so it only matches before truncation
+	_, span := tel.TracerProvider().Tracer("test").Start(context.Background(), "notify")
+	span.SetAttributes(attribute.String("recipient", "contact jane.doe@example.com please"))
+	span.End()
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	got := spans[0].Attributes[0].Value.AsString()
+	if strings.Contains(got, "jane") || !strings.Contains(got, processors.DefaultRedactionMask) {
+		t.Errorf("Expected the email to be redacted before truncation, got %q", got)
+	}
+	if !strings.HasSuffix(got, processors.TruncationSuffix) {
+		t.Errorf("Expected the redacted value to be truncated, got %q", got)
+	}
+}
+
+// shutdownExporter records whether it was shut down
+type shutdownExporter struct {
+	tracetest.InMemoryExporter
+	shutdown bool
+}
+
+func (e *shutdownExporter) Shutdown(ctx context.Context) error {
+	e.shutdown = true
+	return nil
+}
+
+func TestNewFailureRestoresGlobals(t *testing.T) {
+	RegisterInstrumentation("failing-test", func(t *Telemetry, cfg *config.InstrumentationConfig) (Instrumentation, error) {
+		return nil, errors.New("boom")
+	})
+
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+	}{
+		{
+			name: "propagator",
+			configure: func(cfg *config.Config) {
+				cfg.Tracing.Propagators = []string{"bogus"}
+			},
+		},
+		{
+			name: "instrumentation",
+			configure: func(cfg *config.Config) {
+				cfg.Instrumentations["failing"] = &config.InstrumentationConfig{Module: "failing-test", Enabled: true}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Metrics.Enabled = false
+			cfg.Logging.Enabled = false
+			tt.configure(cfg)
+
+			before := otel.GetTracerProvider()
+			exporter := &shutdownExporter{}
+			_, err := New(WithConfig(cfg), WithSpanExporter(exporter), WithLogger(log.New(io.Discard, "", 0)))
+			if err == nil {
+				t.Fatal("Expected New to fail")
+			}
+			if otel.GetTracerProvider() != before {
+				t.Error("Expected the global tracer provider to be unchanged")
+			}
+			if tt.name == "instrumentation" && !exporter.shutdown {
+				t.Error("Expected the started tracer provider to be shut down")
+			}
+		})
+	}
+}
+
+func TestConfigReturnsCopy(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tracing.Propagators = []string{"tracecontext"}
+	tel := &Telemetry{config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	copied := tel.Config()
+	copied.ServiceName = "changed"
+	copied.Tracing.Propagators[0] = "b3"
+	copied.Logging.Level = "debug"
+
+	if cfg.ServiceName == "changed" || cfg.Tracing.Propagators[0] != "tracecontext" || cfg.Logging.Level == "debug" {
+		t.Error("Expected changes to the returned config not to affect the instance")
+	}
+}
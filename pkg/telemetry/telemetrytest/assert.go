@@ -0,0 +1,298 @@
+package telemetrytest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SpanMatcher checks one property of a span
+type SpanMatcher struct {
+	// want describes the expected property, e.g. name="db.query"
+	want string
+	// got describes the property of span, ok if it matches
+	got func(span sdktrace.ReadOnlySpan) (string, bool)
+}
+
+// WithName matches spans by name
+func WithName(name string) SpanMatcher {
+	return SpanMatcher{
+		want: fmt.Sprintf("name=%q", name),
+		got: func(span sdktrace.ReadOnlySpan) (string, bool) {
+			return fmt.Sprintf("name=%q", span.Name()), span.Name() == name
+		},
+	}
+}
+
+// WithAttr matches spans having the attribute key with value. Values are
+// compared by their printed form, so 3 matches int64(3).
+func WithAttr(key string, value interface{}) SpanMatcher {
+	want := fmt.Sprintf("%s=%s", key, formatValue(value))
+	return SpanMatcher{
+		want: want,
+		got: func(span sdktrace.ReadOnlySpan) (string, bool) {
+			for _, kv := range span.Attributes() {
+				if string(kv.Key) == key {
+					got := fmt.Sprintf("%s=%s", key, formatValue(kv.Value.AsInterface()))
+					return got, got == want
+				}
+			}
+			return key + " missing", false
+		},
+	}
+}
+
+// WithKind matches spans by kind
+func WithKind(kind oteltrace.SpanKind) SpanMatcher {
+	return SpanMatcher{
+		want: "kind=" + kind.String(),
+		got: func(span sdktrace.ReadOnlySpan) (string, bool) {
+			return "kind=" + span.SpanKind().String(), span.SpanKind() == kind
+		},
+	}
+}
+
+// WithStatus matches spans by status code
+func WithStatus(code codes.Code) SpanMatcher {
+	return SpanMatcher{
+		want: "status=" + code.String(),
+		got: func(span sdktrace.ReadOnlySpan) (string, bool) {
+			return "status=" + span.Status().Code.String(), span.Status().Code == code
+		},
+	}
+}
+
+// WithParentOf matches the children of parent
+func WithParentOf(parent sdktrace.ReadOnlySpan) SpanMatcher {
+	return SpanMatcher{
+		want: fmt.Sprintf("parent=%q", parent.Name()),
+		got: func(span sdktrace.ReadOnlySpan) (string, bool) {
+			return "parent=" + span.Parent().SpanID().String(), span.Parent().SpanID() == parent.SpanContext().SpanID()
+		},
+	}
+}
+
+// formatValue prints attribute values uniformly, quoting strings
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case attribute.Value:
+		return formatValue(v.AsInterface())
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// AssertSpan fails the test unless a recorded span matches all matchers,
+// and returns the first matching span. The failure lists how every
+// recorded span differs.
+func AssertSpan(t testing.TB, rec *Recorder, matchers ...SpanMatcher) sdktrace.ReadOnlySpan {
+	t.Helper()
+	spans := rec.Spans()
+	for _, span := range spans {
+		if len(spanMismatches(span, matchers)) == 0 {
+			return span
+		}
+	}
+	t.Errorf("no span matches %s\n%s", describeMatchers(matchers), describeSpans(spans, matchers))
+	return nil
+}
+
+// AssertNoSpan fails the test if a recorded span matches all matchers
+func AssertNoSpan(t testing.TB, rec *Recorder, matchers ...SpanMatcher) {
+	t.Helper()
+	for _, span := range rec.Spans() {
+		if len(spanMismatches(span, matchers)) == 0 {
+			t.Errorf("unexpected span %q matches %s", span.Name(), describeMatchers(matchers))
+			return
+		}
+	}
+}
+
+// spanMismatches returns the differences of span to the matchers
+func spanMismatches(span sdktrace.ReadOnlySpan, matchers []SpanMatcher) []string {
+	var mismatches []string
+	for _, m := range matchers {
+		if got, ok := m.got(span); !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s, want %s", got, m.want))
+		}
+	}
+	return mismatches
+}
+
+func describeMatchers(matchers []SpanMatcher) string {
+	wants := make([]string, len(matchers))
+	for i, m := range matchers {
+		wants[i] = m.want
+	}
+	return strings.Join(wants, " ")
+}
+
+func describeSpans(spans []sdktrace.ReadOnlySpan, matchers []SpanMatcher) string {
+	if len(spans) == 0 {
+		return "no spans were recorded"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "recorded spans:")
+	for _, span := range spans {
+		fmt.Fprintf(&b, "\n  %q: %s", span.Name(), strings.Join(spanMismatches(span, matchers), "; "))
+	}
+	return b.String()
+}
+
+// AssertCounterValue fails the test unless the sum of the counter's data
+// points having attrs equals want. Without attrs, all data points are summed.
+func AssertCounterValue(t testing.TB, rec *Recorder, name string, want float64, attrs ...attribute.KeyValue) {
+	t.Helper()
+	data, ok := findMetric(t, rec, name)
+	if !ok {
+		return
+	}
+
+	var got float64
+	var matched int
+	switch sum := data.(type) {
+	case metricdata.Sum[int64]:
+		for _, dp := range sum.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				got += float64(dp.Value)
+				matched++
+			}
+		}
+	case metricdata.Sum[float64]:
+		for _, dp := range sum.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				got += dp.Value
+				matched++
+			}
+		}
+	default:
+		t.Errorf("metric %s is a %T, not a counter", name, data)
+		return
+	}
+
+	if matched == 0 {
+		t.Errorf("metric %s has no data points with %s\n%s", name, describeAttributes(attrs), describeDataPoints(data))
+		return
+	}
+	if got != want {
+		t.Errorf("metric %s with %s: got %v, want %v\n%s", name, describeAttributes(attrs), got, want, describeDataPoints(data))
+	}
+}
+
+// AssertHistogramCount fails the test unless the histogram recorded want
+// measurements with attrs
+func AssertHistogramCount(t testing.TB, rec *Recorder, name string, want uint64, attrs ...attribute.KeyValue) {
+	t.Helper()
+	data, ok := findMetric(t, rec, name)
+	if !ok {
+		return
+	}
+
+	var got uint64
+	switch histogram := data.(type) {
+	case metricdata.Histogram[int64]:
+		for _, dp := range histogram.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				got += dp.Count
+			}
+		}
+	case metricdata.Histogram[float64]:
+		for _, dp := range histogram.DataPoints {
+			if hasAttributes(dp.Attributes, attrs) {
+				got += dp.Count
+			}
+		}
+	default:
+		t.Errorf("metric %s is a %T, not a histogram", name, data)
+		return
+	}
+	if got != want {
+		t.Errorf("metric %s with %s: got %d measurements, want %d\n%s", name, describeAttributes(attrs), got, want, describeDataPoints(data))
+	}
+}
+
+// findMetric returns the data of the metric, failing the test with the
+// recorded metric names if there is none
+func findMetric(t testing.TB, rec *Recorder, name string) (metricdata.Aggregation, bool) {
+	t.Helper()
+	rm := rec.Metrics()
+	var names []string
+	if rm != nil {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == name {
+					return m.Data, true
+				}
+				names = append(names, m.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		t.Errorf("metric %s not found, no metrics were recorded", name)
+	} else {
+		t.Errorf("metric %s not found, recorded metrics: %s", name, strings.Join(names, ", "))
+	}
+	return nil, false
+}
+
+// hasAttributes returns whether set contains all attrs
+func hasAttributes(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if value, ok := set.Value(kv.Key); !ok || value != kv.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func describeAttributes(attrs []attribute.KeyValue) string {
+	if len(attrs) == 0 {
+		return "any attributes"
+	}
+	parts := make([]string, len(attrs))
+	for i, kv := range attrs {
+		parts[i] = fmt.Sprintf("%s=%s", kv.Key, formatValue(kv.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeDataPoints lists the data points of counters and histograms
+func describeDataPoints(data metricdata.Aggregation) string {
+	var lines []string
+	add := func(set attribute.Set, value interface{}) {
+		attrs := set.ToSlice()
+		lines = append(lines, fmt.Sprintf("  {%s}: %v", strings.TrimPrefix(describeAttributes(attrs), "any attributes"), value))
+	}
+	switch d := data.(type) {
+	case metricdata.Sum[int64]:
+		for _, dp := range d.DataPoints {
+			add(dp.Attributes, dp.Value)
+		}
+	case metricdata.Sum[float64]:
+		for _, dp := range d.DataPoints {
+			add(dp.Attributes, dp.Value)
+		}
+	case metricdata.Histogram[int64]:
+		for _, dp := range d.DataPoints {
+			add(dp.Attributes, fmt.Sprintf("count %d", dp.Count))
+		}
+	case metricdata.Histogram[float64]:
+		for _, dp := range d.DataPoints {
+			add(dp.Attributes, fmt.Sprintf("count %d", dp.Count))
+		}
+	}
+	if len(lines) == 0 {
+		return "recorded data points: none"
+	}
+	return "recorded data points:\n" + strings.Join(lines, "\n")
+}
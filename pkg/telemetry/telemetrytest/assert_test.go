@@ -0,0 +1,95 @@
+package telemetrytest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// failures records the errors of assertions instead of failing the test
+type failures struct {
+	testing.TB
+	errors []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSpan(t *testing.T) {
+	rec := NewForTest(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /books", oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+	_, child := otel.Tracer("test").Start(ctx, "db.query", oteltrace.WithAttributes(
+		attribute.String("db.system", "hana"),
+		attribute.Int64("db.rows", 3),
+	))
+	child.SetStatus(codes.Error, "timeout")
+	child.End()
+	parent.End()
+
+	server := AssertSpan(t, rec, WithName("GET /books"), WithKind(oteltrace.SpanKindServer))
+	AssertSpan(t, rec, WithName("db.query"), WithAttr("db.system", "hana"), WithAttr("db.rows", 3),
+		WithStatus(codes.Error), WithParentOf(server))
+	AssertNoSpan(t, rec, WithName("db.query"), WithAttr("db.system", "postgresql"))
+
+	f := &failures{TB: t}
+	if span := AssertSpan(f, rec, WithName("db.query"), WithAttr("db.system", "postgresql")); span != nil {
+		t.Error("Expected no matching span")
+	}
+	if len(f.errors) != 1 {
+		t.Fatalf("Expected one failure, got %v", f.errors)
+	}
+	for _, expected := range []string{
+		`no span matches name="db.query" db.system="postgresql"`,
+		`"db.query": db.system="hana", want db.system="postgresql"`,
+		`"GET /books": name="GET /books", want name="db.query"; db.system missing, want db.system="postgresql"`,
+	} {
+		if !strings.Contains(f.errors[0], expected) {
+			t.Errorf("Expected %q in:\n%s", expected, f.errors[0])
+		}
+	}
+}
+
+func TestAssertCounterValue(t *testing.T) {
+	rec := NewForTest(t)
+	ctx := context.Background()
+	counter, _ := otel.Meter("test").Int64Counter("http_requests_total")
+	counter.Add(ctx, 2, metric.WithAttributes(attribute.String("method", "GET")))
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String("method", "POST")))
+	histogram, _ := otel.Meter("test").Float64Histogram("http_duration")
+	histogram.Record(ctx, 0.5)
+	histogram.Record(ctx, 1.5)
+
+	AssertCounterValue(t, rec, "http_requests_total", 3)
+	AssertCounterValue(t, rec, "http_requests_total", 2, attribute.String("method", "GET"))
+	AssertHistogramCount(t, rec, "http_duration", 2)
+
+	f := &failures{TB: t}
+	AssertCounterValue(f, rec, "http_requests_total", 5, attribute.String("method", "POST"))
+	AssertCounterValue(f, rec, "http_requests", 1)
+	AssertHistogramCount(f, rec, "http_requests_total", 1)
+	if len(f.errors) != 3 {
+		t.Fatalf("Expected three failures, got %v", f.errors)
+	}
+	for i, expected := range []string{
+		`metric http_requests_total with method="POST": got 1, want 5`,
+		"metric http_requests not found, recorded metrics: http_duration, http_requests_total",
+		"not a histogram",
+	} {
+		if !strings.Contains(f.errors[i], expected) {
+			t.Errorf("Expected %q in:\n%s", expected, f.errors[i])
+		}
+	}
+	if !strings.Contains(f.errors[0], `{method="GET"}: 2`) {
+		t.Errorf("Expected the recorded data points in:\n%s", f.errors[0])
+	}
+}
@@ -0,0 +1,92 @@
+package telemetrytest
+
+import (
+	"context"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanExporter keeps exported spans in memory
+type SpanExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+// NewSpanExporter creates an empty in-memory span exporter
+func NewSpanExporter() *SpanExporter {
+	return &SpanExporter{}
+}
+
+// ExportSpans records the spans
+func (e *SpanExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Shutdown keeps the recorded spans
+func (e *SpanExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Spans returns the recorded spans in the order they ended
+func (e *SpanExporter) Spans() []sdktrace.ReadOnlySpan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdktrace.ReadOnlySpan(nil), e.spans...)
+}
+
+// Reset discards the recorded spans
+func (e *SpanExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// LogExporter keeps exported log records in memory
+type LogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+// NewLogExporter creates an empty in-memory log exporter
+func NewLogExporter() *LogExporter {
+	return &LogExporter{}
+}
+
+// Export records clones of the records, as the SDK reuses them
+func (e *LogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+	return nil
+}
+
+// ForceFlush does nothing, records are kept as they are exported
+func (e *LogExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown keeps the recorded log records
+func (e *LogExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Records returns the recorded log records in the order they were exported
+func (e *LogExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]sdklog.Record(nil), e.records...)
+}
+
+// Reset discards the recorded log records
+func (e *LogExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.records = nil
+}
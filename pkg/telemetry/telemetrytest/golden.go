@@ -0,0 +1,77 @@
+package telemetrytest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv names the environment variable that makes AssertGolden
+// rewrite the golden files instead of comparing against them
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// AssertGolden fails the test unless got equals testdata/<name>.golden.
+// Run the tests with UPDATE_GOLDEN=1, or with -update if the test binary
+// defines that flag, to write got to the file instead. Combine it with the
+// deterministic console options to snapshot the output of formatters:
+//
+//	var buf bytes.Buffer
+//	exporter := console.NewSpanExporter(console.WithWriter(&buf),
+//		console.WithSpanFormatter(myFormatter{}), console.WithDeterministic(time.Time{}))
+//	...
+//	telemetrytest.AssertGolden(t, "checkout", buf.Bytes())
+func AssertGolden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with %s=1 to create it: %v", UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, run with %s=1 to update it\n%s", path, UpdateGoldenEnv, diffLines(string(want), string(got)))
+	}
+}
+
+// updateGolden reports whether golden files are rewritten
+func updateGolden() bool {
+	if v := os.Getenv(UpdateGoldenEnv); v != "" && v != "0" && v != "false" {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		return f.Value.String() == "true"
+	}
+	return false
+}
+
+// diffLines describes the first line where got differs from want
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	line := func(lines []string, i int) string {
+		if i >= len(lines) {
+			return "<end of output>"
+		}
+		return fmt.Sprintf("%q", lines[i])
+	}
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		if w, g := line(wantLines, i), line(gotLines, i); w != g {
+			return fmt.Sprintf("first difference at line %d:\n  want: %s\n  got:  %s\nfull output:\n%s", i+1, w, g, got)
+		}
+	}
+	return "full output:\n" + got
+}
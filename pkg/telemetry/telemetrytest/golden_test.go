@@ -0,0 +1,64 @@
+package telemetrytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/exporters/console"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestAssertGoldenConsole(t *testing.T) {
+	var buf bytes.Buffer
+	correlator := console.NewCorrelator(time.Hour, console.WithWriter(&buf),
+		console.WithDeterministic(time.Time{}), console.WithImportantAttributes("*"))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(correlator.SpanExporter()))
+	logExporter := correlator.LogExporter(console.WithLogWriter(&buf),
+		console.WithLogFormatter(&console.CompactLogFormatter{}), console.WithLogDeterministic(time.Time{}))
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(logExporter)))
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "POST /orders")
+	root.SetAttributes(attribute.String("http.route", "/orders"), attribute.Int("http.status_code", 500))
+	childCtx, child := tp.Tracer("test").Start(ctx, "db.insert")
+	var record log.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(log.SeverityWarn)
+	record.SetBody(log.StringValue("retrying insert"))
+	record.AddAttributes(log.Int("attempt", 2))
+	lp.Logger("test").Emit(childCtx, record)
+	child.RecordError(errors.New("deadlock"))
+	child.SetStatus(codes.Error, "deadlock")
+	child.End()
+	root.End()
+
+	_ = tp.Shutdown(context.Background())
+	_ = lp.Shutdown(context.Background())
+
+	AssertGolden(t, "console", buf.Bytes())
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	t.Setenv(UpdateGoldenEnv, "")
+	f := &failures{TB: t}
+	AssertGolden(f, "console", []byte("[telemetry] - something else\n"))
+	if len(f.errors) != 1 {
+		t.Fatalf("Expected one failure, got %v", f.errors)
+	}
+	for _, expected := range []string{
+		"testdata/console.golden",
+		"first difference at line 1:",
+		`got:  "[telemetry] - something else"`,
+	} {
+		if !strings.Contains(f.errors[0], expected) {
+			t.Errorf("Expected %q in:\n%s", expected, f.errors[0])
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package telemetrytest
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// requestHeader tags requests sent through a Server, so it knows when the
+// handler of a request has returned
+const requestHeader = "X-Telemetrytest-Request"
+
+// Exchange is a test request with the response and the spans ended while
+// it was served
+type Exchange struct {
+	// Response has its body read into Body
+	Response *http.Response
+	Body     []byte
+
+	// Spans lists the spans ended while the request was served
+	Spans []sdktrace.ReadOnlySpan
+
+	// Parent is the remote parent sent with WithRemoteParent
+	Parent oteltrace.SpanContext
+}
+
+// Span returns the first span of the request matching all matchers, nil if
+// there is none
+func (e *Exchange) Span(matchers ...SpanMatcher) sdktrace.ReadOnlySpan {
+	for _, span := range e.Spans {
+		if len(spanMismatches(span, matchers)) == 0 {
+			return span
+		}
+	}
+	return nil
+}
+
+// ServerSpan returns the first server span of the request, nil if the
+// request was not traced or not sampled
+func (e *Exchange) ServerSpan() sdktrace.ReadOnlySpan {
+	return e.Span(WithKind(oteltrace.SpanKindServer))
+}
+
+// RequestOption configures a test request
+type RequestOption func(*requestConfig)
+
+// requestConfig holds the settings of a test request
+type requestConfig struct {
+	parent  bool
+	sampled bool
+}
+
+// WithRemoteParent sends the context of a random remote span with the
+// request through the global propagator, e.g. to verify propagation or
+// parent-based sampling. The parent is reported as Exchange.Parent.
+func WithRemoteParent(sampled bool) RequestOption {
+	return func(c *requestConfig) {
+		c.parent = true
+		c.sampled = sampled
+	}
+}
+
+// prepare applies opts to req and returns the remote parent sent
+func prepare(req *http.Request, opts []RequestOption) oteltrace.SpanContext {
+	cfg := &requestConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if !cfg.parent {
+		return oteltrace.SpanContext{}
+	}
+
+	var traceID oteltrace.TraceID
+	var spanID oteltrace.SpanID
+	_, _ = rand.Read(traceID[:])
+	_, _ = rand.Read(spanID[:])
+	scc := oteltrace.SpanContextConfig{TraceID: traceID, SpanID: spanID, Remote: true}
+	if cfg.sampled {
+		scc.TraceFlags = oteltrace.FlagsSampled
+	}
+	parent := oteltrace.NewSpanContext(scc)
+	ctx := oteltrace.ContextWithRemoteSpanContext(context.Background(), parent)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return parent
+}
+
+// Serve runs req through handler in memory and returns the response with
+// the spans ended meanwhile
+func Serve(t testing.TB, rec *Recorder, handler http.Handler, req *http.Request, opts ...RequestOption) *Exchange {
+	t.Helper()
+	parent := prepare(req, opts)
+	before := len(rec.Spans())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+	return &Exchange{Response: resp, Body: body, Spans: rec.Spans()[before:], Parent: parent}
+}
+
+// Server is an httptest.Server whose requests report the spans ended while
+// they were served. Requests sent through Do are serialized, so the spans
+// of concurrent requests are not mixed up.
+type Server struct {
+	*httptest.Server
+
+	t       testing.TB
+	rec     *Recorder
+	client  *http.Client
+	mu      sync.Mutex
+	next    int
+	pending sync.Map
+}
+
+// NewServer starts a server for handler, closed when the test ends
+func NewServer(t testing.TB, rec *Recorder, handler http.Handler) *Server {
+	t.Helper()
+	s := &Server{t: t, rec: rec}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestHeader)
+		r.Header.Del(requestHeader)
+		if done, ok := s.pending.Load(id); ok {
+			// Middleware ends its span after the response is written, so
+			// the client waits for the handler to return
+			defer close(done.(chan struct{}))
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	// Redirects are returned rather than followed, they are requests of
+	// their own
+	s.client = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Get requests path from the server
+func (s *Server) Get(path string, opts ...RequestOption) *Exchange {
+	s.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, s.URL+path, nil)
+	if err != nil {
+		s.t.Fatalf("failed to create request: %v", err)
+	}
+	return s.Do(req, opts...)
+}
+
+// Do sends req, waits for its handler to return and returns the response
+// with the spans ended meanwhile
+func (s *Server) Do(req *http.Request, opts ...RequestOption) *Exchange {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parent := prepare(req, opts)
+	s.next++
+	id := strconv.Itoa(s.next)
+	done := make(chan struct{})
+	s.pending.Store(id, done)
+	defer s.pending.Delete(id)
+	req.Header.Set(requestHeader, id)
+
+	before := len(s.rec.Spans())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.t.Fatalf("failed to send request: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		s.t.Fatalf("failed to read response: %v", err)
+	}
+	<-done
+
+	return &Exchange{Response: resp, Body: body, Spans: s.rec.Spans()[before:], Parent: parent}
+}
@@ -0,0 +1,70 @@
+package telemetrytest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/instrumentation/router"
+	"go.opentelemetry.io/otel"
+)
+
+// booksHandler serves a traced chi router with a nested span per request
+func booksHandler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(router.Chi())
+	r.Get("/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := otel.Tracer("test").Start(r.Context(), "db.query")
+		span.End()
+		_, _ = w.Write([]byte("book " + chi.URLParam(r, "id")))
+	})
+	return r
+}
+
+func TestServer(t *testing.T) {
+	rec := NewForTest(t)
+	server := NewServer(t, rec, booksHandler())
+
+	first := server.Get("/books/1")
+	if first.Response.StatusCode != http.StatusOK || string(first.Body) != "book 1" {
+		t.Fatalf("Unexpected response %d %q", first.Response.StatusCode, first.Body)
+	}
+	if len(first.Spans) != 2 {
+		t.Fatalf("Expected the server and db spans of the request, got %d", len(first.Spans))
+	}
+	serverSpan := first.ServerSpan()
+	if serverSpan == nil || serverSpan.Name() != "GET /books/{id}" {
+		t.Fatalf("Expected the route span, got %v", serverSpan)
+	}
+	if first.Span(WithName("db.query"), WithParentOf(serverSpan)) == nil {
+		t.Error("Expected the db span below the server span")
+	}
+
+	// Each exchange reports its own spans only
+	second := server.Get("/books/2", WithRemoteParent(true))
+	if len(second.Spans) != 2 {
+		t.Fatalf("Expected 2 spans of the second request, got %d", len(second.Spans))
+	}
+	if got := second.ServerSpan().Parent(); got.SpanID() != second.Parent.SpanID() || got.TraceID() != second.Parent.TraceID() {
+		t.Errorf("Expected the remote parent to be propagated, got parent %v", got.SpanID())
+	}
+
+	// Parent-based sampling drops requests of unsampled traces
+	if unsampled := server.Get("/books/3", WithRemoteParent(false)); len(unsampled.Spans) != 0 {
+		t.Errorf("Expected no spans for an unsampled parent, got %d", len(unsampled.Spans))
+	}
+}
+
+func TestServe(t *testing.T) {
+	rec := NewForTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/books/7", nil)
+
+	ex := Serve(t, rec, booksHandler(), req)
+	if string(ex.Body) != "book 7" {
+		t.Errorf("Unexpected body %q", ex.Body)
+	}
+	if ex.Span(WithName("GET /books/{id}"), WithAttr("http.route", "/books/{id}")) == nil {
+		t.Errorf("Expected the route span, got %d spans", len(ex.Spans))
+	}
+}
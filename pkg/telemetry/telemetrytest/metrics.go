@@ -0,0 +1,159 @@
+package telemetrytest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// MetricExporter keeps the last exported metrics in memory. With the
+// default cumulative temporality, they hold the totals since the start.
+type MetricExporter struct {
+	temporality sdkmetric.TemporalitySelector
+
+	mu      sync.Mutex
+	metrics *metricdata.ResourceMetrics
+}
+
+// NewMetricExporter creates an in-memory metric exporter with cumulative
+// temporality
+func NewMetricExporter() *MetricExporter {
+	return &MetricExporter{temporality: sdkmetric.DefaultTemporalitySelector}
+}
+
+// Temporality returns the temporality of the instrument kind
+func (e *MetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return e.temporality(kind)
+}
+
+// Aggregation returns the default aggregation of the instrument kind
+func (e *MetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+// Export records a deep copy of the metrics, as the reader reuses them
+func (e *MetricExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	copied := copyResourceMetrics(rm)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.metrics = copied
+	return nil
+}
+
+// ForceFlush does nothing, metrics are kept as they are exported
+func (e *MetricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown keeps the recorded metrics
+func (e *MetricExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Metrics returns the last exported metrics, nil if none were exported
+func (e *MetricExporter) Metrics() *metricdata.ResourceMetrics {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.metrics
+}
+
+// Reset discards the recorded metrics
+func (e *MetricExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.metrics = nil
+}
+
+// copyResourceMetrics deep-copies the slices of rm
+func copyResourceMetrics(rm *metricdata.ResourceMetrics) *metricdata.ResourceMetrics {
+	copied := &metricdata.ResourceMetrics{Resource: rm.Resource}
+	for _, sm := range rm.ScopeMetrics {
+		scope := metricdata.ScopeMetrics{Scope: sm.Scope}
+		for _, m := range sm.Metrics {
+			m.Data = copyAggregation(m.Data)
+			scope.Metrics = append(scope.Metrics, m)
+		}
+		copied.ScopeMetrics = append(copied.ScopeMetrics, scope)
+	}
+	return copied
+}
+
+// copyAggregation deep-copies the data points of an aggregation
+func copyAggregation(data metricdata.Aggregation) metricdata.Aggregation {
+	switch d := data.(type) {
+	case metricdata.Gauge[int64]:
+		d.DataPoints = copyDataPoints(d.DataPoints)
+		return d
+	case metricdata.Gauge[float64]:
+		d.DataPoints = copyDataPoints(d.DataPoints)
+		return d
+	case metricdata.Sum[int64]:
+		d.DataPoints = copyDataPoints(d.DataPoints)
+		return d
+	case metricdata.Sum[float64]:
+		d.DataPoints = copyDataPoints(d.DataPoints)
+		return d
+	case metricdata.Histogram[int64]:
+		d.DataPoints = copyHistogramDataPoints(d.DataPoints)
+		return d
+	case metricdata.Histogram[float64]:
+		d.DataPoints = copyHistogramDataPoints(d.DataPoints)
+		return d
+	case metricdata.ExponentialHistogram[int64]:
+		d.DataPoints = copyExponentialDataPoints(d.DataPoints)
+		return d
+	case metricdata.ExponentialHistogram[float64]:
+		d.DataPoints = copyExponentialDataPoints(d.DataPoints)
+		return d
+	case metricdata.Summary:
+		points := slices.Clone(d.DataPoints)
+		for i := range points {
+			points[i].QuantileValues = slices.Clone(points[i].QuantileValues)
+		}
+		d.DataPoints = points
+		return d
+	default:
+		return data
+	}
+}
+
+func copyDataPoints[N int64 | float64](points []metricdata.DataPoint[N]) []metricdata.DataPoint[N] {
+	points = slices.Clone(points)
+	for i := range points {
+		points[i].Exemplars = copyExemplars(points[i].Exemplars)
+	}
+	return points
+}
+
+func copyHistogramDataPoints[N int64 | float64](points []metricdata.HistogramDataPoint[N]) []metricdata.HistogramDataPoint[N] {
+	points = slices.Clone(points)
+	for i := range points {
+		points[i].Bounds = slices.Clone(points[i].Bounds)
+		points[i].BucketCounts = slices.Clone(points[i].BucketCounts)
+		points[i].Exemplars = copyExemplars(points[i].Exemplars)
+	}
+	return points
+}
+
+func copyExponentialDataPoints[N int64 | float64](points []metricdata.ExponentialHistogramDataPoint[N]) []metricdata.ExponentialHistogramDataPoint[N] {
+	points = slices.Clone(points)
+	for i := range points {
+		points[i].PositiveBucket.Counts = slices.Clone(points[i].PositiveBucket.Counts)
+		points[i].NegativeBucket.Counts = slices.Clone(points[i].NegativeBucket.Counts)
+		points[i].Exemplars = copyExemplars(points[i].Exemplars)
+	}
+	return points
+}
+
+func copyExemplars[N int64 | float64](exemplars []metricdata.Exemplar[N]) []metricdata.Exemplar[N] {
+	exemplars = slices.Clone(exemplars)
+	for i := range exemplars {
+		exemplars[i].FilteredAttributes = slices.Clone(exemplars[i].FilteredAttributes)
+		exemplars[i].SpanID = slices.Clone(exemplars[i].SpanID)
+		exemplars[i].TraceID = slices.Clone(exemplars[i].TraceID)
+	}
+	return exemplars
+}
@@ -0,0 +1,155 @@
+// Package telemetrytest records the telemetry of an application in memory,
+// so unit tests can assert on emitted spans, metrics and log records:
+//
+//	func TestCheckout(t *testing.T) {
+//		rec := telemetrytest.NewForTest(t)
+//
+//		checkout(context.Background())
+//
+//		if spans := rec.Spans(); len(spans) != 1 || spans[0].Name() != "checkout" {
+//			t.Errorf("Expected a checkout span, got %v", spans)
+//		}
+//	}
+//
+// NewForTest runs the same pipeline as telemetry.New, with the configured
+// exporters replaced by in-memory ones. The providers are installed as the
+// global ones for the duration of the test, so tests using NewForTest must
+// not run in parallel with each other.
+package telemetrytest
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Recorder is a telemetry instance exporting into memory
+type Recorder struct {
+	*telemetry.Telemetry
+
+	spans   *SpanExporter
+	metrics *MetricExporter
+	logs    *LogExporter
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// NewForTest creates a telemetry instance recording all signals in memory
+// and shuts it down when the test ends, restoring the previous global
+// providers. By default spans are exported as they end, logs of all levels
+// except the startup report are recorded and host and runtime metrics are
+// off; pass
+// telemetry.WithConfig to change that. The configured exporters are always
+// replaced.
+func NewForTest(t testing.TB, opts ...telemetry.Option) *Recorder {
+	t.Helper()
+
+	cfg, err := config.NewBuilder().
+		ServiceName("test").
+		Disabled(false).
+		Tracing(config.WithSpanProcessor("simple")).
+		Metrics(config.WithHostMetrics(false), config.WithRuntimeMetrics(false)).
+		Logging(config.WithLogLevel("trace"), config.WithStartupReport(false)).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build test configuration: %v", err)
+	}
+
+	r := &Recorder{
+		spans:   NewSpanExporter(),
+		metrics: NewMetricExporter(),
+		logs:    NewLogExporter(),
+	}
+
+	// Restore the global providers the instance replaces
+	tracerProvider, meterProvider := otel.GetTracerProvider(), otel.GetMeterProvider()
+	loggerProvider, propagator := global.GetLoggerProvider(), otel.GetTextMapPropagator()
+
+	opts = append([]telemetry.Option{
+		telemetry.WithConfig(cfg),
+		telemetry.WithLogger(log.New(testWriter{t}, "[telemetry] ", 0)),
+	}, opts...)
+	opts = append(opts,
+		telemetry.WithSpanExporter(r.spans),
+		telemetry.WithMetricExporter(r.metrics),
+		telemetry.WithLogExporter(r.logs),
+	)
+	r.Telemetry, err = telemetry.New(opts...)
+	if err != nil {
+		t.Fatalf("failed to initialize telemetry: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := r.Shutdown(context.Background()); err != nil {
+			t.Errorf("failed to shutdown telemetry: %v", err)
+		}
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetMeterProvider(meterProvider)
+		global.SetLoggerProvider(loggerProvider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return r
+}
+
+// Spans flushes pending spans and returns all recorded spans
+func (r *Recorder) Spans() []sdktrace.ReadOnlySpan {
+	if tp := r.TracerProvider(); tp != nil {
+		_ = tp.ForceFlush(context.Background())
+	}
+	return r.spans.Spans()
+}
+
+// Metrics collects the metrics and returns them, nil if metrics are disabled
+func (r *Recorder) Metrics() *metricdata.ResourceMetrics {
+	if mp := r.MeterProvider(); mp != nil {
+		_ = mp.ForceFlush(context.Background())
+	}
+	return r.metrics.Metrics()
+}
+
+// Logs flushes pending log records and returns all recorded records
+func (r *Recorder) Logs() []sdklog.Record {
+	if lp := r.LoggerProvider(); lp != nil {
+		_ = lp.ForceFlush(context.Background())
+	}
+	return r.logs.Records()
+}
+
+// Reset discards the spans and log records recorded so far. Cumulative
+// metrics keep their totals.
+func (r *Recorder) Reset() {
+	r.Spans()
+	r.Logs()
+	r.spans.Reset()
+	r.logs.Reset()
+	r.metrics.Reset()
+}
+
+// Shutdown shuts down the telemetry instance once; the recorded telemetry
+// stays available
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	r.shutdownOnce.Do(func() {
+		r.shutdownErr = r.Telemetry.Shutdown(ctx)
+	})
+	return r.shutdownErr
+}
+
+// testWriter writes the messages of the telemetry logger to the test log
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,86 @@
+package telemetrytest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewForTest(t *testing.T) {
+	rec := NewForTest(t)
+	ctx := context.Background()
+
+	_, span := otel.Tracer("test").Start(ctx, "checkout")
+	span.SetAttributes(attribute.String("order.id", "42"))
+	span.End()
+
+	counter, _ := otel.Meter("test").Int64Counter("orders")
+	counter.Add(ctx, 2, metric.WithAttributes(attribute.String("status", "ok")))
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String("status", "ok")))
+
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("order placed"))
+	record.SetSeverity(otellog.SeverityDebug)
+	global.GetLoggerProvider().Logger("test").Emit(ctx, record)
+
+	spans := rec.Spans()
+	if len(spans) != 1 || spans[0].Name() != "checkout" {
+		t.Fatalf("Expected the checkout span, got %v", spans)
+	}
+
+	rm := rec.Metrics()
+	if rm == nil || len(rm.ScopeMetrics) != 1 {
+		t.Fatalf("Expected the test meter's metrics, got %+v", rm)
+	}
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 3 {
+		t.Errorf("Expected a sum of 3 orders, got %+v", rm.ScopeMetrics[0].Metrics[0].Data)
+	}
+
+	logs := rec.Logs()
+	if len(logs) != 1 || logs[0].Body().AsString() != "order placed" {
+		t.Fatalf("Expected the debug record, got %v", logs)
+	}
+
+	rec.Reset()
+	if len(rec.Spans()) != 0 || len(rec.Logs()) != 0 {
+		t.Error("Expected Reset to discard spans and logs")
+	}
+}
+
+func TestNewForTestRestoresGlobals(t *testing.T) {
+	before := otel.GetTracerProvider()
+	t.Run("recorder", func(t *testing.T) {
+		rec := NewForTest(t)
+		if otel.GetTracerProvider() != rec.TracerProvider() {
+			t.Error("Expected the recorder's tracer provider to be global")
+		}
+	})
+	if otel.GetTracerProvider() != before {
+		t.Error("Expected the previous tracer provider to be restored")
+	}
+}
+
+func TestMetricExporterCopies(t *testing.T) {
+	exporter := NewMetricExporter()
+	rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{{
+			Name: "orders",
+			Data: metricdata.Sum[int64]{DataPoints: []metricdata.DataPoint[int64]{{Value: 1}}},
+		}},
+	}}}
+	_ = exporter.Export(context.Background(), rm)
+
+	// The reader reuses the data points of the next collection
+	rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints[0].Value = 5
+	recorded := exporter.Metrics().ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	if recorded.DataPoints[0].Value != 1 {
+		t.Errorf("Expected the recorded value to be kept, got %d", recorded.DataPoints[0].Value)
+	}
+}
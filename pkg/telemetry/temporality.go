@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// temporalitySelector translates metrics.config.temporality, using the
+// OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE values. Backends such as
+// Dynatrace only accept delta temporality.
+func temporalitySelector(name string) (metric.TemporalitySelector, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "cumulative":
+		return metric.DefaultTemporalitySelector, nil
+	case "delta":
+		// Up-down counters stay cumulative, their deltas are meaningless
+		return func(kind metric.InstrumentKind) metricdata.Temporality {
+			switch kind {
+			case metric.InstrumentKindUpDownCounter, metric.InstrumentKindObservableUpDownCounter:
+				return metricdata.CumulativeTemporality
+			default:
+				return metricdata.DeltaTemporality
+			}
+		}, nil
+	case "lowmemory":
+		// Only synchronous counters and histograms use delta
+		return func(kind metric.InstrumentKind) metricdata.Temporality {
+			switch kind {
+			case metric.InstrumentKindCounter, metric.InstrumentKindHistogram:
+				return metricdata.DeltaTemporality
+			default:
+				return metricdata.CumulativeTemporality
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported metric temporality: %s", name)
+	}
+}
+
+// aggregationSelector translates metrics.config.histogram_aggregation
+func aggregationSelector(name string) (metric.AggregationSelector, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "explicit_bucket_histogram":
+		return metric.DefaultAggregationSelector, nil
+	case "base2_exponential_bucket_histogram":
+		return func(kind metric.InstrumentKind) metric.Aggregation {
+			if kind == metric.InstrumentKindHistogram {
+				return metric.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}
+			}
+			return metric.DefaultAggregationSelector(kind)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported histogram aggregation: %s", name)
+	}
+}
@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTemporalitySelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     metric.InstrumentKind
+		expected metricdata.Temporality
+	}{
+		{"cumulative", metric.InstrumentKindCounter, metricdata.CumulativeTemporality},
+		{"delta", metric.InstrumentKindCounter, metricdata.DeltaTemporality},
+		{"delta", metric.InstrumentKindObservableCounter, metricdata.DeltaTemporality},
+		{"delta", metric.InstrumentKindUpDownCounter, metricdata.CumulativeTemporality},
+		{"lowmemory", metric.InstrumentKindHistogram, metricdata.DeltaTemporality},
+		{"lowmemory", metric.InstrumentKindObservableCounter, metricdata.CumulativeTemporality},
+	}
+
+	for _, tt := range tests {
+		selector, err := temporalitySelector(tt.name)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", tt.name, err)
+		}
+		if got := selector(tt.kind); got != tt.expected {
+			t.Errorf("Expected %v for %s/%v, got %v", tt.expected, tt.name, tt.kind, got)
+		}
+	}
+
+	if _, err := temporalitySelector("sometimes"); err == nil {
+		t.Error("Expected error for unknown temporality")
+	}
+}
+
+func TestAggregationSelector(t *testing.T) {
+	selector, err := aggregationSelector("base2_exponential_bucket_histogram")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := selector(metric.InstrumentKindHistogram).(metric.AggregationBase2ExponentialHistogram); !ok {
+		t.Error("Expected exponential histogram aggregation for histograms")
+	}
+	if _, ok := selector(metric.InstrumentKindCounter).(metric.AggregationSum); !ok {
+		t.Error("Expected sum aggregation for counters")
+	}
+
+	if _, err := aggregationSelector("tdigest"); err == nil {
+		t.Error("Expected error for unknown aggregation")
+	}
+}
@@ -0,0 +1,30 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WithTenant returns a context carrying the tenant ID. When tenant.enabled
+// is set, spans and log records created from it carry sap.tenant_id; use
+// TenantMeasurementOption to add it to custom metrics.
+func WithTenant(ctx context.Context, id string) context.Context {
+	return tenant.ContextWithID(ctx, id)
+}
+
+// TenantFromContext returns the tenant ID set by WithTenant
+func TenantFromContext(ctx context.Context) string {
+	return tenant.FromContext(ctx)
+}
+
+// TenantMeasurementOption adds the tenant of ctx to a measurement, capped at
+// tenant.max_tenants, e.g. counter.Add(ctx, 1, tel.TenantMeasurementOption(ctx)).
+// It adds nothing unless tenant.enabled is set.
+func (t *Telemetry) TenantMeasurementOption(ctx context.Context) metric.MeasurementOption {
+	if t.tenantLimiter == nil {
+		return metric.WithAttributes()
+	}
+	return t.tenantLimiter.MeasurementOption(ctx)
+}
@@ -0,0 +1,98 @@
+// Package tenant stamps the tenant of multitenant CAP applications onto
+// spans, log records and metrics as sap.tenant_id, so telemetry can be
+// sliced by tenant.
+//
+// The tenant is carried in the baggage, so it also reaches downstream
+// services when the baggage propagator is installed.
+package tenant
+
+import (
+	"context"
+	"sync"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/internal/baggageattr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// Key is the baggage member and attribute key holding the tenant ID
+	Key = "sap.tenant_id"
+
+	// Other replaces tenant IDs in metrics once the tenant limit is reached
+	Other = "_other"
+)
+
+// FromContext returns the tenant ID carried in the baggage of ctx
+func FromContext(ctx context.Context) string {
+	return baggageattr.FromContext(ctx, Key)
+}
+
+// ContextWithID stores the tenant ID in the baggage of ctx
+func ContextWithID(ctx context.Context, id string) context.Context {
+	return baggageattr.ContextWithValue(ctx, Key, id)
+}
+
+// Limiter caps the number of distinct tenant IDs recorded in metrics.
+// The first tenants seen keep their ID, later ones are recorded as Other.
+type Limiter struct {
+	max  int
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+// NewLimiter creates a limiter admitting max tenants; 0 means unlimited
+func NewLimiter(max int) *Limiter {
+	return &Limiter{max: max, seen: make(map[string]struct{})}
+}
+
+// Limit returns id, or Other once the limit is reached for new tenants
+func (l *Limiter) Limit(id string) string {
+	if l == nil || l.max <= 0 {
+		return id
+	}
+
+	l.mu.RLock()
+	_, ok := l.seen[id]
+	l.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[id]; ok {
+		return id
+	}
+	if len(l.seen) >= l.max {
+		return Other
+	}
+	l.seen[id] = struct{}{}
+	return id
+}
+
+// MetricAttributes returns the sap.tenant_id attribute of ctx for metric
+// measurements, subject to the limit, or nil without a tenant. A nil
+// limiter admits all tenants.
+func (l *Limiter) MetricAttributes(ctx context.Context) []attribute.KeyValue {
+	id := FromContext(ctx)
+	if id == "" {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String(Key, l.Limit(id))}
+}
+
+// MeasurementOption adds the tenant of ctx to a measurement, e.g.
+// counter.Add(ctx, 1, limiter.MeasurementOption(ctx))
+func (l *Limiter) MeasurementOption(ctx context.Context) metric.MeasurementOption {
+	return metric.WithAttributes(l.MetricAttributes(ctx)...)
+}
+
+// Processor stamps the tenant of the current context onto spans (on
+// start) and log records (on emit). It is both a span and a log processor.
+type Processor = baggageattr.Processor
+
+// NewProcessor creates a tenant stamping processor
+func NewProcessor() Processor {
+	return baggageattr.NewProcessor(Key)
+}
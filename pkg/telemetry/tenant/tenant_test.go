@@ -0,0 +1,87 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProcessor(t *testing.T) {
+	ctx := ContextWithID(context.Background(), "t1")
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(NewProcessor()), sdktrace.WithSyncer(exporter))
+	_, span := tp.Tracer("test").Start(ctx, "op")
+	span.End()
+
+	found := false
+	for _, kv := range exporter.GetSpans()[0].Attributes {
+		if kv.Key == Key && kv.Value.AsString() == "t1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected sap.tenant_id span attribute")
+	}
+
+	var got string
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(NewProcessor()), sdklog.WithProcessor(inspect(func(r *sdklog.Record) {
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == Key {
+				got = kv.Value.AsString()
+			}
+			return true
+		})
+	})))
+	lp.Logger("test").Emit(ctx, log.Record{})
+	if got != "t1" {
+		t.Errorf("Expected sap.tenant_id log attribute, got %q", got)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2)
+
+	for _, id := range []string{"t1", "t2", "t1"} {
+		if got := l.Limit(id); got != id {
+			t.Errorf("Expected %s to be admitted, got %s", id, got)
+		}
+	}
+	if got := l.Limit("t3"); got != Other {
+		t.Errorf("Expected %s beyond the limit, got %s", Other, got)
+	}
+
+	if got := NewLimiter(0).Limit("t3"); got != "t3" {
+		t.Error("Expected unlimited limiter to admit all tenants")
+	}
+}
+
+func TestMetricAttributes(t *testing.T) {
+	l := NewLimiter(1)
+
+	if attrs := l.MetricAttributes(context.Background()); attrs != nil {
+		t.Errorf("Expected no attributes without tenant, got %v", attrs)
+	}
+	l.MetricAttributes(ContextWithID(context.Background(), "t1"))
+	attrs := l.MetricAttributes(ContextWithID(context.Background(), "t2"))
+	if len(attrs) != 1 || attrs[0].Value.AsString() != Other {
+		t.Errorf("Expected %s for second tenant, got %v", Other, attrs)
+	}
+
+	var unlimited *Limiter
+	attrs = unlimited.MetricAttributes(ContextWithID(context.Background(), "t2"))
+	if len(attrs) != 1 || attrs[0].Value.AsString() != "t2" {
+		t.Errorf("Expected a nil limiter to admit all tenants, got %v", attrs)
+	}
+}
+
+// inspect is a log processor calling fn for every record
+type inspect func(*sdklog.Record)
+
+func (f inspect) OnEmit(_ context.Context, r *sdklog.Record) error { f(r); return nil }
+func (f inspect) Shutdown(context.Context) error                   { return nil }
+func (f inspect) ForceFlush(context.Context) error                 { return nil }
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// tenantOf returns the tenant attribute the measurement option adds
+func tenantOf(opt metric.MeasurementOption) string {
+	attrs := metric.NewAddConfig([]metric.AddOption{opt}).Attributes()
+	v, _ := attrs.Value(attribute.Key(tenant.Key))
+	return v.AsString()
+}
+
+func TestTenantMeasurementOption(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Tenant = &config.TenantConfig{Enabled: true, MaxTenants: 1}
+	tel := &Telemetry{config: cfg, tenantLimiter: tenant.NewLimiter(cfg.Tenant.GetMaxTenants())}
+
+	if got := tenantOf(tel.TenantMeasurementOption(WithTenant(context.Background(), "t1"))); got != "t1" {
+		t.Errorf("Expected the first tenant, got %q", got)
+	}
+	if got := tenantOf(tel.TenantMeasurementOption(WithTenant(context.Background(), "t2"))); got != tenant.Other {
+		t.Errorf("Expected %s beyond the limit, got %q", tenant.Other, got)
+	}
+
+	// The limit belongs to the instance
+	other := &Telemetry{config: cfg, tenantLimiter: tenant.NewLimiter(cfg.Tenant.GetMaxTenants())}
+	if got := tenantOf(other.TenantMeasurementOption(WithTenant(context.Background(), "t2"))); got != "t2" {
+		t.Errorf("Expected another instance to have its own limit, got %q", got)
+	}
+
+	disabled := &Telemetry{config: config.NewDefaultConfig()}
+	if got := tenantOf(disabled.TenantMeasurementOption(WithTenant(context.Background(), "t1"))); got != "" {
+		t.Errorf("Expected no tenant attribute unless tenant is enabled, got %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// DurationBuckets are the default bucket boundaries in seconds of timer
+// histograms, those of the HTTP duration semantic conventions
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// Timer measures a duration into a histogram in seconds
+type Timer struct {
+	histogram *HistogramInstrument
+	start     time.Time
+	stopped   atomic.Bool
+}
+
+// StartTimer starts measuring into the histogram name, created on first
+// use with unit "s" and DurationBuckets unless opts override them:
+//
+//	timer := telemetry.StartTimer("checkout.duration")
+//	defer timer.Stop(ctx, attribute.String("payment", "card"))
+func StartTimer(name string, opts ...metric.Float64HistogramOption) *Timer {
+	opts = append([]metric.Float64HistogramOption{
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(DurationBuckets...),
+	}, opts...)
+	return &Timer{histogram: Histogram(name, opts...), start: time.Now()}
+}
+
+// Stop records the duration since the timer started and returns it. Only
+// the first call records.
+func (t *Timer) Stop(ctx context.Context, attrs ...attribute.KeyValue) time.Duration {
+	elapsed := time.Since(t.start)
+	if t.stopped.CompareAndSwap(false, true) {
+		t.histogram.Record(ctx, elapsed.Seconds(), attrs...)
+	}
+	return elapsed
+}
+
+// Measure runs fn and records its duration into the histogram name as
+// StartTimer does. If fn fails, the duration gets the error.type of its
+// error.
+func Measure(ctx context.Context, name string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
+	timer := StartTimer(name)
+	err := fn(ctx)
+	if err != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], semconv.ErrorTypeKey.String(ErrorType(err)))
+	}
+	timer.Stop(ctx, attrs...)
+	return err
+}
@@ -0,0 +1,66 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestTimer(t *testing.T) {
+	reader := recordGlobalMetrics(t)
+	ctx := context.Background()
+
+	timer := StartTimer("checkout.duration")
+	time.Sleep(5 * time.Millisecond)
+	elapsed := timer.Stop(ctx, attribute.String("payment", "card"))
+	timer.Stop(ctx, attribute.String("payment", "card"))
+	if elapsed < 5*time.Millisecond {
+		t.Errorf("Expected at least 5ms, got %v", elapsed)
+	}
+
+	histogram := collectMetrics(t, reader)["checkout.duration"].(metricdata.Histogram[float64])
+	dp := histogram.DataPoints[0]
+	if dp.Count != 1 {
+		t.Errorf("Expected a single measurement, got %d", dp.Count)
+	}
+	if dp.Sum != elapsed.Seconds() {
+		t.Errorf("Expected %v seconds, got %v", elapsed.Seconds(), dp.Sum)
+	}
+	if len(dp.Bounds) != len(DurationBuckets) {
+		t.Errorf("Expected the default buckets, got %v", dp.Bounds)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	reader := recordGlobalMetrics(t)
+	ctx := context.Background()
+
+	_ = Measure(ctx, "import.duration", func(ctx context.Context) error { return nil })
+	if err := Measure(ctx, "import.duration", func(ctx context.Context) error {
+		return context.DeadlineExceeded
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the error of fn, got %v", err)
+	}
+
+	histogram := collectMetrics(t, reader)["import.duration"].(metricdata.Histogram[float64])
+	if len(histogram.DataPoints) != 2 {
+		t.Fatalf("Expected a data point for success and failure, got %d", len(histogram.DataPoints))
+	}
+	var failures int
+	for _, dp := range histogram.DataPoints {
+		if v, ok := dp.Attributes.Value(semconv.ErrorTypeKey); ok {
+			failures++
+			if v.AsString() != ErrorTypeTimeout {
+				t.Errorf("Expected error.type %q, got %q", ErrorTypeTimeout, v.AsString())
+			}
+		}
+	}
+	if failures != 1 {
+		t.Errorf("Expected 1 failed data point, got %d", failures)
+	}
+}
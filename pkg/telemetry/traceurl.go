@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// TraceURL renders the tracing.trace_url template for traceID, e.g. to link
+// the trace from error responses and logs. It returns "" without a template
+// or for an invalid trace ID.
+func (t *Telemetry) TraceURL(traceID oteltrace.TraceID) string {
+	if t.config.Tracing == nil || t.config.Tracing.TraceURL == "" || !traceID.IsValid() {
+		return ""
+	}
+	return strings.NewReplacer(
+		config.TraceIDPlaceholder, traceID.String(),
+		config.ServiceNamePlaceholder, url.PathEscape(t.config.ServiceName),
+	).Replace(t.config.Tracing.TraceURL)
+}
+
+// TraceURLFromContext renders the trace URL of the span of ctx
+func (t *Telemetry) TraceURLFromContext(ctx context.Context) string {
+	return t.TraceURL(oteltrace.SpanContextFromContext(ctx).TraceID())
+}
@@ -0,0 +1,37 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceURL(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceName = "book shop"
+	tel := &Telemetry{config: cfg}
+	traceID := oteltrace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+
+	if got := tel.TraceURL(traceID); got != "" {
+		t.Errorf("Expected no URL without a template, got %q", got)
+	}
+
+	cfg.Tracing.TraceURL = "https://grafana.example.com/explore?service={service_name}&traceId={trace_id}"
+	want := "https://grafana.example.com/explore?service=book%20shop&traceId=4bf92f3577b34da6a3ce929d0e0e4736"
+	if got := tel.TraceURL(traceID); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := tel.TraceURL(oteltrace.TraceID{}); got != "" {
+		t.Errorf("Expected no URL for an invalid trace ID, got %q", got)
+	}
+
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  oteltrace.SpanID{1},
+	}))
+	if got := tel.TraceURLFromContext(ctx); got != want {
+		t.Errorf("Expected %q from the context, got %q", want, got)
+	}
+}
@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// createViews translates the metrics.views config section into SDK views
+func createViews(cfgs []*config.MetricViewConfig) ([]metric.View, error) {
+	views := make([]metric.View, 0, len(cfgs))
+	for i, vc := range cfgs {
+		if vc == nil {
+			continue
+		}
+		view, err := createView(vc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric view %d (%s): %w", i, vc.Instrument, err)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// createView translates a single view
+func createView(vc *config.MetricViewConfig) (metric.View, error) {
+	if vc.Instrument == "" {
+		return nil, fmt.Errorf("instrument is required")
+	}
+	if vc.Name != "" && strings.ContainsAny(vc.Instrument, "*?") {
+		return nil, fmt.Errorf("cannot rename instruments matched by a wildcard")
+	}
+	if len(vc.AttributeKeys) > 0 && len(vc.DropAttributes) > 0 {
+		return nil, fmt.Errorf("attribute_keys and drop_attributes are mutually exclusive")
+	}
+
+	criteria := metric.Instrument{
+		Name:  vc.Instrument,
+		Scope: instrumentation.Scope{Name: vc.Meter},
+	}
+
+	stream := metric.Stream{
+		Name:        vc.Name,
+		Description: vc.Description,
+	}
+
+	switch {
+	case len(vc.AttributeKeys) > 0:
+		stream.AttributeFilter = attribute.NewAllowKeysFilter(toKeys(vc.AttributeKeys)...)
+	case len(vc.DropAttributes) > 0:
+		stream.AttributeFilter = attribute.NewDenyKeysFilter(toKeys(vc.DropAttributes)...)
+	}
+
+	switch {
+	case vc.Drop:
+		stream.Aggregation = metric.AggregationDrop{}
+	case len(vc.Buckets) > 0:
+		stream.Aggregation = metric.AggregationExplicitBucketHistogram{Boundaries: vc.Buckets}
+	}
+
+	return metric.NewView(criteria, stream), nil
+}
+
+// toKeys converts strings into attribute keys
+func toKeys(keys []string) []attribute.Key {
+	out := make([]attribute.Key, len(keys))
+	for i, k := range keys {
+		out[i] = attribute.Key(k)
+	}
+	return out
+}
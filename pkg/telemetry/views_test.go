@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/config"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestCreateViews(t *testing.T) {
+	views, err := createViews([]*config.MetricViewConfig{
+		{Instrument: "http.server.duration", Name: "http.server.request.duration", Buckets: []float64{10, 100}},
+		{Instrument: "orders.*", DropAttributes: []string{"user.id"}},
+		{Instrument: "noisy", Drop: true},
+	})
+	if err != nil {
+		t.Fatalf("createViews failed: %v", err)
+	}
+
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(metric.WithReader(reader), metric.WithView(views...))
+	meter := mp.Meter("test")
+
+	hist, _ := meter.Float64Histogram("http.server.duration")
+	hist.Record(context.Background(), 42)
+	counter, _ := meter.Int64Counter("orders.created")
+	counter.Add(context.Background(), 1, otelmetric.WithAttributes(attribute.String("user.id", "u1"), attribute.String("tenant", "t1")))
+	noisy, _ := meter.Int64Counter("noisy")
+	noisy.Add(context.Background(), 1)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	metrics := map[string]metricdata.Metrics{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+
+	if _, ok := metrics["noisy"]; ok {
+		t.Error("Expected dropped instrument not to be exported")
+	}
+	renamed, ok := metrics["http.server.request.duration"]
+	if !ok {
+		t.Fatal("Expected renamed histogram")
+	}
+	if bounds := renamed.Data.(metricdata.Histogram[float64]).DataPoints[0].Bounds; len(bounds) != 2 {
+		t.Errorf("Expected 2 explicit bucket boundaries, got %v", bounds)
+	}
+	attrs := metrics["orders.created"].Data.(metricdata.Sum[int64]).DataPoints[0].Attributes
+	if _, ok := attrs.Value("user.id"); ok {
+		t.Error("Expected user.id attribute to be dropped")
+	}
+	if _, ok := attrs.Value("tenant"); !ok {
+		t.Error("Expected tenant attribute to be kept")
+	}
+}
+
+func TestCreateViews_Invalid(t *testing.T) {
+	if _, err := createViews([]*config.MetricViewConfig{{Instrument: "http.*", Name: "renamed"}}); err == nil {
+		t.Error("Expected error renaming a wildcard view")
+	}
+	if _, err := createViews([]*config.MetricViewConfig{{Name: "renamed"}}); err == nil {
+		t.Error("Expected error without instrument")
+	}
+}
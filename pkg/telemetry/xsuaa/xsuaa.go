@@ -0,0 +1,254 @@
+// Package xsuaa attributes requests to the user and tenant of their XSUAA
+// JWT, so traces can be filtered by user or zone without exposing
+// personal data.
+//
+// User IDs, names and emails are pseudonymized with HMAC-SHA256. Without a
+// key set by WithHashKey, a random key is generated per process, so the
+// same user only maps to the same value within one process.
+//
+// The middleware only decodes the token, it does not verify it. Install it
+// behind the authentication middleware that validates the token.
+package xsuaa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Claims of XSUAA tokens
+const (
+	ClaimUserID   = "user_id"
+	ClaimUserName = "user_name"
+	ClaimEmail    = "email"
+	ClaimZoneID   = "zid"
+	ClaimScope    = "scope"
+	ClaimClientID = "client_id"
+	ClaimOrigin   = "origin"
+)
+
+// Attribute keys the well-known claims are recorded as; other claims are
+// recorded as xsuaa.<claim>
+const (
+	UserIDKey = attribute.Key("enduser.id")
+	ScopeKey  = attribute.Key("enduser.scope")
+)
+
+// DefaultClaims are recorded unless WithClaims is used
+var DefaultClaims = []string{ClaimUserID, ClaimZoneID, ClaimScope}
+
+// DefaultHashedClaims identify a person and are hashed unless WithHashedClaims is used
+var DefaultHashedClaims = []string{ClaimUserID, ClaimUserName, ClaimEmail}
+
+// config holds the middleware settings
+type config struct {
+	claims  []string
+	hashed  map[string]bool
+	key     []byte
+	baggage bool
+}
+
+// Option configures the middleware
+type Option func(*config)
+
+// WithClaims sets the allow-list of claims recorded on the span
+func WithClaims(claims ...string) Option {
+	return func(c *config) {
+		c.claims = claims
+	}
+}
+
+// WithHashedClaims sets the claims recorded as HMAC-SHA256 instead of in clear
+func WithHashedClaims(claims ...string) Option {
+	return func(c *config) {
+		c.hashed = make(map[string]bool, len(claims))
+		for _, claim := range claims {
+			c.hashed[claim] = true
+		}
+	}
+}
+
+// WithHashKey sets the HMAC key of hashed claims. Share it between the
+// instances of an application so a user maps to the same value across them
+// and restarts; keep it secret, as anyone with the key can match the
+// values against a list of known user IDs. An empty key keeps the random
+// per-process key.
+func WithHashKey(key []byte) Option {
+	return func(c *config) {
+		c.key = key
+	}
+}
+
+// WithHashSalt sets the HMAC key of hashed claims from a string.
+//
+// Deprecated: use WithHashKey.
+func WithHashSalt(salt string) Option {
+	return WithHashKey([]byte(salt))
+}
+
+// processKey is the HMAC key used when none is configured
+var processKey = sync.OnceValue(func() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("xsuaa: failed to generate hash key: %v", err))
+	}
+	return key
+})
+
+// WithoutBaggage keeps the user and zone out of the baggage, so they are
+// not propagated to downstream services
+func WithoutBaggage() Option {
+	return func(c *config) {
+		c.baggage = false
+	}
+}
+
+// Middleware records the allow-listed claims of the bearer token on the
+// active span. The user ID (enduser.id) and zone (sap.tenant_id) are also
+// put into the baggage. Requests without a decodable token pass unchanged.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	cfg := &config{claims: DefaultClaims, baggage: true}
+	WithHashedClaims(DefaultHashedClaims...)(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if len(cfg.key) == 0 {
+		cfg.key = processKey()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := ParseClaims(r.Header.Get("Authorization"))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			trace.SpanFromContext(ctx).SetAttributes(cfg.attributes(claims)...)
+
+			if cfg.baggage {
+				if zone, ok := claims[ClaimZoneID].(string); ok && zone != "" && cfg.allowed(ClaimZoneID) {
+					ctx = tenant.ContextWithID(ctx, zone)
+				}
+				if user, ok := claims[ClaimUserID].(string); ok && user != "" && cfg.allowed(ClaimUserID) {
+					if member, err := baggage.NewMemberRaw(string(UserIDKey), cfg.value(ClaimUserID, user)); err == nil {
+						if bag, err := baggage.FromContext(ctx).SetMember(member); err == nil {
+							ctx = baggage.ContextWithBaggage(ctx, bag)
+						}
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ParseClaims decodes the payload of a bearer token without verifying it
+func ParseClaims(authorization string) (map[string]interface{}, error) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return nil, fmt.Errorf("no bearer token")
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse token payload: %w", err)
+	}
+	return claims, nil
+}
+
+// allowed reports whether the claim is on the allow-list
+func (c *config) allowed(claim string) bool {
+	for _, a := range c.claims {
+		if a == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// attributes converts the allow-listed claims into span attributes
+func (c *config) attributes(claims map[string]interface{}) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(c.claims))
+	for _, claim := range c.claims {
+		raw, ok := claims[claim]
+		if !ok {
+			continue
+		}
+
+		if claim == ClaimScope {
+			if scopes := strings.Join(stringSlice(raw), " "); scopes != "" {
+				attrs = append(attrs, ScopeKey.String(scopes))
+			}
+			continue
+		}
+
+		value := fmt.Sprint(raw)
+		if value == "" {
+			continue
+		}
+		attrs = append(attrs, attributeKey(claim).String(c.value(claim, value)))
+	}
+	return attrs
+}
+
+// value hashes the claim value with the HMAC key if configured
+func (c *config) value(claim, value string) string {
+	if !c.hashed[claim] {
+		return value
+	}
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// attributeKey maps a claim to its attribute key
+func attributeKey(claim string) attribute.Key {
+	switch claim {
+	case ClaimUserID:
+		return UserIDKey
+	case ClaimZoneID:
+		return tenant.Key
+	default:
+		return attribute.Key("xsuaa." + claim)
+	}
+}
+
+// stringSlice converts a JSON array or space separated string into strings
+func stringSlice(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
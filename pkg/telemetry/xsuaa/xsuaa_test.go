@@ -0,0 +1,131 @@
+package xsuaa
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iklimetscisco/cap-go-telemetry/pkg/telemetry/tenant"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// token builds an unsigned JWT with the given payload
+func token(payload string) string {
+	enc := base64.RawURLEncoding
+	return "Bearer " + enc.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+
+	var inner context.Context
+	handler := Middleware(WithClaims(ClaimUserID, ClaimZoneID, ClaimScope, ClaimOrigin))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { inner = r.Context() }))
+
+	ctx, span := tracer.Start(context.Background(), "GET")
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.Header.Set("Authorization", token(`{"user_id":"u-1","email":"a@example.com","zid":"zone-1","scope":["app.Read","app.Write"],"origin":"sap.default"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	span.End()
+
+	values := map[string]string{}
+	for _, kv := range exporter.GetSpans()[0].Attributes {
+		values[string(kv.Key)] = kv.Value.AsString()
+	}
+	if v := values["enduser.id"]; v == "" || v == "u-1" {
+		t.Errorf("Expected hashed user ID, got %q", v)
+	}
+	if values[tenant.Key] != "zone-1" {
+		t.Errorf("Expected zone as tenant, got %q", values[tenant.Key])
+	}
+	if values["enduser.scope"] != "app.Read app.Write" {
+		t.Errorf("Expected scopes, got %q", values["enduser.scope"])
+	}
+	if values["xsuaa.origin"] != "sap.default" {
+		t.Errorf("Expected origin claim, got %q", values["xsuaa.origin"])
+	}
+	if _, ok := values["xsuaa.email"]; ok {
+		t.Error("Expected email not to be recorded without allow-listing")
+	}
+
+	if tenant.FromContext(inner) != "zone-1" {
+		t.Error("Expected zone in baggage")
+	}
+	if baggage.FromContext(inner).Member("enduser.id").Value() != values["enduser.id"] {
+		t.Error("Expected hashed user ID in baggage")
+	}
+}
+
+// hashedUserID returns the enduser.id recorded by a middleware with opts
+func hashedUserID(t *testing.T, opts ...Option) string {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+	handler := Middleware(opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	ctx, span := tracer.Start(context.Background(), "GET")
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.Header.Set("Authorization", token(`{"user_id":"u-1"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	span.End()
+
+	for _, kv := range exporter.GetSpans()[0].Attributes {
+		if kv.Key == UserIDKey {
+			return kv.Value.AsString()
+		}
+	}
+	t.Fatal("Expected enduser.id to be recorded")
+	return ""
+}
+
+func TestMiddleware_HashKey(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("k1"))
+	mac.Write([]byte("u-1"))
+	if got, want := hashedUserID(t, WithHashKey([]byte("k1"))), hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("Expected HMAC-SHA256 of the user ID, got %q, want %q", got, want)
+	}
+	if hashedUserID(t, WithHashKey([]byte("k1"))) == hashedUserID(t, WithHashKey([]byte("k2"))) {
+		t.Error("Expected different keys to give different values")
+	}
+
+	// Without a key, values are stable within the process but not a plain hash
+	unkeyed := hashedUserID(t)
+	if unkeyed != hashedUserID(t) {
+		t.Error("Expected the per-process key to be reused")
+	}
+	plain := sha256.Sum256([]byte("u-1"))
+	if unkeyed == hex.EncodeToString(plain[:]) {
+		t.Error("Expected the user ID not to be hashed without a key")
+	}
+}
+
+func TestMiddleware_NoToken(t *testing.T) {
+	called := false
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !called {
+		t.Error("Expected request without bearer token to pass")
+	}
+}
+
+func TestParseClaims(t *testing.T) {
+	if _, err := ParseClaims("Bearer not-a-jwt"); err == nil {
+		t.Error("Expected error for malformed token")
+	}
+	claims, err := ParseClaims(token(`{"zid":"z"}`))
+	if err != nil || claims["zid"] != "z" {
+		t.Errorf("Expected zid claim, got %v (%v)", claims, err)
+	}
+}